@@ -2,18 +2,56 @@ package ai
 
 import (
 	"context"
+	"errors"
 
 	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
 )
 
 // TODO: do we want to rename from GenerateText to Generate and from StreamText to Stream?
 
+// TextResponse is the result of a GenerateTextSteps call.
+//
+// It embeds the [api.Response] generated by the model in the final step, so
+// it can be used just like the response returned by GenerateText.
+type TextResponse struct {
+	// Response is the response generated by the model in the final step.
+	*api.Response
+
+	// Steps contains the details of every model call made during the
+	// generation, in order. It always contains at least one step.
+	Steps []*Step
+
+	// TotalUsage is the sum of the token usage across all steps.
+	TotalUsage api.Usage
+}
+
+// StreamTextResponse is the result of a StreamTextSteps call.
+//
+// It embeds an [api.StreamResponse] whose Stream yields the events of every
+// step. For multi-step generations, the intermediate finish events are
+// omitted and the final [api.FinishEvent] reports the total usage across
+// all steps.
+type StreamTextResponse struct {
+	// StreamResponse contains the stream of events across all steps. The
+	// request and response info are the ones from the first step.
+	*api.StreamResponse
+
+	// Steps contains the details of every model call made during the
+	// generation, in order. It is populated as the stream is consumed and
+	// is complete once the stream has been fully iterated.
+	Steps []*Step
+
+	// TotalUsage is the sum of the token usage across all completed steps.
+	TotalUsage api.Usage
+}
+
 // GenerateText uses a language model to generate a text response from a given prompt.
 //
 // This function does not stream its output.
 //
-// It returns a [api.Response] containing the generated text, the results of
-// any tool calls, and additional information.
+// It returns a [api.Response] containing the generated text, tool calls, and
+// additional information.
 //
 // A prompt is a sequence of [api.Message]s:
 //
@@ -34,8 +72,37 @@ import (
 // The last argument can optionally be a series of [GenerateOption] arguments:
 //
 //	GenerateText(ctx, messages, WithMaxTokens(100))
+//
+// When executable tools are provided with [WithExecutableTools], GenerateText
+// runs the tools requested by the model and calls the model again with their
+// results, until the model stops calling tools or the step limit is reached:
+//
+//	GenerateText(ctx, messages, WithExecutableTools(weatherTool), WithMaxSteps(5))
+//
+// The returned response is the one generated by the model in the final step.
+// Use [GenerateTextSteps] to also get the details of every step and the total
+// usage across all steps.
 func GenerateText(ctx context.Context, prompt []api.Message, opts ...GenerateOption) (*api.Response, error) {
-	config := buildGenerateConfig(opts)
+	resp, err := GenerateTextSteps(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response, nil
+}
+
+// GenerateTextSteps is like GenerateText, but returns a [TextResponse] that
+// also contains the details of every step of the generation and the total
+// usage across all steps:
+//
+//	resp, err := GenerateTextSteps(ctx, messages, WithExecutableTools(weatherTool))
+//	for _, step := range resp.Steps {
+//		fmt.Println(step.ToolResults)
+//	}
+func GenerateTextSteps(ctx context.Context, prompt []api.Message, opts ...GenerateOption) (*TextResponse, error) {
+	config, err := buildGenerateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 	return generate(ctx, prompt, config)
 }
 
@@ -60,8 +127,35 @@ func GenerateTextStr(ctx context.Context, prompt string, opts ...GenerateOption)
 	return GenerateText(ctx, []api.Message{msg}, opts...)
 }
 
-func generate(ctx context.Context, prompt []api.Message, opts GenerateOptions) (*api.Response, error) {
-	return opts.Model.Generate(ctx, prompt, opts.CallOptions)
+func generate(ctx context.Context, prompt []api.Message, opts GenerateOptions) (*TextResponse, error) {
+	prompt, err := downloadUnsupportedURLs(ctx, opts.Model, prompt, opts.Download)
+	if err != nil {
+		return nil, err
+	}
+
+	runner := newStepRunner(prompt, opts)
+	retrier := newRetrier(opts.MaxRetries, opts.Backoff)
+	for {
+		step := runner.newStep()
+		resp, err := retry(ctx, retrier, func() (*api.Response, error) {
+			return opts.Model.Generate(ctx, step.Prompt, step.CallOptions)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		more, err := runner.finishStep(ctx, step, resp)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return &TextResponse{
+				Response:   step.Response,
+				Steps:      runner.steps,
+				TotalUsage: runner.totalUsage,
+			}, nil
+		}
+	}
 }
 
 // StreamText uses a language model to generate a streaming text response from a given prompt.
@@ -90,8 +184,27 @@ func generate(ctx context.Context, prompt []api.Message, opts GenerateOptions) (
 // The last argument can optionally be a series of [GenerateOption] arguments:
 //
 //	StreamText(ctx, messages, WithMaxTokens(100))
+//
+// Like [GenerateText], StreamText runs a multi-step loop when executable tools
+// are provided. Only the first model call happens before StreamText returns;
+// subsequent steps run as the stream is consumed. Use [StreamTextSteps] to
+// also get the details of every step.
 func StreamText(ctx context.Context, prompt []api.Message, opts ...GenerateOption) (*api.StreamResponse, error) {
-	config := buildGenerateConfig(opts)
+	resp, err := StreamTextSteps(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.StreamResponse, nil
+}
+
+// StreamTextSteps is like StreamText, but returns a [StreamTextResponse] that
+// also contains the details of every step of the generation. The steps are
+// populated as the stream is consumed.
+func StreamTextSteps(ctx context.Context, prompt []api.Message, opts ...GenerateOption) (*StreamTextResponse, error) {
+	config, err := buildGenerateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 	return stream(ctx, prompt, config)
 }
 
@@ -116,6 +229,120 @@ func StreamTextStr(ctx context.Context, prompt string, opts ...GenerateOption) (
 	return StreamText(ctx, []api.Message{msg}, opts...)
 }
 
-func stream(ctx context.Context, prompt []api.Message, opts GenerateOptions) (*api.StreamResponse, error) {
-	return opts.Model.Stream(ctx, prompt, opts.CallOptions)
+func stream(ctx context.Context, prompt []api.Message, opts GenerateOptions) (*StreamTextResponse, error) {
+	prompt, err := downloadUnsupportedURLs(ctx, opts.Model, prompt, opts.Download)
+	if err != nil {
+		return nil, err
+	}
+
+	runner := newStepRunner(prompt, opts)
+	retrier := newRetrier(opts.MaxRetries, opts.Backoff)
+	step := runner.newStep()
+	first, err := streamWithRetries(ctx, retrier, opts.Model, step.Prompt, step.CallOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StreamTextResponse{
+		StreamResponse: &api.StreamResponse{
+			RequestInfo:  first.RequestInfo,
+			ResponseInfo: first.ResponseInfo,
+		},
+	}
+	result.Stream = func(yield func(api.StreamEvent) bool) {
+		var finish *api.FinishEvent
+		current := first
+		for {
+			resp, stepFinish, err := streamStep(current, step, yield)
+			if stepFinish != nil {
+				finish = stepFinish
+			}
+			if errors.Is(err, errStreamStopped) {
+				return
+			}
+			if err != nil {
+				break
+			}
+
+			more, err := runner.finishStep(ctx, step, resp)
+			result.Steps = runner.steps
+			result.TotalUsage = runner.totalUsage
+			if err == nil && more {
+				step = runner.newStep()
+				current, err = streamWithRetries(ctx, retrier, opts.Model, step.Prompt, step.CallOptions)
+			}
+			if err != nil {
+				if !yield(&api.ErrorEvent{Err: err}) {
+					return
+				}
+				break
+			}
+			if !more {
+				break
+			}
+		}
+
+		if finish == nil {
+			return
+		}
+		if len(runner.steps) > 1 {
+			finish = &api.FinishEvent{
+				FinishReason:     finish.FinishReason,
+				Usage:            runner.totalUsage,
+				ProviderMetadata: finish.ProviderMetadata,
+			}
+		}
+		yield(finish)
+	}
+	return result, nil
+}
+
+// errStreamStopped is returned by streamStep when the consumer stops iterating.
+var errStreamStopped = errors.New("stream stopped by consumer")
+
+// streamStep forwards the events of a single step to yield and builds the
+// step's response from them. The finish event is held back and returned
+// instead, since only one finish event is sent for the whole generation.
+//
+// Errors that occur while building the response are forwarded as error events
+// before being returned.
+func streamStep(
+	current *api.StreamResponse, step *Step, yield func(api.StreamEvent) bool,
+) (*api.Response, *api.FinishEvent, error) {
+	b := builder.NewResponseBuilder()
+	_ = b.AddMetadata(current)
+
+	var finish *api.FinishEvent
+	var buildErr error
+	for event := range current.Stream {
+		if buildErr == nil {
+			buildErr = b.AddEvent(event)
+		}
+		switch e := event.(type) {
+		case *api.FinishEvent:
+			finish = e
+			continue
+		case *api.StreamStartEvent, *api.ResponseMetadataEvent:
+			// Only the first step starts the stream, and the response
+			// metadata of later steps would conflict with the metadata of
+			// the first step. The warnings of later steps are available in
+			// the responses of their steps.
+			if step.Number > 0 {
+				continue
+			}
+		}
+		if !yield(event) {
+			return nil, finish, errStreamStopped
+		}
+	}
+
+	resp, err := b.Build()
+	if buildErr != nil {
+		if !yield(&api.ErrorEvent{Err: buildErr}) {
+			return nil, finish, errStreamStopped
+		}
+		return nil, finish, buildErr
+	}
+	// Errors reported by the stream itself were already forwarded.
+	return resp, finish, err
 }
@@ -1 +1,376 @@
 package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+	"go.jetify.com/ai/provider/mock"
+)
+
+var weatherTool = NewTool(
+	"get_weather",
+	"Get the weather for a location",
+	&jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"location": {Type: "string"},
+		},
+	},
+	func(ctx context.Context, args json.RawMessage) (any, error) {
+		var input struct {
+			Location string `json:"location"`
+		}
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, err
+		}
+		return map[string]any{"location": input.Location, "temperature": 21}, nil
+	},
+)
+
+func toolCallResponse(id string, usage api.Usage) *api.Response {
+	return &api.Response{
+		Content: []api.ContentBlock{
+			&api.TextBlock{Text: "Let me check."},
+			&api.ToolCallBlock{
+				ToolCallID: id,
+				ToolName:   "get_weather",
+				Args:       json.RawMessage(`{"location":"Paris"}`),
+			},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+		Usage:        usage,
+	}
+}
+
+func textResponse(text string, usage api.Usage) *api.Response {
+	return &api.Response{
+		Content:      []api.ContentBlock{&api.TextBlock{Text: text}},
+		FinishReason: api.FinishReasonStop,
+		Usage:        usage,
+	}
+}
+
+func userPrompt(text string) []api.Message {
+	return []api.Message{&api.UserMessage{Content: []api.ContentBlock{&api.TextBlock{Text: text}}}}
+}
+
+func TestGenerateText_SingleStep(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: textResponse("Hello", api.Usage{InputTokens: 1, OutputTokens: 2, TotalTokens: 3})},
+	})
+
+	resp, err := GenerateTextSteps(t.Context(), userPrompt("Hi"), WithModel(model))
+	require.NoError(t, err)
+
+	assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "Hello"}}, resp.Content)
+	require.Len(t, resp.Steps, 1)
+	assert.Equal(t, resp.Usage, resp.TotalUsage)
+	model.AssertCount(t)
+}
+
+func TestGenerateText_ExecutesToolsInLoop(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: toolCallResponse("call_1", api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15})},
+		{Response: textResponse("It's 21 degrees in Paris.", api.Usage{InputTokens: 20, OutputTokens: 7, TotalTokens: 27})},
+	})
+
+	var observed []int
+	resp, err := GenerateTextSteps(t.Context(), userPrompt("What's the weather in Paris?"),
+		WithModel(model),
+		WithExecutableTools(weatherTool),
+		WithOnStepFinish(func(ctx context.Context, step *Step) error {
+			observed = append(observed, step.Number)
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	model.AssertCount(t)
+
+	assert.Equal(t, "It's 21 degrees in Paris.", resp.Content[0].(*api.TextBlock).Text)
+	assert.Equal(t, api.Usage{InputTokens: 30, OutputTokens: 12, TotalTokens: 42}, resp.TotalUsage)
+	assert.Equal(t, []int{0, 1}, observed)
+
+	require.Len(t, resp.Steps, 2)
+	first := resp.Steps[0]
+	assert.Len(t, first.Prompt, 1)
+	require.Len(t, first.CallOptions.Tools, 1)
+	assert.Equal(t, weatherTool.Definition, first.CallOptions.Tools[0])
+	require.Len(t, first.ToolResults, 1)
+	assert.Equal(t, api.ToolResultBlock{
+		ToolCallID: "call_1",
+		ToolName:   "get_weather",
+		Result:     map[string]any{"location": "Paris", "temperature": 21},
+	}, first.ToolResults[0])
+
+	// The second step sends the assistant response and the tool results back.
+	second := resp.Steps[1]
+	require.Len(t, second.Prompt, 3)
+	assert.Equal(t, &api.AssistantMessage{Content: toolCallResponse("call_1", api.Usage{}).Content}, second.Prompt[1])
+	assert.Equal(t, &api.ToolMessage{Content: first.ToolResults}, second.Prompt[2])
+	assert.Empty(t, second.ToolResults)
+}
+
+func TestGenerateText_StopConditions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []GenerateOption
+		wantSteps int
+		wantErr   string
+	}{
+		{
+			name:      "max steps",
+			opts:      []GenerateOption{WithMaxSteps(2)},
+			wantSteps: 2,
+		},
+		{
+			name:      "step count",
+			opts:      []GenerateOption{WithStopWhen(StepCountIs(3))},
+			wantSteps: 3,
+		},
+		{
+			name:      "has tool call",
+			opts:      []GenerateOption{WithStopWhen(HasToolCall("get_weather"))},
+			wantSteps: 1,
+		},
+		{
+			name: "callback stops the loop",
+			opts: []GenerateOption{WithOnStepFinish(func(ctx context.Context, step *Step) error {
+				if step.Number == 1 {
+					return ErrStopSteps
+				}
+				return nil
+			})},
+			wantSteps: 2,
+		},
+		{
+			name: "callback error aborts",
+			opts: []GenerateOption{WithOnStepFinish(func(ctx context.Context, step *Step) error {
+				return errors.New("boom")
+			})},
+			wantErr: "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([]mock.MockResult, 5)
+			for i := range results {
+				results[i] = mock.MockResult{Response: toolCallResponse("call", api.Usage{})}
+			}
+			model := mock.NewGenerateModel(results)
+
+			opts := append([]GenerateOption{WithModel(model), WithExecutableTools(weatherTool)}, tt.opts...)
+			resp, err := GenerateTextSteps(t.Context(), userPrompt("Weather?"), opts...)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, resp.Steps, tt.wantSteps)
+		})
+	}
+}
+
+func TestGenerateText_ClientToolStopsLoop(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: &api.Response{
+			Content: []api.ContentBlock{
+				&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "client_side_tool", Args: json.RawMessage(`{}`)},
+			},
+			FinishReason: api.FinishReasonToolCalls,
+		}},
+	})
+
+	resp, err := GenerateTextSteps(t.Context(), userPrompt("Hi"),
+		WithModel(model),
+		WithTools(&api.FunctionTool{Name: "client_side_tool"}),
+		WithExecutableTools(weatherTool),
+	)
+	require.NoError(t, err)
+	model.AssertCount(t)
+
+	require.Len(t, resp.Steps, 1)
+	assert.Empty(t, resp.Steps[0].ToolResults)
+	assert.Equal(t, api.FinishReasonToolCalls, resp.FinishReason)
+}
+
+func TestGenerateText_UnknownToolRecovers(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: &api.Response{
+			Content: []api.ContentBlock{
+				&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_forecast", Args: json.RawMessage(`{}`)},
+			},
+			FinishReason: api.FinishReasonToolCalls,
+		}},
+		{Response: toolCallResponse("call_2", api.Usage{})},
+		{Response: textResponse("It's 21 degrees.", api.Usage{})},
+	})
+
+	resp, err := GenerateTextSteps(t.Context(), userPrompt("Weather in Paris?"), WithModel(model), WithExecutableTools(weatherTool))
+	require.NoError(t, err)
+	model.AssertCount(t)
+
+	// The call to the unknown tool is sent back to the model as an error.
+	require.Len(t, resp.Steps, 3)
+	require.Len(t, resp.Steps[0].ToolResults, 1)
+	result := resp.Steps[0].ToolResults[0]
+	assert.Equal(t, "call_1", result.ToolCallID)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Result, "get_forecast")
+	toolMessage := model.Calls()[1].Prompt[2].(*api.ToolMessage)
+	assert.Equal(t, resp.Steps[0].ToolResults, toolMessage.Content)
+
+	require.Len(t, resp.Steps[1].ToolResults, 1)
+	assert.False(t, resp.Steps[1].ToolResults[0].IsError)
+	assert.Equal(t, api.FinishReasonStop, resp.FinishReason)
+}
+
+func TestGenerateText_ModelError(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: toolCallResponse("call_1", api.Usage{})},
+		{Error: errors.New("rate limit exceeded")},
+	})
+
+	_, err := GenerateTextStr(t.Context(), "Hi", WithModel(model), WithExecutableTools(weatherTool))
+	require.ErrorContains(t, err, "rate limit exceeded")
+}
+
+func TestStreamText_ExecutesToolsInLoop(t *testing.T) {
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{ID: "resp_1"},
+				&api.TextDeltaEvent{TextDelta: "Let me check."},
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "get_weather", ArgsDelta: []byte(`{"location":"Paris"}`)},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonToolCalls,
+					Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+				},
+			},
+			{
+				&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "other", Message: "second step"}}},
+				&api.ResponseMetadataEvent{ID: "resp_2"},
+				&api.TextDeltaEvent{TextDelta: "It's 21 degrees."},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonStop,
+					Usage:        api.Usage{InputTokens: 20, OutputTokens: 7, TotalTokens: 27},
+				},
+			},
+		},
+	}
+
+	resp, err := StreamTextSteps(t.Context(), userPrompt("What's the weather in Paris?"),
+		WithModel(model), WithExecutableTools(weatherTool))
+	require.NoError(t, err)
+
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+
+	assert.Equal(t, []api.StreamEvent{
+		&api.StreamStartEvent{},
+		&api.ResponseMetadataEvent{ID: "resp_1"},
+		&api.TextDeltaEvent{TextDelta: "Let me check."},
+		&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "get_weather", ArgsDelta: []byte(`{"location":"Paris"}`)},
+		&api.TextDeltaEvent{TextDelta: "It's 21 degrees."},
+		&api.FinishEvent{
+			FinishReason: api.FinishReasonStop,
+			Usage:        api.Usage{InputTokens: 30, OutputTokens: 12, TotalTokens: 42},
+		},
+	}, events)
+
+	require.Len(t, resp.Steps, 2)
+	assert.Equal(t, []api.CallWarning{{Type: "other", Message: "second step"}}, resp.Steps[1].Response.Warnings)
+	assert.Equal(t, api.Usage{InputTokens: 30, OutputTokens: 12, TotalTokens: 42}, resp.TotalUsage)
+	require.Len(t, resp.Steps[0].ToolResults, 1)
+	assert.Equal(t, "call_1", resp.Steps[0].ToolResults[0].ToolCallID)
+	assert.Len(t, model.prompts[1], 3)
+
+	// The combined stream can still be turned into a single response.
+	_, err = builder.StreamToResponse(&api.StreamResponse{Stream: func(yield func(api.StreamEvent) bool) {
+		for _, event := range events {
+			if !yield(event) {
+				return
+			}
+		}
+	}})
+	require.NoError(t, err)
+}
+
+func TestStreamText_SingleStepPassesEventsThrough(t *testing.T) {
+	finish := &api.FinishEvent{FinishReason: api.FinishReasonStop}
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{&api.TextDeltaEvent{TextDelta: "Hello"}, finish},
+		},
+	}
+
+	resp, err := StreamTextSteps(t.Context(), userPrompt("Hi"), WithModel(model))
+	require.NoError(t, err)
+
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+	assert.Same(t, finish, events[1])
+	assert.Len(t, resp.Steps, 1)
+}
+
+func TestGenerateText_ReturnsFinalResponse(t *testing.T) {
+	final := textResponse("It's 21 degrees in Paris.", api.Usage{InputTokens: 20, OutputTokens: 7, TotalTokens: 27})
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: toolCallResponse("call_1", api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15})},
+		{Response: final},
+	})
+
+	resp, err := GenerateTextStr(t.Context(), "What's the weather in Paris?",
+		WithModel(model), WithExecutableTools(weatherTool))
+	require.NoError(t, err)
+	assert.Same(t, final, resp)
+}
+
+func TestStreamText_SetupError(t *testing.T) {
+	_, err := StreamTextStr(t.Context(), "Hi", WithModel(&streamModel{}))
+	require.Error(t, err)
+}
+
+// streamModel is a language model that returns scripted streams.
+type streamModel struct {
+	streams [][]api.StreamEvent
+	prompts [][]api.Message
+}
+
+func (m *streamModel) ProviderName() string              { return "stream-provider" }
+func (m *streamModel) ModelID() string                   { return "stream-model" }
+func (m *streamModel) SupportedUrls() []api.SupportedURL { return nil }
+
+func (m *streamModel) Generate(context.Context, []api.Message, api.CallOptions) (*api.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *streamModel) Stream(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+	if len(m.prompts) >= len(m.streams) {
+		return nil, errors.New("no more streams")
+	}
+	events := m.streams[len(m.prompts)]
+	m.prompts = append(m.prompts, prompt)
+	return &api.StreamResponse{
+		Stream: func(yield func(api.StreamEvent) bool) {
+			for _, event := range events {
+				if !yield(event) {
+					return
+				}
+			}
+		},
+	}, nil
+}
@@ -0,0 +1,678 @@
+package aitesting
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.jetify.com/ai/api"
+	"gopkg.in/yaml.v3"
+)
+
+// CassetteMode determines whether a CassetteModel records or replays calls.
+type CassetteMode string
+
+const (
+	// CassetteModeAuto replays the cassette if the file exists, and records a
+	// new cassette otherwise (default behavior).
+	CassetteModeAuto CassetteMode = ""
+	// CassetteModeReplay always replays the cassette, and fails if the file
+	// doesn't exist. The wrapped model is never called.
+	CassetteModeReplay CassetteMode = "replay"
+	// CassetteModeRecord always calls the wrapped model, and overwrites the
+	// cassette with the recorded calls.
+	CassetteModeRecord CassetteMode = "record"
+)
+
+// CassetteConfig is the configuration for a CassetteModel.
+type CassetteConfig struct {
+	// Path is the path of the cassette file. Files with a ".yaml" or ".yml"
+	// extension are stored as YAML, all others as JSON.
+	Path string
+
+	// Model is the model that is called when recording. It is optional when
+	// replaying, in which case the provider name and model ID stored in the
+	// cassette are reported instead.
+	Model api.LanguageModel
+
+	// Mode determines whether calls are recorded or replayed.
+	Mode CassetteMode
+}
+
+// CassetteModel is a language model that records the calls made to another
+// model in a cassette file, and replays them in later runs. It makes tests
+// that use real models deterministic, fast and runnable without API keys.
+//
+// Calls are matched by a hash of the prompt and the call options, excluding
+// the HTTP headers. Identical calls are replayed in the order they were
+// recorded. A call that doesn't match any recorded call fails the test and
+// returns an error.
+//
+// Responses and streams are replayed with their request body and response
+// info, without the response headers. Provider metadata is replayed as JSON
+// values, which the GetMetadata helpers of the providers convert back to
+// their types. Errors returned by a call or sent in a stream are replayed as
+// [api.APICallError]s if they were API call errors, with their status code
+// and retry headers, and with their message only otherwise.
+//
+// In record mode, the cassette is saved when Close is called. If the tester
+// has a Cleanup method, like testing.T, Close is called automatically at the
+// end of the test.
+//
+// Example:
+//
+//	model := aitesting.NewCassetteModel(t, aitesting.CassetteConfig{
+//		Path:  "testdata/weather.yaml",
+//		Model: openai.NewLanguageModel(openai.ChatModelGPT5),
+//	})
+//	resp, err := ai.GenerateTextStr(ctx, "What's the weather?", ai.WithModel(model))
+type CassetteModel struct {
+	t         T
+	model     api.LanguageModel
+	path      string
+	recording bool
+
+	mu       sync.Mutex
+	cassette cassette
+	replayed map[replayKey]int
+	closed   bool
+}
+
+var (
+	_ api.LanguageModel                = &CassetteModel{}
+	_ api.ObjectGenerationModeProvider = &CassetteModel{}
+)
+
+// NewCassetteModel creates a new CassetteModel. It fails the test if the
+// cassette can't be loaded, or if no model is provided when recording.
+func NewCassetteModel(t T, config CassetteConfig) *CassetteModel {
+	m := &CassetteModel{
+		t:        t,
+		model:    config.Model,
+		path:     config.Path,
+		replayed: map[replayKey]int{},
+	}
+
+	_, err := os.Stat(config.Path)
+	switch {
+	case config.Mode == CassetteModeRecord:
+		m.recording = true
+	case config.Mode == CassetteModeAuto && errors.Is(err, os.ErrNotExist):
+		m.recording = true
+	case err != nil:
+		t.Errorf("cassette %s: %v", config.Path, err)
+		t.FailNow()
+		return m
+	}
+
+	if m.recording {
+		if config.Model == nil {
+			t.Errorf("cassette %s: a model is required to record calls", config.Path)
+			t.FailNow()
+			return m
+		}
+		m.cassette = cassette{
+			Provider:   config.Model.ProviderName(),
+			ModelID:    config.Model.ModelID(),
+			ObjectMode: objectGenerationMode(config.Model),
+		}
+	} else if err := m.load(); err != nil {
+		t.Errorf("cassette %s: %v", config.Path, err)
+		t.FailNow()
+		return m
+	}
+
+	if cleaner, ok := t.(interface{ Cleanup(func()) }); ok {
+		cleaner.Cleanup(m.Close)
+	}
+	return m
+}
+
+func (m *CassetteModel) ProviderName() string {
+	if m.model != nil {
+		return m.model.ProviderName()
+	}
+	return m.cassette.Provider
+}
+
+func (m *CassetteModel) ModelID() string {
+	if m.model != nil {
+		return m.model.ModelID()
+	}
+	return m.cassette.ModelID
+}
+
+func (m *CassetteModel) SupportedUrls() []api.SupportedURL {
+	if m.model != nil {
+		return m.model.SupportedUrls()
+	}
+	return nil
+}
+
+// DefaultObjectGenerationMode returns the mode preferred by the wrapped model,
+// or the one stored in the cassette when replaying without a model.
+func (m *CassetteModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	if m.model != nil {
+		return objectGenerationMode(m.model)
+	}
+	return m.cassette.ObjectMode
+}
+
+func (m *CassetteModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	hash, err := callHash(prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.recording {
+		call, err := m.next(hash, callKindGenerate)
+		if err != nil {
+			return nil, err
+		}
+		if call.Error != nil {
+			return nil, call.Error.err()
+		}
+		var resp api.Response
+		if err := json.Unmarshal(call.Response, &resp); err != nil {
+			return nil, fmt.Errorf("cassette %s: invalid response: %w", m.path, err)
+		}
+		resp.RequestInfo = call.requestInfo()
+		return &resp, nil
+	}
+
+	resp, callErr := m.model.Generate(ctx, prompt, opts)
+	call, err := newRecordedCall(hash, callKindGenerate, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	if callErr != nil {
+		call.Error = newRecordedError(callErr)
+	} else {
+		if call.Response, err = marshalResponse(resp); err != nil {
+			return nil, err
+		}
+		call.setRequestInfo(resp.RequestInfo)
+	}
+	m.record(call)
+	return resp, callErr
+}
+
+func (m *CassetteModel) Stream(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+	hash, err := callHash(prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.recording {
+		call, err := m.next(hash, callKindStream)
+		if err != nil {
+			return nil, err
+		}
+		if call.Error != nil {
+			return nil, call.Error.err()
+		}
+		events, err := decodeEvents(call.Events)
+		if err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", m.path, err)
+		}
+		return &api.StreamResponse{
+			RequestInfo:  call.requestInfo(),
+			ResponseInfo: call.ResponseInfo,
+			Stream: func(yield func(api.StreamEvent) bool) {
+				for _, event := range events {
+					if !yield(event) {
+						return
+					}
+				}
+			},
+		}, nil
+	}
+
+	call, err := newRecordedCall(hash, callKindStream, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, callErr := m.model.Stream(ctx, prompt, opts)
+	if callErr != nil {
+		call.Error = newRecordedError(callErr)
+		m.record(call)
+		return nil, callErr
+	}
+	call.setRequestInfo(resp.RequestInfo)
+	call.ResponseInfo = stripResponseInfo(resp.ResponseInfo)
+
+	result := *resp
+	result.Stream = func(yield func(api.StreamEvent) bool) {
+		// The call is recorded once the stream ends, even if the consumer
+		// stops early, so that the events it saw can be replayed.
+		defer m.record(call)
+		for event := range resp.Stream {
+			call.Events = append(call.Events, encodeEvent(event))
+			if !yield(event) {
+				return
+			}
+		}
+	}
+	return &result, nil
+}
+
+// Close saves the cassette when recording. When replaying, it fails the test
+// if some of the recorded calls were not replayed. It is safe to call Close
+// more than once.
+func (m *CassetteModel) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	m.closed = true
+
+	if m.recording {
+		if err := m.save(); err != nil {
+			m.t.Errorf("cassette %s: failed to save: %v", m.path, err)
+		}
+		return
+	}
+
+	replayed := 0
+	for _, count := range m.replayed {
+		replayed += count
+	}
+	if replayed < len(m.cassette.Calls) {
+		m.t.Errorf("cassette %s: expected %d calls, received %d", m.path, len(m.cassette.Calls), replayed)
+	}
+}
+
+// replayKey identifies the recorded calls that are replayed in order: a
+// Generate and a Stream call with the same hash are matched separately.
+type replayKey struct {
+	hash string
+	kind callKind
+}
+
+// next returns the next recorded call with the given hash and kind. It fails
+// the test if there is none.
+func (m *CassetteModel) next(hash string, kind callKind) (*recordedCall, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := replayKey{hash: hash, kind: kind}
+	skip := m.replayed[key]
+	for i := range m.cassette.Calls {
+		call := &m.cassette.Calls[i]
+		if call.Hash != hash || call.Kind != kind {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		m.replayed[key]++
+		return call, nil
+	}
+
+	err := fmt.Errorf("cassette %s: no recorded %s call matches hash %s (%d calls recorded); "+
+		"delete the cassette or use CassetteModeRecord to record it again", m.path, kind, hash, len(m.cassette.Calls))
+	m.t.Errorf("%v", err)
+	return nil, err
+}
+
+func (m *CassetteModel) record(call *recordedCall) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cassette.Calls = append(m.cassette.Calls, *call)
+}
+
+func (m *CassetteModel) load() error {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return err
+	}
+	if isYAML(m.path) {
+		if data, err = yamlToJSON(data); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(data, &m.cassette); err != nil {
+		return err
+	}
+
+	// The cassette is saved indented, which also indents the JSON values
+	// nested in responses and events, like tool call arguments. Compact them
+	// so that they are replayed as they were recorded.
+	for i := range m.cassette.Calls {
+		call := &m.cassette.Calls[i]
+		if call.Response, err = compactJSON(call.Response); err != nil {
+			return err
+		}
+		if call.Request, err = compactJSON(call.Request); err != nil {
+			return err
+		}
+		for j := range call.Events {
+			if call.Events[j].Data, err = compactJSON(call.Events[j].Data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *CassetteModel) save() error {
+	data, err := json.MarshalIndent(m.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if isYAML(m.path) {
+		if data, err = jsonToYAML(data); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0o644)
+}
+
+// cassette is the content of a cassette file.
+type cassette struct {
+	Provider   string                   `json:"provider"`
+	ModelID    string                   `json:"model_id"`
+	ObjectMode api.ObjectGenerationMode `json:"object_mode,omitzero"`
+	Calls      []recordedCall           `json:"calls"`
+}
+
+type callKind string
+
+const (
+	callKindGenerate callKind = "generate"
+	callKindStream   callKind = "stream"
+)
+
+// recordedCall is a single call recorded in a cassette. The prompt and
+// options are stored for readability only: calls are matched by hash.
+type recordedCall struct {
+	Hash    string          `json:"hash"`
+	Kind    callKind        `json:"kind"`
+	Prompt  json.RawMessage `json:"prompt"`
+	Options json.RawMessage `json:"options,omitempty"`
+
+	// Request is the body of the request sent to the provider, if it is JSON.
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Response is the response of a generate call.
+	Response json.RawMessage `json:"response,omitempty"`
+
+	// ResponseInfo and Events are the response info and events of a stream
+	// call.
+	ResponseInfo *api.ResponseInfo `json:"response_info,omitempty"`
+	Events       []recordedEvent   `json:"events,omitempty"`
+
+	Error *recordedError `json:"error,omitempty"`
+}
+
+// setRequestInfo records the request body sent to the provider. Bodies that
+// are not JSON are not recorded.
+func (c *recordedCall) setRequestInfo(info *api.RequestInfo) {
+	if info != nil && json.Valid(info.Body) {
+		c.Request = info.Body
+	}
+}
+
+// requestInfo returns the recorded request info, or nil if the request body
+// was not recorded.
+func (c *recordedCall) requestInfo() *api.RequestInfo {
+	if len(c.Request) == 0 {
+		return nil
+	}
+	return &api.RequestInfo{Body: c.Request}
+}
+
+func newRecordedCall(hash string, kind callKind, prompt []api.Message, opts api.CallOptions) (*recordedCall, error) {
+	promptJSON, optsJSON, err := normalizeCall(prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &recordedCall{Hash: hash, Kind: kind, Prompt: promptJSON, Options: optsJSON}, nil
+}
+
+// recordedEvent is a stream event tagged with its type. The error of an error
+// event is also recorded in Error, so that it can be rebuilt with its type.
+type recordedEvent struct {
+	Type  api.EventType   `json:"type"`
+	Data  json.RawMessage `json:"data"`
+	Error *recordedError  `json:"error,omitempty"`
+}
+
+// recordedError is an error returned by a recorded call, or sent in an error
+// event. API call errors are recorded with their status code and retry
+// headers, so that they are replayed as [api.APICallError]s that are retried
+// like the original ones. Other errors are replayed with their message only.
+type recordedError struct {
+	Message string `json:"message"`
+
+	// Type is "api_call" for an [api.APICallError].
+	Type       string      `json:"type,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	StatusCode int         `json:"status_code,omitempty"`
+	Retryable  bool        `json:"retryable,omitempty"`
+	Headers    http.Header `json:"headers,omitempty"`
+}
+
+const recordedErrorAPICall = "api_call"
+
+// retryHeaders are the response headers recorded for API call errors, since
+// they determine the delay before a retry.
+var retryHeaders = []string{"Retry-After", "Retry-After-Ms"}
+
+func newRecordedError(err error) *recordedError {
+	recorded := &recordedError{Message: err.Error()}
+	var callErr *api.APICallError
+	if !errors.As(err, &callErr) {
+		return recorded
+	}
+
+	recorded.Type = recordedErrorAPICall
+	recorded.StatusCode = callErr.StatusCode
+	recorded.Retryable = callErr.IsRetryable()
+	if callErr.URL != nil {
+		recorded.URL = callErr.URL.String()
+	}
+	if callErr.Response != nil {
+		for _, name := range retryHeaders {
+			if value := callErr.Response.Header.Get(name); value != "" {
+				if recorded.Headers == nil {
+					recorded.Headers = http.Header{}
+				}
+				recorded.Headers.Set(name, value)
+			}
+		}
+	}
+	return recorded
+}
+
+// err rebuilds the recorded error.
+func (r *recordedError) err() error {
+	if r.Type != recordedErrorAPICall {
+		return errors.New(r.Message)
+	}
+	var req *http.Request
+	if r.URL != "" {
+		if u, err := url.Parse(r.URL); err == nil {
+			req = &http.Request{Method: http.MethodPost, URL: u}
+		}
+	}
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode: r.StatusCode,
+		Header:     r.Headers,
+		Body:       http.NoBody,
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	return api.NewAPICallError(r.Message, req, resp, nil)
+}
+
+// UnmarshalJSON also accepts errors recorded as a plain message by earlier
+// versions of the cassette format.
+func (r *recordedError) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		*r = recordedError{Message: message}
+		return nil
+	}
+	type plain recordedError
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// callHash returns a hash of the normalized prompt and call options.
+func callHash(prompt []api.Message, opts api.CallOptions) (string, error) {
+	promptJSON, optsJSON, err := normalizeCall(prompt, opts)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write(promptJSON)
+	hash.Write([]byte{0})
+	hash.Write(optsJSON)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// normalizeCall returns the canonical JSON of the prompt and the call
+// options, with sorted keys and without the HTTP headers, which often contain
+// credentials or values that change between runs.
+func normalizeCall(prompt []api.Message, opts api.CallOptions) (json.RawMessage, json.RawMessage, error) {
+	opts.Headers = nil
+	promptJSON, err := canonicalJSON(prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode prompt: %w", err)
+	}
+	optsJSON, err := canonicalJSON(&opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode call options: %w", err)
+	}
+	return promptJSON, optsJSON, nil
+}
+
+// canonicalJSON encodes v as JSON with the object keys sorted.
+func canonicalJSON(v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// marshalResponse encodes the response without the raw request and response
+// bodies and headers, which are large and may contain credentials.
+func marshalResponse(resp *api.Response) (json.RawMessage, error) {
+	if resp == nil {
+		return json.RawMessage("null"), nil
+	}
+	stripped := *resp
+	stripped.RequestInfo = nil
+	stripped.ResponseInfo = stripResponseInfo(resp.ResponseInfo)
+	return json.Marshal(&stripped)
+}
+
+// stripResponseInfo returns a copy of the response info without the raw
+// response body and headers.
+func stripResponseInfo(info *api.ResponseInfo) *api.ResponseInfo {
+	if info == nil {
+		return nil
+	}
+	stripped := *info
+	stripped.Headers = nil
+	stripped.Body = nil
+	return &stripped
+}
+
+func encodeEvent(event api.StreamEvent) recordedEvent {
+	data, err := json.Marshal(event)
+	if err != nil {
+		data, _ = json.Marshal(&api.ErrorEvent{Err: err})
+		return recordedEvent{Type: api.EventError, Data: data}
+	}
+	recorded := recordedEvent{Type: event.Type(), Data: data}
+	if errEvent, ok := event.(*api.ErrorEvent); ok {
+		if err, ok := errEvent.Err.(error); ok {
+			recorded.Error = newRecordedError(err)
+		}
+	}
+	return recorded
+}
+
+func decodeEvents(recorded []recordedEvent) ([]api.StreamEvent, error) {
+	events := make([]api.StreamEvent, 0, len(recorded))
+	for i, r := range recorded {
+		event, err := decodeEvent(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event at index %d: %w", i, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func decodeEvent(r recordedEvent) (api.StreamEvent, error) {
+	if r.Type == api.EventError && r.Error != nil {
+		return &api.ErrorEvent{Err: r.Error.err()}, nil
+	}
+	return api.UnmarshalStreamEvent(r.Type, r.Data)
+}
+
+func compactJSON(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func objectGenerationMode(model api.LanguageModel) api.ObjectGenerationMode {
+	if provider, ok := model.(api.ObjectGenerationModeProvider); ok {
+		return provider.DefaultObjectGenerationMode()
+	}
+	return api.ObjectGenerationModeNone
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// jsonToYAML converts a JSON document to YAML, so that the JSON encoding of
+// the api types is preserved.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+// yamlToJSON converts a YAML document to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var generic any
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
@@ -0,0 +1,326 @@
+package aitesting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+// echoModel answers every call with the text of the last user message, and
+// counts the calls it receives.
+type echoModel struct {
+	calls int
+	err   error
+}
+
+func (m *echoModel) ProviderName() string              { return "echo" }
+func (m *echoModel) ModelID() string                   { return "echo-1" }
+func (m *echoModel) SupportedUrls() []api.SupportedURL { return nil }
+func (m *echoModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	return api.ObjectGenerationModeTool
+}
+
+// echoMetadata is the provider metadata returned by echoModel.
+type echoMetadata struct {
+	RequestID string `json:"request_id"`
+	Cached    bool   `json:"cached,omitempty"`
+}
+
+func echoProviderMetadata() *api.ProviderMetadata {
+	return api.NewProviderMetadata(map[string]any{"echo": &echoMetadata{RequestID: "req-1", Cached: true}})
+}
+
+func echoRequestInfo(prompt []api.Message) *api.RequestInfo {
+	return &api.RequestInfo{Body: []byte(`{"input":"` + lastUserText(prompt) + `"}`)}
+}
+
+func (m *echoModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &api.Response{
+		Content:          []api.ContentBlock{&api.TextBlock{Text: lastUserText(prompt)}},
+		FinishReason:     api.FinishReasonStop,
+		Usage:            api.Usage{InputTokens: 3, OutputTokens: 2},
+		ProviderMetadata: echoProviderMetadata(),
+		RequestInfo:      echoRequestInfo(prompt),
+		ResponseInfo: &api.ResponseInfo{
+			ID:        "resp-1",
+			Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Headers:   http.Header{"Openai-Organization": []string{"secret"}},
+		},
+	}, nil
+}
+
+func (m *echoModel) Stream(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	events := []api.StreamEvent{
+		&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}}},
+		&api.RawChunkEvent{Data: json.RawMessage(`{"id":"resp-1"}`)},
+		&api.ResponseMetadataEvent{ID: "resp-1"},
+		&api.TextDeltaEvent{TextDelta: lastUserText(prompt)},
+		&api.ToolCallEvent{ToolCallID: "call-1", ToolName: "lookup", Args: json.RawMessage(`{"q":"x"}`)},
+		&api.ErrorEvent{Err: errors.New("stream failed")},
+		&api.ErrorEvent{Err: overloadedError()},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop, Usage: api.Usage{OutputTokens: 2}, ProviderMetadata: echoProviderMetadata()},
+	}
+	return &api.StreamResponse{
+		RequestInfo: echoRequestInfo(prompt),
+		ResponseInfo: &api.ResponseInfo{
+			ID:      "resp-1",
+			Status:  "200 OK",
+			Headers: http.Header{"Openai-Organization": []string{"secret"}},
+		},
+		Stream: func(yield func(api.StreamEvent) bool) {
+			for _, event := range events {
+				if !yield(event) {
+					return
+				}
+			}
+		},
+	}, nil
+}
+
+// overloadedError returns a retryable API call error.
+func overloadedError() error {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/messages", nil)
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"3"}, "X-Request-Id": []string{"req-2"}},
+	}
+	return api.NewAPICallError("Overloaded", req, resp, nil)
+}
+
+// assertAPICallError checks that err is the API call error returned by
+// overloadedError.
+func assertAPICallError(t *testing.T, err error) {
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	assert.Equal(t, "Overloaded", callErr.Error())
+	assert.Equal(t, http.StatusServiceUnavailable, callErr.StatusCode)
+	assert.True(t, callErr.IsRetryable())
+	assert.Equal(t, "https://api.example.com/v1/messages", callErr.URL.String())
+	assert.Equal(t, "3", callErr.Response.Header.Get("Retry-After"))
+}
+
+func lastUserText(prompt []api.Message) string {
+	for i := len(prompt) - 1; i >= 0; i-- {
+		if msg, ok := prompt[i].(*api.UserMessage); ok {
+			if text, ok := msg.Content[0].(*api.TextBlock); ok {
+				return text.Text
+			}
+		}
+	}
+	return ""
+}
+
+func userPrompt(text string) []api.Message {
+	return []api.Message{&api.UserMessage{Content: []api.ContentBlock{&api.TextBlock{Text: text}}}}
+}
+
+func TestCassetteModel_RecordAndReplay(t *testing.T) {
+	for _, name := range []string{"cassette.json", "cassette.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixtures", name)
+			temperature := 0.5
+			opts := api.CallOptions{Temperature: &temperature}
+
+			// Record
+			model := &echoModel{}
+			recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: model})
+			recorded, err := recorder.Generate(t.Context(), userPrompt("hello"), opts)
+			require.NoError(t, err)
+			recordedStream, err := recorder.Stream(t.Context(), userPrompt("stream"), opts)
+			require.NoError(t, err)
+			recordedEvents := CollectEvents(recordedStream)
+			recorder.Close()
+			require.Equal(t, 2, model.calls)
+			require.FileExists(t, path)
+
+			// Replay without a model
+			mt := &mockT{}
+			replayer := NewCassetteModel(mt, CassetteConfig{Path: path})
+			assert.Equal(t, "echo", replayer.ProviderName())
+			assert.Equal(t, "echo-1", replayer.ModelID())
+			assert.Equal(t, api.ObjectGenerationModeTool, replayer.DefaultObjectGenerationMode())
+
+			// Headers are not part of the hash.
+			opts.Headers = http.Header{"Authorization": []string{"Bearer other"}}
+			replayed, err := replayer.Generate(t.Context(), userPrompt("hello"), opts)
+			require.NoError(t, err)
+			assert.Equal(t, recorded.Content, replayed.Content)
+			assert.Equal(t, recorded.Usage, replayed.Usage)
+			assert.Equal(t, recorded.ResponseInfo.Timestamp, replayed.ResponseInfo.Timestamp)
+			assert.Nil(t, replayed.ResponseInfo.Headers)
+			assert.Equal(t, recorded.RequestInfo, replayed.RequestInfo)
+			assert.Equal(t, &echoMetadata{RequestID: "req-1", Cached: true}, api.GetMetadata[echoMetadata]("echo", replayed))
+
+			replayedStream, err := replayer.Stream(t.Context(), userPrompt("stream"), opts)
+			require.NoError(t, err)
+			assert.Equal(t, recordedStream.RequestInfo, replayedStream.RequestInfo)
+			assert.Equal(t, &api.ResponseInfo{ID: "resp-1", Status: "200 OK"}, replayedStream.ResponseInfo)
+			replayedEvents := CollectEvents(replayedStream)
+			require.Len(t, replayedEvents, len(recordedEvents))
+			for i := range recordedEvents {
+				switch e := recordedEvents[i].(type) {
+				case *api.ErrorEvent:
+					assert.EqualError(t, replayedEvents[i].(*api.ErrorEvent), e.Error())
+				case *api.FinishEvent:
+					finish := replayedEvents[i].(*api.FinishEvent)
+					assert.Equal(t, e.Usage, finish.Usage)
+					assert.Equal(t, api.GetMetadata[echoMetadata]("echo", e), api.GetMetadata[echoMetadata]("echo", finish))
+				default:
+					assert.Equal(t, recordedEvents[i], replayedEvents[i])
+				}
+			}
+			assertAPICallError(t, replayedEvents[len(replayedEvents)-2].(*api.ErrorEvent).Unwrap())
+
+			replayer.Close()
+			assert.False(t, mt.failed, mt.errors)
+		})
+	}
+}
+
+func TestCassetteModel_ReplaysMixedKinds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	prompt := userPrompt("hello")
+
+	recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: &echoModel{}})
+	_, err := recorder.Generate(t.Context(), prompt, api.CallOptions{})
+	require.NoError(t, err)
+	stream, err := recorder.Stream(t.Context(), prompt, api.CallOptions{})
+	require.NoError(t, err)
+	CollectEvents(stream)
+	recorder.Close()
+
+	// The Generate and Stream calls have the same hash, but are replayed
+	// separately.
+	mt := &mockT{}
+	replayer := NewCassetteModel(mt, CassetteConfig{Path: path})
+	resp, err := replayer.Generate(t.Context(), prompt, api.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "hello"}}, resp.Content)
+	stream, err = replayer.Stream(t.Context(), prompt, api.CallOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, CollectEvents(stream))
+
+	replayer.Close()
+	assert.False(t, mt.failed, mt.errors)
+}
+
+func TestCassetteModel_UnmatchedCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: &echoModel{}})
+	_, err := recorder.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.NoError(t, err)
+	recorder.Close()
+
+	mt := &mockT{}
+	replayer := NewCassetteModel(mt, CassetteConfig{Path: path, Mode: CassetteModeReplay})
+
+	// Different prompt
+	_, err = replayer.Generate(t.Context(), userPrompt("goodbye"), api.CallOptions{})
+	require.Error(t, err)
+	assert.True(t, mt.failed)
+
+	// Same prompt, but streamed
+	_, err = replayer.Stream(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.Error(t, err)
+
+	// Identical calls are only replayed as many times as they were recorded.
+	_, err = replayer.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.NoError(t, err)
+	_, err = replayer.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.Error(t, err)
+}
+
+func TestCassetteModel_UnusedCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: &echoModel{}})
+	_, err := recorder.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.NoError(t, err)
+	recorder.Close()
+
+	mt := &mockT{}
+	replayer := NewCassetteModel(mt, CassetteConfig{Path: path})
+	replayer.Close()
+	assert.True(t, mt.failed)
+}
+
+func TestCassetteModel_RecordsErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: &echoModel{err: errors.New("rate limited")}})
+	_, err := recorder.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.EqualError(t, err, "rate limited")
+	recorder.Close()
+
+	replayer := NewCassetteModel(&mockT{}, CassetteConfig{Path: path})
+	_, err = replayer.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.EqualError(t, err, "rate limited")
+}
+
+func TestCassetteModel_RecordsAPICallErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: &echoModel{err: overloadedError()}})
+	_, err := recorder.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	assertAPICallError(t, err)
+	_, err = recorder.Stream(t.Context(), userPrompt("stream"), api.CallOptions{})
+	assertAPICallError(t, err)
+	recorder.Close()
+
+	replayer := NewCassetteModel(&mockT{}, CassetteConfig{Path: path})
+	_, err = replayer.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	assertAPICallError(t, err)
+	_, err = replayer.Stream(t.Context(), userPrompt("stream"), api.CallOptions{})
+	assertAPICallError(t, err)
+
+	// Only the headers that affect retries are recorded.
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	assert.Empty(t, callErr.Response.Header.Get("X-Request-Id"))
+}
+
+func TestCassetteModel_ReplaysPlainErrorMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	hash, err := callHash(userPrompt("hello"), api.CallOptions{})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(`{"provider": "echo", "model_id": "echo-1", "calls": [
+		{"hash": "`+hash+`", "kind": "generate", "prompt": [], "error": "rate limited"}
+	]}`), 0o644))
+
+	replayer := NewCassetteModel(&mockT{}, CassetteConfig{Path: path})
+	_, err = replayer.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.EqualError(t, err, "rate limited")
+}
+
+func TestCassetteModel_Modes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	mt := &mockT{}
+	NewCassetteModel(mt, CassetteConfig{Path: path, Mode: CassetteModeReplay})
+	assert.True(t, mt.failNowed, "replaying a missing cassette should fail")
+
+	mt = &mockT{}
+	NewCassetteModel(mt, CassetteConfig{Path: path})
+	assert.True(t, mt.failNowed, "recording without a model should fail")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"provider": "echo", "model_id": "echo-1", "calls": []}`), 0o644))
+	model := &echoModel{}
+	recorder := NewCassetteModel(&mockT{}, CassetteConfig{Path: path, Model: model, Mode: CassetteModeRecord})
+	_, err := recorder.Generate(t.Context(), userPrompt("hello"), api.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, model.calls, "record mode should call the model even if the cassette exists")
+}
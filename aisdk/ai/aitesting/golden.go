@@ -0,0 +1,211 @@
+package aitesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.jetify.com/ai/api"
+)
+
+// UpdateGoldenEnv is the environment variable that makes GoldenResponse and
+// GoldenEvents rewrite the golden files with the actual values instead of
+// comparing against them:
+//
+//	UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// normalizedTimestamp replaces the timestamps of normalized responses and
+// events.
+var normalizedTimestamp = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// GoldenResponse compares a response with the snapshot stored in a golden
+// file, and reports a failure with a diff if they differ.
+//
+// The response is normalized with NormalizeResponse before it is compared, so
+// that IDs and timestamps don't change the snapshot between runs. The raw
+// request and response bodies and headers are not stored.
+//
+// Files with a ".yaml" or ".yml" extension are stored as YAML, all others as
+// JSON. A missing golden file fails the test, like an outdated one: golden
+// files are created or rewritten from the actual response when the
+// UPDATE_GOLDEN environment variable is set.
+func GoldenResponse(testingT T, path string, resp *api.Response) {
+	data, err := marshalResponse(NormalizeResponse(resp))
+	if err != nil {
+		testingT.Errorf("golden %s: failed to encode response: %v", path, err)
+		return
+	}
+	matchGolden(testingT, path, data)
+}
+
+// GoldenEvents compares a sequence of stream events with the snapshot stored
+// in a golden file, and reports a failure with a diff if they differ. Events
+// are normalized with NormalizeEvents, and the golden file is handled as in
+// GoldenResponse.
+func GoldenEvents(testingT T, path string, events []api.StreamEvent) {
+	normalized := NormalizeEvents(events)
+	recorded := make([]recordedEvent, len(normalized))
+	for i, event := range normalized {
+		recorded[i] = encodeEvent(event)
+	}
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		testingT.Errorf("golden %s: failed to encode events: %v", path, err)
+		return
+	}
+	matchGolden(testingT, path, data)
+}
+
+// matchGolden compares JSON data with the content of a golden file, or writes
+// the file if UPDATE_GOLDEN is set.
+func matchGolden(testingT T, path string, data json.RawMessage) {
+	actual, err := formatGolden(path, data)
+	if err != nil {
+		testingT.Errorf("golden %s: %v", path, err)
+		return
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := writeGolden(path, actual); err != nil {
+			testingT.Errorf("golden %s: failed to save: %v", path, err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		testingT.Errorf("golden %s is missing; run the test with %s=1 to create it", path, UpdateGoldenEnv)
+		return
+	}
+	if err != nil {
+		testingT.Errorf("golden %s: %v", path, err)
+		return
+	}
+
+	assert.Equal(testingT, string(expected), string(actual),
+		fmt.Sprintf("golden %s is out of date; run the test with %s=1 to update it", path, UpdateGoldenEnv))
+}
+
+// formatGolden formats JSON data as it is stored in a golden file.
+func formatGolden(path string, data json.RawMessage) ([]byte, error) {
+	if isYAML(path) {
+		return jsonToYAML(data)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeGolden(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// NormalizeResponse returns a copy of the response with its volatile fields
+// replaced by stable values: the response, tool call and source IDs are
+// replaced by "id-1", "id-2", ... in order of appearance, and the timestamp by
+// a fixed time. Identical IDs are replaced by the same value, so that
+// references between them are preserved.
+func NormalizeResponse(resp *api.Response) *api.Response {
+	if resp == nil {
+		return nil
+	}
+	n := &normalizer{ids: map[string]string{}}
+	normalized := *resp
+	if resp.ResponseInfo != nil {
+		info := *resp.ResponseInfo
+		info.ID = n.id(info.ID)
+		info.Timestamp = n.timestamp(info.Timestamp)
+		normalized.ResponseInfo = &info
+	}
+	if resp.Content != nil {
+		normalized.Content = make([]api.ContentBlock, len(resp.Content))
+		for i, block := range resp.Content {
+			normalized.Content[i] = n.block(block)
+		}
+	}
+	return &normalized
+}
+
+// NormalizeEvents returns copies of the events with their volatile fields
+// replaced by stable values, as in NormalizeResponse. IDs are replaced
+// consistently across the events, so that the deltas of a tool call keep
+// referring to the same call.
+func NormalizeEvents(events []api.StreamEvent) []api.StreamEvent {
+	n := &normalizer{ids: map[string]string{}}
+	normalized := make([]api.StreamEvent, len(events))
+	for i, event := range events {
+		normalized[i] = n.event(event)
+	}
+	return normalized
+}
+
+// normalizer replaces IDs by stable placeholders.
+type normalizer struct {
+	ids map[string]string
+}
+
+func (n *normalizer) id(id string) string {
+	if id == "" {
+		return ""
+	}
+	if placeholder, ok := n.ids[id]; ok {
+		return placeholder
+	}
+	placeholder := fmt.Sprintf("id-%d", len(n.ids)+1)
+	n.ids[id] = placeholder
+	return placeholder
+}
+
+func (n *normalizer) timestamp(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return normalizedTimestamp
+}
+
+func (n *normalizer) block(block api.ContentBlock) api.ContentBlock {
+	switch b := block.(type) {
+	case *api.ToolCallBlock:
+		normalized := *b
+		normalized.ToolCallID = n.id(b.ToolCallID)
+		return &normalized
+	case *api.SourceBlock:
+		normalized := *b
+		normalized.ID = n.id(b.ID)
+		return &normalized
+	}
+	return block
+}
+
+func (n *normalizer) event(event api.StreamEvent) api.StreamEvent {
+	switch e := event.(type) {
+	case *api.ResponseMetadataEvent:
+		normalized := *e
+		normalized.ID = n.id(e.ID)
+		normalized.Timestamp = n.timestamp(e.Timestamp)
+		return &normalized
+	case *api.ToolCallEvent:
+		normalized := *e
+		normalized.ToolCallID = n.id(e.ToolCallID)
+		return &normalized
+	case *api.ToolCallDeltaEvent:
+		normalized := *e
+		normalized.ToolCallID = n.id(e.ToolCallID)
+		return &normalized
+	case *api.SourceEvent:
+		normalized := *e
+		normalized.Source.ID = n.id(e.Source.ID)
+		return &normalized
+	}
+	return event
+}
@@ -0,0 +1,121 @@
+package aitesting
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestGoldenEvents(t *testing.T) {
+	GoldenEvents(t, "testdata/golden/tool_call_events.yaml", toolCallStream)
+
+	// The IDs of another run don't change the snapshot.
+	events := slices.Clone(toolCallStream)
+	events[1] = &api.ResponseMetadataEvent{ID: "resp_other", ModelID: "gpt-5"}
+	for i, event := range events {
+		switch e := event.(type) {
+		case *api.ToolCallDeltaEvent:
+			delta := *e
+			delta.ToolCallID = "call_other"
+			events[i] = &delta
+		case *api.ToolCallEvent:
+			call := *e
+			call.ToolCallID = "call_other"
+			events[i] = &call
+		}
+	}
+	mock := &mockT{}
+	GoldenEvents(mock, "testdata/golden/tool_call_events.yaml", events)
+	assert.False(t, mock.failed, "errors: %v", mock.errors)
+}
+
+func TestGoldenResponse(t *testing.T) {
+	response := func(responseID, callID string, text string) *api.Response {
+		return &api.Response{
+			Content: []api.ContentBlock{
+				&api.TextBlock{Text: text},
+				&api.ToolCallBlock{ToolCallID: callID, ToolName: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+			},
+			FinishReason: api.FinishReasonToolCalls,
+			Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+			ResponseInfo: &api.ResponseInfo{
+				ID:        responseID,
+				ModelID:   "gpt-5",
+				Timestamp: time.Now(),
+				Headers:   map[string][]string{"Authorization": {"secret"}},
+			},
+		}
+	}
+
+	for _, name := range []string{"response.json", "response.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "golden", name)
+
+			// A missing golden file fails the test.
+			mock := &mockT{}
+			GoldenResponse(mock, path, response("resp_1", "call_1", "Let me check."))
+			assert.True(t, mock.failed)
+			assert.NoFileExists(t, path)
+
+			// Unless it is created.
+			t.Setenv(UpdateGoldenEnv, "1")
+			GoldenResponse(t, path, response("resp_1", "call_1", "Let me check."))
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "resp_1")
+			assert.NotContains(t, string(data), "secret")
+			t.Setenv(UpdateGoldenEnv, "")
+
+			// Volatile fields are normalized.
+			mock = &mockT{}
+			GoldenResponse(mock, path, response("resp_2", "call_2", "Let me check."))
+			assert.False(t, mock.failed, "errors: %v", mock.errors)
+
+			// Other changes fail the test.
+			mock = &mockT{}
+			GoldenResponse(mock, path, response("resp_2", "call_2", "Checking."))
+			assert.True(t, mock.failed)
+
+			// Unless the golden file is updated.
+			t.Setenv(UpdateGoldenEnv, "1")
+			mock = &mockT{}
+			GoldenResponse(mock, path, response("resp_2", "call_2", "Checking."))
+			assert.False(t, mock.failed, "errors: %v", mock.errors)
+			updated, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(updated), "Checking.")
+		})
+	}
+}
+
+func TestNormalizeEvents(t *testing.T) {
+	timestamp := time.Date(2025, time.March, 4, 12, 0, 0, 0, time.UTC)
+	events := []api.StreamEvent{
+		&api.ResponseMetadataEvent{ID: "resp_123", ModelID: "gpt-5", Timestamp: timestamp},
+		&api.ToolCallDeltaEvent{ToolCallID: "call_a", ToolName: "search", ArgsDelta: []byte(`{}`)},
+		&api.ToolCallDeltaEvent{ToolCallID: "call_b", ToolName: "search", ArgsDelta: []byte(`{}`)},
+		&api.ToolCallEvent{ToolCallID: "call_a", ToolName: "search", Args: json.RawMessage(`{}`)},
+		&api.SourceEvent{Source: api.Source{SourceType: "url", ID: "src_1", URL: "https://example.com"}},
+		&api.TextDeltaEvent{TextDelta: "Hi"},
+	}
+
+	assert.Equal(t, []api.StreamEvent{
+		&api.ResponseMetadataEvent{ID: "id-1", ModelID: "gpt-5", Timestamp: normalizedTimestamp},
+		&api.ToolCallDeltaEvent{ToolCallID: "id-2", ToolName: "search", ArgsDelta: []byte(`{}`)},
+		&api.ToolCallDeltaEvent{ToolCallID: "id-3", ToolName: "search", ArgsDelta: []byte(`{}`)},
+		&api.ToolCallEvent{ToolCallID: "id-2", ToolName: "search", Args: json.RawMessage(`{}`)},
+		&api.SourceEvent{Source: api.Source{SourceType: "url", ID: "id-4", URL: "https://example.com"}},
+		&api.TextDeltaEvent{TextDelta: "Hi"},
+	}, NormalizeEvents(events))
+
+	// The original events are not modified.
+	assert.Equal(t, "resp_123", events[0].(*api.ResponseMetadataEvent).ID)
+	assert.Equal(t, "call_a", events[3].(*api.ToolCallEvent).ToolCallID)
+}
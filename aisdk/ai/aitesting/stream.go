@@ -0,0 +1,179 @@
+package aitesting
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+)
+
+// CollectEvents consumes the stream of a response and returns its events.
+func CollectEvents(resp *api.StreamResponse) []api.StreamEvent {
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+	return events
+}
+
+// EventTypes returns the types of the events, in order. It is useful to check
+// the shape of a stream without spelling out every event:
+//
+//	assert.Equal(t, []api.EventType{
+//		api.EventStreamStart,
+//		api.EventTextDelta,
+//		api.EventTextDelta,
+//		api.EventFinish,
+//	}, aitesting.EventTypes(events))
+func EventTypes(events []api.StreamEvent) []api.EventType {
+	types := make([]api.EventType, len(events))
+	for i, event := range events {
+		if event != nil {
+			types[i] = event.Type()
+		}
+	}
+	return types
+}
+
+// StreamValid checks that a sequence of stream events follows the ordering
+// rules that consumers of a stream rely on, and reports a failure for each
+// violation. It returns true if the stream is valid.
+//
+// The rules are:
+//   - No event is nil.
+//   - There is at most one StreamStartEvent, and it is the first event.
+//   - There is exactly one FinishEvent, and it is the last event. Streams that
+//     contain an ErrorEvent may end without a FinishEvent.
+//   - There is at most one ToolCallEvent per tool call ID, and no
+//     ToolCallDeltaEvent for a tool call follows its ToolCallEvent.
+//   - The arguments of every ToolCallEvent are valid JSON, or empty.
+func StreamValid(testingT T, events []api.StreamEvent) bool {
+	valid := true
+	fail := func(format string, args ...any) {
+		assert.Fail(testingT, "Invalid stream", fmt.Sprintf(format, args...))
+		valid = false
+	}
+
+	finishes := 0
+	hasError := false
+	completedCalls := map[string]bool{}
+	for i, event := range events {
+		switch e := event.(type) {
+		case nil:
+			fail("Event at index %d is nil", i)
+		case *api.StreamStartEvent:
+			if i != 0 {
+				fail("StreamStartEvent at index %d must be the first event", i)
+			}
+		case *api.FinishEvent:
+			finishes++
+			if i != len(events)-1 {
+				fail("FinishEvent at index %d must be the last event, but the stream has %d events", i, len(events))
+			}
+		case *api.ErrorEvent:
+			hasError = true
+		case *api.ToolCallDeltaEvent:
+			if completedCalls[e.ToolCallID] {
+				fail("ToolCallDeltaEvent at index %d follows the ToolCallEvent for tool call %q", i, e.ToolCallID)
+			}
+		case *api.ToolCallEvent:
+			if completedCalls[e.ToolCallID] {
+				fail("Duplicate ToolCallEvent at index %d for tool call %q", i, e.ToolCallID)
+			}
+			completedCalls[e.ToolCallID] = true
+			if len(e.Args) > 0 && !json.Valid(e.Args) {
+				fail("ToolCallEvent at index %d has invalid JSON arguments: %s", i, e.Args)
+			}
+		}
+	}
+
+	switch {
+	case finishes > 1:
+		fail("Expected exactly one FinishEvent, got %d", finishes)
+	case finishes == 0 && !hasError:
+		fail("Expected exactly one FinishEvent, got none")
+	}
+	return valid
+}
+
+// StreamContains builds a response from the stream events, accumulating the
+// text, reasoning and tool call argument deltas, and checks that it contains
+// the expected fields using the same partial matching as ResponseContains:
+//
+//	aitesting.StreamContains(t, &api.Response{
+//		Content: []api.ContentBlock{
+//			&api.ToolCallBlock{ToolName: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+//		},
+//		FinishReason: api.FinishReasonToolCalls,
+//	}, events)
+func StreamContains(testingT T, expected *api.Response, events []api.StreamEvent) {
+	resp, err := buildResponse(events)
+	if err != nil {
+		assert.Fail(testingT, "Failed to build response from stream", "%v", err)
+		return
+	}
+	ResponseContains(testingT, expected, resp)
+}
+
+// StreamMatchesResponse checks that the stream events are equivalent to a
+// response returned by Generate for the same call: the response built from
+// the events with a [builder.ResponseBuilder] must have the same content,
+// finish reason, usage and warnings.
+//
+// Tool call arguments are compared as JSON, since streamed arguments are often
+// formatted differently. Request and response info and provider metadata are
+// not compared, since they legitimately differ between the two APIs.
+func StreamMatchesResponse(testingT T, generated *api.Response, events []api.StreamEvent) {
+	streamed, err := buildResponse(events)
+	if err != nil {
+		assert.Fail(testingT, "Failed to build response from stream", "%v", err)
+		return
+	}
+
+	if assert.Len(testingT, streamed.Content, len(generated.Content), "Content length mismatch") {
+		for i, expected := range generated.Content {
+			actual := streamed.Content[i]
+			expectedCall, ok := expected.(*api.ToolCallBlock)
+			if !ok {
+				assert.Equal(testingT, expected, actual, "Content block mismatch at index %d", i)
+				continue
+			}
+			actualCall, ok := actual.(*api.ToolCallBlock)
+			if !ok {
+				assert.Fail(testingT, "Content block mismatch",
+					"Content block at index %d: expected %T, got %T", i, expected, actual)
+				continue
+			}
+			assert.Equal(testingT, expectedCall.ToolCallID, actualCall.ToolCallID, "ToolCallBlock.ToolCallID mismatch at index %d", i)
+			assert.Equal(testingT, expectedCall.ToolName, actualCall.ToolName, "ToolCallBlock.ToolName mismatch at index %d", i)
+			assert.JSONEq(testingT, jsonArgs(expectedCall.Args), jsonArgs(actualCall.Args), "ToolCallBlock.Args mismatch at index %d", i)
+		}
+	}
+	assert.Equal(testingT, generated.FinishReason, streamed.FinishReason, "FinishReason mismatch")
+	assert.Equal(testingT, generated.Usage, streamed.Usage, "Usage mismatch")
+	if len(generated.Warnings) > 0 || len(streamed.Warnings) > 0 {
+		assert.Equal(testingT, generated.Warnings, streamed.Warnings, "Warnings mismatch")
+	}
+}
+
+// buildResponse builds a response from stream events.
+func buildResponse(events []api.StreamEvent) (*api.Response, error) {
+	b := builder.NewResponseBuilder()
+	for i, event := range events {
+		if err := b.AddEvent(event); err != nil {
+			return nil, fmt.Errorf("event at index %d: %w", i, err)
+		}
+	}
+	return b.Build()
+}
+
+// jsonArgs returns the arguments of a tool call, treating empty arguments as
+// an empty object.
+func jsonArgs(args json.RawMessage) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	return string(args)
+}
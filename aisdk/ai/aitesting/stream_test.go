@@ -0,0 +1,237 @@
+package aitesting
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.jetify.com/ai/api"
+)
+
+var toolCallStream = []api.StreamEvent{
+	&api.StreamStartEvent{},
+	&api.ResponseMetadataEvent{ID: "resp_123", ModelID: "gpt-5"},
+	&api.TextDeltaEvent{TextDelta: "Let me "},
+	&api.TextDeltaEvent{TextDelta: "check."},
+	&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "get_weather", ArgsDelta: []byte(`{"city":`)},
+	&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "get_weather", ArgsDelta: []byte(`"Paris"}`)},
+	&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+	&api.FinishEvent{
+		FinishReason: api.FinishReasonToolCalls,
+		Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	},
+}
+
+func TestEventTypes(t *testing.T) {
+	assert.Equal(t, []api.EventType{
+		api.EventStreamStart,
+		api.EventResponseMetadata,
+		api.EventTextDelta,
+		api.EventTextDelta,
+		api.EventToolCallDelta,
+		api.EventToolCallDelta,
+		api.EventToolCall,
+		api.EventFinish,
+	}, EventTypes(toolCallStream))
+}
+
+func TestStreamValid(t *testing.T) {
+	finish := &api.FinishEvent{FinishReason: api.FinishReasonStop}
+
+	tests := []struct {
+		name     string
+		events   []api.StreamEvent
+		wantFail bool
+	}{
+		{
+			name:   "valid stream",
+			events: toolCallStream,
+		},
+		{
+			name:   "error without finish",
+			events: []api.StreamEvent{&api.StreamStartEvent{}, &api.ErrorEvent{Err: errors.New("overloaded")}},
+		},
+		{
+			name:     "missing finish",
+			events:   []api.StreamEvent{&api.StreamStartEvent{}, &api.TextDeltaEvent{TextDelta: "Hi"}},
+			wantFail: true,
+		},
+		{
+			name:     "finish is not last",
+			events:   []api.StreamEvent{finish, &api.TextDeltaEvent{TextDelta: "Hi"}},
+			wantFail: true,
+		},
+		{
+			name:     "two finish events",
+			events:   []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hi"}, finish, finish},
+			wantFail: true,
+		},
+		{
+			name:     "stream start is not first",
+			events:   []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hi"}, &api.StreamStartEvent{}, finish},
+			wantFail: true,
+		},
+		{
+			name:     "nil event",
+			events:   []api.StreamEvent{nil, finish},
+			wantFail: true,
+		},
+		{
+			name: "delta after tool call",
+			events: []api.StreamEvent{
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{}`)},
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "search", ArgsDelta: []byte(`{}`)},
+				finish,
+			},
+			wantFail: true,
+		},
+		{
+			name: "duplicate tool call",
+			events: []api.StreamEvent{
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{}`)},
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{}`)},
+				finish,
+			},
+			wantFail: true,
+		},
+		{
+			name: "invalid tool call arguments",
+			events: []api.StreamEvent{
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{"q":`)},
+				finish,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockT{}
+			valid := StreamValid(mock, tt.events)
+			assert.Equal(t, tt.wantFail, mock.failed, "errors: %v", mock.errors)
+			assert.Equal(t, !tt.wantFail, valid)
+		})
+	}
+}
+
+func TestStreamContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected *api.Response
+		events   []api.StreamEvent
+		wantFail bool
+	}{
+		{
+			name: "accumulated text and tool call",
+			expected: &api.Response{
+				Content: []api.ContentBlock{
+					&api.TextBlock{Text: "Let me check."},
+					&api.ToolCallBlock{ToolName: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+				},
+				FinishReason: api.FinishReasonToolCalls,
+				Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+			},
+			events: toolCallStream,
+		},
+		{
+			name: "accumulated deltas without complete tool call",
+			expected: &api.Response{
+				Content: []api.ContentBlock{
+					&api.ToolCallBlock{ToolCallID: "call_1", Args: json.RawMessage(`{"city":"Paris"}`)},
+				},
+			},
+			events: []api.StreamEvent{
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "get_weather", ArgsDelta: []byte(`{"city":`)},
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "get_weather", ArgsDelta: []byte(`"Paris"}`)},
+				&api.FinishEvent{FinishReason: api.FinishReasonToolCalls},
+			},
+		},
+		{
+			name: "text mismatch",
+			expected: &api.Response{
+				Content: []api.ContentBlock{&api.TextBlock{Text: "Let me"}},
+			},
+			events:   toolCallStream,
+			wantFail: true,
+		},
+		{
+			name:     "events after finish",
+			expected: &api.Response{},
+			events: []api.StreamEvent{
+				&api.FinishEvent{FinishReason: api.FinishReasonStop},
+				&api.TextDeltaEvent{TextDelta: "Hi"},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockT{}
+			StreamContains(mock, tt.expected, tt.events)
+			assert.Equal(t, tt.wantFail, mock.failed, "errors: %v", mock.errors)
+		})
+	}
+}
+
+func TestStreamMatchesResponse(t *testing.T) {
+	generated := &api.Response{
+		Content: []api.ContentBlock{
+			&api.TextBlock{Text: "Let me check."},
+			&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{ "city": "Paris" }`)},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+		Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		ResponseInfo: &api.ResponseInfo{ID: "resp_456"},
+	}
+
+	tests := []struct {
+		name      string
+		generated *api.Response
+		wantFail  bool
+	}{
+		{
+			name:      "equivalent",
+			generated: generated,
+		},
+		{
+			name: "different finish reason",
+			generated: &api.Response{
+				Content:      generated.Content,
+				FinishReason: api.FinishReasonStop,
+				Usage:        generated.Usage,
+			},
+			wantFail: true,
+		},
+		{
+			name: "different tool call arguments",
+			generated: &api.Response{
+				Content: []api.ContentBlock{
+					generated.Content[0],
+					&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{"city":"London"}`)},
+				},
+				FinishReason: generated.FinishReason,
+				Usage:        generated.Usage,
+			},
+			wantFail: true,
+		},
+		{
+			name: "missing content",
+			generated: &api.Response{
+				Content:      generated.Content[:1],
+				FinishReason: generated.FinishReason,
+				Usage:        generated.Usage,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockT{}
+			StreamMatchesResponse(mock, tt.generated, toolCallStream)
+			assert.Equal(t, tt.wantFail, mock.failed, "errors: %v", mock.errors)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Embedding is a vector, i.e. an array of numbers.
+// It is e.g. used to represent a text as a vector of word embeddings.
+type Embedding []float64
+
+// EmbeddingModel represents a model that converts values into embeddings.
+//
+// T is the type of the values that the model can embed.
+// This will allow us to go beyond text embeddings in the future,
+// e.g. to support image embeddings
+type EmbeddingModel[T any] interface {
+	// ProviderName returns the name of the provider for logging purposes.
+	ProviderName() string
+
+	// ModelID returns the provider-specific model ID for logging purposes.
+	ModelID() string
+
+	// MaxEmbeddingsPerCall returns the limit of how many embeddings can be
+	// generated in a single call. Zero means there is no limit.
+	MaxEmbeddingsPerCall() int
+
+	// SupportsParallelCalls returns if the model can handle multiple embedding
+	// calls in parallel.
+	SupportsParallelCalls() bool
+
+	// Embed generates a list of embeddings for the given input values.
+	//
+	// Implementations should return a TooManyEmbeddingValuesForCallError if
+	// more than MaxEmbeddingsPerCall values are provided.
+	Embed(ctx context.Context, values []T, opts EmbeddingOptions) (*EmbeddingResponse, error)
+}
+
+// EmbeddingOptions represents the options for generating embeddings.
+type EmbeddingOptions struct {
+	// Headers are additional HTTP headers to be sent with the request.
+	// Only applicable for HTTP-based providers.
+	Headers http.Header `json:"headers,omitempty"`
+
+	// ProviderMetadata contains additional provider-specific metadata.
+	// The metadata is passed through to the provider from the AI SDK and enables
+	// provider-specific functionality that can be fully encapsulated in the provider.
+	ProviderMetadata *ProviderMetadata `json:"provider_metadata,omitzero"`
+}
+
+func (o *EmbeddingOptions) GetProviderMetadata() *ProviderMetadata { return o.ProviderMetadata }
+
+// EmbeddingResponse represents the response from generating embeddings.
+type EmbeddingResponse struct {
+	// Embeddings are the generated embeddings. They are in the same order as the input values.
+	Embeddings []Embedding `json:"embeddings"`
+
+	// Usage contains token usage information.
+	Usage EmbeddingUsage `json:"usage,omitzero"`
+
+	// ProviderMetadata contains provider-specific metadata.
+	ProviderMetadata *ProviderMetadata `json:"provider_metadata,omitzero"`
+
+	// ResponseInfo is optional response information for telemetry and debugging purposes.
+	ResponseInfo *ResponseInfo `json:"response,omitzero"`
+}
+
+func (r *EmbeddingResponse) GetProviderMetadata() *ProviderMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ProviderMetadata
+}
+
+// EmbeddingUsage represents token usage information. We only have input tokens for embeddings.
+type EmbeddingUsage struct {
+	Tokens int `json:"tokens"`
+}
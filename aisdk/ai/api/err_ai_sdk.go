@@ -17,6 +17,13 @@ func (e *AISDKError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause if it is an error, so that errors.Is and
+// errors.As can inspect it.
+func (e *AISDKError) Unwrap() error {
+	err, _ := e.Cause.(error)
+	return err
+}
+
 // NewAISDKError creates an AI SDK Error.
 // Parameters:
 //   - name: The name of the error.
@@ -31,9 +31,29 @@ func (e *APICallError) IsRetryable() bool {
 	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusConflict || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
 }
 
+// NewAPICallError creates a new APICallError from the HTTP request and response
+// of a failed API call.
+// Parameters:
+//   - message: The error message
+//   - req: The HTTP request that was sent (optional)
+//   - resp: The HTTP response that was received (optional)
+//   - cause: The underlying cause of the error (optional)
+func NewAPICallError(message string, req *http.Request, resp *http.Response, cause any) *APICallError {
+	err := &APICallError{
+		AISDKError: NewAISDKError("AI_APICallError", message, cause),
+		Request:    req,
+		Response:   resp,
+	}
+	if req != nil {
+		err.URL = req.URL
+	}
+	if resp != nil {
+		err.StatusCode = resp.StatusCode
+	}
+	return err
+}
+
 // TODO:
-// - Consider providing a constructor that takes a request and response,
-//   and initializes the fields from the request and response.
 // - Better approach to handling Data
 // - Should http.Request be a shallow copy with headers removed? (it might
 //   otherwise expose sensitive information)
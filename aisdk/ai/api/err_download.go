@@ -0,0 +1,41 @@
+package api
+
+import "fmt"
+
+// DownloadError indicates that the content of a URL in the prompt couldn't be
+// downloaded.
+type DownloadError struct {
+	*AISDKError
+
+	// URL is the URL that failed to download
+	URL string
+
+	// StatusCode is the HTTP status code of the response, or 0 if no response
+	// was received
+	StatusCode int
+
+	// StatusText is the HTTP status text of the response, if any
+	StatusText string
+}
+
+// NewDownloadError creates a new DownloadError instance
+// Parameters:
+//   - url: The URL that failed to download
+//   - statusCode: The HTTP status code of the response (optional)
+//   - statusText: The HTTP status text of the response (optional)
+//   - cause: The underlying cause of the error (optional)
+func NewDownloadError(url string, statusCode int, statusText string, cause any) *DownloadError {
+	var message string
+	switch {
+	case cause != nil:
+		message = fmt.Sprintf("Failed to download %s: %v", url, cause)
+	default:
+		message = fmt.Sprintf("Failed to download %s: %d %s", url, statusCode, statusText)
+	}
+	return &DownloadError{
+		AISDKError: NewAISDKError("AI_DownloadError", message, cause),
+		URL:        url,
+		StatusCode: statusCode,
+		StatusText: statusText,
+	}
+}
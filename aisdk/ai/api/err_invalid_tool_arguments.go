@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// InvalidToolArgumentsError indicates that the arguments of a tool call
+// generated by the model are not valid JSON, or don't match the input schema
+// of the tool.
+type InvalidToolArgumentsError struct {
+	*AISDKError
+
+	// ToolName is the name of the tool that was called
+	ToolName string
+
+	// ToolArgs are the arguments generated by the model
+	ToolArgs json.RawMessage
+}
+
+// NewInvalidToolArgumentsError creates a new InvalidToolArgumentsError instance
+// Parameters:
+//   - toolName: The name of the tool that was called
+//   - toolArgs: The arguments generated by the model
+//   - cause: The parsing or validation error
+func NewInvalidToolArgumentsError(toolName string, toolArgs json.RawMessage, cause any) *InvalidToolArgumentsError {
+	message := fmt.Sprintf("Invalid arguments for tool %s: %v", toolName, cause)
+	return &InvalidToolArgumentsError{
+		AISDKError: NewAISDKError("AI_InvalidToolArgumentsError", message, cause),
+		ToolName:   toolName,
+		ToolArgs:   toolArgs,
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NoSuchToolError indicates that the model called a tool that was not
+// provided in the call options.
+type NoSuchToolError struct {
+	*AISDKError
+
+	// ToolName is the name of the tool that was called
+	ToolName string
+
+	// AvailableTools are the names of the tools that were provided
+	AvailableTools []string
+}
+
+// NewNoSuchToolError creates a new NoSuchToolError instance
+// Parameters:
+//   - toolName: The name of the tool that was called
+//   - availableTools: The names of the tools that were provided
+func NewNoSuchToolError(toolName string, availableTools []string) *NoSuchToolError {
+	var message string
+	switch {
+	case len(availableTools) == 0:
+		message = fmt.Sprintf("Model tried to call unavailable tool '%s'. No tools are available.", toolName)
+	default:
+		message = fmt.Sprintf("Model tried to call unavailable tool '%s'. Available tools: %s.",
+			toolName, strings.Join(availableTools, ", "))
+	}
+	return &NoSuchToolError{
+		AISDKError:     NewAISDKError("AI_NoSuchToolError", message, nil),
+		ToolName:       toolName,
+		AvailableTools: availableTools,
+	}
+}
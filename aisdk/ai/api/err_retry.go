@@ -0,0 +1,61 @@
+package api
+
+// RetryReason describes why a retried call failed.
+type RetryReason string
+
+const (
+	// RetryReasonMaxRetriesExceeded indicates that every attempt failed with
+	// a retryable error.
+	RetryReasonMaxRetriesExceeded RetryReason = "maxRetriesExceeded"
+
+	// RetryReasonErrorNotRetryable indicates that an attempt failed with an
+	// error that can't be retried.
+	RetryReasonErrorNotRetryable RetryReason = "errorNotRetryable"
+
+	// RetryReasonAbort indicates that the context was canceled while waiting
+	// to retry.
+	RetryReasonAbort RetryReason = "abort"
+)
+
+// RetryError indicates that a call failed after being retried. It contains
+// the errors of every attempt.
+type RetryError struct {
+	*AISDKError
+
+	// Reason describes why the call failed
+	Reason RetryReason
+
+	// Errors contains the error of every attempt, in order
+	Errors []error
+}
+
+// NewRetryError creates a new RetryError instance
+// Parameters:
+//   - message: The error message
+//   - reason: The reason why the call failed
+//   - errors: The errors of every attempt, in order
+func NewRetryError(message string, reason RetryReason, errors []error) *RetryError {
+	var lastError error
+	if len(errors) > 0 {
+		lastError = errors[len(errors)-1]
+	}
+	return &RetryError{
+		AISDKError: NewAISDKError("AI_RetryError", message, lastError),
+		Reason:     reason,
+		Errors:     errors,
+	}
+}
+
+// LastError returns the error of the last attempt.
+func (e *RetryError) LastError() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Unwrap returns the errors of every attempt, so that errors.Is and errors.As
+// can inspect them.
+func (e *RetryError) Unwrap() []error {
+	return e.Errors
+}
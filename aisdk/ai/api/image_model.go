@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// ImageModel represents a model that generates images from a text prompt.
+type ImageModel interface {
+	// ProviderName returns the name of the provider for logging purposes.
+	ProviderName() string
+
+	// ModelID returns the provider-specific model ID for logging purposes.
+	ModelID() string
+
+	// MaxImagesPerCall returns the limit of how many images can be generated
+	// in a single call. Zero means that a single image is generated per call.
+	MaxImagesPerCall() int
+
+	// Generate generates images based on the given prompt.
+	Generate(ctx context.Context, prompt string, opts ImageCallOptions) (*ImageResponse, error)
+}
+
+// ImageCallOptions represents the options for generating images.
+type ImageCallOptions struct {
+	// N is the number of images to generate.
+	N int `json:"n,omitzero"`
+
+	// Size of the images to generate.
+	// Must have the format `{width}x{height}`.
+	// An empty string will use the provider's default size.
+	Size string `json:"size,omitzero"`
+
+	// AspectRatio of the images to generate.
+	// Must have the format `{width}:{height}`.
+	// An empty string will use the provider's default aspect ratio.
+	AspectRatio string `json:"aspect_ratio,omitzero"`
+
+	// Seed for the image generation.
+	// Zero will use the provider's default seed.
+	Seed int `json:"seed,omitzero"`
+
+	// Headers are additional HTTP headers to be sent with the request.
+	// Only applicable for HTTP-based providers.
+	Headers http.Header `json:"headers,omitempty"`
+
+	// ProviderMetadata contains additional provider-specific metadata.
+	// The metadata is passed through to the provider from the AI SDK and enables
+	// provider-specific functionality that can be fully encapsulated in the provider.
+	ProviderMetadata *ProviderMetadata `json:"provider_metadata,omitzero"`
+}
+
+func (o *ImageCallOptions) GetProviderMetadata() *ProviderMetadata { return o.ProviderMetadata }
+
+// ImageResponse represents the response from generating images.
+type ImageResponse struct {
+	// Images are the generated images. They can be used directly as content in
+	// a prompt.
+	Images []*ImageBlock `json:"images"`
+
+	// Warnings for the call, e.g. unsupported settings.
+	Warnings []CallWarning `json:"warnings,omitempty"`
+
+	// ProviderMetadata contains provider-specific metadata.
+	ProviderMetadata *ProviderMetadata `json:"provider_metadata,omitzero"`
+
+	// ResponseInfo is optional response information for telemetry and debugging purposes.
+	ResponseInfo *ResponseInfo `json:"response,omitzero"`
+}
+
+func (r *ImageResponse) GetProviderMetadata() *ProviderMetadata {
+	if r == nil {
+		return nil
+	}
+	return r.ProviderMetadata
+}
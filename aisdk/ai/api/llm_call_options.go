@@ -51,8 +51,6 @@ type CallOptions struct {
 	// Only applicable for HTTP-based providers.
 	Headers http.Header `json:"headers,omitempty"`
 
-	// TODO: Add maxRetries at the ai package level but not provider level.
-
 	// =====
 	// Tool-related, might consider moving to a separate struct.
 	// =====
@@ -64,6 +62,12 @@ type CallOptions struct {
 	// Defaults to 'auto'.
 	ToolChoice *ToolChoice `json:"tool_choice,omitzero"`
 
+	// Reasoning configures the reasoning (also called thinking) of models that
+	// support it. Providers map it to their native settings, and return an
+	// "unsupported-setting" warning for models that can't reason. If nil, the
+	// default behavior of the model is used.
+	Reasoning *ReasoningOptions `json:"reasoning,omitzero"`
+
 	// =====
 	// Provider-specific fields, should not expose in main ai package.
 	// =====
@@ -77,9 +81,9 @@ type CallOptions struct {
 	// provider-specific functionality that can be fully encapsulated in the provider.
 	ProviderMetadata *ProviderMetadata `json:"provider_metadata,omitzero"`
 
-	// TODO:
-	// Include raw chunks in the stream. Only applicable for streaming calls.
-	// IncludeRawChunks bool
+	// IncludeRawChunks requests a RawChunkEvent with the unparsed payload of
+	// every chunk sent by the provider. Only applicable for streaming calls.
+	IncludeRawChunks bool `json:"include_raw_chunks,omitzero"`
 
 	// TODO:
 	// Do we want to let users specify a model name at this level, to let the
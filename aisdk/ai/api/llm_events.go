@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -16,6 +17,9 @@ import (
 type EventType string
 
 const (
+	// EventStreamStart is the first event of every stream, carrying the warnings for the call.
+	EventStreamStart EventType = "stream-start"
+
 	// EventTextDelta represents an incremental text response from the model.
 	EventTextDelta EventType = "text-delta"
 
@@ -46,6 +50,10 @@ const (
 	// EventError indicates that an error occurred during the stream.
 	EventError EventType = "error"
 
+	// EventRawChunk contains an unparsed chunk sent by the provider. It is only
+	// sent when CallOptions.IncludeRawChunks is set.
+	EventRawChunk EventType = "raw-chunk"
+
 	// TODO: How should we handle refusal events? Do we need an additional event type?
 )
 
@@ -55,6 +63,17 @@ type StreamEvent interface {
 	Type() EventType
 }
 
+// StreamStartEvent is the first event of every stream.
+//
+// It carries the warnings for the call, e.g. for unsupported settings, which
+// are otherwise returned in Response.Warnings by non-streaming calls.
+type StreamStartEvent struct {
+	// Warnings contains warnings about the call options and prompt
+	Warnings []CallWarning `json:"warnings,omitempty"`
+}
+
+func (b *StreamStartEvent) Type() EventType { return EventStreamStart }
+
 // TextDeltaEvent represents an incremental text response from the model
 //
 // Used to update a TextBlock incrementally.
@@ -71,10 +90,17 @@ func (b *TextDeltaEvent) Type() EventType { return EventTextDelta }
 type ReasoningEvent struct {
 	// TextDelta is a partial reasoning text from the model
 	TextDelta string `json:"text_delta"`
+
+	// ProviderMetadata contains provider-specific data for the reasoning
+	// block, like the encrypted content of redacted reasoning. An event with
+	// provider metadata starts a new ReasoningBlock.
+	ProviderMetadata *ProviderMetadata `json:"provider_metadata,omitzero"`
 }
 
 func (b *ReasoningEvent) Type() EventType { return EventReasoning }
 
+func (b *ReasoningEvent) GetProviderMetadata() *ProviderMetadata { return b.ProviderMetadata }
+
 // ReasoningSignatureEvent represents an incremental signature update for reasoning text.
 //
 // Used to update the signature field of a ReasoningBlock.
@@ -144,8 +170,6 @@ type ToolCallDeltaEvent struct {
 
 func (b *ToolCallDeltaEvent) Type() EventType { return EventToolCallDelta }
 
-// TODOTODO Stream Start.
-
 // ResponseMetadataEvent contains additional response metadata.
 //
 // It will be sent as soon as it is available, without having to wait for
@@ -193,3 +217,98 @@ type ErrorEvent struct {
 
 func (b *ErrorEvent) Type() EventType { return EventError }
 func (b *ErrorEvent) Error() string   { return fmt.Sprintf("%v", b.Err) }
+
+// Unwrap returns the underlying error if Err is an error, so that errors.Is
+// and errors.As can inspect it.
+func (b *ErrorEvent) Unwrap() error {
+	err, _ := b.Err.(error)
+	return err
+}
+
+// AsError returns Err if it is an error, or an error with its message
+// otherwise. Use it to return the error of a stream from a function.
+func (b *ErrorEvent) AsError() error {
+	if err, ok := b.Err.(error); ok {
+		return err
+	}
+	return errors.New(fmt.Sprint(b.Err))
+}
+
+// MarshalJSON encodes errors by their message, since most error types have no
+// JSON representation. Other values are encoded as they are.
+func (b *ErrorEvent) MarshalJSON() ([]byte, error) {
+	value := b.Err
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	return json.Marshal(struct {
+		Err any `json:"error"`
+	}{Err: value})
+}
+
+// UnmarshalJSON decodes error messages encoded by MarshalJSON as errors.
+// Other values are decoded as they are.
+func (b *ErrorEvent) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Err any `json:"error"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	if message, ok := temp.Err.(string); ok {
+		b.Err = errors.New(message)
+	} else {
+		b.Err = temp.Err
+	}
+	return nil
+}
+
+// RawChunkEvent contains a chunk of the stream exactly as it was sent by the
+// provider, before it was decoded into other events.
+//
+// It is only sent when CallOptions.IncludeRawChunks is set, and precedes the
+// events decoded from the chunk.
+type RawChunkEvent struct {
+	// Data is the unparsed payload of the chunk, usually a JSON object
+	Data json.RawMessage `json:"data"`
+}
+
+func (b *RawChunkEvent) Type() EventType { return EventRawChunk }
+
+// UnmarshalStreamEvent decodes the JSON encoding of a stream event of the
+// given type.
+func UnmarshalStreamEvent(eventType EventType, data []byte) (StreamEvent, error) {
+	var event StreamEvent
+	switch eventType {
+	case EventStreamStart:
+		event = &StreamStartEvent{}
+	case EventTextDelta:
+		event = &TextDeltaEvent{}
+	case EventReasoning:
+		event = &ReasoningEvent{}
+	case EventReasoningSignature:
+		event = &ReasoningSignatureEvent{}
+	case EventSource:
+		event = &SourceEvent{}
+	case EventFile:
+		event = &FileEvent{}
+	case EventToolCall:
+		event = &ToolCallEvent{}
+	case EventToolCallDelta:
+		event = &ToolCallDeltaEvent{}
+	case EventResponseMetadata:
+		event = &ResponseMetadataEvent{}
+	case EventFinish:
+		event = &FinishEvent{}
+	case EventError:
+		event = &ErrorEvent{}
+	case EventRawChunk:
+		event = &RawChunkEvent{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
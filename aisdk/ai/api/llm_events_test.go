@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalStreamEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    StreamEvent
+		jsonStr  string
+		expected StreamEvent
+	}{
+		{
+			name:    "text_delta",
+			event:   &TextDeltaEvent{TextDelta: "Hello"},
+			jsonStr: `{"text_delta":"Hello"}`,
+		},
+		{
+			name:    "tool_call",
+			event:   &ToolCallEvent{ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+			jsonStr: `{"tool_call_id":"call_1","tool_name":"weather","args":{"city":"Paris"}}`,
+		},
+		{
+			name:    "finish",
+			event:   &FinishEvent{FinishReason: FinishReasonStop, Usage: Usage{InputTokens: 1, OutputTokens: 2, TotalTokens: 3}},
+			jsonStr: `{"usage":{"input_tokens":1,"output_tokens":2,"total_tokens":3},"finish_reason":"stop"}`,
+		},
+		{
+			name:     "error",
+			event:    &ErrorEvent{Err: errors.New("boom")},
+			jsonStr:  `{"error":"boom"}`,
+			expected: &ErrorEvent{Err: errors.New("boom")},
+		},
+		{
+			name:    "error_with_structured_value",
+			event:   &ErrorEvent{Err: map[string]any{"code": "rate_limit"}},
+			jsonStr: `{"error":{"code":"rate_limit"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.event)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.jsonStr, string(data))
+
+			decoded, err := UnmarshalStreamEvent(tt.event.Type(), data)
+			require.NoError(t, err)
+			expected := tt.expected
+			if expected == nil {
+				expected = tt.event
+			}
+			assert.Equal(t, expected, decoded)
+		})
+	}
+}
+
+func TestUnmarshalStreamEvent_UnknownType(t *testing.T) {
+	_, err := UnmarshalStreamEvent("unknown", []byte(`{}`))
+	assert.EqualError(t, err, `unknown event type "unknown"`)
+}
+
+func TestErrorEvent_AsError(t *testing.T) {
+	err := errors.New("boom")
+	assert.Same(t, err, (&ErrorEvent{Err: err}).AsError())
+	assert.EqualError(t, (&ErrorEvent{Err: map[string]any{"code": 500}}).AsError(), "map[code:500]")
+}
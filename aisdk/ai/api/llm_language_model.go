@@ -156,11 +156,17 @@ type Usage struct {
 	TotalTokens int `json:"total_tokens"`
 
 	// ReasoningTokens is the number of tokens used by model as part of the reasoning process.
+	// They are included in OutputTokens. Zero if the provider doesn't report them separately.
 	ReasoningTokens int `json:"reasoning_tokens,omitzero"`
 
 	// CachedInputTokens is the number of input tokens that were cached from a previous call.
 	CachedInputTokens int `json:"cached_input_tokens,omitzero"`
 
+	// CacheWriteInputTokens is the number of input tokens that were written to
+	// the provider's prompt cache, for providers that charge for cache writes,
+	// like Anthropic. Zero if the provider doesn't report them separately.
+	CacheWriteInputTokens int `json:"cache_write_input_tokens,omitzero"`
+
 	// TODO: consider adding ToolCount, StepCount fields
 }
 
@@ -170,7 +176,8 @@ func (u *Usage) IsZero() bool {
 		u.OutputTokens == 0 &&
 		u.TotalTokens == 0 &&
 		u.ReasoningTokens == 0 &&
-		u.CachedInputTokens == 0
+		u.CachedInputTokens == 0 &&
+		u.CacheWriteInputTokens == 0
 }
 
 // RequestInfo contains optional request information for telemetry.
@@ -0,0 +1,86 @@
+package api
+
+import "sync"
+
+// ModelCatalog returns the capabilities, limits and price of the models of a
+// provider. It returns false for models that are not in the catalog.
+type ModelCatalog func(modelID string) (ModelInfo, bool)
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = map[string]ModelCatalog{}
+)
+
+// RegisterModelCatalog registers the catalog of the named provider, replacing
+// any catalog previously registered for it. Providers register their catalog
+// when their package is initialized, so that it can be looked up without
+// importing the provider.
+func RegisterModelCatalog(providerName string, catalog ModelCatalog) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	catalogs[providerName] = catalog
+}
+
+// LookupCatalogModel returns the model info of the given model from the
+// catalog registered for the named provider. It returns false if no catalog
+// is registered for the provider, or if the model is not in the catalog.
+func LookupCatalogModel(providerName, modelID string) (ModelInfo, bool) {
+	catalogsMu.RLock()
+	catalog, ok := catalogs[providerName]
+	catalogsMu.RUnlock()
+	if !ok {
+		return ModelInfo{}, false
+	}
+	return catalog(modelID)
+}
+
+// ModelSpec describes a group of models of a provider that share the same
+// capabilities, limits and price, e.g. a model and its dated snapshots.
+type ModelSpec struct {
+	// IDs are the IDs of the models.
+	IDs []string
+
+	ContextWindow   int
+	MaxOutputTokens int
+
+	// Input lists the kinds of content the models accept.
+	Input []Modality
+
+	// Output lists the kinds of content the models generate. Defaults to text.
+	Output []Modality
+
+	// Pricing is the price of the models, or nil if it is unknown.
+	Pricing *Pricing
+}
+
+// NewModelCatalog returns a catalog with the models of the given specs,
+// served by the named provider. The catalog returns copies of the model info,
+// which can be modified by the callers.
+func NewModelCatalog(providerName string, specs []ModelSpec) ModelCatalog {
+	models := map[string]ModelInfo{}
+	for _, spec := range specs {
+		output := spec.Output
+		if output == nil {
+			output = []Modality{ModalityText}
+		}
+		for _, id := range spec.IDs {
+			models[id] = ModelInfo{
+				Provider:         providerName,
+				ModelID:          id,
+				ContextWindow:    spec.ContextWindow,
+				MaxOutputTokens:  spec.MaxOutputTokens,
+				InputModalities:  spec.Input,
+				OutputModalities: output,
+				Pricing:          spec.Pricing,
+			}
+		}
+	}
+
+	return func(modelID string) (ModelInfo, bool) {
+		info, ok := models[modelID]
+		if !ok {
+			return ModelInfo{}, false
+		}
+		return info.Clone(), true
+	}
+}
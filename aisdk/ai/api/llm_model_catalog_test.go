@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelCatalog(t *testing.T) {
+	catalog := NewModelCatalog("test", []ModelSpec{
+		{
+			IDs:           []string{"chat", "chat-2025"},
+			ContextWindow: 1000, MaxOutputTokens: 100, Input: []Modality{ModalityText, ModalityImage},
+			Pricing: &Pricing{Input: 1, Output: 2},
+		},
+		{
+			IDs:    []string{"voice"},
+			Input:  []Modality{ModalityAudio},
+			Output: []Modality{ModalityText, ModalityAudio},
+		},
+	})
+	RegisterModelCatalog("test", catalog)
+
+	info, ok := LookupCatalogModel("test", "chat-2025")
+	assert.True(t, ok)
+	assert.Equal(t, ModelInfo{
+		Provider:         "test",
+		ModelID:          "chat-2025",
+		ContextWindow:    1000,
+		MaxOutputTokens:  100,
+		InputModalities:  []Modality{ModalityText, ModalityImage},
+		OutputModalities: []Modality{ModalityText},
+		Pricing:          &Pricing{Input: 1, Output: 2},
+	}, info)
+
+	// The catalog returns copies.
+	info.Pricing.Input = 10
+	info, _ = LookupCatalogModel("test", "chat")
+	assert.Equal(t, &Pricing{Input: 1, Output: 2}, info.Pricing)
+
+	info, ok = LookupCatalogModel("test", "voice")
+	assert.True(t, ok)
+	assert.Equal(t, []Modality{ModalityText, ModalityAudio}, info.OutputModalities)
+	assert.Nil(t, info.Pricing)
+
+	_, ok = LookupCatalogModel("test", "unknown")
+	assert.False(t, ok)
+	_, ok = LookupCatalogModel("unknown", "chat")
+	assert.False(t, ok)
+}
@@ -0,0 +1,129 @@
+package api
+
+import "slices"
+
+// Modality is a kind of content that a model accepts or generates.
+type Modality string
+
+const (
+	ModalityText  Modality = "text"
+	ModalityImage Modality = "image"
+	ModalityAudio Modality = "audio"
+	// ModalityFile represents documents like PDFs.
+	ModalityFile Modality = "file"
+)
+
+// ModelInfo describes the capabilities, limits and price of a language model.
+// Fields that are unknown are left as zero values.
+type ModelInfo struct {
+	// Provider is the name of the provider that serves the model.
+	Provider string `json:"provider"`
+
+	// ModelID is the ID of the model, as used by the provider.
+	ModelID string `json:"model_id"`
+
+	// ContextWindow is the maximum number of input and output tokens that the
+	// model can handle in a single call.
+	ContextWindow int `json:"context_window,omitzero"`
+
+	// MaxOutputTokens is the maximum number of tokens the model can generate in
+	// a single call, including reasoning tokens.
+	MaxOutputTokens int `json:"max_output_tokens,omitzero"`
+
+	// InputModalities lists the kinds of content the model accepts.
+	InputModalities []Modality `json:"input_modalities,omitempty"`
+
+	// OutputModalities lists the kinds of content the model generates.
+	OutputModalities []Modality `json:"output_modalities,omitempty"`
+
+	// Pricing is the price of the model, or nil if it is unknown.
+	Pricing *Pricing `json:"pricing,omitzero"`
+}
+
+// Clone returns a copy of the model info that can be modified without
+// changing the original.
+func (m *ModelInfo) Clone() ModelInfo {
+	clone := *m
+	clone.InputModalities = slices.Clone(m.InputModalities)
+	clone.OutputModalities = slices.Clone(m.OutputModalities)
+	if m.Pricing != nil {
+		pricing := *m.Pricing
+		clone.Pricing = &pricing
+	}
+	return clone
+}
+
+// SupportsInput reports whether the model accepts the given modality.
+func (m *ModelInfo) SupportsInput(modality Modality) bool {
+	return slices.Contains(m.InputModalities, modality)
+}
+
+// Cost returns the cost in USD of the given usage, or 0 if the pricing of the
+// model is unknown.
+func (m *ModelInfo) Cost(usage Usage) float64 {
+	return m.Pricing.Cost(usage)
+}
+
+// Pricing is the price of a language model, in USD per million tokens.
+type Pricing struct {
+	// Input is the price of input tokens.
+	Input float64 `json:"input"`
+
+	// CachedInput is the price of input tokens read from the provider's prompt
+	// cache. Zero means cached tokens are charged as regular input tokens.
+	CachedInput float64 `json:"cached_input,omitzero"`
+
+	// CacheWriteInput is the price of input tokens written to the provider's
+	// prompt cache. Zero means they are charged as regular input tokens.
+	CacheWriteInput float64 `json:"cache_write_input,omitzero"`
+
+	// Output is the price of output tokens.
+	Output float64 `json:"output"`
+
+	// Reasoning is the price of reasoning tokens. Zero means reasoning tokens
+	// are charged as regular output tokens.
+	Reasoning float64 `json:"reasoning,omitzero"`
+
+	// CachedInputExcluded indicates that the provider doesn't count the tokens
+	// read from or written to the cache in Usage.InputTokens, like Anthropic.
+	// By default, they are assumed to be a subset of the input tokens, like
+	// OpenAI.
+	CachedInputExcluded bool `json:"cached_input_excluded,omitzero"`
+}
+
+// Cost returns the cost in USD of the given usage. It returns 0 if the pricing
+// is nil.
+//
+// Reasoning tokens are assumed to be a subset of the output tokens, as
+// reported by all the built-in providers.
+func (p *Pricing) Cost(usage Usage) float64 {
+	if p == nil {
+		return 0
+	}
+
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	cacheWritePrice := p.CacheWriteInput
+	if cacheWritePrice == 0 {
+		cacheWritePrice = p.Input
+	}
+	inputTokens := usage.InputTokens
+	if !p.CachedInputExcluded {
+		inputTokens = max(inputTokens-usage.CachedInputTokens-usage.CacheWriteInputTokens, 0)
+	}
+
+	reasoningPrice := p.Reasoning
+	if reasoningPrice == 0 {
+		reasoningPrice = p.Output
+	}
+	outputTokens := max(usage.OutputTokens-usage.ReasoningTokens, 0)
+
+	cost := float64(inputTokens)*p.Input +
+		float64(usage.CachedInputTokens)*cachedPrice +
+		float64(usage.CacheWriteInputTokens)*cacheWritePrice +
+		float64(outputTokens)*p.Output +
+		float64(usage.ReasoningTokens)*reasoningPrice
+	return cost / 1_000_000
+}
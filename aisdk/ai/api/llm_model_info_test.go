@@ -0,0 +1,100 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	tests := []struct {
+		name     string
+		pricing  *Pricing
+		usage    Usage
+		expected float64
+	}{
+		{
+			name:     "nil pricing",
+			pricing:  nil,
+			usage:    Usage{InputTokens: 1000, OutputTokens: 1000},
+			expected: 0,
+		},
+		{
+			name:     "input and output",
+			pricing:  &Pricing{Input: 2, Output: 8},
+			usage:    Usage{InputTokens: 1_000_000, OutputTokens: 500_000},
+			expected: 2 + 4,
+		},
+		{
+			name:     "cached input included in input tokens",
+			pricing:  &Pricing{Input: 2, CachedInput: 0.5, Output: 8},
+			usage:    Usage{InputTokens: 1_000_000, CachedInputTokens: 400_000},
+			expected: 0.6*2 + 0.4*0.5,
+		},
+		{
+			name:     "cached input excluded from input tokens",
+			pricing:  &Pricing{Input: 3, CachedInput: 0.3, Output: 15, CachedInputExcluded: true},
+			usage:    Usage{InputTokens: 1_000_000, CachedInputTokens: 1_000_000},
+			expected: 3 + 0.3,
+		},
+		{
+			name:     "cache writes excluded from input tokens",
+			pricing:  &Pricing{Input: 3, CachedInput: 0.3, CacheWriteInput: 3.75, Output: 15, CachedInputExcluded: true},
+			usage:    Usage{InputTokens: 1_000_000, CachedInputTokens: 1_000_000, CacheWriteInputTokens: 1_000_000},
+			expected: 3 + 0.3 + 3.75,
+		},
+		{
+			name:     "cache writes included in input tokens",
+			pricing:  &Pricing{Input: 2, CachedInput: 0.5, CacheWriteInput: 2.5, Output: 8},
+			usage:    Usage{InputTokens: 1_000_000, CachedInputTokens: 200_000, CacheWriteInputTokens: 400_000},
+			expected: 0.4*2 + 0.2*0.5 + 0.4*2.5,
+		},
+		{
+			name:     "cache writes without a cache write price",
+			pricing:  &Pricing{Input: 3, Output: 15, CachedInputExcluded: true},
+			usage:    Usage{CacheWriteInputTokens: 1_000_000},
+			expected: 3,
+		},
+		{
+			name:     "cached input without a cached price",
+			pricing:  &Pricing{Input: 10, Output: 30},
+			usage:    Usage{InputTokens: 1_000_000, CachedInputTokens: 500_000},
+			expected: 10,
+		},
+		{
+			name:     "reasoning charged as output",
+			pricing:  &Pricing{Input: 1, Output: 4},
+			usage:    Usage{OutputTokens: 1_000_000, ReasoningTokens: 600_000},
+			expected: 4,
+		},
+		{
+			name:     "reasoning with its own price",
+			pricing:  &Pricing{Input: 1, Output: 4, Reasoning: 2},
+			usage:    Usage{OutputTokens: 1_000_000, ReasoningTokens: 500_000},
+			expected: 0.5*4 + 0.5*2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, tt.pricing.Cost(tt.usage), 1e-9)
+			info := ModelInfo{Pricing: tt.pricing}
+			assert.InDelta(t, tt.expected, info.Cost(tt.usage), 1e-9)
+		})
+	}
+}
+
+func TestModelInfo_Clone(t *testing.T) {
+	info := ModelInfo{
+		InputModalities: []Modality{ModalityText, ModalityImage},
+		Pricing:         &Pricing{Input: 1},
+	}
+	clone := info.Clone()
+	clone.InputModalities[0] = ModalityAudio
+	clone.Pricing.Input = 2
+
+	assert.Equal(t, ModalityText, info.InputModalities[0])
+	assert.InDelta(t, 1.0, info.Pricing.Input, 0)
+	assert.True(t, info.SupportsInput(ModalityImage))
+	assert.False(t, info.SupportsInput(ModalityAudio))
+}
@@ -13,3 +13,13 @@ const (
 	// ObjectGenerationModeTool indicates the model should use tool calls to generate objects
 	ObjectGenerationModeTool ObjectGenerationMode = "tool"
 )
+
+// ObjectGenerationModeProvider is an optional interface that language models can
+// implement to declare the object generation mode that works best for them.
+//
+// The AI SDK uses it to decide how to request structured outputs when the user
+// does not explicitly choose a mode.
+type ObjectGenerationModeProvider interface {
+	// DefaultObjectGenerationMode returns the preferred mode for object generation.
+	DefaultObjectGenerationMode() ObjectGenerationMode
+}
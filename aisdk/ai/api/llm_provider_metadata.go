@@ -2,6 +2,8 @@ package api
 
 import (
 	"encoding/json"
+	"maps"
+	"slices"
 )
 
 // ProviderMetadata provides access to provider-specific metadata structures.
@@ -54,6 +56,15 @@ func (p *ProviderMetadata) Has(provider string) bool {
 	return exists
 }
 
+// Providers returns the names of the providers that have metadata, sorted
+// alphabetically.
+func (p *ProviderMetadata) Providers() []string {
+	if p == nil || p.data == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(p.data))
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 // It serializes the underlying data map to JSON.
 func (p *ProviderMetadata) MarshalJSON() ([]byte, error) {
@@ -84,6 +95,9 @@ type MetadataSource interface {
 // GetMetadata is a generic helper function to retrieve provider-specific
 // metadata as a pointer to the requested type.
 //
+// Metadata decoded from JSON, e.g. from a cached or recorded response, is
+// stored as generic JSON values. It is converted to the requested type.
+//
 // If the provider is not found or the type doesn't match, it returns nil.
 //
 // We recommend providers use this helper to expose predefined metadata functions.
@@ -114,6 +128,19 @@ func GetMetadata[T any](provider string, source MetadataSource) *T {
 		return &value
 	}
 
+	// Metadata decoded from JSON is converted through its JSON encoding:
+	if generic, ok := metadata.(map[string]any); ok {
+		data, err := json.Marshal(generic)
+		if err != nil {
+			return nil
+		}
+		var converted T
+		if err := json.Unmarshal(data, &converted); err != nil {
+			return nil
+		}
+		return &converted
+	}
+
 	// We couldn't cast it to the right type, return nil:
 	return nil
 }
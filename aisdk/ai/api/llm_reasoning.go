@@ -0,0 +1,80 @@
+package api
+
+// ReasoningEffort is the relative amount of reasoning a model should do before
+// answering.
+type ReasoningEffort string
+
+const (
+	ReasoningEffortMinimal ReasoningEffort = "minimal"
+	ReasoningEffortLow     ReasoningEffort = "low"
+	ReasoningEffortMedium  ReasoningEffort = "medium"
+	ReasoningEffortHigh    ReasoningEffort = "high"
+)
+
+// reasoningBudgets are the token budgets that correspond to each effort, from
+// the lowest to the highest. A budget maps to the lowest effort whose budget
+// is greater or equal.
+var reasoningBudgets = []struct {
+	effort ReasoningEffort
+	budget int
+}{
+	{ReasoningEffortMinimal, 1024},
+	{ReasoningEffortLow, 4096},
+	{ReasoningEffortMedium, 8192},
+	{ReasoningEffortHigh, 16384},
+}
+
+// ReasoningOptions configures the reasoning of a model in a provider-neutral
+// way. Providers that take a reasoning effort (e.g. OpenAI) and providers that
+// take a token budget (e.g. Anthropic) each map these options to their native
+// settings, so that the same options can be used with any provider.
+//
+// Set either Effort or BudgetTokens. If both are set, providers use the one
+// they support natively.
+type ReasoningOptions struct {
+	// Effort is the relative amount of reasoning the model should do.
+	Effort ReasoningEffort `json:"effort,omitzero"`
+
+	// BudgetTokens is the maximum number of tokens the model should use for
+	// reasoning.
+	BudgetTokens int `json:"budget_tokens,omitzero"`
+
+	// IncludeSummary requests the model to return a summary of its reasoning,
+	// as reasoning content, for providers that only return it on request.
+	IncludeSummary bool `json:"include_summary,omitzero"`
+}
+
+// ResolvedEffort returns the reasoning effort, derived from BudgetTokens if
+// Effort is not set. Defaults to ReasoningEffortMedium.
+func (o *ReasoningOptions) ResolvedEffort() ReasoningEffort {
+	if o.Effort != "" {
+		return o.Effort
+	}
+	if o.BudgetTokens <= 0 {
+		return ReasoningEffortMedium
+	}
+	for _, level := range reasoningBudgets {
+		if o.BudgetTokens <= level.budget {
+			return level.effort
+		}
+	}
+	return ReasoningEffortHigh
+}
+
+// ResolvedBudget returns the reasoning budget in tokens, derived from Effort
+// if BudgetTokens is not set. Defaults to the budget of ReasoningEffortMedium.
+func (o *ReasoningOptions) ResolvedBudget() int {
+	if o.BudgetTokens > 0 {
+		return o.BudgetTokens
+	}
+	effort := o.Effort
+	if effort == "" {
+		effort = ReasoningEffortMedium
+	}
+	for _, level := range reasoningBudgets {
+		if level.effort == effort {
+			return level.budget
+		}
+	}
+	return reasoningBudgets[len(reasoningBudgets)-1].budget
+}
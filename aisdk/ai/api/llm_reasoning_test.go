@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReasoningOptions_Resolve(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        ReasoningOptions
+		expectedEffort ReasoningEffort
+		expectedBudget int
+	}{
+		{
+			name:           "defaults",
+			expectedEffort: ReasoningEffortMedium,
+			expectedBudget: 8192,
+		},
+		{
+			name:           "effort",
+			options:        ReasoningOptions{Effort: ReasoningEffortLow},
+			expectedEffort: ReasoningEffortLow,
+			expectedBudget: 4096,
+		},
+		{
+			name:           "small budget",
+			options:        ReasoningOptions{BudgetTokens: 500},
+			expectedEffort: ReasoningEffortMinimal,
+			expectedBudget: 500,
+		},
+		{
+			name:           "budget between efforts",
+			options:        ReasoningOptions{BudgetTokens: 5000},
+			expectedEffort: ReasoningEffortMedium,
+			expectedBudget: 5000,
+		},
+		{
+			name:           "large budget",
+			options:        ReasoningOptions{BudgetTokens: 100_000},
+			expectedEffort: ReasoningEffortHigh,
+			expectedBudget: 100_000,
+		},
+		{
+			name:           "effort and budget",
+			options:        ReasoningOptions{Effort: ReasoningEffortHigh, BudgetTokens: 2000},
+			expectedEffort: ReasoningEffortHigh,
+			expectedBudget: 2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedEffort, tt.options.ResolvedEffort())
+			assert.Equal(t, tt.expectedBudget, tt.options.ResolvedBudget())
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// TokenEstimator estimates the tokens of a prompt without calling a
+// tokenizer. Providers without a token counting API use it to count tokens,
+// with costs tuned to their models.
+type TokenEstimator struct {
+	// CharsPerToken is the average number of characters per token of text.
+	CharsPerToken int
+
+	// MessageTokens is the overhead of each message (role, separators).
+	MessageTokens int
+
+	// PromptTokens is the overhead of the whole prompt, e.g. the tokens that
+	// prime the reply of the model.
+	PromptTokens int
+
+	// ImageTokens is the approximate cost of an image, which for most
+	// providers depends on its resolution.
+	ImageTokens int
+
+	// FileTokens is the approximate cost of a non-text file, e.g. a PDF page.
+	FileTokens int
+}
+
+// DefaultTokenEstimator counts text as 4 characters per token, which is the
+// average of English text for most tokenizers, and images and non-text files
+// at a fixed cost.
+var DefaultTokenEstimator = TokenEstimator{
+	CharsPerToken: 4,
+	MessageTokens: 4,
+	ImageTokens:   1_000,
+	FileTokens:    1_500,
+}
+
+// Estimate returns the approximate number of tokens of the prompt.
+func (e TokenEstimator) Estimate(prompt []Message) int {
+	tokens := e.PromptTokens
+	for _, msg := range prompt {
+		tokens += e.MessageTokens
+		switch m := msg.(type) {
+		case *SystemMessage:
+			tokens += e.textTokens(m.Content)
+		case *UserMessage:
+			tokens += e.blockTokens(m.Content)
+		case *AssistantMessage:
+			tokens += e.blockTokens(m.Content)
+		case *ToolMessage:
+			for i := range m.Content {
+				tokens += e.toolResultTokens(&m.Content[i])
+			}
+		}
+	}
+	return tokens
+}
+
+func (e TokenEstimator) textTokens(text string) int {
+	charsPerToken := max(e.CharsPerToken, 1)
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+func (e TokenEstimator) blockTokens(blocks []ContentBlock) int {
+	tokens := 0
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *TextBlock:
+			tokens += e.textTokens(b.Text)
+		case *ReasoningBlock:
+			tokens += e.textTokens(b.Text)
+		case *ImageBlock:
+			tokens += e.ImageTokens
+		case *FileBlock:
+			if strings.HasPrefix(b.MediaType, "text/") && len(b.Data) > 0 {
+				tokens += e.textTokens(string(b.Data))
+			} else {
+				tokens += e.FileTokens
+			}
+		case *ToolCallBlock:
+			tokens += e.textTokens(b.ToolName) + e.textTokens(string(b.Args))
+		case *ToolResultBlock:
+			tokens += e.toolResultTokens(b)
+		case *SourceBlock:
+			tokens += e.textTokens(b.URL) + e.textTokens(b.Title)
+		}
+	}
+	return tokens
+}
+
+func (e TokenEstimator) toolResultTokens(result *ToolResultBlock) int {
+	if len(result.Content) > 0 {
+		return e.blockTokens(result.Content)
+	}
+	if text, ok := result.Result.(string); ok {
+		return e.textTokens(text)
+	}
+	data, _ := json.Marshal(result.Result)
+	return e.textTokens(string(data))
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenEstimator(t *testing.T) {
+	prompt := []Message{
+		&SystemMessage{Content: strings.Repeat("a", 30)},
+		&UserMessage{Content: []ContentBlock{
+			&ImageBlock{URL: "https://example.com/cat.png"},
+			&FileBlock{MediaType: "application/pdf", Data: []byte("%PDF")},
+			&FileBlock{MediaType: "text/plain", Data: []byte(strings.Repeat("b", 5))},
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		estimator TokenEstimator
+		expected  int
+	}{
+		{
+			name:      "default",
+			estimator: DefaultTokenEstimator,
+			expected:  4 + 8 + 4 + 1_000 + 1_500 + 2,
+		},
+		{
+			name:      "custom costs",
+			estimator: TokenEstimator{CharsPerToken: 3, MessageTokens: 3, PromptTokens: 3, ImageTokens: 85, FileTokens: 500},
+			expected:  3 + 3 + 10 + 3 + 85 + 500 + 2,
+		},
+		{
+			name:      "zero value counts characters",
+			estimator: TokenEstimator{},
+			expected:  30 + 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.estimator.Estimate(prompt))
+		})
+	}
+}
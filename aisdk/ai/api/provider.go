@@ -1,6 +1,6 @@
 package api
 
-// Provider is a provider for language and text embedding models.
+// Provider is a provider for language, text embedding and image models.
 type Provider interface {
 	// LanguageModel returns the language model with the given id.
 	// The model id is then passed to the provider function to get the model.
@@ -22,19 +22,17 @@ type Provider interface {
 	// Returns:
 	//   The text embedding model associated with the id
 	//   error of type NoSuchModelError if no such model exists
-	// TODO: uncomment when we're ready to support embeddings.
-	// TextEmbeddingModel(modelID string) (EmbeddingModel[string], error)
+	TextEmbeddingModel(modelID string) (EmbeddingModel[string], error)
 
 	// ImageModel returns the image model with the given id.
 	// The model id is then passed to the provider function to get the model.
-	// This method is optional and may return nil if image models are not supported.
 	//
 	// Parameters:
 	//   modelID: The id of the model to return.
 	//
 	// Returns:
-	//   The image model associated with the id, or nil if image models are not supported
-	//   error of type NoSuchModelError if no such model exists and image models are supported
-	// TODO: Uncomment when we're ready to support images models.
-	// ImageModel(modelID string) (*ImageModel, error)
+	//   The image model associated with the id
+	//   error of type NoSuchModelError if no such model exists, or if the
+	//   provider doesn't support image models
+	ImageModel(modelID string) (ImageModel, error)
 }
@@ -26,6 +26,8 @@ type ResponseBuilder struct {
 	resp api.Response
 	// Map of tool call ID to index in Content array (for parallel tool calls)
 	toolCallIndices map[string]int
+	// Set of tool call IDs for which a complete tool call event was received
+	completedToolCalls map[string]bool
 	// Error encountered during event processing
 	err error
 
@@ -46,9 +48,10 @@ func NewResponseBuilder() *ResponseBuilder {
 			Warnings:     []api.CallWarning{},
 			FinishReason: api.FinishReason(""),
 		},
-		toolCallIndices: make(map[string]int),
-		currentState:    noState,
-		err:             nil,
+		toolCallIndices:    make(map[string]int),
+		completedToolCalls: make(map[string]bool),
+		currentState:       noState,
+		err:                nil,
 	}
 }
 
@@ -70,6 +73,11 @@ func (b *ResponseBuilder) AddEvent(event api.StreamEvent) error {
 
 	switch evt := event.(type) {
 	// Handle pointer types
+	case *api.StreamStartEvent:
+		return b.addStreamStart(evt)
+	case *api.RawChunkEvent:
+		// Raw chunks are decoded into other events by the provider.
+		return nil
 	case *api.TextDeltaEvent:
 		return b.addTextDelta(evt)
 	case *api.ReasoningEvent:
@@ -96,6 +104,12 @@ func (b *ResponseBuilder) AddEvent(event api.StreamEvent) error {
 	}
 }
 
+// addStreamStart adds the warnings of a stream start event to the response.
+func (b *ResponseBuilder) addStreamStart(e *api.StreamStartEvent) error {
+	b.resp.Warnings = append(b.resp.Warnings, e.Warnings...)
+	return nil
+}
+
 // addTextDelta adds a text delta event to the response.
 func (b *ResponseBuilder) addTextDelta(e *api.TextDeltaEvent) error {
 	// Only concatenate with last block if the last content block is a TextBlock
@@ -117,24 +131,28 @@ func (b *ResponseBuilder) addTextDelta(e *api.TextDeltaEvent) error {
 
 // addReasoning adds a reasoning event to the response.
 func (b *ResponseBuilder) addReasoning(e *api.ReasoningEvent) error {
-	// Only concatenate with last block if the last content block is a ReasoningBlock
-	if len(b.resp.Content) > 0 {
-		if lastBlock, ok := b.resp.Content[len(b.resp.Content)-1].(*api.ReasoningBlock); ok {
+	// Only concatenate with last block if the last content block is a
+	// ReasoningBlock. Events with provider metadata, like redacted reasoning,
+	// start a block of their own and are never extended.
+	if len(b.resp.Content) > 0 && e.ProviderMetadata == nil {
+		if lastBlock, ok := b.resp.Content[len(b.resp.Content)-1].(*api.ReasoningBlock); ok && lastBlock.ProviderMetadata == nil {
 			// Append to existing reasoning block
 			lastBlock.Text += e.TextDelta
 			return nil
 		}
 	}
 
-	// Validate state transition
-	if b.currentState != noState && b.currentState != reasoningState {
+	// Validate state transition. Reasoning may follow text, e.g. when a model
+	// interleaves its reasoning with the answer.
+	if b.currentState != noState && b.currentState != reasoningState && b.currentState != textState {
 		return fmt.Errorf("invalid state transition: cannot add reasoning in state %v", b.currentState)
 	}
 
 	// Create new reasoning block
 	b.currentState = reasoningState
 	b.resp.Content = append(b.resp.Content, &api.ReasoningBlock{
-		Text: e.TextDelta,
+		Text:             e.TextDelta,
+		ProviderMetadata: e.ProviderMetadata,
 	})
 	return nil
 }
@@ -155,12 +173,22 @@ func (b *ResponseBuilder) addReasoningSignature(e *api.ReasoningSignatureEvent)
 // addToolCall adds a tool call event to the response.
 func (b *ResponseBuilder) addToolCall(e *api.ToolCallEvent) error {
 	// Check for duplicate tool call ID
-	if _, exists := b.toolCallIndices[e.ToolCallID]; exists {
+	if b.completedToolCalls[e.ToolCallID] {
 		return fmt.Errorf("duplicate tool call ID: %s", e.ToolCallID)
 	}
+	b.completedToolCalls[e.ToolCallID] = true
 
 	b.currentState = toolCallState
 
+	// Some providers stream the arguments as deltas and then send the complete
+	// tool call. In that case the complete call replaces the accumulated args.
+	if idx, exists := b.toolCallIndices[e.ToolCallID]; exists {
+		toolCall := b.resp.Content[idx].(*api.ToolCallBlock)
+		toolCall.ToolName = e.ToolName
+		toolCall.Args = slices.Clone(e.Args)
+		return nil
+	}
+
 	// Create new tool call block
 	toolCall := &api.ToolCallBlock{
 		ToolCallID: e.ToolCallID,
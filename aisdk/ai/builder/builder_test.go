@@ -1,4 +1,4 @@
-package builder
+package builder_test
 
 import (
 	"encoding/json"
@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.jetify.com/ai/aitesting"
 	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
 )
 
 func TestResponseBuilder(t *testing.T) {
@@ -28,6 +30,20 @@ func TestResponseBuilder(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "stream start warnings and raw chunks",
+			events: []api.StreamEvent{
+				&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}}},
+				&api.RawChunkEvent{Data: json.RawMessage(`{"delta":"Hello"}`)},
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+			},
+			expected: &api.Response{
+				Content: []api.ContentBlock{
+					&api.TextBlock{Text: "Hello"},
+				},
+				Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}},
+			},
+		},
 		{
 			name: "multiple text deltas",
 			events: []api.StreamEvent{
@@ -218,6 +234,30 @@ func TestResponseBuilder(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "complete tool call after deltas",
+			events: []api.StreamEvent{
+				&api.ToolCallDeltaEvent{
+					ToolCallID: "call_1",
+					ToolName:   "test_tool",
+					ArgsDelta:  json.RawMessage(`{"arg1":`),
+				},
+				&api.ToolCallEvent{
+					ToolCallID: "call_1",
+					ToolName:   "test_tool",
+					Args:       json.RawMessage(`{"arg1":"value1"}`),
+				},
+			},
+			expected: &api.Response{
+				Content: []api.ContentBlock{
+					&api.ToolCallBlock{
+						ToolCallID: "call_1",
+						ToolName:   "test_tool",
+						Args:       json.RawMessage(`{"arg1":"value1"}`),
+					},
+				},
+			},
+		},
 		{
 			name: "multiple tool calls through deltas",
 			events: []api.StreamEvent{
@@ -346,19 +386,19 @@ func TestResponseBuilder(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			builder := NewResponseBuilder()
+			b := builder.NewResponseBuilder()
 
 			for _, event := range tt.events {
-				err := builder.AddEvent(event)
+				err := b.AddEvent(event)
 				assert.NoError(t, err)
 			}
 
 			if tt.metadata != nil {
-				err := builder.AddMetadata(tt.metadata)
+				err := b.AddMetadata(tt.metadata)
 				assert.NoError(t, err)
 			}
 
-			resp, err := builder.Build()
+			resp, err := b.Build()
 			if tt.name == "error event" {
 				assert.Error(t, err)
 				assert.Equal(t, "test error", err.Error())
@@ -455,10 +495,10 @@ func TestResponseBuilder_ValueTypes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			builder := NewResponseBuilder()
+			b := builder.NewResponseBuilder()
 
 			for _, event := range tt.events {
-				err := builder.AddEvent(event)
+				err := b.AddEvent(event)
 				if tt.wantErr {
 					assert.Error(t, err)
 					return
@@ -466,7 +506,7 @@ func TestResponseBuilder_ValueTypes(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			resp, err := builder.Build()
+			resp, err := b.Build()
 			if tt.name == "error event value type" {
 				assert.Error(t, err)
 				assert.Equal(t, "test error", err.Error())
@@ -477,3 +517,85 @@ func TestResponseBuilder_ValueTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestResponseBuilder_ReasoningMetadata(t *testing.T) {
+	redacted := api.NewProviderMetadata(map[string]any{"anthropic": map[string]any{"redacted_data": "abc"}})
+	b := builder.NewResponseBuilder()
+	for _, event := range []api.StreamEvent{
+		&api.ReasoningEvent{TextDelta: "Thinking"},
+		&api.ReasoningEvent{ProviderMetadata: redacted},
+		&api.ReasoningEvent{TextDelta: "More thinking"},
+	} {
+		require.NoError(t, b.AddEvent(event))
+	}
+
+	resp, err := b.Build()
+	require.NoError(t, err)
+	// Reasoning with provider metadata is kept in a block of its own.
+	assert.Equal(t, []api.ContentBlock{
+		&api.ReasoningBlock{Text: "Thinking"},
+		&api.ReasoningBlock{ProviderMetadata: redacted},
+		&api.ReasoningBlock{Text: "More thinking"},
+	}, resp.Content)
+}
+
+func TestResponseBuilder_DuplicateToolCall(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []api.StreamEvent
+	}{
+		{
+			name: "duplicate tool call",
+			events: []api.StreamEvent{
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "test_tool", Args: json.RawMessage(`{}`)},
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "test_tool", Args: json.RawMessage(`{}`)},
+			},
+		},
+		{
+			name: "duplicate tool call after deltas",
+			events: []api.StreamEvent{
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "test_tool", ArgsDelta: []byte(`{}`)},
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "test_tool", Args: json.RawMessage(`{}`)},
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "test_tool", Args: json.RawMessage(`{}`)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := builder.NewResponseBuilder()
+			last := len(tt.events) - 1
+			for _, event := range tt.events[:last] {
+				require.NoError(t, b.AddEvent(event))
+			}
+			assert.EqualError(t, b.AddEvent(tt.events[last]), "duplicate tool call ID: call_1")
+		})
+	}
+}
+
+func TestResponseToStream(t *testing.T) {
+	resp := &api.Response{
+		Content: []api.ContentBlock{
+			&api.ReasoningBlock{Text: "Thinking", Signature: "sig"},
+			&api.TextBlock{Text: "Hello"},
+			&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_weather", Args: []byte(`{"location":"Paris"}`)},
+			&api.SourceBlock{ID: "source-0", URL: "https://example.com", Title: "Example"},
+			&api.FileBlock{MediaType: "image/png", Data: []byte{1, 2, 3}},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+		Usage:        api.Usage{InputTokens: 3, OutputTokens: 4, TotalTokens: 7},
+		ResponseInfo: &api.ResponseInfo{ID: "resp_1", ModelID: "model"},
+		Warnings:     []api.CallWarning{{Type: "other", Message: "warning"}},
+	}
+
+	roundTrip, err := builder.StreamToResponse(builder.ResponseToStream(resp))
+	require.NoError(t, err)
+
+	assert.Equal(t, resp.Content[:3], roundTrip.Content[:3])
+	assert.Equal(t, "https://example.com", roundTrip.Content[3].(*api.SourceBlock).URL)
+	assert.Equal(t, resp.Content[4], roundTrip.Content[4])
+	assert.Equal(t, resp.FinishReason, roundTrip.FinishReason)
+	assert.Equal(t, resp.Usage, roundTrip.Usage)
+	assert.Equal(t, resp.ResponseInfo, roundTrip.ResponseInfo)
+	assert.Equal(t, resp.Warnings, roundTrip.Warnings)
+}
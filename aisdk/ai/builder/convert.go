@@ -29,3 +29,72 @@ func StreamToResponse(stream *api.StreamResponse) (*api.Response, error) {
 
 	return resp, nil
 }
+
+// ResponseToStream converts a Response into a StreamResponse whose stream
+// yields events equivalent to the response content. It is the inverse of
+// StreamToResponse, and is useful to serve non-streaming results to code that
+// expects a stream.
+func ResponseToStream(resp *api.Response) *api.StreamResponse {
+	if resp == nil {
+		return nil
+	}
+
+	events := responseEvents(resp)
+	return &api.StreamResponse{
+		Stream: func(yield func(api.StreamEvent) bool) {
+			for _, event := range events {
+				if !yield(event) {
+					return
+				}
+			}
+		},
+		RequestInfo:  resp.RequestInfo,
+		ResponseInfo: resp.ResponseInfo,
+	}
+}
+
+// responseEvents returns the stream events that describe the response.
+func responseEvents(resp *api.Response) []api.StreamEvent {
+	events := []api.StreamEvent{&api.StreamStartEvent{Warnings: resp.Warnings}}
+
+	if info := resp.ResponseInfo; info != nil && (info.ID != "" || !info.Timestamp.IsZero() || info.ModelID != "") {
+		events = append(events, &api.ResponseMetadataEvent{
+			ID:        info.ID,
+			Timestamp: info.Timestamp,
+			ModelID:   info.ModelID,
+		})
+	}
+
+	for _, block := range resp.Content {
+		switch b := block.(type) {
+		case *api.TextBlock:
+			events = append(events, &api.TextDeltaEvent{TextDelta: b.Text})
+		case *api.ReasoningBlock:
+			events = append(events, &api.ReasoningEvent{TextDelta: b.Text, ProviderMetadata: b.ProviderMetadata})
+			if b.Signature != "" {
+				events = append(events, &api.ReasoningSignatureEvent{Signature: b.Signature})
+			}
+		case *api.ToolCallBlock:
+			events = append(events, &api.ToolCallEvent{
+				ToolCallID: b.ToolCallID,
+				ToolName:   b.ToolName,
+				Args:       b.Args,
+			})
+		case *api.SourceBlock:
+			source := api.Source{SourceType: "url", ID: b.ID, URL: b.URL, Title: b.Title}
+			if b.ProviderMetadata != nil {
+				source.ProviderMetadata = *b.ProviderMetadata
+			}
+			events = append(events, &api.SourceEvent{Source: source})
+		case *api.FileBlock:
+			events = append(events, &api.FileEvent{MediaType: b.MediaType, Data: b.Data})
+		}
+	}
+
+	events = append(events, &api.FinishEvent{
+		Usage:            resp.Usage,
+		FinishReason:     resp.FinishReason,
+		ProviderMetadata: resp.ProviderMetadata,
+	})
+	return events
+}
@@ -0,0 +1,405 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+	"go.jetify.com/pkg/cachehash"
+	"go.jetify.com/pkg/filecache"
+)
+
+// CacheMetadataKey is the provider metadata key used by CacheMiddleware. Cached
+// responses carry a *CacheMetadata under this key, and calls can set one to
+// configure the cache (see WithCacheBypass).
+const CacheMetadataKey = "cache"
+
+// CacheMetadata describes how a call interacts with CacheMiddleware.
+type CacheMetadata struct {
+	// Hit is true for responses that were served from the cache.
+	Hit bool `json:"hit,omitzero"`
+
+	// Bypass skips the cache for a call: the model is always called, and its
+	// response is not stored.
+	Bypass bool `json:"bypass,omitzero"`
+}
+
+// GetCacheMetadata returns the cache metadata of a response or call options,
+// or nil if there is none. A response served from the cache has metadata with
+// Hit set to true.
+func GetCacheMetadata(source api.MetadataSource) *CacheMetadata {
+	return api.GetMetadata[CacheMetadata](CacheMetadataKey, source)
+}
+
+// WithCacheBypass skips the cache configured with CacheMiddleware for a call.
+func WithCacheBypass() GenerateOption {
+	return WithProviderMetadata(CacheMetadataKey, &CacheMetadata{Bypass: true})
+}
+
+// CacheStore stores the responses cached by CacheMiddleware.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the response stored under the key. It returns false if there
+	// is no response, or if it has expired.
+	Get(ctx context.Context, key string) (*api.Response, bool, error)
+
+	// Set stores the response under the key. A ttl of zero or less means the
+	// response never expires.
+	Set(ctx context.Context, key string, resp *api.Response, ttl time.Duration) error
+}
+
+// MemoryCacheStore is a CacheStore that keeps responses in memory. It stores
+// and returns copies of the responses, so that callers can modify them
+// without affecting the cache. The values of the provider metadata are not
+// copied.
+type MemoryCacheStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+type memoryCacheEntry struct {
+	resp    *api.Response
+	expires time.Time
+}
+
+var _ CacheStore = &MemoryCacheStore{}
+
+// NewMemoryCacheStore creates an empty MemoryCacheStore.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		entries: map[string]memoryCacheEntry{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) (*api.Response, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && s.now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return cloneResponse(entry.resp), true, nil
+}
+
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, resp *api.Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := memoryCacheEntry{resp: cloneResponse(resp)}
+	if ttl > 0 {
+		entry.expires = s.now().Add(ttl)
+	}
+	s.entries[key] = entry
+	return nil
+}
+
+// FileCacheStore is a CacheStore that keeps responses in files, using a
+// filecache.Cache. Responses are stored as JSON, so provider metadata is
+// returned as generic maps instead of the provider's types. The GetMetadata
+// functions of the providers convert them back to their types.
+type FileCacheStore struct {
+	cache *filecache.Cache[*api.Response]
+}
+
+var _ CacheStore = &FileCacheStore{}
+
+// NewFileCacheStore creates a FileCacheStore that stores responses in the
+// given cache:
+//
+//	store := ai.NewFileCacheStore(filecache.New[*api.Response]("my-app/llm"))
+func NewFileCacheStore(cache *filecache.Cache[*api.Response]) *FileCacheStore {
+	return &FileCacheStore{cache: cache}
+}
+
+func (s *FileCacheStore) Get(ctx context.Context, key string) (*api.Response, bool, error) {
+	resp, err := s.cache.Get(key)
+	if filecache.IsCacheMiss(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return resp, true, nil
+}
+
+func (s *FileCacheStore) Set(ctx context.Context, key string, resp *api.Response, ttl time.Duration) error {
+	if ttl <= 0 {
+		// filecache has no way to store values without an expiration
+		return s.cache.SetWithTime(key, resp, time.Now().AddDate(100, 0, 0))
+	}
+	return s.cache.Set(key, resp, ttl)
+}
+
+// CacheOptions configures CacheMiddleware.
+type CacheOptions struct {
+	// Store is where responses are cached. Defaults to a new MemoryCacheStore.
+	Store CacheStore
+
+	// TTL is how long responses are cached. Zero means they never expire.
+	TTL time.Duration
+
+	// OnError is called when the response of a streaming call can't be
+	// stored in the cache. The stream has already completed by then, so the
+	// error is not sent as an event. If nil, the error is dropped.
+	OnError func(ctx context.Context, err error)
+}
+
+// CacheMiddleware returns a middleware that caches the responses of the model,
+// which is useful during development and evaluations, where the same prompts
+// are sent over and over.
+//
+// Calls are keyed by the provider name, model ID, prompt and call options,
+// excluding the HTTP headers. Streaming calls served from the cache replay the
+// cached response as a stream of events, and responses of streaming calls are
+// only cached if the stream is consumed completely without errors.
+//
+// Cached responses carry a CacheMetadata with Hit set to true, which can be
+// read with GetCacheMetadata. Use WithCacheBypass to skip the cache for a
+// call. Failures to read or write the cache are returned as errors, except
+// for failures to store a streamed response, which are reported to
+// CacheOptions.OnError.
+//
+//	model := ai.WrapLanguageModel(openai.NewLanguageModel(openai.ChatModelGPT5),
+//		ai.CacheMiddleware(ai.CacheOptions{TTL: 24 * time.Hour}),
+//	)
+func CacheMiddleware(options CacheOptions) *Middleware {
+	if options.Store == nil {
+		options.Store = NewMemoryCacheStore()
+	}
+	store := options.Store
+
+	return &Middleware{
+		WrapGenerate: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+			opts, bypass := cacheCallOptions(opts)
+			if bypass {
+				return model.Generate(ctx, prompt, opts)
+			}
+
+			key, err := cacheKey(model, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+			if cached, ok, err := store.Get(ctx, key); err != nil {
+				return nil, err
+			} else if ok {
+				return cacheHit(cached), nil
+			}
+
+			resp, err := model.Generate(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+			if err := store.Set(ctx, key, resp, options.TTL); err != nil {
+				return nil, err
+			}
+			return resp, nil
+		},
+
+		WrapStream: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+			opts, bypass := cacheCallOptions(opts)
+			if bypass {
+				return model.Stream(ctx, prompt, opts)
+			}
+
+			key, err := cacheKey(model, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+			if cached, ok, err := store.Get(ctx, key); err != nil {
+				return nil, err
+			} else if ok {
+				return builder.ResponseToStream(cacheHit(cached)), nil
+			}
+
+			resp, err := model.Stream(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			result := *resp
+			result.Stream = func(yield func(api.StreamEvent) bool) {
+				b := builder.NewResponseBuilder()
+				cacheable := b.AddMetadata(resp) == nil
+				for event := range resp.Stream {
+					if _, ok := event.(*api.ErrorEvent); ok {
+						cacheable = false
+					}
+					if cacheable && b.AddEvent(event) != nil {
+						cacheable = false
+					}
+					if !yield(event) {
+						return
+					}
+				}
+				if !cacheable {
+					return
+				}
+
+				built, err := b.Build()
+				if err == nil {
+					err = store.Set(ctx, key, built, options.TTL)
+				}
+				if err != nil && options.OnError != nil {
+					options.OnError(ctx, err)
+				}
+			}
+			return &result, nil
+		},
+	}
+}
+
+// cacheCallOptions removes the cache metadata from the call options, so that
+// it's neither sent to the model nor part of the cache key, and reports
+// whether the call bypasses the cache.
+func cacheCallOptions(opts api.CallOptions) (api.CallOptions, bool) {
+	metadata := GetCacheMetadata(&opts)
+	if metadata == nil && !opts.ProviderMetadata.Has(CacheMetadataKey) {
+		return opts, false
+	}
+
+	providerMetadata := api.NewProviderMetadata(nil)
+	for _, provider := range opts.ProviderMetadata.Providers() {
+		if provider == CacheMetadataKey {
+			continue
+		}
+		value, _ := opts.ProviderMetadata.Get(provider)
+		providerMetadata.Set(provider, value)
+	}
+	opts.ProviderMetadata = providerMetadata
+	return opts, metadata != nil && metadata.Bypass
+}
+
+// cacheKey returns the key of a call in the cache.
+func cacheKey(model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (string, error) {
+	opts.Headers = nil
+	key, err := cachehash.JSON(struct {
+		Provider string           `json:"provider"`
+		ModelID  string           `json:"model_id"`
+		Prompt   []api.Message    `json:"prompt"`
+		Options  *api.CallOptions `json:"options"`
+	}{
+		Provider: model.ProviderName(),
+		ModelID:  model.ModelID(),
+		Prompt:   prompt,
+		Options:  &opts,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cache key: %w", err)
+	}
+	return key, nil
+}
+
+// cacheHit returns a copy of the cached response marked as a cache hit.
+func cacheHit(cached *api.Response) *api.Response {
+	resp := *cached
+	resp.ProviderMetadata = withMetadata(cached.ProviderMetadata, CacheMetadataKey, &CacheMetadata{Hit: true})
+	return &resp
+}
+
+// withMetadata returns a copy of the provider metadata with the value set for
+// the given key, leaving the original untouched.
+func withMetadata(source *api.ProviderMetadata, key string, value any) *api.ProviderMetadata {
+	metadata := cloneMetadata(source)
+	if metadata == nil {
+		metadata = api.NewProviderMetadata(nil)
+	}
+	metadata.Set(key, value)
+	return metadata
+}
+
+// cloneMetadata returns a copy of the provider metadata. The values of the
+// providers are shared with the original.
+func cloneMetadata(source *api.ProviderMetadata) *api.ProviderMetadata {
+	if source == nil {
+		return nil
+	}
+	metadata := api.NewProviderMetadata(nil)
+	for _, provider := range source.Providers() {
+		value, _ := source.Get(provider)
+		metadata.Set(provider, value)
+	}
+	return metadata
+}
+
+// cloneResponse returns a copy of the response whose content, warnings and
+// request and response information can be modified without affecting the
+// original.
+func cloneResponse(resp *api.Response) *api.Response {
+	clone := *resp
+	clone.Content = cloneContent(resp.Content)
+	clone.ProviderMetadata = cloneMetadata(resp.ProviderMetadata)
+	clone.Warnings = slices.Clone(resp.Warnings)
+	if resp.RequestInfo != nil {
+		info := *resp.RequestInfo
+		info.Body = slices.Clone(info.Body)
+		clone.RequestInfo = &info
+	}
+	if resp.ResponseInfo != nil {
+		info := *resp.ResponseInfo
+		info.Headers = info.Headers.Clone()
+		info.Body = slices.Clone(info.Body)
+		clone.ResponseInfo = &info
+	}
+	return &clone
+}
+
+func cloneContent(content []api.ContentBlock) []api.ContentBlock {
+	if content == nil {
+		return nil
+	}
+	clone := make([]api.ContentBlock, len(content))
+	for i, block := range content {
+		clone[i] = cloneContentBlock(block)
+	}
+	return clone
+}
+
+func cloneContentBlock(block api.ContentBlock) api.ContentBlock {
+	switch block := block.(type) {
+	case *api.TextBlock:
+		clone := *block
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	case *api.ReasoningBlock:
+		clone := *block
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	case *api.ImageBlock:
+		clone := *block
+		clone.Data = slices.Clone(block.Data)
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	case *api.FileBlock:
+		clone := *block
+		clone.Data = slices.Clone(block.Data)
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	case *api.ToolCallBlock:
+		clone := *block
+		clone.Args = slices.Clone(block.Args)
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	case *api.ToolResultBlock:
+		clone := *block
+		clone.Content = cloneContent(block.Content)
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	case *api.SourceBlock:
+		clone := *block
+		clone.ProviderMetadata = cloneMetadata(block.ProviderMetadata)
+		return &clone
+	default:
+		return block
+	}
+}
@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/aitesting"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+	"go.jetify.com/pkg/filecache"
+)
+
+func TestCacheMiddleware_Generate(t *testing.T) {
+	stores := map[string]CacheStore{
+		"memory": NewMemoryCacheStore(),
+		"file":   NewFileCacheStore(filecache.New("ai-test", filecache.WithCacheDir[*api.Response](t.TempDir()))),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			inner := mock.NewGenerateModel([]mock.MockResult{
+				{Response: textResponse("Hello", api.Usage{InputTokens: 1})},
+				{Response: textResponse("Other prompt", api.Usage{})},
+				{Response: textResponse("Bypassed", api.Usage{})},
+			})
+			model := WrapLanguageModel(inner, CacheMiddleware(CacheOptions{Store: store}))
+
+			first, err := GenerateTextStr(t.Context(), "Hi", WithModel(model))
+			require.NoError(t, err)
+			assert.Nil(t, GetCacheMetadata(first))
+
+			// Headers are not part of the cache key
+			second, err := GenerateTextStr(t.Context(), "Hi", WithModel(model),
+				WithHeaders(http.Header{"X-Request-Id": {"2"}}))
+			require.NoError(t, err)
+			assert.Equal(t, first.Content, second.Content)
+			assert.Equal(t, first.Usage, second.Usage)
+			assert.Equal(t, &CacheMetadata{Hit: true}, GetCacheMetadata(second))
+
+			other, err := GenerateTextStr(t.Context(), "Bye", WithModel(model))
+			require.NoError(t, err)
+			assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "Other prompt"}}, other.Content)
+
+			bypassed, err := GenerateTextStr(t.Context(), "Hi", WithModel(model), WithCacheBypass())
+			require.NoError(t, err)
+			assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "Bypassed"}}, bypassed.Content)
+			assert.Nil(t, GetCacheMetadata(bypassed))
+
+			// The cache metadata is not sent to the model
+			assert.False(t, inner.Calls()[2].Options.ProviderMetadata.Has(CacheMetadataKey))
+			inner.AssertCount(t)
+		})
+	}
+}
+
+func TestCacheMiddleware_GenerateError(t *testing.T) {
+	inner := mock.NewGenerateModel([]mock.MockResult{
+		{Error: errors.New("rate limit exceeded")},
+		{Response: textResponse("Hello", api.Usage{})},
+	})
+	model := WrapLanguageModel(inner, CacheMiddleware(CacheOptions{}))
+
+	_, err := model.Generate(t.Context(), nil, api.CallOptions{})
+	require.Error(t, err)
+
+	resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, GetCacheMetadata(resp), "errors are not cached")
+	inner.AssertCount(t)
+}
+
+func TestCacheMiddleware_Stream(t *testing.T) {
+	events := []api.StreamEvent{
+		&api.StreamStartEvent{},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.TextDeltaEvent{TextDelta: " world"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop, Usage: api.Usage{InputTokens: 1, OutputTokens: 2}},
+	}
+	inner := mock.NewStreamModel([]mock.StreamResult{
+		{Events: events[:2], StreamError: errors.New("connection reset")},
+		{Events: events},
+	})
+	model := WrapLanguageModel(inner, CacheMiddleware(CacheOptions{}))
+
+	// Streams that fail are not cached
+	resp, err := model.Stream(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	assert.Len(t, slices.Collect(resp.Stream), 3)
+
+	resp, err = model.Stream(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, events, slices.Collect(resp.Stream))
+
+	// The complete stream was cached, and is replayed without calling the model
+	resp, err = model.Stream(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	replayed := slices.Collect(resp.Stream)
+	require.Len(t, replayed, 3)
+	assert.Equal(t, &api.TextDeltaEvent{TextDelta: "Hello world"}, replayed[1])
+	finish, ok := replayed[2].(*api.FinishEvent)
+	require.True(t, ok)
+	assert.Equal(t, events[3].(*api.FinishEvent).Usage, finish.Usage)
+	assert.Equal(t, &CacheMetadata{Hit: true}, GetCacheMetadata(&api.Response{ProviderMetadata: finish.ProviderMetadata}))
+
+	// Generate shares the cache with Stream
+	generated, err := model.Generate(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "Hello world"}}, generated.Content)
+	inner.AssertCount(t)
+}
+
+// failingCacheStore is a CacheStore that fails to store responses.
+type failingCacheStore struct {
+	*MemoryCacheStore
+}
+
+func (s *failingCacheStore) Set(ctx context.Context, key string, resp *api.Response, ttl time.Duration) error {
+	return errors.New("disk full")
+}
+
+func TestCacheMiddleware_StreamStoreError(t *testing.T) {
+	events := []api.StreamEvent{
+		&api.StreamStartEvent{},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop},
+	}
+	inner := mock.NewStreamModel([]mock.StreamResult{{Events: events}})
+	var storeErr error
+	model := WrapLanguageModel(inner, CacheMiddleware(CacheOptions{
+		Store:   &failingCacheStore{MemoryCacheStore: NewMemoryCacheStore()},
+		OnError: func(ctx context.Context, err error) { storeErr = err },
+	}))
+
+	// The stream completes without errors, and the failure is reported to
+	// OnError.
+	resp, err := model.Stream(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	streamed := aitesting.CollectEvents(resp)
+	assert.Equal(t, events, streamed)
+	aitesting.StreamValid(t, streamed)
+	require.EqualError(t, storeErr, "disk full")
+}
+
+func TestMemoryCacheStore_TTL(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryCacheStore()
+	store.now = func() time.Time { return now }
+
+	resp := textResponse("Hello", api.Usage{})
+	require.NoError(t, store.Set(t.Context(), "expiring", resp, time.Minute))
+	require.NoError(t, store.Set(t.Context(), "forever", resp, 0))
+
+	cached, ok, err := store.Get(t.Context(), "expiring")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, resp, cached)
+
+	now = now.Add(2 * time.Minute)
+	_, ok, err = store.Get(t.Context(), "expiring")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = store.Get(t.Context(), "forever")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCacheStores_ReturnCopies(t *testing.T) {
+	type testMetadata struct {
+		ServedBy string `json:"served_by"`
+	}
+
+	stores := map[string]CacheStore{
+		"memory": NewMemoryCacheStore(),
+		"file":   NewFileCacheStore(filecache.New("ai-test", filecache.WithCacheDir[*api.Response](t.TempDir()))),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			resp := &api.Response{
+				Content: []api.ContentBlock{
+					&api.TextBlock{Text: "Hello"},
+					&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "search", Args: []byte(`{}`)},
+				},
+				FinishReason: api.FinishReasonToolCalls,
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					"test": &testMetadata{ServedBy: "test-provider"},
+				}),
+				ResponseInfo: &api.ResponseInfo{ID: "resp_1", Headers: http.Header{"X-Id": {"1"}}},
+			}
+			require.NoError(t, store.Set(t.Context(), "key", resp, 0))
+
+			// The stored response is not affected by changes to the original.
+			resp.Content[0].(*api.TextBlock).Text = "Changed"
+			resp.ResponseInfo.Headers.Set("X-Id", "2")
+
+			first, ok, err := store.Get(t.Context(), "key")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, &api.TextBlock{Text: "Hello"}, first.Content[0])
+			assert.Equal(t, "1", first.ResponseInfo.Headers.Get("X-Id"))
+			assert.Equal(t, &testMetadata{ServedBy: "test-provider"},
+				api.GetMetadata[testMetadata]("test", first))
+
+			// Nor by changes to the responses it returns.
+			first.Content[0].(*api.TextBlock).Text = "Changed"
+			first.Content[1].(*api.ToolCallBlock).Args[0] = '['
+			first.ProviderMetadata.Set("test", &testMetadata{ServedBy: "other"})
+
+			second, ok, err := store.Get(t.Context(), "key")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, &api.TextBlock{Text: "Hello"}, second.Content[0])
+			assert.JSONEq(t, `{}`, string(second.Content[1].(*api.ToolCallBlock).Args))
+			assert.Equal(t, &testMetadata{ServedBy: "test-provider"},
+				api.GetMetadata[testMetadata]("test", second))
+		})
+	}
+}
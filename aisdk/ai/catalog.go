@@ -0,0 +1,190 @@
+package ai
+
+import (
+	"context"
+	"sync"
+
+	"go.jetify.com/ai/api"
+)
+
+var (
+	customModelsMu sync.RWMutex
+	customModels   = map[string]api.ModelInfo{}
+)
+
+// RegisterModelInfo adds a model to the catalog used by LookupModelInfo, or
+// replaces the built-in entry for the same provider and model ID. It is useful
+// for custom models, e.g. served through an OpenAI-compatible provider, and
+// for negotiated prices.
+func RegisterModelInfo(info api.ModelInfo) {
+	customModelsMu.Lock()
+	defer customModelsMu.Unlock()
+	customModels[info.Provider+DefaultSeparator+info.ModelID] = info.Clone()
+}
+
+// LookupModelInfo returns the capabilities, limits and price of the model with
+// the given ID served by the named provider. The catalog contains the models
+// registered with RegisterModelInfo and the models of the catalogs registered
+// by the providers with api.RegisterModelCatalog, like the built-in OpenAI,
+// Anthropic and OpenRouter providers. A provider's catalog is only available
+// once its package is imported. It returns false for unknown models.
+//
+//	info, ok := ai.LookupModelInfo("openai", openai.ChatModelGPT5)
+//	if ok {
+//		fmt.Printf("$%.4f\n", info.Cost(resp.Usage))
+//	}
+func LookupModelInfo(providerName, modelID string) (api.ModelInfo, bool) {
+	customModelsMu.RLock()
+	info, ok := customModels[providerName+DefaultSeparator+modelID]
+	customModelsMu.RUnlock()
+	if ok {
+		return info.Clone(), true
+	}
+	return api.LookupCatalogModel(providerName, modelID)
+}
+
+// ModelInfoOf returns the catalog entry of the given model. See
+// LookupModelInfo.
+func ModelInfoOf(model api.LanguageModel) (api.ModelInfo, bool) {
+	return LookupModelInfo(model.ProviderName(), model.ModelID())
+}
+
+// UsageSummary is the token usage and cost of a set of model calls.
+type UsageSummary struct {
+	// Calls is the number of model calls.
+	Calls int `json:"calls"`
+
+	// Usage is the total token usage of the calls.
+	Usage api.Usage `json:"usage"`
+
+	// Cost is the total cost in USD of the calls whose price is known.
+	Cost float64 `json:"cost"`
+
+	// UnpricedCalls is the number of calls to models without known pricing,
+	// which are not included in Cost.
+	UnpricedCalls int `json:"unpriced_calls,omitzero"`
+}
+
+func (s *UsageSummary) add(usage api.Usage, info api.ModelInfo, ok bool) {
+	s.Calls++
+	s.Usage = addUsage(s.Usage, usage)
+	if ok && info.Pricing != nil {
+		s.Cost += info.Cost(usage)
+	} else {
+		s.UnpricedCalls++
+	}
+}
+
+// UsageAggregator sums the token usage and cost of model calls, in total and
+// per model. Use one aggregator per feature to track spend by feature:
+//
+//	summarizerUsage := ai.NewUsageAggregator()
+//	model := ai.WrapLanguageModel(openai.NewLanguageModel(openai.ChatModelGPT5),
+//		summarizerUsage.Middleware(),
+//	)
+//	...
+//	total := summarizerUsage.Total()
+//
+// Costs are computed with the prices in the catalog (see LookupModelInfo).
+// A UsageAggregator is safe for concurrent use.
+type UsageAggregator struct {
+	mu      sync.Mutex
+	total   UsageSummary
+	byModel map[string]*UsageSummary
+}
+
+// NewUsageAggregator creates an empty UsageAggregator.
+func NewUsageAggregator() *UsageAggregator {
+	return &UsageAggregator{byModel: map[string]*UsageSummary{}}
+}
+
+// Add records the usage of a single call to the model with the given ID,
+// served by the named provider.
+func (a *UsageAggregator) Add(providerName, modelID string, usage api.Usage) {
+	info, ok := LookupModelInfo(providerName, modelID)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.total.add(usage, info, ok)
+	key := providerName + DefaultSeparator + modelID
+	summary := a.byModel[key]
+	if summary == nil {
+		summary = &UsageSummary{}
+		a.byModel[key] = summary
+	}
+	summary.add(usage, info, ok)
+}
+
+// AddSteps records the usage of every step of a generation made with the
+// given model, e.g. the Steps of a TextResponse.
+func (a *UsageAggregator) AddSteps(model api.LanguageModel, steps []*Step) {
+	for _, step := range steps {
+		if step.Response != nil {
+			a.Add(model.ProviderName(), model.ModelID(), step.Response.Usage)
+		}
+	}
+}
+
+// Total returns the usage and cost of all the recorded calls.
+func (a *UsageAggregator) Total() UsageSummary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total
+}
+
+// ByModel returns the usage and cost of the recorded calls, keyed by
+// "provider:model".
+func (a *UsageAggregator) ByModel() map[string]UsageSummary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make(map[string]UsageSummary, len(a.byModel))
+	for key, summary := range a.byModel {
+		result[key] = *summary
+	}
+	return result
+}
+
+// Reset clears all the recorded calls.
+func (a *UsageAggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total = UsageSummary{}
+	a.byModel = map[string]*UsageSummary{}
+}
+
+// Middleware returns a middleware that records the usage of every call made to
+// the model, including each step of multi-step generations. Streaming calls
+// are recorded when their finish event is received.
+func (a *UsageAggregator) Middleware() *Middleware {
+	return &Middleware{
+		WrapGenerate: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+			resp, err := model.Generate(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+			a.Add(model.ProviderName(), model.ModelID(), resp.Usage)
+			return resp, nil
+		},
+		WrapStream: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+			resp, err := model.Stream(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			result := *resp
+			result.Stream = func(yield func(api.StreamEvent) bool) {
+				for event := range resp.Stream {
+					if finish, ok := event.(*api.FinishEvent); ok {
+						a.Add(model.ProviderName(), model.ModelID(), finish.Usage)
+					}
+					if !yield(event) {
+						return
+					}
+				}
+			}
+			return &result, nil
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/anthropic"
+	"go.jetify.com/ai/provider/mock"
+	"go.jetify.com/ai/provider/openai"
+	"go.jetify.com/ai/provider/openrouter"
+	"go.jetify.com/ai/provider/openrouter/model"
+)
+
+func TestLookupModelInfo(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      string
+		modelID       string
+		found         bool
+		contextWindow int
+		pricing       *api.Pricing
+	}{
+		{
+			name:          "openai",
+			provider:      openai.ProviderName,
+			modelID:       openai.ChatModelGPT4o2024_08_06,
+			found:         true,
+			contextWindow: 128_000,
+			pricing:       &api.Pricing{Input: 2.50, CachedInput: 1.25, Output: 10},
+		},
+		{
+			name:          "anthropic",
+			provider:      anthropic.ProviderName,
+			modelID:       anthropic.ModelClaudeSonnet4_0,
+			found:         true,
+			contextWindow: 200_000,
+			pricing:       &api.Pricing{Input: 3, CachedInput: 0.30, CacheWriteInput: 3.75, Output: 15, CachedInputExcluded: true},
+		},
+		{
+			name:          "openrouter",
+			provider:      openrouter.ProviderName,
+			modelID:       model.AnthropicClaude35Sonnet,
+			found:         true,
+			contextWindow: 200_000,
+			pricing:       &api.Pricing{Input: 3, CachedInput: 0.30, Output: 15},
+		},
+		{
+			name:     "unknown model",
+			provider: openai.ProviderName,
+			modelID:  "unknown",
+		},
+		{
+			name:     "unknown provider",
+			provider: "unknown",
+			modelID:  openai.ChatModelGPT5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, ok := LookupModelInfo(tt.provider, tt.modelID)
+			assert.Equal(t, tt.found, ok)
+			if !tt.found {
+				return
+			}
+			assert.Equal(t, tt.provider, info.Provider)
+			assert.Equal(t, tt.modelID, info.ModelID)
+			assert.Equal(t, tt.contextWindow, info.ContextWindow)
+			assert.Equal(t, tt.pricing, info.Pricing)
+			assert.True(t, info.SupportsInput(api.ModalityText))
+		})
+	}
+}
+
+func TestRegisterModelInfo(t *testing.T) {
+	RegisterModelInfo(api.ModelInfo{
+		Provider: "local",
+		ModelID:  "llama",
+		Pricing:  &api.Pricing{Input: 0.1, Output: 0.2},
+	})
+
+	info, ok := ModelInfoOf(mock.NewGenerateModel(nil, mock.WithProviderName("local"), mock.WithModelID("llama")))
+	require.True(t, ok)
+	assert.InDelta(t, 0.3, info.Cost(api.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000}), 1e-9)
+}
+
+func TestUsageAggregator(t *testing.T) {
+	gpt := mock.NewGenerateModel([]mock.MockResult{
+		{Response: textResponse("one", api.Usage{InputTokens: 1_000_000, OutputTokens: 100_000})},
+		{Response: textResponse("two", api.Usage{InputTokens: 1_000_000, CachedInputTokens: 1_000_000})},
+	}, mock.WithProviderName(openai.ProviderName), mock.WithModelID(openai.ChatModelGPT4_1))
+	unknown := mock.NewStreamModel([]mock.StreamResult{{Events: []api.StreamEvent{
+		&api.TextDeltaEvent{TextDelta: "Hi"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop, Usage: api.Usage{InputTokens: 10, OutputTokens: 5}},
+	}}})
+
+	aggregator := NewUsageAggregator()
+	_, err := GenerateTextStr(t.Context(), "one", WithModel(WrapLanguageModel(gpt, aggregator.Middleware())))
+	require.NoError(t, err)
+	_, err = GenerateTextStr(t.Context(), "two", WithModel(WrapLanguageModel(gpt, aggregator.Middleware())))
+	require.NoError(t, err)
+
+	resp, err := WrapLanguageModel(unknown, aggregator.Middleware()).Stream(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	_ = slices.Collect(resp.Stream)
+
+	gptKey := openai.ProviderName + ":" + openai.ChatModelGPT4_1
+	byModel := aggregator.ByModel()
+	require.Len(t, byModel, 2)
+	assert.Equal(t, 2, byModel[gptKey].Calls)
+	// 1M input at $2, 100k output at $8, and 1M cached input at $0.50
+	assert.InDelta(t, 2+0.8+0.5, byModel[gptKey].Cost, 1e-9)
+	assert.Equal(t, UsageSummary{
+		Calls:         1,
+		Usage:         api.Usage{InputTokens: 10, OutputTokens: 5},
+		UnpricedCalls: 1,
+	}, byModel["mock-provider:mock-model"])
+
+	total := aggregator.Total()
+	assert.Equal(t, 3, total.Calls)
+	assert.Equal(t, 1, total.UnpricedCalls)
+	assert.Equal(t, 2_000_010, total.Usage.InputTokens)
+	assert.InDelta(t, byModel[gptKey].Cost, total.Cost, 1e-9)
+
+	aggregator.Reset()
+	assert.Equal(t, UsageSummary{}, aggregator.Total())
+	assert.Empty(t, aggregator.ByModel())
+}
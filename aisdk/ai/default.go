@@ -27,3 +27,15 @@ func DefaultLanguageModel() api.LanguageModel {
 	wrapper := defaultLanguageModel.Load().(*modelWrapper)
 	return wrapper.model
 }
+
+// SetDefaultLanguageModelID sets the default language model by its ID in the
+// default registry, e.g. "openai:gpt-5". It returns an [api.NoSuchModelError]
+// if the model can't be resolved, in which case the default is left unchanged.
+func SetDefaultLanguageModelID(modelID string) error {
+	model, err := DefaultRegistry().LanguageModel(modelID)
+	if err != nil {
+		return err
+	}
+	SetDefaultLanguageModel(model)
+	return nil
+}
@@ -29,3 +29,17 @@ func TestDefaultLanguageModel(t *testing.T) {
 	assert.Equal(t, "openai", restoredModel.ProviderName())
 	assert.Equal(t, openai.ChatModelGPT5, restoredModel.ModelID())
 }
+
+func TestSetDefaultLanguageModelID(t *testing.T) {
+	originalModel := DefaultLanguageModel()
+	t.Cleanup(func() { SetDefaultLanguageModel(originalModel) })
+
+	err := SetDefaultLanguageModelID("anthropic:" + anthropic.ModelClaudeSonnet4_0)
+	assert.NoError(t, err)
+	assert.Equal(t, "anthropic", DefaultLanguageModel().ProviderName())
+	assert.Equal(t, anthropic.ModelClaudeSonnet4_0, DefaultLanguageModel().ModelID())
+
+	err = SetDefaultLanguageModelID("unknown:model")
+	assert.Error(t, err)
+	assert.Equal(t, anthropic.ModelClaudeSonnet4_0, DefaultLanguageModel().ModelID())
+}
@@ -0,0 +1,305 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.jetify.com/ai/api"
+)
+
+// DefaultMaxDownloadSize is the default maximum size in bytes of a file
+// downloaded from a URL in the prompt.
+const DefaultMaxDownloadSize = 32 << 20 // 32 MiB
+
+// maxParallelDownloads is the maximum number of URLs downloaded at once.
+const maxParallelDownloads = 8
+
+// DownloadOptions configures how media URLs in the prompt are downloaded when
+// the model doesn't support them (see [api.LanguageModel.SupportedUrls]).
+type DownloadOptions struct {
+	// Disabled turns off downloads, so that all URLs are passed to the model
+	// as is.
+	Disabled bool
+
+	// HTTPClient is the client used to download the URLs. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	// MaxSize is the maximum size in bytes of a downloaded file. Defaults to
+	// DefaultMaxDownloadSize.
+	MaxSize int64
+}
+
+// WithDownloadOptions configures how media URLs that the model doesn't support
+// are downloaded before calling the model.
+func WithDownloadOptions(options DownloadOptions) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.Download = options
+	}
+}
+
+// downloadUnsupportedURLs downloads the image and file URLs of the prompt that
+// the model doesn't support natively, and returns a copy of the prompt where
+// those blocks contain the downloaded data instead. Only http and https URLs
+// in user messages and tool results are downloaded.
+func downloadUnsupportedURLs(
+	ctx context.Context, model api.LanguageModel, prompt []api.Message, options DownloadOptions,
+) ([]api.Message, error) {
+	if options.Disabled {
+		return prompt, nil
+	}
+	supported, err := compileSupportedURLs(model.SupportedUrls())
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	seen := map[string]bool{}
+	mapMediaBlocks(prompt, func(block api.ContentBlock) api.ContentBlock {
+		rawURL, mediaType, ok := mediaURL(block)
+		if ok && !seen[rawURL] && isDownloadable(rawURL) && !supported.matches(mediaType, rawURL) {
+			seen[rawURL] = true
+			urls = append(urls, rawURL)
+		}
+		return block
+	})
+	if len(urls) == 0 {
+		return prompt, nil
+	}
+
+	client := options.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	maxSize := options.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDownloadSize
+	}
+	files, err := runParallel(ctx, urls, maxParallelDownloads,
+		func(ctx context.Context, rawURL string) (*downloadedFile, error) {
+			return download(ctx, client, rawURL, maxSize)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	byURL := make(map[string]*downloadedFile, len(files))
+	for i, file := range files {
+		byURL[urls[i]] = file
+	}
+	return mapMediaBlocks(prompt, func(block api.ContentBlock) api.ContentBlock {
+		rawURL, _, ok := mediaURL(block)
+		if !ok || byURL[rawURL] == nil {
+			return block
+		}
+		return inlineBlock(block, byURL[rawURL])
+	}), nil
+}
+
+// supportedURLs is the compiled form of the URLs supported by a model.
+type supportedURLs []compiledSupportedURL
+
+type compiledSupportedURL struct {
+	mediaType string
+	patterns  []*regexp.Regexp
+}
+
+func compileSupportedURLs(urls []api.SupportedURL) (supportedURLs, error) {
+	result := make(supportedURLs, 0, len(urls))
+	for _, supported := range urls {
+		compiled := compiledSupportedURL{mediaType: strings.ToLower(supported.MediaType)}
+		for _, pattern := range supported.URLPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid supported URL pattern %q: %w", pattern, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		result = append(result, compiled)
+	}
+	return result, nil
+}
+
+// matches reports whether the model supports the URL for the given media
+// type. The URL is matched in lowercase.
+func (s supportedURLs) matches(mediaType, rawURL string) bool {
+	mediaType = strings.ToLower(mediaType)
+	rawURL = strings.ToLower(rawURL)
+	for _, supported := range s {
+		if !mediaTypeMatches(supported.mediaType, mediaType) {
+			continue
+		}
+		for _, pattern := range supported.patterns {
+			if pattern.MatchString(rawURL) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mediaTypeMatches reports whether the media type matches the pattern, which
+// can use a '*' wildcard for the whole media type or for its subtype.
+func mediaTypeMatches(pattern, mediaType string) bool {
+	switch {
+	case pattern == "*" || pattern == "*/*":
+		return true
+	case strings.HasSuffix(pattern, "/*"):
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	default:
+		return pattern == mediaType
+	}
+}
+
+// mediaURL returns the URL and media type of an image or file block that
+// references its content by URL. Images without a media type are matched as
+// "image/*".
+func mediaURL(block api.ContentBlock) (string, string, bool) {
+	switch b := block.(type) {
+	case *api.ImageBlock:
+		if b.URL == "" || len(b.Data) > 0 {
+			return "", "", false
+		}
+		mediaType := b.MediaType
+		if mediaType == "" {
+			mediaType = "image/*"
+		}
+		return b.URL, mediaType, true
+	case *api.FileBlock:
+		if b.URL == "" || len(b.Data) > 0 {
+			return "", "", false
+		}
+		return b.URL, b.MediaType, true
+	}
+	return "", "", false
+}
+
+// isDownloadable reports whether the URL can be downloaded by the SDK. Other
+// schemes, e.g. data URLs or cloud storage URLs, are left to the provider.
+func isDownloadable(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// mapMediaBlocks returns a copy of the prompt where fn has been applied to the
+// content blocks of the user messages and tool results.
+func mapMediaBlocks(prompt []api.Message, fn func(api.ContentBlock) api.ContentBlock) []api.Message {
+	result := make([]api.Message, len(prompt))
+	for i, msg := range prompt {
+		switch m := msg.(type) {
+		case *api.UserMessage:
+			user := *m
+			user.Content = mapBlocks(m.Content, fn)
+			result[i] = &user
+		case *api.ToolMessage:
+			tool := *m
+			tool.Content = make([]api.ToolResultBlock, len(m.Content))
+			for j, toolResult := range m.Content {
+				toolResult.Content = mapBlocks(toolResult.Content, fn)
+				tool.Content[j] = toolResult
+			}
+			result[i] = &tool
+		default:
+			result[i] = msg
+		}
+	}
+	return result
+}
+
+func mapBlocks(blocks []api.ContentBlock, fn func(api.ContentBlock) api.ContentBlock) []api.ContentBlock {
+	if blocks == nil {
+		return nil
+	}
+	result := make([]api.ContentBlock, len(blocks))
+	for i, block := range blocks {
+		result[i] = fn(block)
+	}
+	return result
+}
+
+// inlineBlock returns a copy of an image or file block with the downloaded
+// data instead of the URL. The media type of the block is kept if it is
+// specific, and replaced by the detected one otherwise.
+func inlineBlock(block api.ContentBlock, file *downloadedFile) api.ContentBlock {
+	switch b := block.(type) {
+	case *api.ImageBlock:
+		image := *b
+		image.URL = ""
+		image.Data = file.data
+		image.MediaType = resolveMediaType(b.MediaType, file.mediaType)
+		return &image
+	case *api.FileBlock:
+		f := *b
+		f.URL = ""
+		f.Data = file.data
+		f.MediaType = resolveMediaType(b.MediaType, file.mediaType)
+		return &f
+	}
+	return block
+}
+
+func resolveMediaType(declared, detected string) string {
+	if declared == "" || strings.Contains(declared, "*") {
+		return detected
+	}
+	return declared
+}
+
+type downloadedFile struct {
+	data      []byte
+	mediaType string
+}
+
+// download fetches the content of the URL, failing if it is larger than
+// maxSize bytes.
+func download(ctx context.Context, client *http.Client, rawURL string, maxSize int64) (*downloadedFile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, api.NewDownloadError(rawURL, 0, "", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, api.NewDownloadError(rawURL, 0, "", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, api.NewDownloadError(rawURL, resp.StatusCode, http.StatusText(resp.StatusCode), nil)
+	}
+	tooLarge := func() error {
+		return api.NewDownloadError(rawURL, resp.StatusCode, http.StatusText(resp.StatusCode),
+			fmt.Errorf("file exceeds the maximum size of %d bytes", maxSize))
+	}
+	if resp.ContentLength > maxSize {
+		return nil, tooLarge()
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, api.NewDownloadError(rawURL, resp.StatusCode, http.StatusText(resp.StatusCode), err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, tooLarge()
+	}
+	return &downloadedFile{
+		data:      data,
+		mediaType: detectMediaType(resp.Header.Get("Content-Type"), data),
+	}, nil
+}
+
+// detectMediaType returns the media type of downloaded content, using the
+// Content-Type header unless it is missing or generic.
+func detectMediaType(contentType string, data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType != "application/octet-stream" && mediaType != "binary/octet-stream" {
+		return mediaType
+	}
+	mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
@@ -0,0 +1,207 @@
+package ai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+// urlModel is a mock model that supports image URLs, like most providers.
+type urlModel struct {
+	*mock.GenerateModel
+}
+
+func (m *urlModel) SupportedUrls() []api.SupportedURL {
+	return []api.SupportedURL{{MediaType: "image/*", URLPatterns: []string{"^https?://images\\."}}}
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func newMediaServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/doc.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.7"))
+	})
+	mux.HandleFunc("/cat", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(pngHeader)
+	})
+	mux.HandleFunc("/large.pdf", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestDownloadUnsupportedURLs(t *testing.T) {
+	server, requests := newMediaServer(t)
+	supportedImage := "http://images.example.com/cat.png"
+
+	tests := []struct {
+		name     string
+		prompt   []api.Message
+		options  DownloadOptions
+		expected []api.Message
+		requests int32
+	}{
+		{
+			name: "supported URL",
+			prompt: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.ImageBlock{URL: supportedImage},
+			}}},
+			expected: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.ImageBlock{URL: supportedImage},
+			}}},
+		},
+		{
+			name: "unsupported file",
+			prompt: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.TextBlock{Text: "Summarize this"},
+				&api.FileBlock{Filename: "doc.pdf", URL: server.URL + "/doc.pdf", MediaType: "application/pdf"},
+			}}},
+			expected: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.TextBlock{Text: "Summarize this"},
+				&api.FileBlock{Filename: "doc.pdf", Data: []byte("%PDF-1.7"), MediaType: "application/pdf"},
+			}}},
+			requests: 1,
+		},
+		{
+			name: "detects media type and downloads each URL once",
+			prompt: []api.Message{
+				&api.UserMessage{Content: []api.ContentBlock{&api.ImageBlock{URL: server.URL + "/cat"}}},
+				&api.AssistantMessage{Content: api.ContentFromText("A cat.")},
+				&api.ToolMessage{Content: []api.ToolResultBlock{{
+					ToolCallID: "call_1",
+					Content:    []api.ContentBlock{&api.ImageBlock{URL: server.URL + "/cat"}},
+				}}},
+			},
+			expected: []api.Message{
+				&api.UserMessage{Content: []api.ContentBlock{&api.ImageBlock{Data: pngHeader, MediaType: "image/png"}}},
+				&api.AssistantMessage{Content: api.ContentFromText("A cat.")},
+				&api.ToolMessage{Content: []api.ToolResultBlock{{
+					ToolCallID: "call_1",
+					Content:    []api.ContentBlock{&api.ImageBlock{Data: pngHeader, MediaType: "image/png"}},
+				}}},
+			},
+			requests: 1,
+		},
+		{
+			name: "non-http URL",
+			prompt: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.FileBlock{URL: "gs://bucket/doc.pdf", MediaType: "application/pdf"},
+			}}},
+			expected: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.FileBlock{URL: "gs://bucket/doc.pdf", MediaType: "application/pdf"},
+			}}},
+		},
+		{
+			name: "disabled",
+			prompt: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.FileBlock{URL: server.URL + "/doc.pdf", MediaType: "application/pdf"},
+			}}},
+			options: DownloadOptions{Disabled: true},
+			expected: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.FileBlock{URL: server.URL + "/doc.pdf", MediaType: "application/pdf"},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			model := &urlModel{mock.NewGenerateModel([]mock.MockResult{
+				{Response: textResponse("ok", api.Usage{})},
+			})}
+
+			_, err := GenerateText(t.Context(), tt.prompt,
+				WithModel(model),
+				WithDownloadOptions(tt.options),
+			)
+			require.NoError(t, err)
+
+			calls := model.Calls()
+			require.Len(t, calls, 1)
+			assert.Equal(t, tt.expected, calls[0].Prompt)
+			assert.Equal(t, tt.requests, requests.Load())
+		})
+	}
+}
+
+func TestDownloadUnsupportedURLs_Errors(t *testing.T) {
+	server, _ := newMediaServer(t)
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		errMsg     string
+	}{
+		{
+			name:       "not found",
+			url:        server.URL + "/missing.pdf",
+			statusCode: http.StatusNotFound,
+			errMsg:     "404 Not Found",
+		},
+		{
+			name:       "too large",
+			url:        server.URL + "/large.pdf",
+			statusCode: http.StatusOK,
+			errMsg:     "file exceeds the maximum size of 10 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := mock.NewGenerateModel(nil)
+			prompt := []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.FileBlock{URL: tt.url, MediaType: "application/pdf"},
+			}}}
+
+			_, err := GenerateText(t.Context(), prompt,
+				WithModel(model),
+				WithDownloadOptions(DownloadOptions{HTTPClient: server.Client(), MaxSize: 10}),
+			)
+			var downloadErr *api.DownloadError
+			require.ErrorAs(t, err, &downloadErr)
+			assert.Equal(t, tt.url, downloadErr.URL)
+			assert.Equal(t, tt.statusCode, downloadErr.StatusCode)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Empty(t, model.Calls())
+		})
+	}
+}
+
+func TestMediaTypeMatches(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		mediaType string
+		expected  bool
+	}{
+		{pattern: "*/*", mediaType: "application/pdf", expected: true},
+		{pattern: "*", mediaType: "image/png", expected: true},
+		{pattern: "image/*", mediaType: "image/png", expected: true},
+		{pattern: "image/*", mediaType: "image/*", expected: true},
+		{pattern: "image/*", mediaType: "application/pdf", expected: false},
+		{pattern: "application/pdf", mediaType: "application/pdf", expected: true},
+		{pattern: "image/png", mediaType: "image/*", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.mediaType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mediaTypeMatches(tt.pattern, tt.mediaType))
+		})
+	}
+}
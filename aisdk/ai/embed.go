@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"go.jetify.com/ai/api"
+)
+
+// EmbedResponse is the result of embedding a single value.
+type EmbedResponse[T any] struct {
+	// Value is the value that was embedded.
+	Value T
+
+	// Embedding is the embedding of the value.
+	Embedding api.Embedding
+
+	// Usage contains the token usage of the call.
+	Usage api.EmbeddingUsage
+
+	// Response is the response returned by the model.
+	Response *api.EmbeddingResponse
+}
+
+// EmbedManyResponse is the result of embedding several values.
+type EmbedManyResponse[T any] struct {
+	// Values are the values that were embedded.
+	Values []T
+
+	// Embeddings are the embeddings of the values, in the same order as the values.
+	Embeddings []api.Embedding
+
+	// Usage contains the combined token usage of all the calls.
+	Usage api.EmbeddingUsage
+
+	// Responses are the responses returned by the model, one for each call.
+	Responses []*api.EmbeddingResponse
+}
+
+// Embed generates the embedding of a single value using the given model.
+//
+// Example:
+//
+//	model := openai.NewEmbeddingModel(openai.EmbeddingModelTextEmbedding3Small)
+//	resp, err := ai.Embed(ctx, model, "sunny day at the beach")
+func Embed[T any](
+	ctx context.Context, model api.EmbeddingModel[T], value T, opts ...EmbedOption,
+) (*EmbedResponse[T], error) {
+	if model == nil {
+		return nil, api.NewInvalidArgumentError("model must not be nil", "model", nil)
+	}
+	config := buildEmbedConfig(opts)
+
+	resp, err := embedChunk(ctx, model, []T{value}, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmbedResponse[T]{
+		Value:     value,
+		Embedding: resp.Embeddings[0],
+		Usage:     resp.Usage,
+		Response:  resp,
+	}, nil
+}
+
+// EmbedMany generates the embeddings of several values using the given model.
+//
+// If the model limits the number of values per call, the values are split into
+// chunks that are embedded concurrently (unless the model doesn't support
+// parallel calls, or WithMaxParallelCalls is used). The embeddings are
+// returned in the same order as the values.
+func EmbedMany[T any](
+	ctx context.Context, model api.EmbeddingModel[T], values []T, opts ...EmbedOption,
+) (*EmbedManyResponse[T], error) {
+	if model == nil {
+		return nil, api.NewInvalidArgumentError("model must not be nil", "model", nil)
+	}
+	config := buildEmbedConfig(opts)
+
+	result := &EmbedManyResponse[T]{
+		Values:     values,
+		Embeddings: make([]api.Embedding, 0, len(values)),
+	}
+	if len(values) == 0 {
+		return result, nil
+	}
+
+	chunks := chunkValues(values, model.MaxEmbeddingsPerCall())
+	maxParallel := len(chunks)
+	if !model.SupportsParallelCalls() {
+		maxParallel = 1
+	} else if config.MaxParallelCalls > 0 {
+		maxParallel = min(config.MaxParallelCalls, maxParallel)
+	}
+
+	responses, err := embedChunks(ctx, model, chunks, maxParallel, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resp := range responses {
+		result.Embeddings = append(result.Embeddings, resp.Embeddings...)
+		result.Usage.Tokens += resp.Usage.Tokens
+	}
+	result.Responses = responses
+	return result, nil
+}
+
+// embedChunks embeds every chunk, running at most maxParallel calls at once.
+// It stops at the first error, canceling the calls in progress.
+func embedChunks[T any](
+	ctx context.Context, model api.EmbeddingModel[T], chunks [][]T, maxParallel int, config EmbedOptions,
+) ([]*api.EmbeddingResponse, error) {
+	return runParallel(ctx, chunks, maxParallel, func(ctx context.Context, chunk []T) (*api.EmbeddingResponse, error) {
+		return embedChunk(ctx, model, chunk, config)
+	})
+}
+
+// embedChunk embeds the values in a single call, with retries.
+func embedChunk[T any](
+	ctx context.Context, model api.EmbeddingModel[T], values []T, config EmbedOptions,
+) (*api.EmbeddingResponse, error) {
+	retrier := newRetrier(config.MaxRetries, config.Backoff)
+	resp, err := retry(ctx, retrier, func() (*api.EmbeddingResponse, error) {
+		return model.Embed(ctx, values, config.EmbeddingOptions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.Embeddings) != len(values) {
+		got := 0
+		if resp != nil {
+			got = len(resp.Embeddings)
+		}
+		return nil, api.NewInvalidResponseDataError(resp,
+			fmt.Sprintf("expected %d embeddings from the model, got %d", len(values), got))
+	}
+	return resp, nil
+}
+
+// chunkValues splits values into chunks of at most size values. A size of
+// zero or less returns a single chunk.
+func chunkValues[T any](values []T, size int) [][]T {
+	if size <= 0 || len(values) <= size {
+		return [][]T{values}
+	}
+
+	chunks := make([][]T, 0, (len(values)+size-1)/size)
+	for start := 0; start < len(values); start += size {
+		end := min(start+size, len(values))
+		chunks = append(chunks, values[start:end])
+	}
+	return chunks
+}
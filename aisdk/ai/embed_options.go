@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"net/http"
+
+	"go.jetify.com/ai/api"
+)
+
+// EmbedOptions contains the options used by Embed and EmbedMany.
+type EmbedOptions struct {
+	EmbeddingOptions api.EmbeddingOptions
+
+	// MaxParallelCalls is the maximum number of concurrent calls made by
+	// EmbedMany when the values are split into several calls. Zero means no
+	// limit. Models that don't support parallel calls are always called
+	// sequentially.
+	MaxParallelCalls int
+
+	// MaxRetries is the maximum number of times a model call is retried when
+	// it fails with a retryable error. Defaults to 2.
+	MaxRetries int
+
+	// Backoff decides how long to wait between retries. If nil, an
+	// ExponentialBackoff with default settings is used.
+	Backoff BackoffPolicy
+}
+
+// EmbedOption is a function that modifies EmbedOptions.
+type EmbedOption func(*EmbedOptions)
+
+// WithEmbeddingHeaders specifies additional HTTP headers to send with the
+// request. Only applicable for HTTP-based providers.
+func WithEmbeddingHeaders(headers http.Header) EmbedOption {
+	return func(o *EmbedOptions) {
+		o.EmbeddingOptions.Headers = headers
+	}
+}
+
+// WithEmbeddingProviderMetadata sets additional provider-specific metadata,
+// e.g. the number of dimensions of the embeddings.
+func WithEmbeddingProviderMetadata(providerName string, metadata any) EmbedOption {
+	return func(o *EmbedOptions) {
+		if o.EmbeddingOptions.ProviderMetadata == nil {
+			o.EmbeddingOptions.ProviderMetadata = api.NewProviderMetadata(map[string]any{})
+		}
+		o.EmbeddingOptions.ProviderMetadata.Set(providerName, metadata)
+	}
+}
+
+// WithEmbeddingOptions sets the entire EmbeddingOptions struct.
+func WithEmbeddingOptions(embeddingOptions api.EmbeddingOptions) EmbedOption {
+	return func(o *EmbedOptions) {
+		o.EmbeddingOptions = embeddingOptions
+	}
+}
+
+// WithMaxParallelCalls limits the number of concurrent calls made by
+// EmbedMany. Zero means no limit.
+func WithMaxParallelCalls(maxParallelCalls int) EmbedOption {
+	return func(o *EmbedOptions) {
+		o.MaxParallelCalls = maxParallelCalls
+	}
+}
+
+// WithEmbeddingMaxRetries sets the maximum number of times a model call is
+// retried when it fails with a retryable [api.APICallError]. Set it to 0 to
+// disable retries. Defaults to 2.
+func WithEmbeddingMaxRetries(maxRetries int) EmbedOption {
+	return func(o *EmbedOptions) {
+		o.MaxRetries = maxRetries
+	}
+}
+
+// WithEmbeddingBackoff sets the policy that decides how long to wait between
+// retries.
+func WithEmbeddingBackoff(backoff BackoffPolicy) EmbedOption {
+	return func(o *EmbedOptions) {
+		o.Backoff = backoff
+	}
+}
+
+// buildEmbedConfig combines multiple embed options into a single EmbedOptions struct.
+func buildEmbedConfig(opts []EmbedOption) EmbedOptions {
+	config := EmbedOptions{
+		EmbeddingOptions: api.EmbeddingOptions{
+			ProviderMetadata: api.NewProviderMetadata(map[string]any{}),
+		},
+		MaxRetries: defaultMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
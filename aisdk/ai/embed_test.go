@@ -0,0 +1,208 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+// fakeEmbeddingModel embeds each string as a vector holding its length, and
+// records the calls it receives.
+type fakeEmbeddingModel struct {
+	maxPerCall int
+	parallel   bool
+	delay      time.Duration
+
+	// errs are returned, in order, by the first calls.
+	errs []error
+
+	mu          sync.Mutex
+	calls       [][]string
+	options     []api.EmbeddingOptions
+	active      atomic.Int32
+	maxActive   atomic.Int32
+	failedCalls int
+}
+
+var _ api.EmbeddingModel[string] = &fakeEmbeddingModel{}
+
+func (m *fakeEmbeddingModel) ProviderName() string        { return "fake" }
+func (m *fakeEmbeddingModel) ModelID() string             { return "fake-embedding" }
+func (m *fakeEmbeddingModel) MaxEmbeddingsPerCall() int   { return m.maxPerCall }
+func (m *fakeEmbeddingModel) SupportsParallelCalls() bool { return m.parallel }
+
+func (m *fakeEmbeddingModel) Embed(ctx context.Context, values []string, opts api.EmbeddingOptions) (*api.EmbeddingResponse, error) {
+	active := m.active.Add(1)
+	defer m.active.Add(-1)
+	for {
+		current := m.maxActive.Load()
+		if active <= current || m.maxActive.CompareAndSwap(current, active) {
+			break
+		}
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, values)
+	m.options = append(m.options, opts)
+	var err error
+	if m.failedCalls < len(m.errs) {
+		err = m.errs[m.failedCalls]
+		m.failedCalls++
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	resp := &api.EmbeddingResponse{}
+	for _, value := range values {
+		resp.Embeddings = append(resp.Embeddings, api.Embedding{float64(len(value))})
+		resp.Usage.Tokens += len(value)
+	}
+	return resp, nil
+}
+
+func TestEmbed(t *testing.T) {
+	model := &fakeEmbeddingModel{}
+
+	resp, err := Embed(t.Context(), model, "hello",
+		WithEmbeddingHeaders(http.Header{"X-Test": []string{"1"}}),
+		WithEmbeddingProviderMetadata("fake", map[string]any{"dimensions": 3}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "hello", resp.Value)
+	require.Equal(t, api.Embedding{5}, resp.Embedding)
+	require.Equal(t, api.EmbeddingUsage{Tokens: 5}, resp.Usage)
+
+	require.Equal(t, [][]string{{"hello"}}, model.calls)
+	require.Equal(t, "1", model.options[0].Headers.Get("X-Test"))
+	require.True(t, model.options[0].ProviderMetadata.Has("fake"))
+}
+
+func TestEmbed_NilModel(t *testing.T) {
+	_, err := Embed[string](t.Context(), nil, "hello")
+
+	var argErr *api.InvalidArgumentError
+	require.ErrorAs(t, err, &argErr)
+}
+
+func TestEmbed_Retries(t *testing.T) {
+	model := &fakeEmbeddingModel{errs: []error{apiCallError(http.StatusTooManyRequests, nil)}}
+
+	resp, err := Embed(t.Context(), model, "hello", WithEmbeddingBackoff(noBackoff{}))
+	require.NoError(t, err)
+	require.Equal(t, api.Embedding{5}, resp.Embedding)
+	require.Len(t, model.calls, 2)
+}
+
+func TestEmbedMany(t *testing.T) {
+	values := []string{"a", "bb", "ccc", "dddd", "eeeee", "ffffff", "ggggggg"}
+	wantEmbeddings := []api.Embedding{{1}, {2}, {3}, {4}, {5}, {6}, {7}}
+
+	tests := []struct {
+		name          string
+		model         *fakeEmbeddingModel
+		opts          []EmbedOption
+		wantCalls     int
+		wantMaxActive int32
+	}{
+		{
+			name:          "no limit",
+			model:         &fakeEmbeddingModel{parallel: true},
+			wantCalls:     1,
+			wantMaxActive: 1,
+		},
+		{
+			name:          "chunks run concurrently",
+			model:         &fakeEmbeddingModel{maxPerCall: 2, parallel: true, delay: 50 * time.Millisecond},
+			wantCalls:     4,
+			wantMaxActive: 4,
+		},
+		{
+			name:          "max parallel calls",
+			model:         &fakeEmbeddingModel{maxPerCall: 2, parallel: true, delay: 50 * time.Millisecond},
+			opts:          []EmbedOption{WithMaxParallelCalls(2)},
+			wantCalls:     4,
+			wantMaxActive: 2,
+		},
+		{
+			name:          "sequential when parallel calls are not supported",
+			model:         &fakeEmbeddingModel{maxPerCall: 3, delay: 5 * time.Millisecond},
+			wantCalls:     3,
+			wantMaxActive: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := EmbedMany(t.Context(), tt.model, values, tt.opts...)
+			require.NoError(t, err)
+
+			require.Equal(t, values, resp.Values)
+			require.Equal(t, wantEmbeddings, resp.Embeddings)
+			require.Equal(t, api.EmbeddingUsage{Tokens: 28}, resp.Usage)
+			require.Len(t, resp.Responses, tt.wantCalls)
+			require.Len(t, tt.model.calls, tt.wantCalls)
+			require.Equal(t, tt.wantMaxActive, tt.model.maxActive.Load())
+
+			for _, call := range tt.model.calls {
+				if tt.model.maxPerCall > 0 {
+					require.LessOrEqual(t, len(call), tt.model.maxPerCall)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbedMany_Empty(t *testing.T) {
+	model := &fakeEmbeddingModel{}
+
+	resp, err := EmbedMany(t.Context(), model, nil)
+	require.NoError(t, err)
+	require.Empty(t, resp.Embeddings)
+	require.Empty(t, model.calls)
+}
+
+func TestEmbedMany_Error(t *testing.T) {
+	wantErr := errors.New("boom")
+	model := &fakeEmbeddingModel{maxPerCall: 1, parallel: true, errs: []error{wantErr}}
+
+	_, err := EmbedMany(t.Context(), model, strings.Split("abcdef", ""), WithEmbeddingMaxRetries(0))
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestChunkValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		size   int
+		want   [][]int
+	}{
+		{name: "no limit", values: []int{1, 2, 3}, size: 0, want: [][]int{{1, 2, 3}}},
+		{name: "fits in one chunk", values: []int{1, 2, 3}, size: 3, want: [][]int{{1, 2, 3}}},
+		{name: "even chunks", values: []int{1, 2, 3, 4}, size: 2, want: [][]int{{1, 2}, {3, 4}}},
+		{name: "last chunk smaller", values: []int{1, 2, 3, 4, 5}, size: 2, want: [][]int{{1, 2}, {3, 4}, {5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, chunkValues(tt.values, tt.size))
+		})
+	}
+}
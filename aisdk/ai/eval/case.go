@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"go.jetify.com/ai/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Case is a single prompt to evaluate, with the expectations used to score
+// the responses of the models.
+type Case struct {
+	// ID identifies the case in the report. It must be unique in a dataset.
+	ID string `json:"id"`
+
+	// System is an optional system message sent before the prompt.
+	System string `json:"system,omitempty"`
+
+	// Prompt is the user message sent to the model.
+	Prompt string `json:"prompt"`
+
+	// Tools are the tools available to the model. They are not executed: the
+	// tool calls of the model are scored against Expected.ToolCalls.
+	Tools []*api.FunctionTool `json:"tools,omitempty"`
+
+	// Tags can be used to group or filter cases.
+	Tags []string `json:"tags,omitempty"`
+
+	// Expected holds the expectations checked by the scorers. Scorers whose
+	// expectation is not set don't score the case.
+	Expected Expected `json:"expected,omitzero"`
+}
+
+// Expected holds the expectations of a case.
+type Expected struct {
+	// Exact is the expected text of the response, ignoring leading and
+	// trailing whitespace. Used by ExactMatch.
+	Exact string `json:"exact,omitempty"`
+
+	// Contains are substrings that the response text must all contain. Used
+	// by Contains.
+	Contains []string `json:"contains,omitempty"`
+
+	// Regex is a regular expression that the response text must match. Used
+	// by Regex.
+	Regex string `json:"regex,omitempty"`
+
+	// Schema is a JSON schema that the response text must be valid JSON for.
+	// Used by JSONSchema.
+	Schema *jsonschema.Schema `json:"schema,omitempty"`
+
+	// ToolCalls are the tool calls that the model must make. Used by
+	// ToolCalls.
+	ToolCalls []ExpectedToolCall `json:"tool_calls,omitempty"`
+
+	// Rubric describes a good response, for the LLM judge. Used by Judge.
+	Rubric string `json:"rubric,omitempty"`
+}
+
+// ExpectedToolCall is a tool call that the model is expected to make.
+type ExpectedToolCall struct {
+	// Name is the name of the tool.
+	Name string `json:"name"`
+
+	// Args are the expected arguments. Only the arguments that are set are
+	// compared, so that arguments with unpredictable values can be ignored.
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// Format is the format of a file of cases.
+type Format string
+
+const (
+	// FormatJSON is a JSON array of cases.
+	FormatJSON Format = "json"
+	// FormatJSONL is a case per line, in JSON.
+	FormatJSONL Format = "jsonl"
+	// FormatYAML is a YAML list of cases.
+	FormatYAML Format = "yaml"
+)
+
+// LoadCases loads the cases of a file, whose format is determined by its
+// extension: ".yaml" or ".yml" for YAML, ".jsonl" for JSON Lines, and JSON
+// otherwise.
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := FormatJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = FormatYAML
+	case ".jsonl":
+		format = FormatJSONL
+	}
+	cases, err := ParseCases(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// ParseCases parses cases in the given format. It returns an error if a case
+// has no ID or prompt, or if two cases have the same ID.
+func ParseCases(data []byte, format Format) ([]Case, error) {
+	var cases []Case
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &cases); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, 16<<20)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var c Case
+			if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		// Cases are decoded from JSON so that the JSON encoding of the api
+		// types, like tool schemas, is used.
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+		jsonData, err := json.Marshal(generic)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(jsonData, &cases); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown case format %q", format)
+	}
+
+	if err := validateCases(cases); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+func validateCases(cases []Case) error {
+	seen := map[string]bool{}
+	for i, c := range cases {
+		if c.ID == "" {
+			return fmt.Errorf("case %d: missing id", i)
+		}
+		if c.Prompt == "" {
+			return fmt.Errorf("case %s: missing prompt", c.ID)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("case %s: duplicate id", c.ID)
+		}
+		seen[c.ID] = true
+	}
+	return nil
+}
+
+// messages returns the prompt of the case.
+func (c *Case) messages() []api.Message {
+	var messages []api.Message
+	if c.System != "" {
+		messages = append(messages, &api.SystemMessage{Content: c.System})
+	}
+	return append(messages, &api.UserMessage{Content: api.ContentFromText(c.Prompt)})
+}
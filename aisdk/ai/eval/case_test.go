@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCases(t *testing.T) {
+	cases, err := LoadCases("testdata/cases.yaml")
+	require.NoError(t, err)
+	require.Len(t, cases, 3)
+
+	assert.Equal(t, "capital", cases[0].ID)
+	assert.Equal(t, "Paris", cases[0].Expected.Exact)
+	assert.Equal(t, []string{"geography"}, cases[0].Tags)
+
+	weather := cases[1]
+	assert.Equal(t, "You are a weather assistant.", weather.System)
+	require.Len(t, weather.Tools, 1)
+	assert.Equal(t, "get_weather", weather.Tools[0].Name)
+	assert.Equal(t, "object", weather.Tools[0].InputSchema.Type)
+	assert.Equal(t, []string{"city"}, weather.Tools[0].InputSchema.Required)
+	assert.Equal(t, []ExpectedToolCall{{
+		Name: "get_weather",
+		Args: map[string]any{"city": "Paris", "days": float64(3)},
+	}}, weather.Expected.ToolCalls)
+
+	require.NotNil(t, cases[2].Expected.Schema)
+	assert.Equal(t, []string{"name"}, cases[2].Expected.Schema.Required)
+}
+
+func TestLoadCases_Formats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cases.json":  `[{"id": "a", "prompt": "A"}, {"id": "b", "prompt": "B"}]`,
+		"cases.jsonl": "{\"id\": \"a\", \"prompt\": \"A\"}\n\n{\"id\": \"b\", \"prompt\": \"B\"}\n",
+		"cases.yml":   "- {id: a, prompt: A}\n- {id: b, prompt: B}\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+			cases, err := LoadCases(path)
+			require.NoError(t, err)
+			assert.Equal(t, []Case{{ID: "a", Prompt: "A"}, {ID: "b", Prompt: "B"}}, cases)
+		})
+	}
+}
+
+func TestParseCases_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  Format
+		wantErr string
+	}{
+		{
+			name:    "missing id",
+			data:    `[{"prompt": "A"}]`,
+			format:  FormatJSON,
+			wantErr: "case 0: missing id",
+		},
+		{
+			name:    "missing prompt",
+			data:    `[{"id": "a"}]`,
+			format:  FormatJSON,
+			wantErr: "case a: missing prompt",
+		},
+		{
+			name:    "duplicate id",
+			data:    "- {id: a, prompt: A}\n- {id: a, prompt: B}\n",
+			format:  FormatYAML,
+			wantErr: "case a: duplicate id",
+		},
+		{
+			name:    "invalid line",
+			data:    "{\"id\": \"a\", \"prompt\": \"A\"}\n{\"id\":\n",
+			format:  FormatJSONL,
+			wantErr: "line 2: unexpected end of JSON input",
+		},
+		{
+			name:    "unknown format",
+			format:  "csv",
+			wantErr: `unknown case format "csv"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCases([]byte(tt.data), tt.format)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package eval runs datasets of prompts against language models and scores
+// their responses, to compare models or catch regressions between model
+// versions.
+//
+// Cases are loaded from JSON, JSON Lines or YAML files, run concurrently
+// against every model, and scored by pluggable scorers. The resulting report
+// aggregates the pass rate, latency and token usage of each model, and can be
+// written as JSON to be diffed between runs:
+//
+//	cases, err := eval.LoadCases("testdata/cases.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	report, err := eval.Run(ctx, []api.LanguageModel{gpt5, sonnet}, cases, eval.Options{
+//		Scorers:           append(eval.DefaultScorers(), eval.Judge(judgeModel)),
+//		Concurrency:       8,
+//		RequestsPerSecond: 2,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	return report.WriteFile("eval-report.json")
+//
+// A case file contains a list of cases, each with a prompt and the
+// expectations used by the scorers:
+//
+//	# cases.yaml
+//	- id: capital
+//	  prompt: What is the capital of France? Answer with one word.
+//	  expected:
+//	    exact: Paris
+//	- id: weather
+//	  prompt: What's the weather in Paris?
+//	  tools:
+//	    - name: get_weather
+//	      input_schema: {type: object, properties: {city: {type: string}}}
+//	  expected:
+//	    tool_calls:
+//	      - name: get_weather
+//	        args: {city: Paris}
+package eval
@@ -0,0 +1,296 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+// promptModel answers each prompt with a fixed response, since the cases are
+// run concurrently and can reach the model in any order.
+type promptModel struct {
+	*mock.GenerateModel
+	responses map[string]*api.Response
+
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func newPromptModel(id string, responses map[string]*api.Response) *promptModel {
+	return &promptModel{
+		GenerateModel: mock.NewGenerateModel(nil, mock.WithProviderName("mock"), mock.WithModelID(id)),
+		responses:     responses,
+	}
+}
+
+func (m *promptModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	m.mu.Lock()
+	m.times = append(m.times, time.Now())
+	m.mu.Unlock()
+
+	user := prompt[len(prompt)-1].(*api.UserMessage)
+	text := user.Content[0].(*api.TextBlock).Text
+	resp, ok := m.responses[text]
+	if !ok {
+		return nil, errors.New("model unavailable")
+	}
+	return resp, nil
+}
+
+func text(s string, outputTokens int) *api.Response {
+	return &api.Response{
+		Content:      api.ContentFromText(s),
+		FinishReason: api.FinishReasonStop,
+		Usage:        api.Usage{InputTokens: 10, OutputTokens: outputTokens, TotalTokens: 10 + outputTokens},
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases, err := LoadCases("testdata/cases.yaml")
+	require.NoError(t, err)
+
+	weatherCall := &api.Response{
+		Content: []api.ContentBlock{&api.ToolCallBlock{
+			ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{"city":"Paris","days":3}`),
+		}},
+		FinishReason: api.FinishReasonToolCalls,
+		Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+	good := newPromptModel("good", map[string]*api.Response{
+		cases[0].Prompt: text("Paris", 1),
+		cases[1].Prompt: weatherCall,
+		cases[2].Prompt: text(`{"name":"Ada"}`, 4),
+	})
+	bad := newPromptModel("bad", map[string]*api.Response{
+		cases[0].Prompt: text("Lyon", 1),
+		cases[1].Prompt: text("It's sunny.", 3),
+	})
+
+	ai.RegisterModelInfo(api.ModelInfo{Provider: "mock", ModelID: "good", Pricing: &api.Pricing{Input: 1, Output: 2}})
+
+	report, err := Run(t.Context(), []api.LanguageModel{good, bad}, cases, Options{Concurrency: 3})
+	require.NoError(t, err)
+
+	// Results are ordered by case and then by model.
+	require.Len(t, report.Results, 6)
+	var order [][2]string
+	for _, result := range report.Results {
+		order = append(order, [2]string{result.CaseID, result.Model})
+	}
+	assert.Equal(t, [][2]string{
+		{"capital", "mock:good"}, {"capital", "mock:bad"},
+		{"weather", "mock:good"}, {"weather", "mock:bad"},
+		{"person", "mock:good"}, {"person", "mock:bad"},
+	}, order)
+
+	assert.True(t, report.Results[0].Pass)
+	assert.Equal(t, "Paris", report.Results[0].Output)
+	assert.Equal(t, []Score{{Scorer: "exact", Value: 1, Pass: true}}, report.Results[0].Scores)
+	assert.False(t, report.Results[1].Pass)
+	assert.True(t, report.Results[2].Pass)
+	assert.False(t, report.Results[3].Pass)
+	assert.Equal(t, "missing calls to get_weather", report.Results[3].Scores[0].Reason)
+	assert.True(t, report.Results[4].Pass)
+	assert.False(t, report.Results[5].Pass)
+	assert.Equal(t, "model unavailable", report.Results[5].Error)
+
+	require.Len(t, report.Models, 2)
+	goodSummary, badSummary := report.Models[0], report.Models[1]
+	assert.Equal(t, "mock:good", goodSummary.Model)
+	assert.Equal(t, 3, goodSummary.Cases)
+	assert.Equal(t, 3, goodSummary.Passed)
+	assert.Equal(t, 1.0, goodSummary.PassRate)
+	assert.Equal(t, api.Usage{InputTokens: 30, OutputTokens: 10, TotalTokens: 40}, goodSummary.Usage)
+	assert.InDelta(t, 0.00005, goodSummary.Cost, 1e-12)
+	assert.Equal(t, []ScorerSummary{
+		{Scorer: "exact", Scored: 1, Passed: 1, MeanScore: 1},
+		{Scorer: "json_schema", Scored: 1, Passed: 1, MeanScore: 1},
+		{Scorer: "tool_calls", Scored: 1, Passed: 1, MeanScore: 1},
+	}, goodSummary.Scorers)
+
+	assert.Equal(t, "mock:bad", badSummary.Model)
+	assert.Equal(t, 0, badSummary.Passed)
+	assert.Equal(t, 0.0, badSummary.PassRate)
+	assert.Equal(t, 1, badSummary.Errors)
+	assert.Equal(t, api.Usage{InputTokens: 20, OutputTokens: 4, TotalTokens: 24}, badSummary.Usage)
+	assert.Zero(t, badSummary.Cost)
+}
+
+func TestRun_InvalidToolCall(t *testing.T) {
+	cases, err := LoadCases("testdata/cases.yaml")
+	require.NoError(t, err)
+	model := newPromptModel("m", map[string]*api.Response{
+		cases[1].Prompt: {
+			Content: []api.ContentBlock{&api.ToolCallBlock{
+				ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{"city":3}`),
+			}},
+			FinishReason: api.FinishReasonToolCalls,
+		},
+	})
+
+	report, err := Run(t.Context(), []api.LanguageModel{model}, cases[1:2], Options{})
+	require.NoError(t, err)
+
+	// The invalid call is scored instead of failing the case.
+	result := report.Results[0]
+	assert.Empty(t, result.Error)
+	assert.False(t, result.Pass)
+	require.Len(t, result.Scores, 1)
+	assert.Equal(t, "tool_calls", result.Scores[0].Scorer)
+	assert.False(t, result.Scores[0].Pass)
+}
+
+func TestRun_ToolsAndSystem(t *testing.T) {
+	cases, err := LoadCases("testdata/cases.yaml")
+	require.NoError(t, err)
+	model := &recordingModel{promptModel: newPromptModel("m", nil)}
+
+	_, err = Run(t.Context(), []api.LanguageModel{model}, cases[1:2], Options{})
+	require.NoError(t, err)
+
+	require.Len(t, model.calls, 1)
+	call := model.calls[0]
+	assert.Equal(t, &api.SystemMessage{Content: "You are a weather assistant."}, call.prompt[0])
+	require.Len(t, call.opts.Tools, 1)
+	assert.Equal(t, cases[1].Tools[0], call.opts.Tools[0])
+}
+
+type recordedCall struct {
+	prompt []api.Message
+	opts   api.CallOptions
+}
+
+type recordingModel struct {
+	*promptModel
+	calls []recordedCall
+}
+
+func (m *recordingModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	m.calls = append(m.calls, recordedCall{prompt: prompt, opts: opts})
+	return m.promptModel.Generate(ctx, prompt, opts)
+}
+
+func TestRun_RateLimit(t *testing.T) {
+	cases := []Case{{ID: "a", Prompt: "A"}, {ID: "b", Prompt: "B"}, {ID: "c", Prompt: "C"}}
+	responses := map[string]*api.Response{"A": text("a", 1), "B": text("b", 1), "C": text("c", 1)}
+	model := newPromptModel("m", responses)
+
+	_, err := Run(t.Context(), []api.LanguageModel{model}, cases, Options{Concurrency: 3, RequestsPerSecond: 50})
+	require.NoError(t, err)
+
+	require.Len(t, model.times, 3)
+	first, last := model.times[0], model.times[0]
+	for _, at := range model.times {
+		if at.Before(first) {
+			first = at
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	assert.GreaterOrEqual(t, last.Sub(first), 35*time.Millisecond)
+}
+
+func TestRun_InvalidArguments(t *testing.T) {
+	model := newPromptModel("m", nil)
+
+	tests := []struct {
+		name    string
+		models  []api.LanguageModel
+		cases   []Case
+		options Options
+	}{
+		{name: "no models", cases: []Case{{ID: "a", Prompt: "A"}}},
+		{
+			name:   "duplicate cases",
+			models: []api.LanguageModel{model},
+			cases:  []Case{{ID: "a", Prompt: "A"}, {ID: "a", Prompt: "B"}},
+		},
+		{
+			name:    "negative concurrency",
+			models:  []api.LanguageModel{model},
+			options: Options{Concurrency: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(t.Context(), tt.models, tt.cases, tt.options)
+			var invalidErr *api.InvalidArgumentError
+			require.ErrorAs(t, err, &invalidErr)
+		})
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := Run(ctx, []api.LanguageModel{newPromptModel("m", nil)}, []Case{{ID: "a", Prompt: "A"}}, Options{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	report := &Report{
+		Models: []ModelSummary{{
+			Model:    "mock:m",
+			Cases:    1,
+			Passed:   1,
+			PassRate: 1,
+			Latency:  LatencySummary{MeanMS: 120, P50MS: 120, P95MS: 120, MaxMS: 120},
+			Usage:    api.Usage{InputTokens: 10, OutputTokens: 1, TotalTokens: 11},
+			Cost:     0.0012,
+			Scorers:  []ScorerSummary{{Scorer: "exact", Scored: 1, Passed: 1, MeanScore: 1}},
+		}},
+		Results: []Result{{
+			CaseID:    "capital",
+			Model:     "mock:m",
+			Pass:      true,
+			Output:    "Paris",
+			Scores:    []Score{{Scorer: "exact", Value: 1, Pass: true}},
+			LatencyMS: 120,
+			Usage:     api.Usage{InputTokens: 10, OutputTokens: 1, TotalTokens: 11},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	assert.JSONEq(t, `{
+		"models": [{
+			"model": "mock:m",
+			"cases": 1,
+			"passed": 1,
+			"pass_rate": 1,
+			"errors": 0,
+			"latency": {"mean_ms": 120, "p50_ms": 120, "p95_ms": 120, "max_ms": 120},
+			"usage": {"input_tokens": 10, "output_tokens": 1, "total_tokens": 11},
+			"cost": 0.0012,
+			"scorers": [{"scorer": "exact", "scored": 1, "passed": 1, "mean_score": 1}]
+		}],
+		"results": [{
+			"case_id": "capital",
+			"model": "mock:m",
+			"pass": true,
+			"output": "Paris",
+			"scores": [{"scorer": "exact", "value": 1, "pass": true}],
+			"latency_ms": 120,
+			"usage": {"input_tokens": 10, "output_tokens": 1, "total_tokens": 11}
+		}]
+	}`, buf.String())
+}
+
+func TestSummarizeLatency(t *testing.T) {
+	latencies := []int64{50, 10, 40, 30, 20, 100, 90, 80, 70, 60}
+	assert.Equal(t, LatencySummary{MeanMS: 55, P50MS: 50, P95MS: 100, MaxMS: 100}, summarizeLatency(latencies))
+	assert.Equal(t, LatencySummary{}, summarizeLatency(nil))
+}
@@ -0,0 +1,205 @@
+package eval
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"slices"
+
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+)
+
+// Report is the result of an evaluation run. Its JSON encoding is stable for
+// the same cases and models, so that reports can be diffed between runs.
+type Report struct {
+	// Models summarizes the results of each model, in the order the models
+	// were passed to Run.
+	Models []ModelSummary `json:"models"`
+
+	// Results are the results of every case for every model, ordered by case
+	// and then by model.
+	Results []Result `json:"results"`
+}
+
+// ModelSummary aggregates the results of a model.
+type ModelSummary struct {
+	// Model identifies the model, as "provider:model-id".
+	Model string `json:"model"`
+
+	// Cases is the number of cases run.
+	Cases int `json:"cases"`
+
+	// Passed is the number of cases that passed.
+	Passed int `json:"passed"`
+
+	// PassRate is the fraction of the cases that passed.
+	PassRate float64 `json:"pass_rate"`
+
+	// Errors is the number of cases that failed with an error.
+	Errors int `json:"errors"`
+
+	// Latency summarizes the duration of the model calls.
+	Latency LatencySummary `json:"latency"`
+
+	// Usage is the total token usage of the model.
+	Usage api.Usage `json:"usage"`
+
+	// Cost is the total cost in USD of the model calls, if the price of the
+	// model is known (see ai.LookupModelInfo).
+	Cost float64 `json:"cost"`
+
+	// Scorers summarizes the scores of each scorer, in the order of
+	// Options.Scorers. Scorers that didn't score any case are omitted.
+	Scorers []ScorerSummary `json:"scorers,omitempty"`
+}
+
+// LatencySummary summarizes the duration of the model calls, in milliseconds.
+type LatencySummary struct {
+	MeanMS int64 `json:"mean_ms"`
+	P50MS  int64 `json:"p50_ms"`
+	P95MS  int64 `json:"p95_ms"`
+	MaxMS  int64 `json:"max_ms"`
+}
+
+// ScorerSummary aggregates the scores of a scorer for a model.
+type ScorerSummary struct {
+	// Scorer is the name of the scorer.
+	Scorer string `json:"scorer"`
+
+	// Scored is the number of cases the scorer applied to.
+	Scored int `json:"scored"`
+
+	// Passed is the number of those cases that passed.
+	Passed int `json:"passed"`
+
+	// MeanScore is the mean value of the scores.
+	MeanScore float64 `json:"mean_score"`
+}
+
+// Result is the result of a case for a model.
+type Result struct {
+	// CaseID is the ID of the case.
+	CaseID string `json:"case_id"`
+
+	// Model identifies the model, as "provider:model-id".
+	Model string `json:"model"`
+
+	// Pass reports whether the model call succeeded and all the scores
+	// passed.
+	Pass bool `json:"pass"`
+
+	// Output is the text generated by the model.
+	Output string `json:"output,omitempty"`
+
+	// Scores are the scores of the scorers that applied to the case.
+	Scores []Score `json:"scores,omitempty"`
+
+	// Error is the error returned by the model or by a scorer.
+	Error string `json:"error,omitempty"`
+
+	// LatencyMS is the duration of the model call, in milliseconds. It
+	// doesn't include the time spent waiting for the rate limiter.
+	LatencyMS int64 `json:"latency_ms"`
+
+	// Usage is the token usage of the model call.
+	Usage api.Usage `json:"usage"`
+}
+
+func newReport(models []api.LanguageModel, scorers []Scorer, results []Result, usage []*ai.UsageAggregator) *Report {
+	report := &Report{Results: results}
+	for m, model := range models {
+		summary := ModelSummary{Model: modelName(model)}
+		var latencies []int64
+		scores := map[string]*ScorerSummary{}
+		for i := m; i < len(results); i += len(models) {
+			result := &results[i]
+			summary.Cases++
+			if result.Pass {
+				summary.Passed++
+			}
+			if result.Error != "" {
+				summary.Errors++
+			}
+			latencies = append(latencies, result.LatencyMS)
+			for _, score := range result.Scores {
+				s := scores[score.Scorer]
+				if s == nil {
+					s = &ScorerSummary{Scorer: score.Scorer}
+					scores[score.Scorer] = s
+				}
+				s.Scored++
+				if score.Pass {
+					s.Passed++
+				}
+				s.MeanScore += score.Value
+			}
+		}
+		if summary.Cases > 0 {
+			summary.PassRate = round(float64(summary.Passed) / float64(summary.Cases))
+		}
+		summary.Latency = summarizeLatency(latencies)
+		total := usage[m].Total()
+		summary.Usage = total.Usage
+		summary.Cost = total.Cost
+		for _, scorer := range scorers {
+			if s := scores[scorer.Name()]; s != nil {
+				s.MeanScore = round(s.MeanScore / float64(s.Scored))
+				summary.Scorers = append(summary.Scorers, *s)
+			}
+		}
+		report.Models = append(report.Models, summary)
+	}
+	return report
+}
+
+func summarizeLatency(latencies []int64) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+	slices.Sort(latencies)
+	var total int64
+	for _, latency := range latencies {
+		total += latency
+	}
+	return LatencySummary{
+		MeanMS: total / int64(len(latencies)),
+		P50MS:  percentile(latencies, 50),
+		P95MS:  percentile(latencies, 95),
+		MaxMS:  latencies[len(latencies)-1],
+	}
+}
+
+// percentile returns the p-th percentile of sorted values, using the
+// nearest-rank method.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// round rounds a ratio to 4 decimal places, to keep reports readable and
+// stable.
+func round(x float64) float64 {
+	return math.Round(x*10000) / 10000
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteFile writes the report as indented JSON to a file.
+func (r *Report) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
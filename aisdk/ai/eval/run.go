@@ -0,0 +1,215 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+)
+
+// defaultConcurrency is the number of cases run in parallel when
+// Options.Concurrency is not set.
+const defaultConcurrency = 4
+
+// Options configures an evaluation run.
+type Options struct {
+	// Scorers score the output of each case. Defaults to DefaultScorers.
+	Scorers []Scorer
+
+	// Concurrency is the maximum number of model calls made in parallel,
+	// across all models. Defaults to 4.
+	Concurrency int
+
+	// RequestsPerSecond limits the rate of the calls made to each model.
+	// Zero means no limit.
+	RequestsPerSecond float64
+
+	// GenerateOptions are passed to GenerateText for every case, for example
+	// to set the temperature. The model and the tools are set by Run.
+	GenerateOptions []ai.GenerateOption
+}
+
+// Run runs every case against every model and scores the outputs.
+//
+// The cases are sent to the models with GenerateText. The tools of a case are
+// declared to the model but not executed, and the tool calls of the model are
+// not validated, so that they can be scored as generated. Errors returned by
+// a model are recorded in the result of the case, which fails, instead of
+// stopping the run. Run only returns an error if its arguments are invalid or
+// if the context is canceled.
+func Run(ctx context.Context, models []api.LanguageModel, cases []Case, options Options) (*Report, error) {
+	if len(models) == 0 {
+		return nil, api.NewInvalidArgumentError("at least one model is required", "models", nil)
+	}
+	if err := validateCases(cases); err != nil {
+		return nil, api.NewInvalidArgumentError(err.Error(), "cases", err)
+	}
+	if options.Concurrency < 0 || options.RequestsPerSecond < 0 {
+		return nil, api.NewInvalidArgumentError("concurrency and requests per second must not be negative", "options", nil)
+	}
+	if options.Scorers == nil {
+		options.Scorers = DefaultScorers()
+	}
+	concurrency := options.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultConcurrency
+	}
+
+	limiters := make([]*rateLimiter, len(models))
+	usage := make([]*ai.UsageAggregator, len(models))
+	for i := range models {
+		limiters[i] = newRateLimiter(options.RequestsPerSecond)
+		usage[i] = ai.NewUsageAggregator()
+	}
+
+	// Results are stored by position so that the report doesn't depend on
+	// the order in which the calls complete.
+	results := make([]Result, len(cases)*len(models))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(results)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c, m := &cases[i/len(models)], i%len(models)
+				results[i] = runCase(ctx, models[m], limiters[m], usage[m], c, options)
+			}
+		}()
+	}
+
+send:
+	for i := range results {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return newReport(models, options.Scorers, results, usage), nil
+}
+
+func runCase(
+	ctx context.Context, model api.LanguageModel, limiter *rateLimiter, usage *ai.UsageAggregator, c *Case, options Options,
+) Result {
+	result := Result{CaseID: c.ID, Model: modelName(model)}
+	if err := limiter.wait(ctx); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Invalid tool calls are scored by the ToolCalls scorer, instead of
+	// failing the case.
+	opts := append([]ai.GenerateOption{ai.WithModel(model), ai.WithoutToolCallValidation()}, options.GenerateOptions...)
+	if len(c.Tools) > 0 {
+		tools := make([]api.ToolDefinition, len(c.Tools))
+		for i, tool := range c.Tools {
+			tools[i] = tool
+		}
+		opts = append(opts, ai.WithTools(tools...))
+	}
+
+	start := time.Now()
+	resp, err := ai.GenerateTextSteps(ctx, c.messages(), opts...)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Usage = resp.TotalUsage
+	usage.AddSteps(model, resp.Steps)
+
+	output := &Output{Text: responseText(resp.Response), Response: resp.Response}
+	result.Output = output.Text
+	result.Pass = true
+	var errs []error
+	for _, scorer := range options.Scorers {
+		score, err := scorer.Score(ctx, c, output)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", scorer.Name(), err))
+			continue
+		}
+		if score == nil {
+			continue
+		}
+		score.Scorer = scorer.Name()
+		result.Scores = append(result.Scores, *score)
+		result.Pass = result.Pass && score.Pass
+	}
+	if err := errors.Join(errs...); err != nil {
+		result.Error = err.Error()
+		result.Pass = false
+	}
+	return result
+}
+
+// modelName identifies a model in the report.
+func modelName(model api.LanguageModel) string {
+	return model.ProviderName() + ai.DefaultSeparator + model.ModelID()
+}
+
+// responseText returns the text generated in a response.
+func responseText(resp *api.Response) string {
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if textBlock, ok := block.(*api.TextBlock); ok {
+			text.WriteString(textBlock.Text)
+		}
+	}
+	return text.String()
+}
+
+// rateLimiter spaces out calls to a model so that they don't exceed a number
+// of requests per second.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	if requestsPerSecond == 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+// wait blocks until the next call is allowed, or the context is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := now
+	if l.next.After(now) {
+		at = l.next
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,269 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+)
+
+// Output is the output of a model for a case.
+type Output struct {
+	// Text is the text generated by the model.
+	Text string
+
+	// Response is the response of the model in the final step.
+	Response *api.Response
+}
+
+// ToolCalls returns the tool calls made by the model.
+func (o *Output) ToolCalls() []*api.ToolCallBlock {
+	if o.Response == nil {
+		return nil
+	}
+	var calls []*api.ToolCallBlock
+	for _, block := range o.Response.Content {
+		if call, ok := block.(*api.ToolCallBlock); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Score is the score given by a scorer to the output of a model.
+type Score struct {
+	// Scorer is the name of the scorer.
+	Scorer string `json:"scorer"`
+
+	// Value is the score, between 0 and 1.
+	Value float64 `json:"value"`
+
+	// Pass reports whether the output meets the expectation.
+	Pass bool `json:"pass"`
+
+	// Reason explains a failing score.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Scorer scores the output of a model for a case.
+type Scorer interface {
+	// Name identifies the scorer in the report.
+	Name() string
+
+	// Score scores the output. It returns nil if the scorer doesn't apply
+	// to the case, typically because the case doesn't set the expectation
+	// checked by the scorer.
+	Score(ctx context.Context, c *Case, output *Output) (*Score, error)
+}
+
+// ScoreFunc is the signature of the function wrapped by NewScorer.
+type ScoreFunc func(ctx context.Context, c *Case, output *Output) (*Score, error)
+
+// NewScorer creates a scorer from a function.
+func NewScorer(name string, fn ScoreFunc) Scorer {
+	return &funcScorer{name: name, fn: fn}
+}
+
+type funcScorer struct {
+	name string
+	fn   ScoreFunc
+}
+
+func (s *funcScorer) Name() string { return s.name }
+
+func (s *funcScorer) Score(ctx context.Context, c *Case, output *Output) (*Score, error) {
+	return s.fn(ctx, c, output)
+}
+
+// DefaultScorers returns the scorers that check the expectations of a case
+// without calling a model: ExactMatch, Contains, Regex, JSONSchema and
+// ToolCalls.
+func DefaultScorers() []Scorer {
+	return []Scorer{ExactMatch(), Contains(), Regex(), JSONSchema(), ToolCalls()}
+}
+
+// passed returns a passing or failing score.
+func passed(pass bool, reason string) *Score {
+	if pass {
+		return &Score{Value: 1, Pass: true}
+	}
+	return &Score{Value: 0, Reason: reason}
+}
+
+// ExactMatch returns a scorer that checks that the text of the output is
+// Expected.Exact, ignoring leading and trailing whitespace.
+func ExactMatch() Scorer {
+	return NewScorer("exact", func(ctx context.Context, c *Case, output *Output) (*Score, error) {
+		if c.Expected.Exact == "" {
+			return nil, nil
+		}
+		pass := strings.TrimSpace(output.Text) == strings.TrimSpace(c.Expected.Exact)
+		return passed(pass, fmt.Sprintf("expected %q, got %q", c.Expected.Exact, output.Text)), nil
+	})
+}
+
+// Contains returns a scorer that checks that the text of the output contains
+// every substring of Expected.Contains. Its value is the fraction of the
+// substrings found.
+func Contains() Scorer {
+	return NewScorer("contains", func(ctx context.Context, c *Case, output *Output) (*Score, error) {
+		if len(c.Expected.Contains) == 0 {
+			return nil, nil
+		}
+		var missing []string
+		for _, s := range c.Expected.Contains {
+			if !strings.Contains(output.Text, s) {
+				missing = append(missing, s)
+			}
+		}
+		if len(missing) == 0 {
+			return &Score{Value: 1, Pass: true}, nil
+		}
+		return &Score{
+			Value:  1 - float64(len(missing))/float64(len(c.Expected.Contains)),
+			Reason: fmt.Sprintf("missing %q", missing),
+		}, nil
+	})
+}
+
+// Regex returns a scorer that checks that the text of the output matches the
+// regular expression Expected.Regex.
+func Regex() Scorer {
+	return NewScorer("regex", func(ctx context.Context, c *Case, output *Output) (*Score, error) {
+		if c.Expected.Regex == "" {
+			return nil, nil
+		}
+		re, err := regexp.Compile(c.Expected.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: invalid regex: %w", c.ID, err)
+		}
+		return passed(re.MatchString(output.Text), fmt.Sprintf("%q doesn't match %s", output.Text, c.Expected.Regex)), nil
+	})
+}
+
+// JSONSchema returns a scorer that checks that the text of the output is JSON
+// that is valid against Expected.Schema.
+func JSONSchema() Scorer {
+	return NewScorer("json_schema", func(ctx context.Context, c *Case, output *Output) (*Score, error) {
+		if c.Expected.Schema == nil {
+			return nil, nil
+		}
+		resolved, err := c.Expected.Schema.Resolve(nil)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: invalid schema: %w", c.ID, err)
+		}
+		var value any
+		if err := json.Unmarshal([]byte(output.Text), &value); err != nil {
+			return passed(false, fmt.Sprintf("invalid JSON: %v", err)), nil
+		}
+		if err := resolved.Validate(value); err != nil {
+			return passed(false, err.Error()), nil
+		}
+		return passed(true, ""), nil
+	})
+}
+
+// ToolCalls returns a scorer that checks that the model made the tool calls of
+// Expected.ToolCalls, in any order. The arguments of a call match if they
+// contain the expected arguments. Its value is the fraction of the expected
+// calls that were made.
+func ToolCalls() Scorer {
+	return NewScorer("tool_calls", func(ctx context.Context, c *Case, output *Output) (*Score, error) {
+		if len(c.Expected.ToolCalls) == 0 {
+			return nil, nil
+		}
+		calls := output.ToolCalls()
+		used := make([]bool, len(calls))
+		var missing []string
+		for _, expected := range c.Expected.ToolCalls {
+			found := false
+			for i, call := range calls {
+				if !used[i] && toolCallMatches(call, expected) {
+					used[i], found = true, true
+					break
+				}
+			}
+			if !found {
+				missing = append(missing, expected.Name)
+			}
+		}
+		if len(missing) == 0 {
+			return &Score{Value: 1, Pass: true}, nil
+		}
+		return &Score{
+			Value:  1 - float64(len(missing))/float64(len(c.Expected.ToolCalls)),
+			Reason: fmt.Sprintf("missing calls to %s", strings.Join(missing, ", ")),
+		}, nil
+	})
+}
+
+func toolCallMatches(call *api.ToolCallBlock, expected ExpectedToolCall) bool {
+	if call.ToolName != expected.Name {
+		return false
+	}
+	if len(expected.Args) == 0 {
+		return true
+	}
+	var args map[string]any
+	if err := json.Unmarshal(call.Args, &args); err != nil {
+		return false
+	}
+	for key, want := range expected.Args {
+		got, ok := args[key]
+		if !ok || !jsonEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual compares two values by their JSON encoding, so that numbers
+// decoded from YAML and JSON compare equal.
+func jsonEqual(a, b any) bool {
+	var normalized [2]any
+	for i, v := range []any{a, b} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if err := json.Unmarshal(data, &normalized[i]); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(normalized[0], normalized[1])
+}
+
+// judgment is the verdict requested from the judge model.
+type judgment struct {
+	Pass   bool    `json:"pass" jsonschema:"whether the response satisfies the rubric"`
+	Score  float64 `json:"score" jsonschema:"how well the response satisfies the rubric, from 0 to 1"`
+	Reason string  `json:"reason" jsonschema:"a short explanation of the verdict"`
+}
+
+// Judge returns a scorer that asks a model to grade the output against
+// Expected.Rubric, for expectations that can't be checked mechanically. The
+// options are passed to the judge model.
+func Judge(model api.LanguageModel, opts ...ai.GenerateOption) Scorer {
+	return NewScorer("judge", func(ctx context.Context, c *Case, output *Output) (*Score, error) {
+		if c.Expected.Rubric == "" {
+			return nil, nil
+		}
+		prompt := fmt.Sprintf(
+			"You are grading the response of an AI assistant.\n\n"+
+				"<prompt>\n%s\n</prompt>\n\n<response>\n%s\n</response>\n\n<rubric>\n%s\n</rubric>\n\n"+
+				"Grade the response against the rubric.",
+			c.Prompt, output.Text, c.Expected.Rubric)
+		opts := append([]ai.GenerateOption{ai.WithModel(model)}, opts...)
+		resp, err := ai.GenerateObjectStr[judgment](ctx, prompt, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("judge: %w", err)
+		}
+		verdict := resp.Object
+		return &Score{Value: min(max(verdict.Score, 0), 1), Pass: verdict.Pass, Reason: verdict.Reason}, nil
+	})
+}
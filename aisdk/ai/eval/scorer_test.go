@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+func toolCallOutput(calls ...*api.ToolCallBlock) *Output {
+	resp := &api.Response{FinishReason: api.FinishReasonToolCalls}
+	for _, call := range calls {
+		resp.Content = append(resp.Content, call)
+	}
+	return &Output{Response: resp}
+}
+
+func TestScorers(t *testing.T) {
+	personSchema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"name": {Type: "string"}},
+		Required:   []string{"name"},
+	}
+	weatherCall := &api.ToolCallBlock{ToolName: "get_weather", Args: json.RawMessage(`{"city":"Paris","days":3}`)}
+
+	tests := []struct {
+		name     string
+		scorer   Scorer
+		expected Expected
+		output   *Output
+		want     *Score
+	}{
+		{
+			name:   "exact without expectation",
+			scorer: ExactMatch(),
+			output: &Output{Text: "Paris"},
+		},
+		{
+			name:     "exact match ignores surrounding whitespace",
+			scorer:   ExactMatch(),
+			expected: Expected{Exact: "Paris"},
+			output:   &Output{Text: " Paris\n"},
+			want:     &Score{Value: 1, Pass: true},
+		},
+		{
+			name:     "exact mismatch",
+			scorer:   ExactMatch(),
+			expected: Expected{Exact: "Paris"},
+			output:   &Output{Text: "paris"},
+			want:     &Score{Reason: `expected "Paris", got "paris"`},
+		},
+		{
+			name:     "contains all",
+			scorer:   Contains(),
+			expected: Expected{Contains: []string{"Paris", "France"}},
+			output:   &Output{Text: "Paris is the capital of France."},
+			want:     &Score{Value: 1, Pass: true},
+		},
+		{
+			name:     "contains some",
+			scorer:   Contains(),
+			expected: Expected{Contains: []string{"Paris", "Seine", "Louvre", "Eiffel"}},
+			output:   &Output{Text: "Paris is on the Seine."},
+			want:     &Score{Value: 0.5, Reason: `missing ["Louvre" "Eiffel"]`},
+		},
+		{
+			name:     "regex match",
+			scorer:   Regex(),
+			expected: Expected{Regex: `^\d{4}-\d{2}-\d{2}$`},
+			output:   &Output{Text: "2025-01-31"},
+			want:     &Score{Value: 1, Pass: true},
+		},
+		{
+			name:     "regex mismatch",
+			scorer:   Regex(),
+			expected: Expected{Regex: `^\d+$`},
+			output:   &Output{Text: "twelve"},
+			want:     &Score{Reason: `"twelve" doesn't match ^\d+$`},
+		},
+		{
+			name:     "valid JSON",
+			scorer:   JSONSchema(),
+			expected: Expected{Schema: personSchema},
+			output:   &Output{Text: `{"name":"Ada"}`},
+			want:     &Score{Value: 1, Pass: true},
+		},
+		{
+			name:     "invalid JSON",
+			scorer:   JSONSchema(),
+			expected: Expected{Schema: personSchema},
+			output:   &Output{Text: `{"name":`},
+			want:     &Score{Reason: "invalid JSON: unexpected end of JSON input"},
+		},
+		{
+			name:     "expected tool call",
+			scorer:   ToolCalls(),
+			expected: Expected{ToolCalls: []ExpectedToolCall{{Name: "get_weather", Args: map[string]any{"city": "Paris"}}}},
+			output:   toolCallOutput(weatherCall),
+			want:     &Score{Value: 1, Pass: true},
+		},
+		{
+			name:   "tool call arguments mismatch",
+			scorer: ToolCalls(),
+			expected: Expected{ToolCalls: []ExpectedToolCall{
+				{Name: "get_weather", Args: map[string]any{"city": "Paris", "days": 3}},
+				{Name: "get_weather", Args: map[string]any{"city": "London"}},
+			}},
+			output: toolCallOutput(weatherCall),
+			want:   &Score{Value: 0.5, Reason: "missing calls to get_weather"},
+		},
+		{
+			name:     "missing tool call",
+			scorer:   ToolCalls(),
+			expected: Expected{ToolCalls: []ExpectedToolCall{{Name: "get_weather"}}},
+			output:   &Output{Text: "It's sunny."},
+			want:     &Score{Reason: "missing calls to get_weather"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, err := tt.scorer.Score(t.Context(), &Case{ID: "case", Expected: tt.expected}, tt.output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, score)
+		})
+	}
+}
+
+func TestScorers_InvalidExpectation(t *testing.T) {
+	_, err := Regex().Score(t.Context(), &Case{ID: "case", Expected: Expected{Regex: "("}}, &Output{})
+	assert.ErrorContains(t, err, "case case: invalid regex")
+}
+
+func TestJudge(t *testing.T) {
+	judgeModel := mock.NewGenerateModel([]mock.MockResult{{
+		Response: &api.Response{
+			Content:      api.ContentFromText(`{"pass":true,"score":0.8,"reason":"Polite and accurate."}`),
+			FinishReason: api.FinishReasonStop,
+		},
+	}})
+	judge := Judge(judgeModel)
+
+	c := &Case{ID: "greeting", Prompt: "Greet the user.", Expected: Expected{Rubric: "The greeting is polite."}}
+	score, err := judge.Score(t.Context(), c, &Output{Text: "Good morning!"})
+	require.NoError(t, err)
+	assert.Equal(t, &Score{Value: 0.8, Pass: true, Reason: "Polite and accurate."}, score)
+
+	calls := judgeModel.Calls()
+	require.Len(t, calls, 1)
+	prompt := calls[0].Prompt[0].(*api.UserMessage).Content[0].(*api.TextBlock).Text
+	assert.Contains(t, prompt, "Greet the user.")
+	assert.Contains(t, prompt, "Good morning!")
+	assert.Contains(t, prompt, "The greeting is polite.")
+
+	// Cases without a rubric are not judged.
+	score, err = judge.Score(t.Context(), &Case{ID: "other"}, &Output{Text: "Hi"})
+	require.NoError(t, err)
+	assert.Nil(t, score)
+	judgeModel.AssertCount(t)
+}
@@ -0,0 +1,459 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.jetify.com/ai/api"
+)
+
+// FallbackProviderName is the provider name reported by a FallbackModel.
+const FallbackProviderName = "fallback"
+
+// FallbackMetadataKey is the provider metadata key under which a FallbackModel
+// records the model that served a call.
+const FallbackMetadataKey = "fallback"
+
+// FallbackMetadata describes which of the models of a FallbackModel served a
+// call.
+type FallbackMetadata struct {
+	// Provider is the provider name of the model that served the call.
+	Provider string `json:"provider"`
+
+	// ModelID is the ID of the model that served the call.
+	ModelID string `json:"model_id"`
+
+	// Attempts is the number of models that were tried, including the one
+	// that served the call.
+	Attempts int `json:"attempts"`
+}
+
+// GetFallbackMetadata returns the fallback metadata of a response or finish
+// event, or nil if the call wasn't made through a FallbackModel.
+func GetFallbackMetadata(source api.MetadataSource) *FallbackMetadata {
+	return api.GetMetadata[FallbackMetadata](FallbackMetadataKey, source)
+}
+
+// FallbackStrategy decides the order in which a FallbackModel tries its
+// models.
+type FallbackStrategy string
+
+const (
+	// FallbackPriority always tries the models in the order they are given.
+	FallbackPriority FallbackStrategy = "priority"
+
+	// FallbackRoundRobin starts each call with the next model in turn, and
+	// falls back to the following ones in order.
+	FallbackRoundRobin FallbackStrategy = "round-robin"
+
+	// FallbackWeighted picks the order of the models at random for each call,
+	// in proportion to their weights.
+	FallbackWeighted FallbackStrategy = "weighted"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+// FallbackOptions configures a FallbackModel.
+type FallbackOptions struct {
+	// Strategy decides the order in which the models are tried. Defaults to
+	// FallbackPriority.
+	Strategy FallbackStrategy
+
+	// Weights are the weights of the models for FallbackWeighted, in the same
+	// order as the models. A model with a weight of zero is only used as a
+	// fallback. Defaults to the same weight for all models.
+	Weights []int
+
+	// Timeout is the maximum duration of a call to a single model before
+	// falling back to the next one. For streams, it applies until the first
+	// content event. Zero means no timeout.
+	Timeout time.Duration
+
+	// FailureThreshold is the number of consecutive failures after which the
+	// circuit of a model opens and the model is skipped for the Cooldown
+	// period. After the cooldown, the model is tried again, and a single
+	// failure opens the circuit again. Defaults to 5; negative values disable
+	// circuit breaking.
+	FailureThreshold int
+
+	// Cooldown is how long a model is skipped once its circuit opens.
+	// Defaults to 30s.
+	Cooldown time.Duration
+
+	// ShouldFallback decides whether an error of a model should be handled by
+	// trying the next model. Defaults to retryable API call errors (see
+	// [api.APICallError.IsRetryable]) and timeouts.
+	ShouldFallback func(err error) bool
+}
+
+// FallbackModel is a language model that sends each call to one of several
+// models, falling back to the next model when a call fails with a retryable
+// error or times out. It can be used to fail over between providers, or to
+// balance the load between them:
+//
+//	model, err := ai.NewFallbackModel([]api.LanguageModel{
+//		openai.NewLanguageModel(openai.ChatModelGPT5),
+//		anthropic.NewLanguageModel(anthropic.ModelClaudeSonnet4_0),
+//	}, ai.FallbackOptions{})
+//
+// Streams fail over only before the first content event: once content has
+// been received, errors are forwarded to the consumer.
+//
+// The model that served a call is recorded in the provider metadata of the
+// response (see GetFallbackMetadata), and in the response info if the
+// provider doesn't report a model ID. A FallbackModel is safe for concurrent
+// use.
+type FallbackModel struct {
+	models   []api.LanguageModel
+	breakers []*circuitBreaker
+	options  FallbackOptions
+	next     atomic.Uint64
+	now      func() time.Time
+	rand     func(n int) int
+}
+
+var _ api.LanguageModel = &FallbackModel{}
+
+// NewFallbackModel creates a FallbackModel that tries the given models. It
+// returns an InvalidArgumentError if there are no models, or the weights
+// don't match the models.
+func NewFallbackModel(models []api.LanguageModel, options FallbackOptions) (*FallbackModel, error) {
+	if len(models) == 0 {
+		return nil, api.NewInvalidArgumentError("at least one model is required", "models", nil)
+	}
+	if options.Strategy == "" {
+		options.Strategy = FallbackPriority
+	}
+	switch options.Strategy {
+	case FallbackPriority, FallbackRoundRobin, FallbackWeighted:
+	default:
+		return nil, api.NewInvalidArgumentError(
+			fmt.Sprintf("unknown fallback strategy %q", options.Strategy), "Strategy", nil)
+	}
+	if options.Weights != nil && len(options.Weights) != len(models) {
+		return nil, api.NewInvalidArgumentError(
+			fmt.Sprintf("got %d weights for %d models", len(options.Weights), len(models)), "Weights", nil)
+	}
+	for _, weight := range options.Weights {
+		if weight < 0 {
+			return nil, api.NewInvalidArgumentError("weights can't be negative", "Weights", nil)
+		}
+	}
+	if options.FailureThreshold == 0 {
+		options.FailureThreshold = defaultFailureThreshold
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = defaultCooldown
+	}
+	if options.ShouldFallback == nil {
+		options.ShouldFallback = isFallbackError
+	}
+
+	breakers := make([]*circuitBreaker, len(models))
+	for i := range breakers {
+		breakers[i] = &circuitBreaker{}
+	}
+	return &FallbackModel{
+		models:   models,
+		breakers: breakers,
+		options:  options,
+		now:      time.Now,
+		rand:     rand.IntN,
+	}, nil
+}
+
+// ProviderName returns FallbackProviderName.
+func (m *FallbackModel) ProviderName() string { return FallbackProviderName }
+
+// ModelID returns the IDs of the models, as "provider:model" separated by
+// commas.
+func (m *FallbackModel) ModelID() string {
+	ids := make([]string, len(m.models))
+	for i, model := range m.models {
+		ids[i] = model.ProviderName() + DefaultSeparator + model.ModelID()
+	}
+	return strings.Join(ids, ",")
+}
+
+// SupportedUrls returns nil, since any of the models may serve a call: the SDK
+// downloads all URLs before calling the model.
+func (m *FallbackModel) SupportedUrls() []api.SupportedURL { return nil }
+
+// Models returns the models of the FallbackModel, in the order they were
+// given.
+func (m *FallbackModel) Models() []api.LanguageModel {
+	return append([]api.LanguageModel(nil), m.models...)
+}
+
+func (m *FallbackModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	var errs []error
+	for attempt, i := range m.order() {
+		model := m.models[i]
+		resp, err := m.generateWithTimeout(ctx, model, prompt, opts)
+		if err == nil {
+			m.breakers[i].success()
+			return withFallbackInfo(resp, model, attempt+1), nil
+		}
+		if !m.shouldFallback(ctx, err) {
+			return nil, err
+		}
+		m.breakers[i].failure(m.now(), m.options)
+		errs = append(errs, err)
+	}
+	return nil, fallbackError(errs)
+}
+
+func (m *FallbackModel) generateWithTimeout(
+	ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions,
+) (*api.Response, error) {
+	if m.options.Timeout <= 0 {
+		return model.Generate(ctx, prompt, opts)
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+	return model.Generate(ctx, prompt, opts)
+}
+
+func (m *FallbackModel) Stream(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+	var errs []error
+	for attempt, i := range m.order() {
+		model := m.models[i]
+		resp, err := m.openStream(ctx, model, prompt, opts)
+		if err == nil {
+			m.breakers[i].success()
+			return withFallbackStreamInfo(resp, model, attempt+1), nil
+		}
+		if !m.shouldFallback(ctx, err) {
+			return nil, err
+		}
+		m.breakers[i].failure(m.now(), m.options)
+		errs = append(errs, err)
+	}
+	return nil, fallbackError(errs)
+}
+
+// openStream opens a stream and waits for its first content event, so that
+// errors that occur before any content is received can fall back to the next
+// model.
+func (m *FallbackModel) openStream(
+	ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions,
+) (*api.StreamResponse, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if m.options.Timeout > 0 {
+		timer := time.AfterFunc(m.options.Timeout, func() { cancel(context.DeadlineExceeded) })
+		defer timer.Stop()
+	}
+
+	resp, err := model.Stream(ctx, prompt, opts)
+	if err != nil {
+		cancel(nil)
+		return nil, timeoutCause(ctx, err)
+	}
+
+	peeked, err := peekStream(ctx, resp, func(err error) bool {
+		return m.options.ShouldFallback(timeoutCause(ctx, err))
+	})
+	if err != nil {
+		cancel(nil)
+		return nil, timeoutCause(ctx, err)
+	}
+
+	stream := peeked.Stream
+	peeked.Stream = func(yield func(api.StreamEvent) bool) {
+		defer cancel(nil)
+		stream(yield)
+	}
+	return peeked, nil
+}
+
+// order returns the indexes of the models in the order they should be tried
+// for the next call. Models whose circuit is open are skipped, unless the
+// circuits of all the models are open, in which case they are all tried.
+func (m *FallbackModel) order() []int {
+	var order []int
+	switch m.options.Strategy {
+	case FallbackRoundRobin:
+		start := int(m.next.Add(1)-1) % len(m.models)
+		for i := range m.models {
+			order = append(order, (start+i)%len(m.models))
+		}
+	case FallbackWeighted:
+		order = m.weightedOrder()
+	default:
+		for i := range m.models {
+			order = append(order, i)
+		}
+	}
+
+	now := m.now()
+	available := make([]int, 0, len(order))
+	for _, i := range order {
+		if m.breakers[i].available(now) {
+			available = append(available, i)
+		}
+	}
+	if len(available) == 0 {
+		return order
+	}
+	return available
+}
+
+// weightedOrder samples the models without replacement, in proportion to
+// their weights. Models with a weight of zero go last, in order.
+func (m *FallbackModel) weightedOrder() []int {
+	weights := make([]int, len(m.models))
+	for i := range weights {
+		weights[i] = 1
+		if m.options.Weights != nil {
+			weights[i] = m.options.Weights[i]
+		}
+	}
+
+	order := make([]int, 0, len(m.models))
+	for {
+		total := 0
+		for _, weight := range weights {
+			total += weight
+		}
+		if total == 0 {
+			break
+		}
+		r := m.rand(total)
+		for i, weight := range weights {
+			if r < weight {
+				order = append(order, i)
+				weights[i] = 0
+				break
+			}
+			r -= weight
+		}
+	}
+	for i := range m.models {
+		if m.options.Weights != nil && m.options.Weights[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// shouldFallback reports whether the error of a model should be handled by
+// trying the next model. Errors caused by the cancellation of the call itself
+// are never retried.
+func (m *FallbackModel) shouldFallback(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && m.options.ShouldFallback(err)
+}
+
+// isFallbackError reports whether the error is a retryable API call error or
+// a timeout.
+func isFallbackError(err error) bool {
+	if isRetryable(err) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// timeoutCause returns context.DeadlineExceeded, wrapped with the error, if
+// the call was canceled because of the timeout of the model.
+func timeoutCause(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
+}
+
+// fallbackError returns the error of a call for which every model failed.
+func fallbackError(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return api.NewRetryError(
+		fmt.Sprintf("All %d models failed. Last error: %v", len(errs), errs[len(errs)-1]),
+		api.RetryReasonMaxRetriesExceeded, errs)
+}
+
+func withFallbackInfo(resp *api.Response, model api.LanguageModel, attempts int) *api.Response {
+	result := *resp
+	result.ResponseInfo = fallbackResponseInfo(resp.ResponseInfo, model)
+	result.ProviderMetadata = withMetadata(resp.ProviderMetadata, FallbackMetadataKey, &FallbackMetadata{
+		Provider: model.ProviderName(),
+		ModelID:  model.ModelID(),
+		Attempts: attempts,
+	})
+	return &result
+}
+
+// withFallbackStreamInfo records the model that served the stream in its
+// response info and in the provider metadata of its finish event.
+func withFallbackStreamInfo(resp *api.StreamResponse, model api.LanguageModel, attempts int) *api.StreamResponse {
+	result := *resp
+	result.ResponseInfo = fallbackResponseInfo(resp.ResponseInfo, model)
+	result.Stream = func(yield func(api.StreamEvent) bool) {
+		for event := range resp.Stream {
+			if finish, ok := event.(*api.FinishEvent); ok {
+				copied := *finish
+				copied.ProviderMetadata = withMetadata(finish.ProviderMetadata, FallbackMetadataKey, &FallbackMetadata{
+					Provider: model.ProviderName(),
+					ModelID:  model.ModelID(),
+					Attempts: attempts,
+				})
+				event = &copied
+			}
+			if !yield(event) {
+				return
+			}
+		}
+	}
+	return &result
+}
+
+func fallbackResponseInfo(info *api.ResponseInfo, model api.LanguageModel) *api.ResponseInfo {
+	result := &api.ResponseInfo{}
+	if info != nil {
+		*result = *info
+	}
+	if result.ModelID == "" {
+		result.ModelID = model.ModelID()
+	}
+	return result
+}
+
+// circuitBreaker tracks the consecutive failures of a model.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// available reports whether the circuit is closed, or its cooldown is over.
+func (b *circuitBreaker) available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+func (b *circuitBreaker) failure(now time.Time, options FallbackOptions) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if options.FailureThreshold > 0 && b.failures >= options.FailureThreshold {
+		b.openUntil = now.Add(options.Cooldown)
+	}
+}
@@ -0,0 +1,321 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+func TestFallbackModel_Generate(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   []mock.MockResult
+		secondary []mock.MockResult
+		expected  string
+		attempts  int
+		errAs     any
+	}{
+		{
+			name:      "primary succeeds",
+			primary:   []mock.MockResult{{Response: textResponse("primary", api.Usage{})}},
+			secondary: nil,
+			expected:  "primary-model",
+			attempts:  1,
+		},
+		{
+			name:      "falls back on retryable error",
+			primary:   []mock.MockResult{{Error: apiCallError(http.StatusServiceUnavailable, nil)}},
+			secondary: []mock.MockResult{{Response: textResponse("secondary", api.Usage{})}},
+			expected:  "secondary-model",
+			attempts:  2,
+		},
+		{
+			name:      "falls back on timeout",
+			primary:   []mock.MockResult{{Error: context.DeadlineExceeded}},
+			secondary: []mock.MockResult{{Response: textResponse("secondary", api.Usage{})}},
+			expected:  "secondary-model",
+			attempts:  2,
+		},
+		{
+			name:    "doesn't fall back on non-retryable error",
+			primary: []mock.MockResult{{Error: apiCallError(http.StatusBadRequest, nil)}},
+			errAs:   new(*api.APICallError),
+		},
+		{
+			name:      "all models fail",
+			primary:   []mock.MockResult{{Error: apiCallError(http.StatusTooManyRequests, nil)}},
+			secondary: []mock.MockResult{{Error: apiCallError(http.StatusInternalServerError, nil)}},
+			errAs:     new(*api.RetryError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := mock.NewGenerateModel(tt.primary, mock.WithProviderName("openai"), mock.WithModelID("primary-model"))
+			secondary := mock.NewGenerateModel(tt.secondary, mock.WithProviderName("anthropic"), mock.WithModelID("secondary-model"))
+			model, err := NewFallbackModel([]api.LanguageModel{primary, secondary}, FallbackOptions{})
+			require.NoError(t, err)
+
+			resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+			if tt.errAs != nil {
+				require.ErrorAs(t, err, tt.errAs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.ResponseInfo.ModelID)
+			metadata := GetFallbackMetadata(resp)
+			require.NotNil(t, metadata)
+			assert.Equal(t, tt.expected, metadata.ModelID)
+			assert.Equal(t, tt.attempts, metadata.Attempts)
+			primary.AssertCount(t)
+			secondary.AssertCount(t)
+		})
+	}
+}
+
+func TestFallbackModel_Timeout(t *testing.T) {
+	slow := &slowModel{GenerateModel: mock.NewGenerateModel(nil)}
+	fast := mock.NewGenerateModel([]mock.MockResult{
+		{Response: textResponse("fast", api.Usage{})},
+	}, mock.WithModelID("fast-model"))
+	model, err := NewFallbackModel([]api.LanguageModel{slow, fast}, FallbackOptions{Timeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "fast-model", GetFallbackMetadata(resp).ModelID)
+}
+
+// slowModel blocks until its context is done.
+type slowModel struct {
+	*mock.GenerateModel
+}
+
+func (m *slowModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFallbackModel_Strategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  FallbackOptions
+		rand     []int
+		expected []string
+	}{
+		{
+			name:     "priority",
+			expected: []string{"a", "a", "a", "a"},
+		},
+		{
+			name:     "round robin",
+			options:  FallbackOptions{Strategy: FallbackRoundRobin},
+			expected: []string{"a", "b", "c", "a"},
+		},
+		{
+			// The first draw of each call picks from the total weight of 4:
+			// [0, 3) is a and 3 is b. c only serves as a fallback.
+			name:     "weighted",
+			options:  FallbackOptions{Strategy: FallbackWeighted, Weights: []int{3, 1, 0}},
+			rand:     []int{0, 0, 3, 0, 2, 0, 3, 0},
+			expected: []string{"a", "b", "a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var models []api.LanguageModel
+			for _, id := range []string{"a", "b", "c"} {
+				models = append(models, mock.NewGenerateModel(nil, mock.WithModelID(id)))
+			}
+			model, err := NewFallbackModel(models, tt.options)
+			require.NoError(t, err)
+			draws := tt.rand
+			model.rand = func(n int) int {
+				r := draws[0]
+				draws = draws[1:]
+				return r
+			}
+
+			var served []string
+			for range tt.expected {
+				resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+				require.NoError(t, err)
+				served = append(served, GetFallbackMetadata(resp).ModelID)
+			}
+			assert.Equal(t, tt.expected, served)
+		})
+	}
+}
+
+func TestFallbackModel_WeightedOrder(t *testing.T) {
+	models := []api.LanguageModel{
+		mock.NewGenerateModel(nil, mock.WithModelID("a")),
+		mock.NewGenerateModel(nil, mock.WithModelID("b")),
+		mock.NewGenerateModel(nil, mock.WithModelID("c")),
+	}
+	model, err := NewFallbackModel(models, FallbackOptions{Strategy: FallbackWeighted, Weights: []int{1, 2, 0}})
+	require.NoError(t, err)
+	model.rand = func(n int) int { return n - 1 }
+
+	assert.Equal(t, []int{1, 0, 2}, model.order())
+}
+
+func TestFallbackModel_CircuitBreaker(t *testing.T) {
+	unavailable := apiCallError(http.StatusServiceUnavailable, nil)
+	primary := mock.NewGenerateModel([]mock.MockResult{
+		{Error: unavailable},
+		{Error: unavailable},
+		// The circuit is open for the third and fourth calls.
+		{Error: unavailable},
+		{Response: textResponse("primary", api.Usage{})},
+	}, mock.WithModelID("primary"))
+	secondary := mock.NewGenerateModel(nil, mock.WithModelID("secondary"))
+	model, err := NewFallbackModel([]api.LanguageModel{primary, secondary}, FallbackOptions{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	model.now = func() time.Time { return now }
+
+	generate := func() string {
+		resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+		require.NoError(t, err)
+		return GetFallbackMetadata(resp).ModelID
+	}
+
+	assert.Equal(t, "secondary", generate())
+	assert.Equal(t, "secondary", generate())
+	assert.Equal(t, "secondary", generate())
+	assert.Equal(t, "secondary", generate())
+	assert.Len(t, primary.Calls(), 2)
+
+	// After the cooldown, the primary is tried again, and a single failure
+	// opens the circuit again.
+	now = now.Add(time.Minute)
+	assert.Equal(t, "secondary", generate())
+	assert.Len(t, primary.Calls(), 3)
+	assert.Equal(t, "secondary", generate())
+	assert.Len(t, primary.Calls(), 3)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, "primary", generate())
+	assert.Equal(t, "primary", generate())
+	assert.Len(t, primary.Calls(), 5)
+}
+
+func TestFallbackModel_Stream(t *testing.T) {
+	unavailable := apiCallError(http.StatusServiceUnavailable, nil)
+	content := []api.StreamEvent{
+		&api.StreamStartEvent{},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop},
+	}
+
+	tests := []struct {
+		name      string
+		primary   mock.StreamResult
+		expected  string
+		events    int
+		streamErr bool
+	}{
+		{
+			name:     "falls back when stream fails to open",
+			primary:  mock.StreamResult{Error: unavailable},
+			expected: "secondary",
+			events:   3,
+		},
+		{
+			name: "falls back on error before content",
+			primary: mock.StreamResult{
+				Events:      []api.StreamEvent{&api.StreamStartEvent{}},
+				StreamError: unavailable,
+			},
+			expected: "secondary",
+			events:   3,
+		},
+		{
+			name: "doesn't fall back after content",
+			primary: mock.StreamResult{
+				Events:      []api.StreamEvent{&api.StreamStartEvent{}, &api.TextDeltaEvent{TextDelta: "Hel"}},
+				StreamError: unavailable,
+			},
+			expected:  "primary",
+			events:    3,
+			streamErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := mock.NewStreamModel([]mock.StreamResult{tt.primary}, mock.WithModelID("primary"))
+			secondary := mock.NewStreamModel([]mock.StreamResult{{Events: content}}, mock.WithModelID("secondary"))
+			model, err := NewFallbackModel([]api.LanguageModel{primary, secondary}, FallbackOptions{})
+			require.NoError(t, err)
+
+			resp, err := model.Stream(t.Context(), nil, api.CallOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.ResponseInfo.ModelID)
+
+			var events []api.StreamEvent
+			var finish *api.FinishEvent
+			var streamErr bool
+			for event := range resp.Stream {
+				events = append(events, event)
+				switch e := event.(type) {
+				case *api.FinishEvent:
+					finish = e
+				case *api.ErrorEvent:
+					streamErr = true
+				}
+			}
+			assert.Len(t, events, tt.events)
+			assert.Equal(t, tt.streamErr, streamErr)
+			if finish != nil {
+				assert.Equal(t, tt.expected, GetFallbackMetadata(finish).ModelID)
+			}
+		})
+	}
+}
+
+func TestNewFallbackModel_InvalidArguments(t *testing.T) {
+	model := mock.NewGenerateModel(nil)
+
+	tests := []struct {
+		name    string
+		models  []api.LanguageModel
+		options FallbackOptions
+	}{
+		{name: "no models"},
+		{
+			name:    "unknown strategy",
+			models:  []api.LanguageModel{model},
+			options: FallbackOptions{Strategy: "random"},
+		},
+		{
+			name:    "weights mismatch",
+			models:  []api.LanguageModel{model},
+			options: FallbackOptions{Strategy: FallbackWeighted, Weights: []int{1, 2}},
+		},
+		{
+			name:    "negative weight",
+			models:  []api.LanguageModel{model},
+			options: FallbackOptions{Strategy: FallbackWeighted, Weights: []int{-1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFallbackModel(tt.models, tt.options)
+			var invalidErr *api.InvalidArgumentError
+			require.ErrorAs(t, err, &invalidErr)
+		})
+	}
+}
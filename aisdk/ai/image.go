@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+
+	"go.jetify.com/ai/api"
+)
+
+// GenerateImageResponse is the result of generating images.
+type GenerateImageResponse struct {
+	// Images are the generated images, in the order of the calls that
+	// generated them. They can be used directly as content in a prompt.
+	Images []*api.ImageBlock
+
+	// Warnings are the warnings returned by all the calls.
+	Warnings []api.CallWarning
+
+	// Responses are the responses returned by the model, one for each call.
+	Responses []*api.ImageResponse
+}
+
+// Image returns the first generated image.
+func (r *GenerateImageResponse) Image() *api.ImageBlock {
+	if r == nil || len(r.Images) == 0 {
+		return nil
+	}
+	return r.Images[0]
+}
+
+// GenerateImage generates images from a text prompt using the given model.
+//
+// If more images are requested than the model can generate in a single call,
+// the request is split into several calls that run concurrently.
+//
+// Example:
+//
+//	model := openai.NewImageModel(openai.ImageModelGPTImage1)
+//	resp, err := ai.GenerateImage(ctx, model, "a lighthouse at dusk",
+//		ai.WithImageCount(2),
+//		ai.WithImageSize("1024x1024"),
+//	)
+func GenerateImage(
+	ctx context.Context, model api.ImageModel, prompt string, opts ...ImageOption,
+) (*GenerateImageResponse, error) {
+	if model == nil {
+		return nil, api.NewInvalidArgumentError("model must not be nil", "model", nil)
+	}
+	config := buildImageConfig(opts)
+	if config.ImageCallOptions.N < 0 {
+		return nil, api.NewInvalidArgumentError("number of images must not be negative", "n", nil)
+	}
+
+	counts := splitImageCount(max(config.ImageCallOptions.N, 1), model.MaxImagesPerCall())
+	maxParallel := len(counts)
+	if config.MaxParallelCalls > 0 {
+		maxParallel = min(config.MaxParallelCalls, maxParallel)
+	}
+
+	retrier := newRetrier(config.MaxRetries, config.Backoff)
+	responses, err := runParallel(ctx, counts, maxParallel, func(ctx context.Context, n int) (*api.ImageResponse, error) {
+		callOptions := config.ImageCallOptions
+		callOptions.N = n
+		return retry(ctx, retrier, func() (*api.ImageResponse, error) {
+			return model.Generate(ctx, prompt, callOptions)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GenerateImageResponse{Responses: responses}
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		result.Images = append(result.Images, resp.Images...)
+		result.Warnings = append(result.Warnings, resp.Warnings...)
+	}
+	if len(result.Images) == 0 {
+		return nil, api.NewNoContentGeneratedError("No image generated: the model did not return any images.")
+	}
+	return result, nil
+}
+
+// splitImageCount splits n images into the number of images generated by each
+// call, given the maximum number of images per call. A maximum of zero or
+// less means one image per call.
+func splitImageCount(n, maxPerCall int) []int {
+	maxPerCall = max(maxPerCall, 1)
+	counts := make([]int, 0, (n+maxPerCall-1)/maxPerCall)
+	for remaining := n; remaining > 0; remaining -= maxPerCall {
+		counts = append(counts, min(remaining, maxPerCall))
+	}
+	return counts
+}
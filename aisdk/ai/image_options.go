@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"net/http"
+
+	"go.jetify.com/ai/api"
+)
+
+// ImageOptions contains the options used by GenerateImage.
+type ImageOptions struct {
+	// ImageCallOptions are the options passed to the model. N is the total
+	// number of images to generate, which may be split across several calls.
+	ImageCallOptions api.ImageCallOptions
+
+	// MaxParallelCalls is the maximum number of concurrent calls made when the
+	// images are split into several calls. Zero means no limit.
+	MaxParallelCalls int
+
+	// MaxRetries is the maximum number of times a model call is retried when
+	// it fails with a retryable error. Defaults to 2.
+	MaxRetries int
+
+	// Backoff decides how long to wait between retries. If nil, an
+	// ExponentialBackoff with default settings is used.
+	Backoff BackoffPolicy
+}
+
+// ImageOption is a function that modifies ImageOptions.
+type ImageOption func(*ImageOptions)
+
+// WithImageCount sets the number of images to generate. Defaults to 1.
+func WithImageCount(n int) ImageOption {
+	return func(o *ImageOptions) {
+		o.ImageCallOptions.N = n
+	}
+}
+
+// WithImageSize sets the size of the images to generate, in the format
+// `{width}x{height}`.
+func WithImageSize(size string) ImageOption {
+	return func(o *ImageOptions) {
+		o.ImageCallOptions.Size = size
+	}
+}
+
+// WithImageAspectRatio sets the aspect ratio of the images to generate, in the
+// format `{width}:{height}`.
+func WithImageAspectRatio(aspectRatio string) ImageOption {
+	return func(o *ImageOptions) {
+		o.ImageCallOptions.AspectRatio = aspectRatio
+	}
+}
+
+// WithImageSeed sets the seed used to generate the images.
+func WithImageSeed(seed int) ImageOption {
+	return func(o *ImageOptions) {
+		o.ImageCallOptions.Seed = seed
+	}
+}
+
+// WithImageHeaders specifies additional HTTP headers to send with the
+// request. Only applicable for HTTP-based providers.
+func WithImageHeaders(headers http.Header) ImageOption {
+	return func(o *ImageOptions) {
+		o.ImageCallOptions.Headers = headers
+	}
+}
+
+// WithImageProviderMetadata sets additional provider-specific metadata, e.g.
+// the quality or style of the images.
+func WithImageProviderMetadata(providerName string, metadata any) ImageOption {
+	return func(o *ImageOptions) {
+		if o.ImageCallOptions.ProviderMetadata == nil {
+			o.ImageCallOptions.ProviderMetadata = api.NewProviderMetadata(map[string]any{})
+		}
+		o.ImageCallOptions.ProviderMetadata.Set(providerName, metadata)
+	}
+}
+
+// WithImageCallOptions sets the entire ImageCallOptions struct.
+func WithImageCallOptions(callOptions api.ImageCallOptions) ImageOption {
+	return func(o *ImageOptions) {
+		o.ImageCallOptions = callOptions
+	}
+}
+
+// WithImageMaxParallelCalls limits the number of concurrent calls made when
+// the images are split into several calls. Zero means no limit.
+func WithImageMaxParallelCalls(maxParallelCalls int) ImageOption {
+	return func(o *ImageOptions) {
+		o.MaxParallelCalls = maxParallelCalls
+	}
+}
+
+// WithImageMaxRetries sets the maximum number of times a model call is
+// retried when it fails with a retryable [api.APICallError]. Set it to 0 to
+// disable retries. Defaults to 2.
+func WithImageMaxRetries(maxRetries int) ImageOption {
+	return func(o *ImageOptions) {
+		o.MaxRetries = maxRetries
+	}
+}
+
+// WithImageBackoff sets the policy that decides how long to wait between
+// retries.
+func WithImageBackoff(backoff BackoffPolicy) ImageOption {
+	return func(o *ImageOptions) {
+		o.Backoff = backoff
+	}
+}
+
+// buildImageConfig combines multiple image options into a single ImageOptions struct.
+func buildImageConfig(opts []ImageOption) ImageOptions {
+	config := ImageOptions{
+		ImageCallOptions: api.ImageCallOptions{
+			ProviderMetadata: api.NewProviderMetadata(map[string]any{}),
+		},
+		MaxRetries: defaultMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+// fakeImageModel generates images whose data is the index of the image within
+// its call, and records the calls it receives.
+type fakeImageModel struct {
+	maxPerCall int
+	empty      bool
+
+	// errs are returned, in order, by the first calls.
+	errs []error
+
+	mu          sync.Mutex
+	counts      []int
+	options     []api.ImageCallOptions
+	failedCalls int
+}
+
+var _ api.ImageModel = &fakeImageModel{}
+
+func (m *fakeImageModel) ProviderName() string  { return "fake" }
+func (m *fakeImageModel) ModelID() string       { return "fake-image" }
+func (m *fakeImageModel) MaxImagesPerCall() int { return m.maxPerCall }
+
+func (m *fakeImageModel) Generate(ctx context.Context, prompt string, opts api.ImageCallOptions) (*api.ImageResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts = append(m.counts, opts.N)
+	m.options = append(m.options, opts)
+	if m.failedCalls < len(m.errs) {
+		err := m.errs[m.failedCalls]
+		m.failedCalls++
+		return nil, err
+	}
+
+	resp := &api.ImageResponse{
+		Warnings: []api.CallWarning{{Type: "other", Message: "call " + strconv.Itoa(len(m.counts))}},
+	}
+	if m.empty {
+		return resp, nil
+	}
+	for i := range opts.N {
+		resp.Images = append(resp.Images, &api.ImageBlock{
+			Data:      []byte(strconv.Itoa(i)),
+			MediaType: "image/png",
+		})
+	}
+	return resp, nil
+}
+
+func TestGenerateImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      *fakeImageModel
+		opts       []ImageOption
+		wantCounts []int
+	}{
+		{
+			name:       "single image by default",
+			model:      &fakeImageModel{maxPerCall: 4},
+			wantCounts: []int{1},
+		},
+		{
+			name:       "fits in one call",
+			model:      &fakeImageModel{maxPerCall: 4},
+			opts:       []ImageOption{WithImageCount(4)},
+			wantCounts: []int{4},
+		},
+		{
+			name:       "split across calls",
+			model:      &fakeImageModel{maxPerCall: 2},
+			opts:       []ImageOption{WithImageCount(5)},
+			wantCounts: []int{2, 2, 1},
+		},
+		{
+			name:       "one image per call without a limit",
+			model:      &fakeImageModel{},
+			opts:       []ImageOption{WithImageCount(3)},
+			wantCounts: []int{1, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := GenerateImage(t.Context(), tt.model, "a lighthouse", tt.opts...)
+			require.NoError(t, err)
+
+			require.ElementsMatch(t, tt.wantCounts, tt.model.counts)
+			require.Len(t, resp.Responses, len(tt.wantCounts))
+			require.Len(t, resp.Warnings, len(tt.wantCounts))
+
+			// Images are returned in the order of the calls.
+			var want []string
+			for _, n := range tt.wantCounts {
+				for i := range n {
+					want = append(want, strconv.Itoa(i))
+				}
+			}
+			var got []string
+			for _, image := range resp.Images {
+				got = append(got, string(image.Data))
+			}
+			require.Equal(t, want, got)
+			require.Equal(t, resp.Images[0], resp.Image())
+		})
+	}
+}
+
+func TestGenerateImage_Options(t *testing.T) {
+	model := &fakeImageModel{maxPerCall: 1}
+
+	_, err := GenerateImage(t.Context(), model, "a lighthouse",
+		WithImageSize("1024x1024"),
+		WithImageAspectRatio("16:9"),
+		WithImageSeed(42),
+		WithImageHeaders(http.Header{"X-Test": []string{"1"}}),
+		WithImageProviderMetadata("fake", map[string]any{"quality": "high"}),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, model.options, 1)
+	opts := model.options[0]
+	require.Equal(t, 1, opts.N)
+	require.Equal(t, "1024x1024", opts.Size)
+	require.Equal(t, "16:9", opts.AspectRatio)
+	require.Equal(t, 42, opts.Seed)
+	require.Equal(t, "1", opts.Headers.Get("X-Test"))
+	require.True(t, opts.ProviderMetadata.Has("fake"))
+}
+
+func TestGenerateImage_Errors(t *testing.T) {
+	boom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		model   api.ImageModel
+		opts    []ImageOption
+		wantErr error
+	}{
+		{
+			name:    "nil model",
+			wantErr: &api.InvalidArgumentError{},
+		},
+		{
+			name:    "negative count",
+			model:   &fakeImageModel{},
+			opts:    []ImageOption{WithImageCount(-1)},
+			wantErr: &api.InvalidArgumentError{},
+		},
+		{
+			name:    "no images",
+			model:   &fakeImageModel{maxPerCall: 1, empty: true},
+			wantErr: &api.NoContentGeneratedError{},
+		},
+		{
+			name:    "call fails",
+			model:   &fakeImageModel{maxPerCall: 1, errs: []error{boom}},
+			opts:    []ImageOption{WithImageCount(3), WithImageMaxRetries(0)},
+			wantErr: boom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateImage(t.Context(), tt.model, "a lighthouse", tt.opts...)
+			require.Error(t, err)
+			require.IsType(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestGenerateImage_Retries(t *testing.T) {
+	model := &fakeImageModel{maxPerCall: 1, errs: []error{apiCallError(http.StatusTooManyRequests, nil)}}
+
+	resp, err := GenerateImage(t.Context(), model, "a lighthouse", WithImageBackoff(noBackoff{}))
+	require.NoError(t, err)
+	require.Len(t, resp.Images, 1)
+	require.Len(t, model.counts, 2)
+}
+
+func TestSplitImageCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		n          int
+		maxPerCall int
+		want       []int
+	}{
+		{name: "no limit", n: 3, maxPerCall: 0, want: []int{1, 1, 1}},
+		{name: "fits in one call", n: 3, maxPerCall: 3, want: []int{3}},
+		{name: "even calls", n: 4, maxPerCall: 2, want: []int{2, 2}},
+		{name: "last call smaller", n: 5, maxPerCall: 2, want: []int{2, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, splitImageCount(tt.n, tt.maxPerCall))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	gomcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+)
+
+// Client is a connection to an MCP server. It exposes the tools of the server
+// as tools that language models can call, and routes the calls back to the
+// server.
+type Client struct {
+	session *gomcp.ClientSession
+}
+
+// ClientOption configures the connection to an MCP server.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	implementation *gomcp.Implementation
+	httpClient     *http.Client
+}
+
+// WithImplementation sets the name and version of the client, which are sent
+// to the server when connecting.
+func WithImplementation(name, version string) ClientOption {
+	return func(o *clientOptions) {
+		o.implementation = &gomcp.Implementation{Name: name, Version: version}
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by ConnectHTTP. Use it to add
+// authentication headers or timeouts.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+func buildClientOptions(opts []ClientOption) clientOptions {
+	options := clientOptions{
+		implementation: &gomcp.Implementation{Name: "go.jetify.com/ai", Version: "v0"},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Connect connects to an MCP server over the given transport and performs the
+// initialization handshake. Close the client when done.
+func Connect(ctx context.Context, transport gomcp.Transport, opts ...ClientOption) (*Client, error) {
+	options := buildClientOptions(opts)
+	session, err := gomcp.NewClient(options.implementation, nil).Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect to MCP server: %w", err)
+	}
+	return &Client{session: session}, nil
+}
+
+// ConnectStdio starts the given command and connects to the MCP server it
+// runs, communicating over its stdin and stdout. Closing the client stops the
+// command.
+//
+//	client, err := mcp.ConnectStdio(ctx, exec.Command("npx", "-y", "@modelcontextprotocol/server-everything"))
+func ConnectStdio(ctx context.Context, cmd *exec.Cmd, opts ...ClientOption) (*Client, error) {
+	return Connect(ctx, &gomcp.CommandTransport{Command: cmd}, opts...)
+}
+
+// ConnectHTTP connects to an MCP server at the given endpoint using the
+// streamable HTTP transport.
+func ConnectHTTP(ctx context.Context, endpoint string, opts ...ClientOption) (*Client, error) {
+	options := buildClientOptions(opts)
+	transport := &gomcp.StreamableClientTransport{
+		Endpoint:   endpoint,
+		HTTPClient: options.httpClient,
+	}
+	return Connect(ctx, transport, opts...)
+}
+
+// Session returns the underlying MCP session, for features other than tools
+// such as prompts and resources.
+func (c *Client) Session() *gomcp.ClientSession {
+	return c.session
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.session.Close()
+}
+
+// Tools lists the tools of the server and returns them as executable tools,
+// ready to be passed to ai.WithExecutableTools. Calls to these tools are
+// routed back to the server.
+//
+//	tools, err := client.Tools(ctx)
+//	resp, err := ai.GenerateTextStr(ctx, "What's the weather in Paris?",
+//		ai.WithModel(model),
+//		ai.WithExecutableTools(tools...),
+//	)
+func (c *Client) Tools(ctx context.Context) ([]*ai.Tool, error) {
+	var tools []*ai.Tool
+	for tool, err := range c.session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("list MCP tools: %w", err)
+		}
+		definition, err := ToolDefinition(tool)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, &ai.Tool{
+			Definition: definition,
+			Execute:    c.execute(tool.Name),
+		})
+	}
+	return tools, nil
+}
+
+// execute returns a ToolFunc that calls the named tool on the server.
+func (c *Client) execute(name string) ai.ToolFunc {
+	return func(ctx context.Context, args json.RawMessage) (any, error) {
+		result, err := c.callTool(ctx, name, args)
+		if err != nil {
+			return nil, err
+		}
+		if result.IsError {
+			return nil, errors.New(resultText(result))
+		}
+		if len(result.Content) == 0 && result.StructuredContent != nil {
+			return result.StructuredContent, nil
+		}
+		return ContentBlocks(result.Content), nil
+	}
+}
+
+// CallTool routes a tool call requested by the model to the server and
+// returns its result. Errors reported by the tool are returned as error
+// results, so that the model can recover; the error is only set if the server
+// couldn't be called.
+//
+// Use it to handle tool calls manually instead of with executable tools.
+func (c *Client) CallTool(ctx context.Context, call *api.ToolCallBlock) (api.ToolResultBlock, error) {
+	result, err := c.callTool(ctx, call.ToolName, call.Args)
+	if err != nil {
+		return api.ToolResultBlock{}, err
+	}
+	return ToolResult(call, result), nil
+}
+
+func (c *Client) callTool(ctx context.Context, name string, args json.RawMessage) (*gomcp.CallToolResult, error) {
+	params := &gomcp.CallToolParams{Name: name}
+	if len(args) > 0 {
+		params.Arguments = args
+	}
+	result, err := c.session.CallTool(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("call MCP tool %q: %w", name, err)
+	}
+	return result, nil
+}
+
+// resultText returns the text content of a tool result, which for error
+// results is the error message.
+func resultText(result *gomcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*gomcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	if len(parts) == 0 {
+		return "tool call failed"
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+
+	gomcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+// serverEnv is set when the test binary is run as a stdio MCP server.
+const serverEnv = "AI_MCP_TEST_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(serverEnv) != "" {
+		if err := newTestServer().Run(context.Background(), &gomcp.StdioTransport{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type weatherArgs struct {
+	City string `json:"city" jsonschema:"the city to get the weather for"`
+}
+
+var pixel = []byte{0x89, 'P', 'N', 'G'}
+
+func newTestServer() *gomcp.Server {
+	server := gomcp.NewServer(&gomcp.Implementation{Name: "test", Version: "v1"}, nil)
+	gomcp.AddTool(server, &gomcp.Tool{Name: "weather", Description: "Get the weather"},
+		func(ctx context.Context, req *gomcp.CallToolRequest, args weatherArgs) (*gomcp.CallToolResult, any, error) {
+			if args.City == "Atlantis" {
+				return &gomcp.CallToolResult{
+					IsError: true,
+					Content: []gomcp.Content{&gomcp.TextContent{Text: "unknown city"}},
+				}, nil, nil
+			}
+			return &gomcp.CallToolResult{
+				Content: []gomcp.Content{&gomcp.TextContent{Text: "Sunny in " + args.City}},
+			}, nil, nil
+		})
+	gomcp.AddTool(server, &gomcp.Tool{Name: "map", Description: "Get a map"},
+		func(ctx context.Context, req *gomcp.CallToolRequest, args weatherArgs) (*gomcp.CallToolResult, any, error) {
+			return &gomcp.CallToolResult{
+				Content: []gomcp.Content{
+					&gomcp.ImageContent{Data: pixel, MIMEType: "image/png"},
+					&gomcp.EmbeddedResource{Resource: &gomcp.ResourceContents{
+						URI: "maps://" + args.City, MIMEType: "text/plain", Text: "Map of " + args.City,
+					}},
+				},
+			}, nil, nil
+		})
+	return server
+}
+
+func connectStdio(t *testing.T) *Client {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), serverEnv+"=1")
+	client, err := ConnectStdio(t.Context(), cmd)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func connectHTTP(t *testing.T) *Client {
+	handler := gomcp.NewStreamableHTTPHandler(func(*http.Request) *gomcp.Server {
+		return newTestServer()
+	}, nil)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := ConnectHTTP(t.Context(), server.URL, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestClient(t *testing.T) {
+	transports := []struct {
+		name    string
+		connect func(t *testing.T) *Client
+	}{
+		{name: "stdio", connect: connectStdio},
+		{name: "http", connect: connectHTTP},
+	}
+
+	for _, transport := range transports {
+		t.Run(transport.name, func(t *testing.T) {
+			client := transport.connect(t)
+
+			tools, err := client.Tools(t.Context())
+			require.NoError(t, err)
+			require.Len(t, tools, 2)
+
+			byName := map[string]*ai.Tool{}
+			for _, tool := range tools {
+				byName[tool.Name()] = tool
+			}
+			weather := byName["weather"]
+			require.NotNil(t, weather)
+			assert.Equal(t, "Get the weather", weather.Definition.Description)
+			require.NotNil(t, weather.Definition.InputSchema)
+			assert.Equal(t, "object", weather.Definition.InputSchema.Type)
+			assert.Equal(t, []string{"city"}, weather.Definition.InputSchema.Required)
+			assert.Equal(t, "the city to get the weather for",
+				weather.Definition.InputSchema.Properties["city"].Description)
+
+			output, err := weather.Execute(t.Context(), json.RawMessage(`{"city":"Paris"}`))
+			require.NoError(t, err)
+			assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "Sunny in Paris"}}, output)
+
+			_, err = weather.Execute(t.Context(), json.RawMessage(`{"city":"Atlantis"}`))
+			assert.EqualError(t, err, "unknown city")
+
+			result, err := client.CallTool(t.Context(), &api.ToolCallBlock{
+				ToolCallID: "call_1",
+				ToolName:   "map",
+				Args:       json.RawMessage(`{"city":"Paris"}`),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, api.ToolResultBlock{
+				ToolCallID: "call_1",
+				ToolName:   "map",
+				Content: []api.ContentBlock{
+					&api.ImageBlock{Data: pixel, MediaType: "image/png"},
+					&api.TextBlock{Text: "Map of Paris"},
+				},
+			}, result)
+		})
+	}
+}
+
+func TestClient_Generate(t *testing.T) {
+	client := connectStdio(t)
+	tools, err := client.Tools(t.Context())
+	require.NoError(t, err)
+
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: &api.Response{
+			Content: []api.ContentBlock{&api.ToolCallBlock{
+				ToolCallID: "call_1",
+				ToolName:   "weather",
+				Args:       json.RawMessage(`{"city":"Paris"}`),
+			}},
+			FinishReason: api.FinishReasonToolCalls,
+		}},
+		{Response: &api.Response{
+			Content:      []api.ContentBlock{&api.TextBlock{Text: "It's sunny."}},
+			FinishReason: api.FinishReasonStop,
+		}},
+	})
+
+	resp, err := ai.GenerateTextStr(t.Context(), "What's the weather in Paris?",
+		ai.WithModel(model),
+		ai.WithExecutableTools(tools...),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "It's sunny."}}, resp.Content)
+
+	calls := model.Calls()
+	require.Len(t, calls, 2)
+	toolMessage, ok := calls[1].Prompt[len(calls[1].Prompt)-1].(*api.ToolMessage)
+	require.True(t, ok)
+	assert.Equal(t, []api.ToolResultBlock{{
+		ToolCallID: "call_1",
+		ToolName:   "weather",
+		Content:    []api.ContentBlock{&api.TextBlock{Text: "Sunny in Paris"}},
+	}}, toolMessage.Content)
+}
+
+func TestContentBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []gomcp.Content
+		expected []api.ContentBlock
+	}{
+		{
+			name:     "audio",
+			content:  []gomcp.Content{&gomcp.AudioContent{Data: []byte("wav"), MIMEType: "audio/wav"}},
+			expected: []api.ContentBlock{&api.FileBlock{Data: []byte("wav"), MediaType: "audio/wav"}},
+		},
+		{
+			name: "binary resources",
+			content: []gomcp.Content{
+				&gomcp.EmbeddedResource{Resource: &gomcp.ResourceContents{
+					URI: "file:///cat.png", MIMEType: "image/png", Blob: pixel,
+				}},
+				&gomcp.EmbeddedResource{Resource: &gomcp.ResourceContents{
+					URI: "file:///doc.pdf", MIMEType: "application/pdf", Blob: []byte("%PDF"),
+				}},
+			},
+			expected: []api.ContentBlock{
+				&api.ImageBlock{Data: pixel, MediaType: "image/png"},
+				&api.FileBlock{Filename: "file:///doc.pdf", Data: []byte("%PDF"), MediaType: "application/pdf"},
+			},
+		},
+		{
+			name: "resource link",
+			content: []gomcp.Content{&gomcp.ResourceLink{
+				URI: "file:///notes.md", Name: "notes", Description: "Meeting notes",
+			}},
+			expected: []api.ContentBlock{
+				&api.TextBlock{Text: "Resource \"notes\": file:///notes.md\nMeeting notes"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ContentBlocks(tt.content))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	gomcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.jetify.com/ai/api"
+)
+
+// ToolDefinition converts an MCP tool into a function tool definition that can
+// be sent to a language model, reusing the tool's input schema.
+func ToolDefinition(tool *gomcp.Tool) (*api.FunctionTool, error) {
+	definition := &api.FunctionTool{
+		Name:        tool.Name,
+		Description: tool.Description,
+	}
+	if tool.InputSchema == nil {
+		return definition, nil
+	}
+
+	// Clients receive the schema as a map[string]any, so round-trip it through
+	// JSON to get a typed schema.
+	data, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("marshal input schema of MCP tool %q: %w", tool.Name, err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal input schema of MCP tool %q: %w", tool.Name, err)
+	}
+	definition.InputSchema = &schema
+	return definition, nil
+}
+
+// ToolResult converts the result of an MCP tool call into a tool result block
+// for the given call.
+func ToolResult(call *api.ToolCallBlock, result *gomcp.CallToolResult) api.ToolResultBlock {
+	block := api.ToolResultBlock{
+		ToolCallID: call.ToolCallID,
+		ToolName:   call.ToolName,
+		IsError:    result.IsError,
+	}
+	switch {
+	case result.IsError:
+		block.Result = resultText(result)
+	case len(result.Content) == 0:
+		block.Result = result.StructuredContent
+	default:
+		block.Content = ContentBlocks(result.Content)
+	}
+	return block
+}
+
+// ContentBlocks converts MCP content into content blocks:
+//   - Text becomes a TextBlock.
+//   - Images become an ImageBlock.
+//   - Audio becomes a FileBlock.
+//   - Embedded resources become a TextBlock for text resources, and an
+//     ImageBlock or FileBlock for binary resources.
+//   - Resource links become a TextBlock describing the link, since the model
+//     can't fetch MCP resources by itself.
+func ContentBlocks(content []gomcp.Content) []api.ContentBlock {
+	blocks := make([]api.ContentBlock, 0, len(content))
+	for _, c := range content {
+		switch c := c.(type) {
+		case *gomcp.TextContent:
+			blocks = append(blocks, &api.TextBlock{Text: c.Text})
+		case *gomcp.ImageContent:
+			blocks = append(blocks, &api.ImageBlock{Data: c.Data, MediaType: c.MIMEType})
+		case *gomcp.AudioContent:
+			blocks = append(blocks, &api.FileBlock{Data: c.Data, MediaType: c.MIMEType})
+		case *gomcp.EmbeddedResource:
+			if c.Resource != nil {
+				blocks = append(blocks, resourceBlock(c.Resource))
+			}
+		case *gomcp.ResourceLink:
+			blocks = append(blocks, &api.TextBlock{Text: resourceLinkText(c)})
+		}
+	}
+	return blocks
+}
+
+func resourceBlock(resource *gomcp.ResourceContents) api.ContentBlock {
+	switch {
+	case resource.Blob == nil:
+		return &api.TextBlock{Text: resource.Text}
+	case strings.HasPrefix(resource.MIMEType, "image/"):
+		return &api.ImageBlock{Data: resource.Blob, MediaType: resource.MIMEType}
+	default:
+		return &api.FileBlock{Filename: resource.URI, Data: resource.Blob, MediaType: resource.MIMEType}
+	}
+}
+
+func resourceLinkText(link *gomcp.ResourceLink) string {
+	name := link.Title
+	if name == "" {
+		name = link.Name
+	}
+	text := fmt.Sprintf("Resource %q: %s", name, link.URI)
+	if link.Description != "" {
+		text += "\n" + link.Description
+	}
+	return text
+}
@@ -0,0 +1,23 @@
+// Package mcp connects language models to the tools of Model Context Protocol
+// (MCP) servers.
+//
+// It connects to servers over stdio or streamable HTTP using the official MCP
+// Go SDK, converts their tools into function tools, and routes the tool calls
+// made by the model back to the server. It is a separate module, so that
+// programs that don't use MCP don't depend on the SDK:
+//
+//	client, err := mcp.ConnectStdio(ctx, exec.Command("my-mcp-server"))
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	tools, err := client.Tools(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := ai.GenerateTextStr(ctx, prompt,
+//		ai.WithModel(model),
+//		ai.WithExecutableTools(tools...),
+//	)
+package mcp
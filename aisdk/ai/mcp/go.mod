@@ -0,0 +1,30 @@
+module go.jetify.com/ai/mcp
+
+go 1.24.0
+
+require (
+	github.com/google/jsonschema-go v0.3.0
+	github.com/modelcontextprotocol/go-sdk v1.1.0
+	github.com/stretchr/testify v1.11.1
+	go.jetify.com/ai v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/anthropics/anthropic-sdk-go v1.19.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gosimple/slug v1.15.0 // indirect
+	github.com/gosimple/unidecode v1.0.1 // indirect
+	github.com/openai/openai-go/v2 v2.7.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/tidwall/gjson v1.18.0 // indirect
+	github.com/tidwall/match v1.2.0 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	github.com/tidwall/sjson v1.2.5 // indirect
+	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	go.jetify.com/pkg v0.0.0-20251201231142-abe4fc632859 // indirect
+	golang.org/x/oauth2 v0.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace go.jetify.com/ai => ../
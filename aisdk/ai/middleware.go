@@ -0,0 +1,194 @@
+package ai
+
+import (
+	"context"
+	"maps"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+)
+
+// Middleware customizes the behavior of a language model, e.g. to add logging,
+// redact content, apply default settings or rewrite prompts.
+//
+// All hooks are optional. Use WrapLanguageModel to apply middleware to a model.
+type Middleware struct {
+	// TransformParams transforms the prompt and call options before they are
+	// passed to the model. It is called for both Generate and Stream.
+	//
+	// Implementations should return modified copies instead of changing the
+	// given prompt or options in place.
+	TransformParams func(ctx context.Context, prompt []api.Message, opts api.CallOptions) ([]api.Message, api.CallOptions, error)
+
+	// WrapGenerate wraps a call to Generate. The model is the next model in
+	// the chain: call model.Generate to continue with the call.
+	WrapGenerate func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.Response, error)
+
+	// WrapStream wraps a call to Stream. The model is the next model in the
+	// chain: call model.Stream to continue with the call. To transform the
+	// events, replace the Stream iterator of the returned response.
+	WrapStream func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error)
+}
+
+// WrapLanguageModel returns a language model that applies the given middleware
+// to every call of the model.
+//
+// The middleware are applied in order: the first middleware sees the call
+// first, and its WrapGenerate and WrapStream hooks wrap all the others.
+//
+//	model := ai.WrapLanguageModel(openai.NewLanguageModel(openai.ChatModelGPT5),
+//		ai.DefaultSettingsMiddleware(api.CallOptions{MaxOutputTokens: 1024}),
+//		ai.ExtractReasoningMiddleware(ai.ExtractReasoningOptions{}),
+//	)
+func WrapLanguageModel(model api.LanguageModel, middleware ...*Middleware) api.LanguageModel {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] == nil {
+			continue
+		}
+		model = &wrappedModel{model: model, middleware: middleware[i]}
+	}
+	return model
+}
+
+// wrappedModel is a language model that applies a single middleware.
+type wrappedModel struct {
+	model      api.LanguageModel
+	middleware *Middleware
+}
+
+var (
+	_ api.LanguageModel                = &wrappedModel{}
+	_ api.ObjectGenerationModeProvider = &wrappedModel{}
+)
+
+func (m *wrappedModel) ProviderName() string { return m.model.ProviderName() }
+
+func (m *wrappedModel) ModelID() string { return m.model.ModelID() }
+
+func (m *wrappedModel) SupportedUrls() []api.SupportedURL { return m.model.SupportedUrls() }
+
+// DefaultObjectGenerationMode returns the mode preferred by the wrapped model.
+func (m *wrappedModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	if provider, ok := m.model.(api.ObjectGenerationModeProvider); ok {
+		return provider.DefaultObjectGenerationMode()
+	}
+	return api.ObjectGenerationModeNone
+}
+
+func (m *wrappedModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	prompt, opts, err := m.transformParams(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	if m.middleware.WrapGenerate == nil {
+		return m.model.Generate(ctx, prompt, opts)
+	}
+	return m.middleware.WrapGenerate(ctx, m.model, prompt, opts)
+}
+
+func (m *wrappedModel) Stream(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+	prompt, opts, err := m.transformParams(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	if m.middleware.WrapStream == nil {
+		return m.model.Stream(ctx, prompt, opts)
+	}
+	return m.middleware.WrapStream(ctx, m.model, prompt, opts)
+}
+
+func (m *wrappedModel) transformParams(ctx context.Context, prompt []api.Message, opts api.CallOptions) ([]api.Message, api.CallOptions, error) {
+	if m.middleware.TransformParams == nil {
+		return prompt, opts, nil
+	}
+	return m.middleware.TransformParams(ctx, prompt, opts)
+}
+
+// DefaultSettingsMiddleware returns a middleware that applies default call
+// options. Settings set explicitly on a call take precedence over the
+// defaults. Headers and provider metadata are merged, with the call values
+// taking precedence for the same header or provider.
+func DefaultSettingsMiddleware(defaults api.CallOptions) *Middleware {
+	return &Middleware{
+		TransformParams: func(ctx context.Context, prompt []api.Message, opts api.CallOptions) ([]api.Message, api.CallOptions, error) {
+			return prompt, applyDefaultSettings(opts, defaults), nil
+		},
+	}
+}
+
+func applyDefaultSettings(opts, defaults api.CallOptions) api.CallOptions {
+	if opts.MaxOutputTokens == 0 {
+		opts.MaxOutputTokens = defaults.MaxOutputTokens
+	}
+	if opts.Temperature == nil {
+		opts.Temperature = defaults.Temperature
+	}
+	if opts.TopP == 0 {
+		opts.TopP = defaults.TopP
+	}
+	if opts.TopK == 0 {
+		opts.TopK = defaults.TopK
+	}
+	if opts.PresencePenalty == 0 {
+		opts.PresencePenalty = defaults.PresencePenalty
+	}
+	if opts.FrequencyPenalty == 0 {
+		opts.FrequencyPenalty = defaults.FrequencyPenalty
+	}
+	if len(opts.StopSequences) == 0 {
+		opts.StopSequences = defaults.StopSequences
+	}
+	if opts.Seed == 0 {
+		opts.Seed = defaults.Seed
+	}
+	if len(opts.Tools) == 0 {
+		opts.Tools = defaults.Tools
+	}
+	if opts.ToolChoice == nil {
+		opts.ToolChoice = defaults.ToolChoice
+	}
+	if opts.ResponseFormat == nil {
+		opts.ResponseFormat = defaults.ResponseFormat
+	}
+	if opts.Reasoning == nil {
+		opts.Reasoning = defaults.Reasoning
+	}
+	if !opts.IncludeRawChunks {
+		opts.IncludeRawChunks = defaults.IncludeRawChunks
+	}
+
+	if len(defaults.Headers) > 0 {
+		headers := defaults.Headers.Clone()
+		maps.Copy(headers, opts.Headers)
+		opts.Headers = headers
+	}
+
+	if !defaults.ProviderMetadata.IsZero() {
+		metadata := api.NewProviderMetadata(nil)
+		for _, provider := range defaults.ProviderMetadata.Providers() {
+			value, _ := defaults.ProviderMetadata.Get(provider)
+			metadata.Set(provider, value)
+		}
+		for _, provider := range opts.ProviderMetadata.Providers() {
+			value, _ := opts.ProviderMetadata.Get(provider)
+			metadata.Set(provider, value)
+		}
+		opts.ProviderMetadata = metadata
+	}
+	return opts
+}
+
+// SimulateStreamingMiddleware returns a middleware that implements Stream by
+// calling Generate on the model and converting the response into a stream of
+// events. It is useful for models that don't support streaming.
+func SimulateStreamingMiddleware() *Middleware {
+	return &Middleware{
+		WrapStream: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+			resp, err := model.Generate(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+			return builder.ResponseToStream(resp), nil
+		},
+	}
+}
@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"context"
+	"iter"
+	"strings"
+
+	"go.jetify.com/ai/api"
+)
+
+// ExtractReasoningOptions configures ExtractReasoningMiddleware.
+type ExtractReasoningOptions struct {
+	// TagName is the name of the XML tag that contains the reasoning,
+	// e.g. "think" for <think>...</think>. Defaults to "think".
+	TagName string
+
+	// Separator is inserted between the text segments that remain after the
+	// reasoning is removed, and between multiple reasoning segments.
+	// Defaults to "\n".
+	Separator string
+
+	// StartWithReasoning indicates that the model output starts inside the
+	// reasoning tag, i.e. the opening tag is part of the prompt and is not
+	// generated by the model.
+	StartWithReasoning bool
+}
+
+// ExtractReasoningMiddleware returns a middleware that extracts reasoning
+// wrapped in XML tags (e.g. <think>...</think>) from the text generated by the
+// model, as done by models like DeepSeek R1. The reasoning is returned as
+// ReasoningBlocks in responses and as ReasoningEvents in streams, in the order
+// in which it appears in the text.
+func ExtractReasoningMiddleware(options ExtractReasoningOptions) *Middleware {
+	if options.TagName == "" {
+		options.TagName = "think"
+	}
+	if options.Separator == "" {
+		options.Separator = "\n"
+	}
+	openTag := "<" + options.TagName + ">"
+	closeTag := "</" + options.TagName + ">"
+
+	return &Middleware{
+		WrapGenerate: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+			resp, err := model.Generate(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			// The text blocks are split as if they were streamed, so that both
+			// APIs return the same content. The reasoning state carries over
+			// from one block to the next, as it does in a stream.
+			extractor := &reasoningExtractor{
+				openTag:     openTag,
+				closeTag:    closeTag,
+				separator:   options.Separator,
+				isReasoning: options.StartWithReasoning,
+			}
+			content := make([]api.ContentBlock, 0, len(resp.Content))
+			for _, block := range resp.Content {
+				textBlock, ok := block.(*api.TextBlock)
+				if !ok || textBlock.Text == "" {
+					content = append(content, block)
+					continue
+				}
+
+				first := len(content)
+				extractor.buffer = textBlock.Text
+				extractor.drain(func(event api.StreamEvent) bool {
+					content = appendSegment(content, first, event, textBlock.ProviderMetadata)
+					return true
+				}, true)
+			}
+
+			result := *resp
+			result.Content = content
+			return &result, nil
+		},
+
+		WrapStream: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+			resp, err := model.Stream(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			extractor := &reasoningExtractor{
+				openTag:     openTag,
+				closeTag:    closeTag,
+				separator:   options.Separator,
+				isReasoning: options.StartWithReasoning,
+			}
+			result := *resp
+			result.Stream = extractor.extract(resp.Stream)
+			return &result, nil
+		},
+	}
+}
+
+// appendSegment adds a text or reasoning event emitted by a reasoningExtractor
+// to content. The last block is extended if it has the same kind and was
+// created for the current text block, i.e. its index is at least first.
+func appendSegment(
+	content []api.ContentBlock, first int, event api.StreamEvent, metadata *api.ProviderMetadata,
+) []api.ContentBlock {
+	var last api.ContentBlock
+	if len(content) > first {
+		last = content[len(content)-1]
+	}
+	switch e := event.(type) {
+	case *api.TextDeltaEvent:
+		if block, ok := last.(*api.TextBlock); ok {
+			block.Text += e.TextDelta
+			return content
+		}
+		return append(content, &api.TextBlock{Text: e.TextDelta, ProviderMetadata: metadata})
+	case *api.ReasoningEvent:
+		if block, ok := last.(*api.ReasoningBlock); ok {
+			block.Text += e.TextDelta
+			return content
+		}
+		return append(content, &api.ReasoningBlock{Text: e.TextDelta})
+	}
+	return content
+}
+
+// reasoningExtractor splits streamed text deltas into text and reasoning
+// events. Text that might be the start of a tag is buffered until the next
+// delta arrives.
+type reasoningExtractor struct {
+	openTag   string
+	closeTag  string
+	separator string
+
+	isReasoning bool
+	buffer      string
+
+	// afterSwitch is true when the last tag switched between text and
+	// reasoning, and no delta has been emitted since.
+	afterSwitch      bool
+	emittedText      bool
+	emittedReasoning bool
+}
+
+func (e *reasoningExtractor) extract(stream iter.Seq[api.StreamEvent]) iter.Seq[api.StreamEvent] {
+	return func(yield func(api.StreamEvent) bool) {
+		for event := range stream {
+			if delta, ok := event.(*api.TextDeltaEvent); ok {
+				e.buffer += delta.TextDelta
+				if !e.drain(yield, false) {
+					return
+				}
+				continue
+			}
+
+			// Any other event ends the current text, so partial tags are
+			// emitted as they are.
+			if !e.drain(yield, true) {
+				return
+			}
+			if !yield(event) {
+				return
+			}
+		}
+		e.drain(yield, true)
+	}
+}
+
+// drain emits the buffered text, switching between text and reasoning at every
+// complete tag. Unless flush is set, a trailing partial tag is kept in the
+// buffer. It returns false if the consumer stopped the iteration.
+func (e *reasoningExtractor) drain(yield func(api.StreamEvent) bool, flush bool) bool {
+	for {
+		tag := e.openTag
+		if e.isReasoning {
+			tag = e.closeTag
+		}
+
+		start := potentialTagIndex(e.buffer, tag)
+		if start < 0 || (flush && start+len(tag) > len(e.buffer)) {
+			ok := e.emit(yield, e.buffer)
+			e.buffer = ""
+			return ok
+		}
+
+		if !e.emit(yield, e.buffer[:start]) {
+			return false
+		}
+		if start+len(tag) > len(e.buffer) {
+			// Partial tag: wait for more text.
+			e.buffer = e.buffer[start:]
+			return true
+		}
+
+		e.buffer = e.buffer[start+len(tag):]
+		e.isReasoning = !e.isReasoning
+		e.afterSwitch = true
+	}
+}
+
+// emit yields a text or reasoning event for the given text, depending on the
+// current state.
+func (e *reasoningExtractor) emit(yield func(api.StreamEvent) bool, text string) bool {
+	if text == "" {
+		return true
+	}
+
+	emitted := &e.emittedText
+	if e.isReasoning {
+		emitted = &e.emittedReasoning
+	}
+	if e.afterSwitch && *emitted {
+		text = e.separator + text
+	}
+	e.afterSwitch = false
+	*emitted = true
+
+	if e.isReasoning {
+		return yield(&api.ReasoningEvent{TextDelta: text})
+	}
+	return yield(&api.TextDeltaEvent{TextDelta: text})
+}
+
+// potentialTagIndex returns the index of the first occurrence of tag in text,
+// or the index of the longest suffix of text that is a prefix of tag. It
+// returns -1 if neither is found.
+func potentialTagIndex(text, tag string) int {
+	if i := strings.Index(text, tag); i >= 0 {
+		return i
+	}
+	for i := max(0, len(text)-len(tag)+1); i < len(text); i++ {
+		if strings.HasPrefix(tag, text[i:]) {
+			return i
+		}
+	}
+	return -1
+}
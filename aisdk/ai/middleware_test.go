@@ -0,0 +1,349 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+	"go.jetify.com/ai/provider/mock"
+	"go.jetify.com/pkg/pointer"
+)
+
+func TestWrapLanguageModel_Order(t *testing.T) {
+	var calls []string
+	trace := func(name string) *Middleware {
+		return &Middleware{
+			TransformParams: func(ctx context.Context, prompt []api.Message, opts api.CallOptions) ([]api.Message, api.CallOptions, error) {
+				calls = append(calls, name+":params")
+				opts.StopSequences = append(opts.StopSequences, name)
+				return prompt, opts, nil
+			},
+			WrapGenerate: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+				calls = append(calls, name+":before")
+				resp, err := model.Generate(ctx, prompt, opts)
+				calls = append(calls, name+":after")
+				return resp, err
+			},
+		}
+	}
+
+	inner := &recordingModel{
+		GenerateModel: mock.NewGenerateModel([]mock.MockResult{{Response: textResponse("Hello", api.Usage{})}}),
+		mode:          api.ObjectGenerationModeTool,
+	}
+	model := WrapLanguageModel(inner, trace("first"), nil, trace("second"))
+
+	_, err := model.Generate(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"first:params", "first:before",
+		"second:params", "second:before",
+		"second:after", "first:after",
+	}, calls)
+	assert.Equal(t, []string{"first", "second"}, inner.calls[0].StopSequences)
+
+	// The wrapped model keeps the identity of the underlying model.
+	assert.Equal(t, inner.ProviderName(), model.ProviderName())
+	assert.Equal(t, inner.ModelID(), model.ModelID())
+	assert.Equal(t, api.ObjectGenerationModeTool, model.(api.ObjectGenerationModeProvider).DefaultObjectGenerationMode())
+}
+
+func TestWrapLanguageModel_WrapStream(t *testing.T) {
+	inner := &streamModel{
+		streams: [][]api.StreamEvent{
+			{&api.TextDeltaEvent{TextDelta: "hello"}, &api.FinishEvent{FinishReason: api.FinishReasonStop}},
+		},
+	}
+	upper := &Middleware{
+		WrapStream: func(ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions) (*api.StreamResponse, error) {
+			resp, err := model.Stream(ctx, prompt, opts)
+			if err != nil {
+				return nil, err
+			}
+			stream := resp.Stream
+			resp.Stream = func(yield func(api.StreamEvent) bool) {
+				for event := range stream {
+					if delta, ok := event.(*api.TextDeltaEvent); ok {
+						event = &api.TextDeltaEvent{TextDelta: delta.TextDelta + "!"}
+					}
+					if !yield(event) {
+						return
+					}
+				}
+			}
+			return resp, nil
+		},
+	}
+
+	resp, err := StreamTextStr(t.Context(), "Hi", WithModel(WrapLanguageModel(inner, upper)))
+	require.NoError(t, err)
+	result, err := builder.StreamToResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, []api.ContentBlock{&api.TextBlock{Text: "hello!"}}, result.Content)
+}
+
+func TestDefaultSettingsMiddleware(t *testing.T) {
+	defaults := api.CallOptions{
+		MaxOutputTokens:  1024,
+		Temperature:      pointer.Float64(0.2),
+		StopSequences:    []string{"STOP"},
+		Reasoning:        &api.ReasoningOptions{Effort: api.ReasoningEffortHigh},
+		IncludeRawChunks: true,
+		Headers:          http.Header{"X-Default": {"1"}, "X-Shared": {"default"}},
+		ProviderMetadata: api.NewProviderMetadata(map[string]any{"openai": "default", "anthropic": "default"}),
+	}
+	opts := api.CallOptions{
+		Temperature:      pointer.Float64(0.9),
+		Headers:          http.Header{"X-Shared": {"call"}},
+		ProviderMetadata: api.NewProviderMetadata(map[string]any{"openai": "call"}),
+	}
+
+	inner := &recordingModel{GenerateModel: mock.NewGenerateModel([]mock.MockResult{{Response: textResponse("Hello", api.Usage{})}})}
+	model := WrapLanguageModel(inner, DefaultSettingsMiddleware(defaults))
+
+	_, err := model.Generate(t.Context(), nil, opts)
+	require.NoError(t, err)
+
+	got := inner.calls[0]
+	assert.Equal(t, 1024, got.MaxOutputTokens)
+	assert.Equal(t, 0.9, *got.Temperature)
+	assert.Equal(t, []string{"STOP"}, got.StopSequences)
+	assert.Equal(t, &api.ReasoningOptions{Effort: api.ReasoningEffortHigh}, got.Reasoning)
+	assert.True(t, got.IncludeRawChunks)
+	assert.Equal(t, http.Header{"X-Default": {"1"}, "X-Shared": {"call"}}, got.Headers)
+	openai, _ := got.ProviderMetadata.Get("openai")
+	anthropic, _ := got.ProviderMetadata.Get("anthropic")
+	assert.Equal(t, "call", openai)
+	assert.Equal(t, "default", anthropic)
+
+	// The defaults are not modified.
+	assert.Equal(t, http.Header{"X-Default": {"1"}, "X-Shared": {"default"}}, defaults.Headers)
+}
+
+func TestSimulateStreamingMiddleware(t *testing.T) {
+	response := &api.Response{
+		Content: []api.ContentBlock{
+			&api.ReasoningBlock{Text: "Thinking", Signature: "sig"},
+			&api.TextBlock{Text: "Hello"},
+			&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_weather", Args: []byte(`{}`)},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+		Usage:        api.Usage{InputTokens: 3, OutputTokens: 4, TotalTokens: 7},
+		ResponseInfo: &api.ResponseInfo{ID: "resp_1"},
+		Warnings:     []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}},
+	}
+	inner := mock.NewGenerateModel([]mock.MockResult{{Response: response}})
+	model := WrapLanguageModel(inner, SimulateStreamingMiddleware())
+
+	resp, err := model.Stream(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+	assert.Equal(t, []api.StreamEvent{
+		&api.StreamStartEvent{Warnings: response.Warnings},
+		&api.ResponseMetadataEvent{ID: "resp_1"},
+		&api.ReasoningEvent{TextDelta: "Thinking"},
+		&api.ReasoningSignatureEvent{Signature: "sig"},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "get_weather", Args: []byte(`{}`)},
+		&api.FinishEvent{FinishReason: api.FinishReasonToolCalls, Usage: response.Usage},
+	}, events)
+	inner.AssertCount(t)
+}
+
+func TestExtractReasoningMiddleware_Generate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ExtractReasoningOptions
+		text    string
+		want    []api.ContentBlock
+	}{
+		{
+			name: "no reasoning",
+			text: "Hello",
+			want: []api.ContentBlock{&api.TextBlock{Text: "Hello"}},
+		},
+		{
+			name: "reasoning before text",
+			text: "<think>Let me think</think>Hello",
+			want: []api.ContentBlock{
+				&api.ReasoningBlock{Text: "Let me think"},
+				&api.TextBlock{Text: "Hello"},
+			},
+		},
+		{
+			name: "multiple reasoning segments",
+			text: "<think>one</think>Hello<think>two</think>World",
+			want: []api.ContentBlock{
+				&api.ReasoningBlock{Text: "one"},
+				&api.TextBlock{Text: "Hello"},
+				&api.ReasoningBlock{Text: "\ntwo"},
+				&api.TextBlock{Text: "\nWorld"},
+			},
+		},
+		{
+			name: "text before reasoning",
+			text: "Hello<think>Let me think</think>",
+			want: []api.ContentBlock{
+				&api.TextBlock{Text: "Hello"},
+				&api.ReasoningBlock{Text: "Let me think"},
+			},
+		},
+		{
+			name:    "custom tag and separator",
+			options: ExtractReasoningOptions{TagName: "reasoning", Separator: " "},
+			text:    "A<reasoning>x</reasoning>B",
+			want: []api.ContentBlock{
+				&api.TextBlock{Text: "A"},
+				&api.ReasoningBlock{Text: "x"},
+				&api.TextBlock{Text: " B"},
+			},
+		},
+		{
+			name:    "start with reasoning",
+			options: ExtractReasoningOptions{StartWithReasoning: true},
+			text:    "Let me think</think>Hello",
+			want: []api.ContentBlock{
+				&api.ReasoningBlock{Text: "Let me think"},
+				&api.TextBlock{Text: "Hello"},
+			},
+		},
+		{
+			name: "only reasoning",
+			text: "<think>Let me think</think>",
+			want: []api.ContentBlock{&api.ReasoningBlock{Text: "Let me think"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := mock.NewGenerateModel([]mock.MockResult{{Response: textResponse(tt.text, api.Usage{})}})
+			model := WrapLanguageModel(inner, ExtractReasoningMiddleware(tt.options))
+
+			resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Content)
+
+			// Streaming the same text builds the same response.
+			streamed := &streamModel{streams: [][]api.StreamEvent{{
+				&api.TextDeltaEvent{TextDelta: tt.text},
+				&api.FinishEvent{FinishReason: api.FinishReasonStop},
+			}}}
+			model = WrapLanguageModel(streamed, ExtractReasoningMiddleware(tt.options))
+			stream, err := model.Stream(t.Context(), nil, api.CallOptions{})
+			require.NoError(t, err)
+			built, err := builder.StreamToResponse(stream)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, built.Content)
+		})
+	}
+}
+
+func TestExtractReasoningMiddleware_GenerateMultipleBlocks(t *testing.T) {
+	inner := mock.NewGenerateModel([]mock.MockResult{{Response: &api.Response{
+		Content: []api.ContentBlock{
+			&api.TextBlock{Text: "Let me think</think>Hello"},
+			&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{}`)},
+			&api.TextBlock{Text: "Done"},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+	}}})
+	model := WrapLanguageModel(inner, ExtractReasoningMiddleware(ExtractReasoningOptions{StartWithReasoning: true}))
+
+	resp, err := model.Generate(t.Context(), nil, api.CallOptions{})
+	require.NoError(t, err)
+	// Only the first text block starts inside the reasoning tag.
+	assert.Equal(t, []api.ContentBlock{
+		&api.ReasoningBlock{Text: "Let me think"},
+		&api.TextBlock{Text: "Hello"},
+		&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "get_weather", Args: json.RawMessage(`{}`)},
+		&api.TextBlock{Text: "Done"},
+	}, resp.Content)
+}
+
+func TestExtractReasoningMiddleware_Stream(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ExtractReasoningOptions
+		deltas  []string
+		want    []api.StreamEvent
+	}{
+		{
+			name:   "tags split across deltas",
+			deltas: []string{"<th", "ink>Let me", " think</t", "hink>Hel", "lo"},
+			want: []api.StreamEvent{
+				&api.ReasoningEvent{TextDelta: "Let me"},
+				&api.ReasoningEvent{TextDelta: " think"},
+				&api.TextDeltaEvent{TextDelta: "Hel"},
+				&api.TextDeltaEvent{TextDelta: "lo"},
+			},
+		},
+		{
+			name:   "separator between segments",
+			deltas: []string{"<think>one</think>Hello<think>two</think>World"},
+			want: []api.StreamEvent{
+				&api.ReasoningEvent{TextDelta: "one"},
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.ReasoningEvent{TextDelta: "\ntwo"},
+				&api.TextDeltaEvent{TextDelta: "\nWorld"},
+			},
+		},
+		{
+			name:   "text before reasoning",
+			deltas: []string{"Hello<th", "ink>Hmm</think>"},
+			want: []api.StreamEvent{
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.ReasoningEvent{TextDelta: "Hmm"},
+			},
+		},
+		{
+			name:    "start with reasoning",
+			options: ExtractReasoningOptions{StartWithReasoning: true},
+			deltas:  []string{"Hmm", "</think>Hi"},
+			want: []api.StreamEvent{
+				&api.ReasoningEvent{TextDelta: "Hmm"},
+				&api.TextDeltaEvent{TextDelta: "Hi"},
+			},
+		},
+		{
+			name:   "partial tag at the end is flushed",
+			deltas: []string{"a <", "thi"},
+			want: []api.StreamEvent{
+				&api.TextDeltaEvent{TextDelta: "a "},
+				&api.TextDeltaEvent{TextDelta: "<thi"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []api.StreamEvent
+			for _, delta := range tt.deltas {
+				events = append(events, &api.TextDeltaEvent{TextDelta: delta})
+			}
+			finish := &api.FinishEvent{FinishReason: api.FinishReasonStop}
+			events = append(events, finish)
+
+			inner := &streamModel{streams: [][]api.StreamEvent{events}}
+			model := WrapLanguageModel(inner, ExtractReasoningMiddleware(tt.options))
+
+			resp, err := model.Stream(t.Context(), nil, api.CallOptions{})
+			require.NoError(t, err)
+
+			var got []api.StreamEvent
+			for event := range resp.Stream {
+				got = append(got, event)
+			}
+			assert.Equal(t, append(tt.want, finish), got)
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+)
+
+// defaultObjectName is the name used for the output schema (and the tool in
+// tool mode) when WithSchemaName is not set.
+const defaultObjectName = "json"
+
+// wrappedValueKey is the property used to wrap values whose schema is not an
+// object, since providers require the top-level schema to be an object.
+const wrappedValueKey = "value"
+
+// ObjectResponse is the result of a GenerateObject call.
+type ObjectResponse[T any] struct {
+	// Object is the generated object, decoded and validated against its schema.
+	Object T
+
+	// Response is the response generated by the model.
+	*api.Response
+}
+
+// GenerateObject uses a language model to generate a structured object of type T.
+//
+// The JSON schema sent to the model is inferred from T (see [jsonschema.For]).
+// Struct fields can be described using the `jsonschema` struct tag:
+//
+//	type Recipe struct {
+//		Name        string   `json:"name" jsonschema:"the name of the recipe"`
+//		Ingredients []string `json:"ingredients"`
+//	}
+//
+//	resp, err := GenerateObject[Recipe](ctx, messages, WithModel(model))
+//	fmt.Println(resp.Object.Name)
+//
+// Depending on the model, the object is requested either through the JSON
+// response format or by forcing a tool call. The mode can be set explicitly
+// with [WithObjectMode].
+//
+// If the model output is not valid JSON, a [api.JSONParseError] is returned. If
+// it doesn't match the schema, a [api.TypeValidationError] is returned.
+func GenerateObject[T any](ctx context.Context, prompt []api.Message, opts ...GenerateOption) (*ObjectResponse[T], error) {
+	config, err := buildGenerateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	output, err := newObjectOutput[T](config)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := generate(ctx, prompt, output.apply(config))
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := output.parse(resp.Response)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectResponse[T]{Object: object, Response: resp.Response}, nil
+}
+
+// GenerateObjectStr is a convenience wrapper around GenerateObject for simple
+// string-based prompts.
+func GenerateObjectStr[T any](ctx context.Context, prompt string, opts ...GenerateOption) (*ObjectResponse[T], error) {
+	msg := &api.UserMessage{
+		Content: []api.ContentBlock{&api.TextBlock{Text: prompt}},
+	}
+	return GenerateObject[T](ctx, []api.Message{msg}, opts...)
+}
+
+// ObjectStreamResponse is the result of a StreamObject call.
+//
+// The underlying stream can only be consumed once: either iterate over
+// PartialObjects, or call Result directly to wait for the final object. Once
+// the stream has been consumed, PartialObjects yields nothing.
+type ObjectStreamResponse[T any] struct {
+	stream *StreamTextResponse
+	output *objectOutput[T]
+
+	consumed bool
+	result   *ObjectResponse[T]
+	err      error
+}
+
+// StreamObject uses a language model to generate a structured object of type
+// T, streaming partial objects as the JSON arrives.
+//
+//	stream, err := StreamObject[Recipe](ctx, messages, WithModel(model))
+//	for partial := range stream.PartialObjects() {
+//		fmt.Println(partial.Name)
+//	}
+//	resp, err := stream.Result()
+//
+// See [GenerateObject] for details on how the schema is inferred and how the
+// object is requested from the model.
+func StreamObject[T any](ctx context.Context, prompt []api.Message, opts ...GenerateOption) (*ObjectStreamResponse[T], error) {
+	config, err := buildGenerateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	output, err := newObjectOutput[T](config)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream(ctx, prompt, output.apply(config))
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectStreamResponse[T]{stream: resp, output: output}, nil
+}
+
+// StreamObjectStr is a convenience wrapper around StreamObject for simple
+// string-based prompts.
+func StreamObjectStr[T any](ctx context.Context, prompt string, opts ...GenerateOption) (*ObjectStreamResponse[T], error) {
+	msg := &api.UserMessage{
+		Content: []api.ContentBlock{&api.TextBlock{Text: prompt}},
+	}
+	return StreamObject[T](ctx, []api.Message{msg}, opts...)
+}
+
+// PartialObjects returns an iterator over the partial objects decoded from the
+// model output as it arrives. Partial objects are not validated against the
+// schema, so fields might be missing or incomplete.
+//
+// Once the iteration is done, the final object is available through Result.
+func (s *ObjectStreamResponse[T]) PartialObjects() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.consume(yield)
+	}
+}
+
+// Result returns the final object once the stream is done. If the stream has
+// not been consumed yet, Result consumes it.
+func (s *ObjectStreamResponse[T]) Result() (*ObjectResponse[T], error) {
+	if !s.consumed {
+		s.consume(nil)
+	}
+	return s.result, s.err
+}
+
+// consume reads the underlying stream, calling yield with every new partial
+// object, and stores the final result.
+func (s *ObjectStreamResponse[T]) consume(yield func(T) bool) {
+	if s.consumed {
+		return
+	}
+	s.consumed = true
+
+	b := builder.NewResponseBuilder()
+	_ = b.AddMetadata(s.stream.StreamResponse)
+
+	var text strings.Builder
+	lastPartial := ""
+	for event := range s.stream.Stream {
+		if err := b.AddEvent(event); err != nil {
+			s.err = err
+			return
+		}
+
+		switch e := event.(type) {
+		case *api.TextDeltaEvent:
+			text.WriteString(e.TextDelta)
+		case *api.ToolCallDeltaEvent:
+			text.Write(e.ArgsDelta)
+		case *api.ToolCallEvent:
+			text.Reset()
+			text.Write(e.Args)
+		default:
+			continue
+		}
+
+		if yield == nil {
+			continue
+		}
+		partialJSON, ok := completePartialJSON(text.String())
+		if !ok || partialJSON == lastPartial {
+			continue
+		}
+		partial, err := s.output.decode([]byte(partialJSON))
+		if err != nil {
+			continue
+		}
+		lastPartial = partialJSON
+		if !yield(partial) {
+			s.err = errors.New("object stream was not fully consumed")
+			return
+		}
+	}
+
+	resp, err := b.Build()
+	if err != nil {
+		s.err = err
+		return
+	}
+	object, err := s.output.parse(resp)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.result = &ObjectResponse[T]{Object: object, Response: resp}
+}
+
+// objectOutput describes how an object of type T is requested from the model
+// and decoded from its response.
+type objectOutput[T any] struct {
+	mode        api.ObjectGenerationMode
+	name        string
+	description string
+	schema      *jsonschema.Schema
+	resolved    *jsonschema.Resolved
+	wrapped     bool
+}
+
+func newObjectOutput[T any](config GenerateOptions) (*objectOutput[T], error) {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return nil, api.NewInvalidArgumentError("cannot infer a JSON schema for the object type", "T", err)
+	}
+
+	// Providers require the top-level schema to be an object, so other
+	// types are wrapped in an object with a single property.
+	wrapped := schema.Type != "object"
+	if wrapped {
+		schema = &jsonschema.Schema{
+			Type:                 "object",
+			Properties:           map[string]*jsonschema.Schema{wrappedValueKey: schema},
+			Required:             []string{wrappedValueKey},
+			AdditionalProperties: api.FalseSchema(),
+		}
+	}
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, api.NewInvalidArgumentError("invalid JSON schema for the object type", "T", err)
+	}
+
+	name := config.SchemaName
+	if name == "" {
+		name = defaultObjectName
+	}
+
+	return &objectOutput[T]{
+		mode:        objectGenerationMode(config),
+		name:        name,
+		description: config.SchemaDescription,
+		schema:      schema,
+		resolved:    resolved,
+		wrapped:     wrapped,
+	}, nil
+}
+
+// objectGenerationMode returns the mode explicitly requested by the user, or
+// the one preferred by the model.
+func objectGenerationMode(config GenerateOptions) api.ObjectGenerationMode {
+	if config.ObjectMode != api.ObjectGenerationModeNone {
+		return config.ObjectMode
+	}
+	if provider, ok := config.Model.(api.ObjectGenerationModeProvider); ok {
+		if mode := provider.DefaultObjectGenerationMode(); mode != api.ObjectGenerationModeNone {
+			return mode
+		}
+	}
+	return api.ObjectGenerationModeJSON
+}
+
+// apply returns a copy of the config that requests the object from the model.
+func (o *objectOutput[T]) apply(config GenerateOptions) GenerateOptions {
+	switch o.mode {
+	case api.ObjectGenerationModeTool:
+		config.CallOptions.Tools = []api.ToolDefinition{
+			&api.FunctionTool{
+				Name:        o.name,
+				Description: o.description,
+				InputSchema: o.schema,
+			},
+		}
+		config.CallOptions.ToolChoice = &api.ToolChoice{Type: "tool", ToolName: o.name}
+	default:
+		config.CallOptions.ResponseFormat = &api.ResponseFormat{
+			Type:        "json",
+			Schema:      o.schema,
+			Name:        o.name,
+			Description: o.description,
+		}
+	}
+	// The object is the final output, so we don't run a tool loop.
+	config.ExecutableTools = nil
+	config.MaxSteps = 1
+	// parse validates the object, reporting JSON and schema errors as
+	// JSONParseError and TypeValidationError.
+	config.SkipToolCallValidation = true
+	return config
+}
+
+// parse extracts the object from the model response, validating it against
+// the schema.
+func (o *objectOutput[T]) parse(resp *api.Response) (T, error) {
+	var zero T
+	text, ok := o.outputText(resp)
+	if !ok {
+		return zero, api.NewNoContentGeneratedError("No object generated: the model did not return any output.")
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return zero, api.NewJSONParseError(text, err)
+	}
+	if err := o.resolved.Validate(value); err != nil {
+		return zero, api.NewTypeValidationError(value, err)
+	}
+
+	object, err := o.decode([]byte(text))
+	if err != nil {
+		return zero, api.NewTypeValidationError(value, err)
+	}
+	return object, nil
+}
+
+// outputText returns the raw JSON generated by the model.
+func (o *objectOutput[T]) outputText(resp *api.Response) (string, bool) {
+	if o.mode == api.ObjectGenerationModeTool {
+		for _, call := range toolCalls(resp.Content) {
+			if call.ToolName == o.name {
+				return string(call.Args), true
+			}
+		}
+		return "", false
+	}
+
+	var text strings.Builder
+	found := false
+	for _, block := range resp.Content {
+		if textBlock, ok := block.(*api.TextBlock); ok {
+			text.WriteString(textBlock.Text)
+			found = true
+		}
+	}
+	return text.String(), found
+}
+
+// decode unmarshals the JSON into a value of type T, unwrapping it if needed.
+func (o *objectOutput[T]) decode(data []byte) (T, error) {
+	var object T
+	if !o.wrapped {
+		err := json.Unmarshal(data, &object)
+		return object, err
+	}
+
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return object, err
+	}
+	value, ok := wrapper[wrappedValueKey]
+	if !ok {
+		return object, errors.New("missing wrapped value")
+	}
+	err := json.Unmarshal(value, &object)
+	return object, err
+}
@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+type recipe struct {
+	Name        string   `json:"name"`
+	Ingredients []string `json:"ingredients"`
+}
+
+func TestGenerateObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     api.ObjectGenerationMode
+		response *api.Response
+		opts     []GenerateOption
+		want     recipe
+		wantErr  error
+	}{
+		{
+			name:     "json mode",
+			mode:     api.ObjectGenerationModeJSON,
+			response: textResponse(`{"name":"Pancakes","ingredients":["flour","milk"]}`, api.Usage{}),
+			want:     recipe{Name: "Pancakes", Ingredients: []string{"flour", "milk"}},
+		},
+		{
+			name:     "tool mode",
+			mode:     api.ObjectGenerationModeTool,
+			response: objectToolResponse(`{"name":"Pancakes","ingredients":["flour"]}`),
+			want:     recipe{Name: "Pancakes", Ingredients: []string{"flour"}},
+		},
+		{
+			name:     "explicit mode overrides the model",
+			mode:     api.ObjectGenerationModeTool,
+			opts:     []GenerateOption{WithObjectMode(api.ObjectGenerationModeJSON)},
+			response: textResponse(`{"name":"Pancakes","ingredients":[]}`, api.Usage{}),
+			want:     recipe{Name: "Pancakes", Ingredients: []string{}},
+		},
+		{
+			name:     "invalid json",
+			mode:     api.ObjectGenerationModeJSON,
+			response: textResponse(`{"name":`, api.Usage{}),
+			wantErr:  &api.JSONParseError{},
+		},
+		{
+			name:     "schema mismatch",
+			mode:     api.ObjectGenerationModeJSON,
+			response: textResponse(`{"name":42}`, api.Usage{}),
+			wantErr:  &api.TypeValidationError{},
+		},
+		{
+			name:     "tool mode invalid json",
+			mode:     api.ObjectGenerationModeTool,
+			response: objectToolResponse(`{"name":`),
+			wantErr:  &api.JSONParseError{},
+		},
+		{
+			name:     "tool mode schema mismatch",
+			mode:     api.ObjectGenerationModeTool,
+			response: objectToolResponse(`{"name":42}`),
+			wantErr:  &api.TypeValidationError{},
+		},
+		{
+			name:     "no output",
+			mode:     api.ObjectGenerationModeTool,
+			response: textResponse("I can't do that.", api.Usage{}),
+			wantErr:  &api.NoContentGeneratedError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &recordingModel{
+				GenerateModel: mock.NewGenerateModel([]mock.MockResult{{Response: tt.response}}),
+				mode:          tt.mode,
+			}
+
+			opts := append([]GenerateOption{WithModel(model)}, tt.opts...)
+			resp, err := GenerateObjectStr[recipe](t.Context(), "Give me a recipe", opts...)
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.IsType(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Object)
+		})
+	}
+}
+
+// objectToolResponse returns a response with a call to the tool used to
+// generate objects in tool mode.
+func objectToolResponse(args string) *api.Response {
+	return &api.Response{
+		Content: []api.ContentBlock{
+			&api.ToolCallBlock{
+				ToolCallID: "call_1",
+				ToolName:   "json",
+				Args:       json.RawMessage(args),
+			},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+	}
+}
+
+func TestGenerateObject_CallOptions(t *testing.T) {
+	t.Run("json mode", func(t *testing.T) {
+		model := &recordingModel{
+			GenerateModel: mock.NewGenerateModel([]mock.MockResult{
+				{Response: textResponse(`{"name":"Pancakes","ingredients":[]}`, api.Usage{})},
+			}),
+			mode: api.ObjectGenerationModeJSON,
+		}
+
+		_, err := GenerateObjectStr[recipe](t.Context(), "Give me a recipe",
+			WithModel(model), WithSchemaName("recipe"), WithSchemaDescription("A cooking recipe"))
+		require.NoError(t, err)
+
+		require.Len(t, model.calls, 1)
+		format := model.calls[0].ResponseFormat
+		require.NotNil(t, format)
+		assert.Equal(t, "json", format.Type)
+		assert.Equal(t, "recipe", format.Name)
+		assert.Equal(t, "A cooking recipe", format.Description)
+		assert.Equal(t, "object", format.Schema.Type)
+		assert.ElementsMatch(t, []string{"name", "ingredients"}, format.Schema.Required)
+		assert.Empty(t, model.calls[0].Tools)
+	})
+
+	t.Run("tool mode", func(t *testing.T) {
+		model := &recordingModel{
+			GenerateModel: mock.NewGenerateModel([]mock.MockResult{
+				{Response: &api.Response{Content: []api.ContentBlock{
+					&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "json", Args: json.RawMessage(`{"name":"Pancakes","ingredients":[]}`)},
+				}}},
+			}),
+			mode: api.ObjectGenerationModeTool,
+		}
+
+		_, err := GenerateObjectStr[recipe](t.Context(), "Give me a recipe",
+			WithModel(model), WithExecutableTools(weatherTool))
+		require.NoError(t, err)
+
+		require.Len(t, model.calls, 1)
+		opts := model.calls[0]
+		assert.Nil(t, opts.ResponseFormat)
+		require.Len(t, opts.Tools, 1)
+		assert.Equal(t, "json", opts.Tools[0].(*api.FunctionTool).Name)
+		assert.Equal(t, &api.ToolChoice{Type: "tool", ToolName: "json"}, opts.ToolChoice)
+	})
+}
+
+func TestGenerateObject_NonObjectType(t *testing.T) {
+	model := &recordingModel{
+		GenerateModel: mock.NewGenerateModel([]mock.MockResult{
+			{Response: textResponse(`{"value":["red","green"]}`, api.Usage{})},
+		}),
+		mode: api.ObjectGenerationModeJSON,
+	}
+
+	resp, err := GenerateObjectStr[[]string](t.Context(), "List two colors", WithModel(model))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"red", "green"}, resp.Object)
+
+	schema := model.calls[0].ResponseFormat.Schema
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "array", schema.Properties["value"].Type)
+}
+
+func TestStreamObject(t *testing.T) {
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{
+				&api.TextDeltaEvent{TextDelta: `{"name":"Pan`},
+				&api.TextDeltaEvent{TextDelta: `cakes","ingr`},
+				&api.TextDeltaEvent{TextDelta: `edients":["flour",`},
+				&api.TextDeltaEvent{TextDelta: `"milk"]}`},
+				&api.FinishEvent{FinishReason: api.FinishReasonStop},
+			},
+		},
+	}
+
+	stream, err := StreamObjectStr[recipe](t.Context(), "Give me a recipe", WithModel(model))
+	require.NoError(t, err)
+
+	var partials []recipe
+	for partial := range stream.PartialObjects() {
+		partials = append(partials, partial)
+	}
+	assert.Equal(t, []recipe{
+		{Name: "Pan"},
+		{Name: "Pancakes"},
+		{Name: "Pancakes", Ingredients: []string{"flour"}},
+		{Name: "Pancakes", Ingredients: []string{"flour", "milk"}},
+	}, partials)
+
+	resp, err := stream.Result()
+	require.NoError(t, err)
+	assert.Equal(t, recipe{Name: "Pancakes", Ingredients: []string{"flour", "milk"}}, resp.Object)
+	assert.Equal(t, api.FinishReasonStop, resp.FinishReason)
+}
+
+func TestStreamObject_ToolMode(t *testing.T) {
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "json", ArgsDelta: []byte(`{"name":"Pan`)},
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "json", ArgsDelta: []byte(`cakes","ingredients":[]}`)},
+				&api.FinishEvent{FinishReason: api.FinishReasonToolCalls},
+			},
+		},
+	}
+
+	stream, err := StreamObjectStr[recipe](t.Context(), "Give me a recipe",
+		WithModel(model), WithObjectMode(api.ObjectGenerationModeTool))
+	require.NoError(t, err)
+
+	resp, err := stream.Result()
+	require.NoError(t, err)
+	assert.Equal(t, recipe{Name: "Pancakes", Ingredients: []string{}}, resp.Object)
+
+	_, err = stream.Result()
+	require.NoError(t, err)
+	assert.Empty(t, collect(stream.PartialObjects()))
+}
+
+func TestStreamObject_InvalidResult(t *testing.T) {
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{
+				&api.TextDeltaEvent{TextDelta: `{"name":"Pancakes"}`},
+				&api.FinishEvent{FinishReason: api.FinishReasonStop},
+			},
+		},
+	}
+
+	stream, err := StreamObjectStr[recipe](t.Context(), "Give me a recipe", WithModel(model))
+	require.NoError(t, err)
+
+	assert.Len(t, collect(stream.PartialObjects()), 1)
+	_, err = stream.Result()
+	var validationErr *api.TypeValidationError
+	require.ErrorAs(t, err, &validationErr)
+}
+
+func collect[T any](seq func(yield func(T) bool)) []T {
+	var values []T
+	for v := range seq {
+		values = append(values, v)
+	}
+	return values
+}
+
+// recordingModel is a mock model that prefers the given object generation mode
+// and records the call options it receives.
+type recordingModel struct {
+	*mock.GenerateModel
+	mode  api.ObjectGenerationMode
+	calls []api.CallOptions
+}
+
+func (m *recordingModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	return m.mode
+}
+
+func (m *recordingModel) Generate(ctx context.Context, prompt []api.Message, opts api.CallOptions) (*api.Response, error) {
+	m.calls = append(m.calls, opts)
+	return m.GenerateModel.Generate(ctx, prompt, opts)
+}
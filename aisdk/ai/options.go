@@ -9,6 +9,56 @@ import (
 type GenerateOptions struct {
 	CallOptions api.CallOptions
 	Model       api.LanguageModel
+
+	// ModelID is the ID of the model to use, resolved with the default
+	// registry, e.g. "openai:gpt-5". If set, it takes precedence over Model.
+	ModelID string
+
+	// ExecutableTools are tools that the SDK executes automatically when the
+	// model calls them.
+	ExecutableTools []*Tool
+
+	// MaxSteps is the maximum number of model calls in a multi-step generation.
+	MaxSteps int
+
+	// StopConditions decide when to stop a multi-step generation early.
+	StopConditions []StopCondition
+
+	// OnStepFinish is called after each step of the generation.
+	OnStepFinish StepCallback
+
+	// RepairToolCall fixes the tool calls generated by the model that don't
+	// match the available tools. See WithToolCallRepair for how the calls
+	// that are still invalid are handled.
+	RepairToolCall ToolCallRepairFunc
+
+	// SkipToolCallValidation disables the validation of the tool calls
+	// generated by the model.
+	SkipToolCallValidation bool
+
+	// ObjectMode is how GenerateObject and StreamObject request the object
+	// from the model. If empty, the model's preferred mode is used.
+	ObjectMode api.ObjectGenerationMode
+
+	// SchemaName is the name of the object schema used by GenerateObject and
+	// StreamObject.
+	SchemaName string
+
+	// SchemaDescription is the description of the object schema used by
+	// GenerateObject and StreamObject.
+	SchemaDescription string
+
+	// MaxRetries is the maximum number of times a model call is retried when
+	// it fails with a retryable error. Defaults to 2.
+	MaxRetries int
+
+	// Backoff decides how long to wait between retries. If nil, an
+	// ExponentialBackoff with default settings is used.
+	Backoff BackoffPolicy
+
+	// Download configures how media URLs in the prompt that the model doesn't
+	// support are downloaded before calling the model.
+	Download DownloadOptions
 }
 
 // GenerateOption is a function that modifies GenerateConfig.
@@ -18,6 +68,16 @@ type GenerateOption func(*GenerateOptions)
 func WithModel(model api.LanguageModel) GenerateOption {
 	return func(o *GenerateOptions) {
 		o.Model = model
+		o.ModelID = ""
+	}
+}
+
+// WithModelID sets the language model to use for generation by its ID in the
+// default registry, e.g. "anthropic:claude-sonnet-4-0" or an alias. If the
+// model can't be resolved, the call fails with an [api.NoSuchModelError].
+func WithModelID(modelID string) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.ModelID = modelID
 	}
 }
 
@@ -85,6 +145,27 @@ func WithResponseFormat(format *api.ResponseFormat) GenerateOption {
 	}
 }
 
+// WithReasoning configures the reasoning (also called thinking) of models that
+// support it, independently of the provider:
+//
+//	WithReasoning(api.ReasoningOptions{Effort: api.ReasoningEffortHigh, IncludeSummary: true})
+//	WithReasoning(api.ReasoningOptions{BudgetTokens: 4096})
+//
+// Each provider maps the options to its native settings, e.g. a reasoning
+// effort for OpenAI or a thinking budget for Anthropic. Models that don't
+// support reasoning ignore the options and return an "unsupported-setting"
+// warning. Provider-specific reasoning settings in the provider metadata take
+// precedence.
+//
+// Not every provider reports the reasoning tokens in Usage.ReasoningTokens.
+// Anthropic doesn't count them separately: they are only included in
+// Usage.OutputTokens, and Usage.ReasoningTokens is zero.
+func WithReasoning(options api.ReasoningOptions) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.CallOptions.Reasoning = &options
+	}
+}
+
 // WithSeed provides an integer seed for random sampling.
 // If supported by the model, calls will generate deterministic results.
 func WithSeed(seed int) GenerateOption {
@@ -115,6 +196,95 @@ func WithToolChoice(toolChoice *api.ToolChoice) GenerateOption {
 	}
 }
 
+// WithExecutableTools specifies tools that the SDK executes automatically
+// whenever the model calls them. The tool results are sent back to the model,
+// which is called again until it stops calling tools or a stop condition is met.
+//
+// The tool definitions are sent to the model in addition to any tools set with
+// WithTools. Unless WithMaxSteps is used, at most 10 steps are run.
+func WithExecutableTools(tools ...*Tool) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.ExecutableTools = append(o.ExecutableTools, tools...)
+	}
+}
+
+// WithMaxSteps sets the maximum number of model calls in a multi-step generation.
+// A value of 1 disables the loop: tools are still executed but their results
+// are not sent back to the model.
+func WithMaxSteps(maxSteps int) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.MaxSteps = maxSteps
+	}
+}
+
+// WithStopWhen adds conditions that stop a multi-step generation early.
+// The generation stops as soon as any of the conditions is met.
+func WithStopWhen(conditions ...StopCondition) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.StopConditions = append(o.StopConditions, conditions...)
+	}
+}
+
+// WithOnStepFinish sets a callback that is called after each step of the
+// generation, once the tools requested in that step have been executed.
+// The callback can return ErrStopSteps to stop the loop.
+func WithOnStepFinish(callback StepCallback) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.OnStepFinish = callback
+	}
+}
+
+// WithObjectMode sets how GenerateObject and StreamObject request the object
+// from the model: using the JSON response format, or by forcing a tool call.
+// By default, the mode preferred by the model is used.
+func WithObjectMode(mode api.ObjectGenerationMode) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.ObjectMode = mode
+	}
+}
+
+// WithSchemaName sets the name of the object schema used by GenerateObject and
+// StreamObject. Some providers use it as additional guidance for the model.
+func WithSchemaName(name string) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.SchemaName = name
+	}
+}
+
+// WithSchemaDescription sets the description of the object schema used by
+// GenerateObject and StreamObject. Some providers use it as additional
+// guidance for the model.
+func WithSchemaDescription(description string) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.SchemaDescription = description
+	}
+}
+
+// WithMaxRetries sets the maximum number of times a model call is retried when
+// it fails with a retryable [api.APICallError] (e.g. rate limits or server
+// errors). Set it to 0 to disable retries. Defaults to 2.
+//
+// For StreamText, only failures that occur before the first event of a
+// stream are retried.
+//
+// The default clients of the OpenAI and Anthropic providers don't retry on
+// their own. Clients passed to the providers explicitly should disable their
+// retries, otherwise every attempt is retried again by the client.
+func WithMaxRetries(maxRetries int) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.MaxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the policy that decides how long to wait between retries.
+// Delays requested by the provider through the Retry-After or retry-after-ms
+// response headers take precedence over the policy.
+func WithBackoff(backoff BackoffPolicy) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.Backoff = backoff
+	}
+}
+
 // WithProviderMetadata sets additional provider-specific metadata.
 // The metadata is passed through to the provider from the AI SDK and enables
 // provider-specific functionality that can be fully encapsulated in the provider.
@@ -135,16 +305,26 @@ func WithCallOptions(callOptions api.CallOptions) GenerateOption {
 	}
 }
 
-// buildGenerateConfig combines multiple generate options into a single GenerateConfig struct.
-func buildGenerateConfig(opts []GenerateOption) GenerateOptions {
+// buildGenerateConfig combines multiple generate options into a single
+// GenerateConfig struct, and resolves the model ID if one is set.
+func buildGenerateConfig(opts []GenerateOption) (GenerateOptions, error) {
 	config := GenerateOptions{
 		CallOptions: api.CallOptions{
 			ProviderMetadata: api.NewProviderMetadata(map[string]any{}),
 		},
-		Model: DefaultLanguageModel(),
+		Model:      DefaultLanguageModel(),
+		MaxRetries: defaultMaxRetries,
 	}
 	for _, opt := range opts {
 		opt(&config)
 	}
-	return config
+
+	if config.ModelID != "" {
+		model, err := DefaultRegistry().LanguageModel(config.ModelID)
+		if err != nil {
+			return GenerateOptions{}, err
+		}
+		config.Model = model
+	}
+	return config, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/stretchr/testify/assert"
@@ -92,6 +93,15 @@ func TestCallOptionBuilders(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "WithReasoning",
+			option: WithReasoning(api.ReasoningOptions{Effort: api.ReasoningEffortHigh, IncludeSummary: true}),
+			expected: GenerateOptions{
+				CallOptions: api.CallOptions{
+					Reasoning: &api.ReasoningOptions{Effort: api.ReasoningEffortHigh, IncludeSummary: true},
+				},
+			},
+		},
 		{
 			name:   "WithSeed",
 			option: WithSeed(42),
@@ -115,6 +125,16 @@ func TestCallOptionBuilders(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "WithMaxRetries",
+			option:   WithMaxRetries(5),
+			expected: GenerateOptions{MaxRetries: 5},
+		},
+		{
+			name:     "WithBackoff",
+			option:   WithBackoff(ExponentialBackoff{InitialDelay: time.Second}),
+			expected: GenerateOptions{Backoff: ExponentialBackoff{InitialDelay: time.Second}},
+		},
 		{
 			name: "WithProviderMetadata_SingleProvider",
 			option: WithProviderMetadata("test-provider", map[string]any{
@@ -201,7 +221,8 @@ func TestBuildCallOptions(t *testing.T) {
 				CallOptions: api.CallOptions{
 					ProviderMetadata: api.NewProviderMetadata(map[string]any{}),
 				},
-				Model: DefaultLanguageModel(),
+				Model:      DefaultLanguageModel(),
+				MaxRetries: defaultMaxRetries,
 			},
 		},
 		{
@@ -216,7 +237,8 @@ func TestBuildCallOptions(t *testing.T) {
 					Temperature:      pointer.Float64(0.7),
 					ProviderMetadata: api.NewProviderMetadata(map[string]any{}),
 				},
-				Model: DefaultLanguageModel(),
+				Model:      DefaultLanguageModel(),
+				MaxRetries: defaultMaxRetries,
 			},
 		},
 		{
@@ -229,14 +251,16 @@ func TestBuildCallOptions(t *testing.T) {
 					Tools:            []api.ToolDefinition{&api.FunctionTool{Name: "test-tool"}},
 					ProviderMetadata: api.NewProviderMetadata(map[string]any{}),
 				},
-				Model: DefaultLanguageModel(),
+				Model:      DefaultLanguageModel(),
+				MaxRetries: defaultMaxRetries,
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			opts := buildGenerateConfig(tt.opts)
+			opts, err := buildGenerateConfig(tt.opts)
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, opts)
 		})
 	}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// runParallel calls fn for every input, running at most maxParallel calls at
+// once, and returns the results in the same order as the inputs. It stops at
+// the first error, canceling the calls in progress.
+func runParallel[In, Out any](
+	ctx context.Context, inputs []In, maxParallel int, fn func(ctx context.Context, input In) (Out, error),
+) ([]Out, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		results   = make([]Out, len(inputs))
+		semaphore = make(chan struct{}, max(maxParallel, 1))
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for i, input := range inputs {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			fail(ctx.Err())
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result, err := fn(ctx, input)
+			if err != nil {
+				fail(err)
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
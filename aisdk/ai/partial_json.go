@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Parser states for objects and arrays in completePartialJSON.
+const (
+	expectKeyOrEnd = iota
+	expectKey
+	expectColon
+	expectValue
+	expectValueOrEnd
+	afterValue
+)
+
+// jsonFrame is an open object or array while scanning partial JSON.
+type jsonFrame struct {
+	kind  byte // '{' or '['
+	state int
+}
+
+// completePartialJSON turns a prefix of a JSON document into a valid JSON
+// document. Incomplete trailing tokens (e.g. a partial key or literal) are
+// dropped, and any open strings, arrays and objects are closed.
+//
+// It returns false if the prefix does not contain enough data to produce a
+// valid document, or if it is not valid JSON to begin with.
+func completePartialJSON(text string) (string, bool) {
+	var stack []jsonFrame
+	best := ""
+	found := false
+
+	// snapshot records a valid completion of text[:end].
+	snapshot := func(end int, extra string) {
+		var sb strings.Builder
+		sb.WriteString(text[:end])
+		sb.WriteString(extra)
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].kind == '{' {
+				sb.WriteByte('}')
+			} else {
+				sb.WriteByte(']')
+			}
+		}
+		best = sb.String()
+		found = true
+	}
+
+	// valueDone updates the state after a complete value. It returns true
+	// when the top-level value is complete.
+	valueDone := func() bool {
+		if len(stack) == 0 {
+			return true
+		}
+		stack[len(stack)-1].state = afterValue
+		return false
+	}
+
+	expectingValue := func() bool {
+		if len(stack) == 0 {
+			return true
+		}
+		state := stack[len(stack)-1].state
+		return state == expectValue || state == expectValueOrEnd
+	}
+
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			i++
+			continue
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = &stack[len(stack)-1]
+		}
+
+		switch {
+		case c == '{' || c == '[':
+			if !expectingValue() {
+				return best, found
+			}
+			frame := jsonFrame{kind: c, state: expectKeyOrEnd}
+			if c == '[' {
+				frame.state = expectValueOrEnd
+			}
+			stack = append(stack, frame)
+			i++
+			snapshot(i, "")
+
+		case c == '}' || c == ']':
+			if top == nil {
+				return best, found
+			}
+			if c == '}' && (top.kind != '{' || (top.state != expectKeyOrEnd && top.state != afterValue)) {
+				return best, found
+			}
+			if c == ']' && (top.kind != '[' || (top.state != expectValueOrEnd && top.state != afterValue)) {
+				return best, found
+			}
+			stack = stack[:len(stack)-1]
+			i++
+			done := valueDone()
+			snapshot(i, "")
+			if done {
+				return best, found
+			}
+
+		case c == ',':
+			if top == nil || top.state != afterValue {
+				return best, found
+			}
+			if top.kind == '{' {
+				top.state = expectKey
+			} else {
+				top.state = expectValue
+			}
+			i++
+
+		case c == ':':
+			if top == nil || top.state != expectColon {
+				return best, found
+			}
+			top.state = expectValue
+			i++
+
+		case c == '"':
+			isKey := top != nil && (top.state == expectKeyOrEnd || top.state == expectKey)
+			if !isKey && !expectingValue() {
+				return best, found
+			}
+			end, complete := scanString(text, i)
+			if !complete {
+				// Partial keys are dropped, partial values are closed.
+				if !isKey {
+					snapshot(end, `"`)
+				}
+				return best, found
+			}
+			i = end
+			if isKey {
+				top.state = expectColon
+				continue
+			}
+			done := valueDone()
+			snapshot(i, "")
+			if done {
+				return best, found
+			}
+
+		default:
+			if !expectingValue() {
+				return best, found
+			}
+			start := i
+			for i < len(text) && strings.IndexByte(" \t\n\r,]}:", text[i]) < 0 {
+				i++
+			}
+			token := text[start:i]
+			isNumber := c == '-' || (c >= '0' && c <= '9')
+			if i == len(text) && isNumber {
+				// The token might be incomplete. Numbers can be trimmed to
+				// their longest valid prefix, literals can't.
+				token = strings.TrimRight(token, ".eE+-")
+			}
+			if token == "" || !json.Valid([]byte(token)) {
+				return best, found
+			}
+			done := valueDone()
+			snapshot(start+len(token), "")
+			if done {
+				return best, found
+			}
+		}
+	}
+	return best, found
+}
+
+// scanString scans the JSON string starting at the opening quote in
+// text[start]. It returns the index just after the closing quote and true if
+// the string is complete. Otherwise it returns the end of the longest prefix
+// of the string that doesn't end in an incomplete escape sequence.
+func scanString(text string, start int) (int, bool) {
+	i := start + 1
+	for i < len(text) {
+		switch text[i] {
+		case '"':
+			return i + 1, true
+		case '\\':
+			if i+1 >= len(text) {
+				return i, false
+			}
+			if text[i+1] == 'u' {
+				if i+6 > len(text) {
+					return i, false
+				}
+				i += 6
+			} else {
+				i += 2
+			}
+		default:
+			i++
+		}
+	}
+	return len(text), false
+}
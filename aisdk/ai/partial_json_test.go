@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletePartialJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{input: "", wantOK: false},
+		{input: "  ", wantOK: false},
+		{input: "{", want: "{}", wantOK: true},
+		{input: `{"na`, want: "{}", wantOK: true},
+		{input: `{"name"`, want: "{}", wantOK: true},
+		{input: `{"name":`, want: "{}", wantOK: true},
+		{input: `{"name": "Pan`, want: `{"name": "Pan"}`, wantOK: true},
+		{input: `{"name": "Pan\`, want: `{"name": "Pan"}`, wantOK: true},
+		{input: `{"name": "Pan\u00`, want: `{"name": "Pan"}`, wantOK: true},
+		{input: `{"name": "Pancakes", "count": 1`, want: `{"name": "Pancakes", "count": 1}`, wantOK: true},
+		{input: `{"count": 1.`, want: `{"count": 1}`, wantOK: true},
+		{input: `{"count": -`, want: `{}`, wantOK: true},
+		{input: `{"ok": tr`, want: `{}`, wantOK: true},
+		{input: `{"ok": true`, want: `{"ok": true}`, wantOK: true},
+		{input: `{"items": ["a", "b`, want: `{"items": ["a", "b"]}`, wantOK: true},
+		{input: `{"items": [{"x": 1}, {`, want: `{"items": [{"x": 1}, {}]}`, wantOK: true},
+		{input: `{"a": 1,`, want: `{"a": 1}`, wantOK: true},
+		{input: `[1, 2`, want: `[1, 2]`, wantOK: true},
+		{input: `"hel`, want: `"hel"`, wantOK: true},
+		{input: `{"a": 1} trailing`, want: `{"a": 1}`, wantOK: true},
+		{input: `}`, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := completePartialJSON(tt.input)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package codec
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"go.jetify.com/ai/api"
@@ -56,6 +57,7 @@ func decodeContent(blocks []anthropic.BetaContentBlockUnion) []api.ContentBlock
 		return content
 	}
 
+	citationCounter := 0
 	for _, block := range blocks {
 		switch block.Type {
 		case "text":
@@ -65,6 +67,9 @@ func decodeContent(blocks []anthropic.BetaContentBlockUnion) []api.ContentBlock
 					Text: block.Text,
 				})
 			}
+			for _, source := range decodeCitations(block.Citations, &citationCounter) {
+				content = append(content, source)
+			}
 		case "tool_use":
 			content = append(content, decodeToolUse(block))
 		case "thinking", "redacted_thinking":
@@ -77,6 +82,24 @@ func decodeContent(blocks []anthropic.BetaContentBlockUnion) []api.ContentBlock
 	return content
 }
 
+// decodeCitations converts the URL-based citations of a text block into SourceBlocks.
+// Citations that don't reference a URL (e.g. document locations) are ignored.
+func decodeCitations(citations []anthropic.BetaTextCitationUnion, counter *int) []*api.SourceBlock {
+	var sources []*api.SourceBlock
+	for _, citation := range citations {
+		if citation.Type != "web_search_result_location" || citation.URL == "" {
+			continue
+		}
+		sources = append(sources, &api.SourceBlock{
+			ID:    fmt.Sprintf("source-%d", *counter),
+			URL:   citation.URL,
+			Title: citation.Title,
+		})
+		*counter++
+	}
+	return sources
+}
+
 // decodeToolUse converts an Anthropic tool use block to an AI SDK ToolCallBlock
 func decodeToolUse(block anthropic.BetaContentBlockUnion) *api.ToolCallBlock {
 	var args string
@@ -116,27 +139,32 @@ func decodeReasoning(block anthropic.BetaContentBlockUnion) *api.ReasoningBlock
 		if block.Data == "" {
 			return nil
 		}
-		// Create ReasoningBlock with redacted data in provider metadata
-		metadata := api.NewProviderMetadata(map[string]any{
-			"anthropic": &Metadata{
-				RedactedData: block.Data,
-			},
-		})
 		return &api.ReasoningBlock{
 			Text:             "", // Empty text for redacted reasoning
-			ProviderMetadata: metadata,
+			ProviderMetadata: redactedReasoningMetadata(block.Data),
 		}
 	}
 	return nil
 }
 
+// redactedReasoningMetadata returns the provider metadata that carries the
+// data of a redacted thinking block.
+func redactedReasoningMetadata(data string) *api.ProviderMetadata {
+	return api.NewProviderMetadata(map[string]any{
+		"anthropic": &Metadata{
+			RedactedData: data,
+		},
+	})
+}
+
 // decodeUsage converts Anthropic Usage to API SDK Usage
 func decodeUsage(usage anthropic.BetaUsage) api.Usage {
 	return api.Usage{
-		InputTokens:       int(usage.InputTokens),
-		OutputTokens:      int(usage.OutputTokens),
-		TotalTokens:       int(usage.InputTokens + usage.OutputTokens),
-		CachedInputTokens: int(usage.CacheReadInputTokens),
+		InputTokens:           int(usage.InputTokens),
+		OutputTokens:          int(usage.OutputTokens),
+		TotalTokens:           int(usage.InputTokens + usage.OutputTokens),
+		CachedInputTokens:     int(usage.CacheReadInputTokens),
+		CacheWriteInputTokens: int(usage.CacheCreationInputTokens),
 	}
 }
 
@@ -154,3 +182,17 @@ func decodeFinishReason(finishReason anthropic.BetaStopReason) api.FinishReason
 		return api.FinishReasonUnknown
 	}
 }
+
+// DecodeError converts errors returned by the Anthropic API into an
+// [api.APICallError], so that callers can inspect the status code and decide
+// whether to retry. Other errors are returned unchanged.
+func DecodeError(err error) error {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	callErr := api.NewAPICallError(err.Error(), apiErr.Request, apiErr.Response, err)
+	callErr.StatusCode = apiErr.StatusCode
+	return callErr
+}
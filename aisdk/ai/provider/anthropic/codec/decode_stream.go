@@ -0,0 +1,287 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"go.jetify.com/ai/api"
+)
+
+// StreamReader is an interface for reading from an SSE stream.
+// This abstraction makes testing easier as we can mock this interface instead of the concrete ssestream.Stream type.
+type StreamReader interface {
+	Next() bool
+	Current() anthropic.BetaRawMessageStreamEventUnion
+	Err() error
+}
+
+// DecodeStreamOptions configures DecodeStream.
+type DecodeStreamOptions struct {
+	// Warnings are sent in the StreamStartEvent that starts the stream.
+	Warnings []api.CallWarning
+
+	// IncludeRawChunks sends a RawChunkEvent with the JSON of every event
+	// received from Anthropic, before the events decoded from it.
+	IncludeRawChunks bool
+}
+
+// DecodeStream converts an Anthropic SSE stream to our API's StreamResponse.
+// This is the main entry point for decoding Anthropic streams.
+func DecodeStream(stream StreamReader, opts DecodeStreamOptions) (*api.StreamResponse, error) {
+	if stream == nil {
+		return nil, errors.New("nil stream provided")
+	}
+	decoder := &streamDecoder{
+		contentBlocks: make(map[int64]*streamBlock),
+		opts:          opts,
+	}
+	return &api.StreamResponse{
+		Stream: decoder.decodeEvents(stream),
+	}, nil
+}
+
+// streamDecoder maintains state while decoding a stream of Anthropic events.
+type streamDecoder struct {
+	opts DecodeStreamOptions
+
+	// Map from content block index to the block being streamed
+	contentBlocks map[int64]*streamBlock
+
+	// Usage statistics
+	usage anthropic.BetaUsage
+
+	// Stop reason reported by the message_delta event
+	stopReason anthropic.BetaStopReason
+
+	// Counter for source citations
+	citationCounter int
+}
+
+// streamBlock tracks information about an ongoing content block.
+type streamBlock struct {
+	blockType string
+
+	// Only set for tool_use blocks
+	toolCallID string
+	toolName   string
+	hasArgs    bool
+
+	// Sources cited by a text block. They are emitted once the block is done
+	// so that they follow the text, matching the order used by DecodeResponse.
+	sources []api.Source
+}
+
+// decodeEvents returns an iterator that yields events from the Anthropic stream.
+func (d *streamDecoder) decodeEvents(stream StreamReader) iter.Seq[api.StreamEvent] {
+	return func(yield func(api.StreamEvent) bool) {
+		if !yield(&api.StreamStartEvent{Warnings: d.opts.Warnings}) {
+			return
+		}
+
+		for stream.Next() {
+			current := stream.Current()
+			if raw := current.RawJSON(); d.opts.IncludeRawChunks && raw != "" {
+				if !yield(&api.RawChunkEvent{Data: json.RawMessage(raw)}) {
+					return
+				}
+			}
+			for _, event := range d.decodeEvent(current) {
+				if !yield(event) {
+					return
+				}
+			}
+		}
+
+		// Check if we encountered an error from the underlying stream
+		if err := stream.Err(); err != nil && !errors.Is(err, io.EOF) {
+			if !yield(&api.ErrorEvent{Err: DecodeError(err)}) {
+				return
+			}
+		}
+
+		yield(&api.FinishEvent{
+			FinishReason: decodeFinishReason(d.stopReason),
+			Usage:        decodeUsage(d.usage),
+			ProviderMetadata: api.NewProviderMetadata(map[string]any{
+				"anthropic": &Metadata{
+					Usage: Usage{
+						InputTokens:              d.usage.InputTokens,
+						OutputTokens:             d.usage.OutputTokens,
+						CacheCreationInputTokens: d.usage.CacheCreationInputTokens,
+						CacheReadInputTokens:     d.usage.CacheReadInputTokens,
+					},
+				},
+			}),
+		})
+	}
+}
+
+// decodeEvent translates an Anthropic event to zero or more events in our API format.
+func (d *streamDecoder) decodeEvent(event anthropic.BetaRawMessageStreamEventUnion) []api.StreamEvent {
+	switch event.Type {
+	case "message_start":
+		return d.decodeMessageStart(event)
+	case "content_block_start":
+		return d.decodeContentBlockStart(event)
+	case "content_block_delta":
+		return d.decodeContentBlockDelta(event)
+	case "content_block_stop":
+		return d.decodeContentBlockStop(event)
+	case "message_delta":
+		return d.decodeMessageDelta(event)
+	case "message_stop":
+		// The finish event is sent once the stream is exhausted.
+		return nil
+	default:
+		// Ignore any other unsupported event types (e.g. "ping")
+		return nil
+	}
+}
+
+// decodeMessageStart handles the message_start event, which carries the
+// response metadata and the input token usage.
+func (d *streamDecoder) decodeMessageStart(event anthropic.BetaRawMessageStreamEventUnion) []api.StreamEvent {
+	msg := event.Message
+	d.usage = msg.Usage
+	return []api.StreamEvent{
+		&api.ResponseMetadataEvent{
+			ID:      msg.ID,
+			ModelID: string(msg.Model),
+		},
+	}
+}
+
+// decodeContentBlockStart handles the start of a new content block.
+func (d *streamDecoder) decodeContentBlockStart(event anthropic.BetaRawMessageStreamEventUnion) []api.StreamEvent {
+	block := event.ContentBlock
+	state := &streamBlock{blockType: block.Type}
+	d.contentBlocks[event.Index] = state
+
+	switch block.Type {
+	case "text":
+		if block.Text != "" {
+			return []api.StreamEvent{&api.TextDeltaEvent{TextDelta: block.Text}}
+		}
+	case "thinking":
+		if block.Thinking != "" {
+			return []api.StreamEvent{&api.ReasoningEvent{TextDelta: block.Thinking}}
+		}
+	case "redacted_thinking":
+		// Redacted thinking is sent whole in the block start. Its data is
+		// kept in the provider metadata, as in DecodeResponse, so that it can
+		// be sent back in the following turns.
+		if block.Data != "" {
+			return []api.StreamEvent{&api.ReasoningEvent{ProviderMetadata: redactedReasoningMetadata(block.Data)}}
+		}
+	case "tool_use":
+		state.toolCallID = block.ID
+		state.toolName = block.Name
+	}
+	return nil
+}
+
+// decodeContentBlockDelta handles incremental updates to a content block.
+func (d *streamDecoder) decodeContentBlockDelta(event anthropic.BetaRawMessageStreamEventUnion) []api.StreamEvent {
+	state, ok := d.contentBlocks[event.Index]
+	if !ok {
+		return []api.StreamEvent{
+			&api.ErrorEvent{Err: fmt.Errorf("received content block delta for unknown index: %d", event.Index)},
+		}
+	}
+
+	delta := event.Delta
+	switch delta.Type {
+	case "text_delta":
+		return []api.StreamEvent{&api.TextDeltaEvent{TextDelta: delta.Text}}
+	case "thinking_delta":
+		return []api.StreamEvent{&api.ReasoningEvent{TextDelta: delta.Thinking}}
+	case "signature_delta":
+		return []api.StreamEvent{&api.ReasoningSignatureEvent{Signature: delta.Signature}}
+	case "input_json_delta":
+		// The first delta of a tool call is often empty, skip it.
+		if delta.PartialJSON == "" {
+			return nil
+		}
+		state.hasArgs = true
+		return []api.StreamEvent{
+			&api.ToolCallDeltaEvent{
+				ToolCallID: state.toolCallID,
+				ToolName:   state.toolName,
+				ArgsDelta:  []byte(delta.PartialJSON),
+			},
+		}
+	case "citations_delta":
+		if source, ok := d.decodeCitation(delta.Citation); ok {
+			state.sources = append(state.sources, source)
+		}
+	}
+	return nil
+}
+
+// decodeContentBlockStop handles the end of a content block.
+func (d *streamDecoder) decodeContentBlockStop(event anthropic.BetaRawMessageStreamEventUnion) []api.StreamEvent {
+	state, ok := d.contentBlocks[event.Index]
+	if !ok {
+		return nil
+	}
+	delete(d.contentBlocks, event.Index)
+
+	var events []api.StreamEvent
+	if state.blockType == "tool_use" && !state.hasArgs {
+		// Tool calls without arguments are decoded as an empty JSON object,
+		// same as DecodeResponse does for non-streaming responses.
+		events = append(events, &api.ToolCallDeltaEvent{
+			ToolCallID: state.toolCallID,
+			ToolName:   state.toolName,
+			ArgsDelta:  []byte("{}"),
+		})
+	}
+	for _, source := range state.sources {
+		events = append(events, &api.SourceEvent{Source: source})
+	}
+	return events
+}
+
+// decodeMessageDelta handles the message_delta event, which carries the stop
+// reason and cumulative usage statistics.
+func (d *streamDecoder) decodeMessageDelta(event anthropic.BetaRawMessageStreamEventUnion) []api.StreamEvent {
+	if event.Delta.StopReason != "" {
+		d.stopReason = event.Delta.StopReason
+	}
+
+	// The usage reported in message_delta is cumulative. Input and cache
+	// counts are only sent by some API versions, so we only override them
+	// when they are present.
+	usage := event.Usage
+	d.usage.OutputTokens = usage.OutputTokens
+	if usage.InputTokens > 0 {
+		d.usage.InputTokens = usage.InputTokens
+	}
+	if usage.CacheCreationInputTokens > 0 {
+		d.usage.CacheCreationInputTokens = usage.CacheCreationInputTokens
+	}
+	if usage.CacheReadInputTokens > 0 {
+		d.usage.CacheReadInputTokens = usage.CacheReadInputTokens
+	}
+	return nil
+}
+
+// decodeCitation converts a URL-based citation into a Source.
+// Citations that don't reference a URL (e.g. document locations) are ignored.
+func (d *streamDecoder) decodeCitation(citation anthropic.BetaCitationsDeltaCitationUnion) (api.Source, bool) {
+	if citation.Type != "web_search_result_location" || citation.URL == "" {
+		return api.Source{}, false
+	}
+	source := api.Source{
+		SourceType: "url",
+		ID:         fmt.Sprintf("source-%d", d.citationCounter),
+		URL:        citation.URL,
+		Title:      citation.Title,
+	}
+	d.citationCounter++
+	return source, true
+}
@@ -0,0 +1,353 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/aitesting"
+	"go.jetify.com/ai/api"
+)
+
+func TestDecodeStreamEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventJSONs []string
+		streamErr  error
+		opts       DecodeStreamOptions
+		want       []api.StreamEvent
+	}{
+		{
+			name: "warnings and raw chunks",
+			eventJSONs: []string{
+				`{"type": "message_start", "message": {"id": "msg_123", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 10}}}`,
+				`{"type": "message_stop"}`,
+			},
+			opts: DecodeStreamOptions{
+				Warnings:         []api.CallWarning{{Type: "unsupported-setting", Setting: "Seed"}},
+				IncludeRawChunks: true,
+			},
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "Seed"}}},
+				&api.RawChunkEvent{Data: json.RawMessage(`{"type": "message_start", "message": {"id": "msg_123", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 10}}}`)},
+				&api.ResponseMetadataEvent{
+					ID:      "msg_123",
+					ModelID: "claude-sonnet-4-0",
+				},
+				&api.RawChunkEvent{Data: json.RawMessage(`{"type": "message_stop"}`)},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonUnknown,
+					Usage:        api.Usage{InputTokens: 10, TotalTokens: 10},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &Metadata{Usage: Usage{InputTokens: 10}},
+					}),
+				},
+			},
+		},
+		{
+			name: "simple text stream",
+			eventJSONs: []string{
+				`{"type": "message_start", "message": {"id": "msg_123", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 10, "output_tokens": 1}}}`,
+				`{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Hello"}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": " world"}}`,
+				`{"type": "content_block_stop", "index": 0}`,
+				`{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 5}}`,
+				`{"type": "message_stop"}`,
+			},
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{
+					ID:      "msg_123",
+					ModelID: "claude-sonnet-4-0",
+				},
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.TextDeltaEvent{TextDelta: " world"},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonStop,
+					Usage: api.Usage{
+						InputTokens:  10,
+						OutputTokens: 5,
+						TotalTokens:  15,
+					},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &Metadata{
+							Usage: Usage{
+								InputTokens:  10,
+								OutputTokens: 5,
+							},
+						},
+					}),
+				},
+			},
+		},
+		{
+			name: "thinking stream with signature",
+			eventJSONs: []string{
+				`{"type": "message_start", "message": {"id": "msg_456", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 20, "cache_read_input_tokens": 4}}}`,
+				`{"type": "content_block_start", "index": 0, "content_block": {"type": "thinking", "thinking": ""}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "Let me think"}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "signature_delta", "signature": "sig_abc"}}`,
+				`{"type": "content_block_stop", "index": 0}`,
+				`{"type": "content_block_start", "index": 1, "content_block": {"type": "text", "text": ""}}`,
+				`{"type": "content_block_delta", "index": 1, "delta": {"type": "text_delta", "text": "42"}}`,
+				`{"type": "content_block_stop", "index": 1}`,
+				`{"type": "message_delta", "delta": {"stop_reason": "max_tokens"}, "usage": {"output_tokens": 30}}`,
+			},
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{
+					ID:      "msg_456",
+					ModelID: "claude-sonnet-4-0",
+				},
+				&api.ReasoningEvent{TextDelta: "Let me think"},
+				&api.ReasoningSignatureEvent{Signature: "sig_abc"},
+				&api.TextDeltaEvent{TextDelta: "42"},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonLength,
+					Usage: api.Usage{
+						InputTokens:       20,
+						OutputTokens:      30,
+						TotalTokens:       50,
+						CachedInputTokens: 4,
+					},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &Metadata{
+							Usage: Usage{
+								InputTokens:          20,
+								OutputTokens:         30,
+								CacheReadInputTokens: 4,
+							},
+						},
+					}),
+				},
+			},
+		},
+		{
+			name: "tool call stream",
+			eventJSONs: []string{
+				`{"type": "message_start", "message": {"id": "msg_789", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 15}}}`,
+				`{"type": "content_block_start", "index": 0, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {}}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": ""}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "{\"location\":"}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "\"Paris\"}"}}`,
+				`{"type": "content_block_stop", "index": 0}`,
+				`{"type": "content_block_start", "index": 1, "content_block": {"type": "tool_use", "id": "toolu_2", "name": "get_time", "input": {}}}`,
+				`{"type": "content_block_stop", "index": 1}`,
+				`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 8}}`,
+			},
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{
+					ID:      "msg_789",
+					ModelID: "claude-sonnet-4-0",
+				},
+				&api.ToolCallDeltaEvent{
+					ToolCallID: "toolu_1",
+					ToolName:   "get_weather",
+					ArgsDelta:  []byte(`{"location":`),
+				},
+				&api.ToolCallDeltaEvent{
+					ToolCallID: "toolu_1",
+					ToolName:   "get_weather",
+					ArgsDelta:  []byte(`"Paris"}`),
+				},
+				&api.ToolCallDeltaEvent{
+					ToolCallID: "toolu_2",
+					ToolName:   "get_time",
+					ArgsDelta:  []byte(`{}`),
+				},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonToolCalls,
+					Usage: api.Usage{
+						InputTokens:  15,
+						OutputTokens: 8,
+						TotalTokens:  23,
+					},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &Metadata{
+							Usage: Usage{
+								InputTokens:  15,
+								OutputTokens: 8,
+							},
+						},
+					}),
+				},
+			},
+		},
+		{
+			name: "citations are emitted after the text block",
+			eventJSONs: []string{
+				`{"type": "message_start", "message": {"id": "msg_cite", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 3}}}`,
+				`{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": "", "citations": []}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "citations_delta", "citation": {"type": "web_search_result_location", "url": "https://example.com", "title": "Example", "cited_text": "foo"}}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "citations_delta", "citation": {"type": "char_location", "cited_text": "bar", "document_index": 0}}}`,
+				`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Foo"}}`,
+				`{"type": "content_block_stop", "index": 0}`,
+				`{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 2}}`,
+			},
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{
+					ID:      "msg_cite",
+					ModelID: "claude-sonnet-4-0",
+				},
+				&api.TextDeltaEvent{TextDelta: "Foo"},
+				&api.SourceEvent{
+					Source: api.Source{
+						SourceType: "url",
+						ID:         "source-0",
+						URL:        "https://example.com",
+						Title:      "Example",
+					},
+				},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonStop,
+					Usage: api.Usage{
+						InputTokens:  3,
+						OutputTokens: 2,
+						TotalTokens:  5,
+					},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &Metadata{
+							Usage: Usage{
+								InputTokens:  3,
+								OutputTokens: 2,
+							},
+						},
+					}),
+				},
+			},
+		},
+		{
+			name: "delta for unknown block and stream error",
+			eventJSONs: []string{
+				`{"type": "content_block_delta", "index": 3, "delta": {"type": "text_delta", "text": "oops"}}`,
+			},
+			streamErr: errors.New("connection reset"),
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ErrorEvent{Err: errors.New("received content block delta for unknown index: 3")},
+				&api.ErrorEvent{Err: errors.New("connection reset")},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonUnknown,
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &Metadata{},
+					}),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Parse the JSON events
+			var events []anthropic.BetaRawMessageStreamEventUnion
+			for _, jsonStr := range testCase.eventJSONs {
+				var event anthropic.BetaRawMessageStreamEventUnion
+				err := json.Unmarshal([]byte(jsonStr), &event)
+				require.NoError(t, err)
+				events = append(events, event)
+			}
+
+			stream := newMockStreamReader(events)
+			stream.err = testCase.streamErr
+
+			result, err := DecodeStream(stream, testCase.opts)
+			require.NoError(t, err)
+
+			got := aitesting.CollectEvents(result)
+			aitesting.StreamValid(t, got)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func TestDecodeStream_MatchesDecodeResponse(t *testing.T) {
+	var msg anthropic.BetaMessage
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "msg_789",
+		"model": "claude-sonnet-4-0",
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 12, "output_tokens": 9},
+		"content": [
+			{"type": "thinking", "thinking": "The user wants the weather.", "signature": "sig_1"},
+			{"type": "redacted_thinking", "data": "EmwKAhgB"},
+			{"type": "text", "text": "Checking the weather."},
+			{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"location": "Paris"}}
+		]
+	}`), &msg))
+	generated, err := DecodeResponse(&msg)
+	require.NoError(t, err)
+
+	var events []anthropic.BetaRawMessageStreamEventUnion
+	for _, eventJSON := range []string{
+		`{"type": "message_start", "message": {"id": "msg_789", "model": "claude-sonnet-4-0", "usage": {"input_tokens": 12}}}`,
+		`{"type": "content_block_start", "index": 0, "content_block": {"type": "thinking", "thinking": ""}}`,
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "The user wants the weather."}}`,
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "signature_delta", "signature": "sig_1"}}`,
+		`{"type": "content_block_stop", "index": 0}`,
+		`{"type": "content_block_start", "index": 1, "content_block": {"type": "redacted_thinking", "data": "EmwKAhgB"}}`,
+		`{"type": "content_block_stop", "index": 1}`,
+		`{"type": "content_block_start", "index": 2, "content_block": {"type": "text", "text": ""}}`,
+		`{"type": "content_block_delta", "index": 2, "delta": {"type": "text_delta", "text": "Checking "}}`,
+		`{"type": "content_block_delta", "index": 2, "delta": {"type": "text_delta", "text": "the weather."}}`,
+		`{"type": "content_block_stop", "index": 2}`,
+		`{"type": "content_block_start", "index": 3, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {}}}`,
+		`{"type": "content_block_delta", "index": 3, "delta": {"type": "input_json_delta", "partial_json": "{\"location\":"}}`,
+		`{"type": "content_block_delta", "index": 3, "delta": {"type": "input_json_delta", "partial_json": " \"Paris\"}"}}`,
+		`{"type": "content_block_stop", "index": 3}`,
+		`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 9}}`,
+		`{"type": "message_stop"}`,
+	} {
+		var event anthropic.BetaRawMessageStreamEventUnion
+		require.NoError(t, json.Unmarshal([]byte(eventJSON), &event))
+		events = append(events, event)
+	}
+	result, err := DecodeStream(newMockStreamReader(events), DecodeStreamOptions{})
+	require.NoError(t, err)
+
+	got := aitesting.CollectEvents(result)
+	aitesting.StreamValid(t, got)
+	aitesting.StreamMatchesResponse(t, generated, got)
+	aitesting.GoldenEvents(t, "testdata/stream_tool_use.yaml", got)
+}
+
+func TestDecodeStream_NilStream(t *testing.T) {
+	_, err := DecodeStream(nil, DecodeStreamOptions{})
+	require.Error(t, err)
+}
+
+// mockStreamReader implements the StreamReader interface for testing
+type mockStreamReader struct {
+	events []anthropic.BetaRawMessageStreamEventUnion
+	index  int
+	err    error
+}
+
+// newMockStreamReader creates a new mock stream reader with the given events
+func newMockStreamReader(events []anthropic.BetaRawMessageStreamEventUnion) *mockStreamReader {
+	return &mockStreamReader{
+		events: events,
+		index:  -1,
+	}
+}
+
+// Next advances to the next event, returning true if there is one, false otherwise
+func (m *mockStreamReader) Next() bool {
+	m.index++
+	return m.index < len(m.events)
+}
+
+// Current returns the current event
+func (m *mockStreamReader) Current() anthropic.BetaRawMessageStreamEventUnion {
+	return m.events[m.index]
+}
+
+// Err returns any error that occurred while reading the stream
+func (m *mockStreamReader) Err() error {
+	return m.err
+}
@@ -73,6 +73,22 @@ func TestDecodeUsage(t *testing.T) {
 				TotalTokens:  300,
 			},
 		},
+		{
+			name: "cache reads and writes",
+			usage: anthropic.BetaUsage{
+				InputTokens:              10,
+				OutputTokens:             20,
+				CacheReadInputTokens:     300,
+				CacheCreationInputTokens: 400,
+			},
+			want: api.Usage{
+				InputTokens:           10,
+				OutputTokens:          20,
+				TotalTokens:           30,
+				CachedInputTokens:     300,
+				CacheWriteInputTokens: 400,
+			},
+		},
 		{
 			name:  "zero usage",
 			usage: anthropic.BetaUsage{},
@@ -337,6 +353,36 @@ func TestDecodeContent(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "text with citations",
+			blocks: []anthropic.BetaContentBlockUnion{
+				{
+					Type: "text",
+					Text: "Paris is the capital of France",
+					Citations: []anthropic.BetaTextCitationUnion{
+						{
+							Type:  "web_search_result_location",
+							URL:   "https://example.com/paris",
+							Title: "Paris",
+						},
+						{
+							Type:      "char_location",
+							CitedText: "capital",
+						},
+					},
+				},
+			},
+			want: []api.ContentBlock{
+				&api.TextBlock{
+					Text: "Paris is the capital of France",
+				},
+				&api.SourceBlock{
+					ID:    "source-0",
+					URL:   "https://example.com/paris",
+					Title: "Paris",
+				},
+			},
+		},
 		{
 			name:   "nil blocks",
 			blocks: nil,
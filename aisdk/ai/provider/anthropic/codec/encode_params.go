@@ -2,6 +2,7 @@ package codec
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"go.jetify.com/ai/api"
@@ -15,7 +16,7 @@ func EncodeParams(
 		return anthropic.BetaMessageNewParams{}, []api.CallWarning{}, err
 	}
 
-	params, warnings, err := encodeCallOptions(opts)
+	params, warnings, err := encodeCallOptions(modelID, opts)
 	if err != nil {
 		return anthropic.BetaMessageNewParams{}, warnings, err
 	}
@@ -35,7 +36,7 @@ func EncodeParams(
 	return params, warnings, nil
 }
 
-func encodeCallOptions(opts api.CallOptions) (anthropic.BetaMessageNewParams, []api.CallWarning, error) {
+func encodeCallOptions(modelID string, opts api.CallOptions) (anthropic.BetaMessageNewParams, []api.CallWarning, error) {
 	params := anthropic.BetaMessageNewParams{
 		MaxTokens: int64(4096), // Default max tokens
 	}
@@ -61,7 +62,7 @@ func encodeCallOptions(opts api.CallOptions) (anthropic.BetaMessageNewParams, []
 	warnings := unsupportedWarnings(opts)
 
 	// Handle thinking-specific configuration
-	thinkingWarnings, err := encodeThinking(&params, opts)
+	thinkingWarnings, err := encodeThinking(&params, modelID, opts)
 	if err != nil {
 		return params, warnings, err
 	}
@@ -122,25 +123,44 @@ func unsupportedWarnings(opts api.CallOptions) []api.CallWarning {
 	return warnings
 }
 
-func encodeThinking(params *anthropic.BetaMessageNewParams, opts api.CallOptions) ([]api.CallWarning, error) {
+// minThinkingBudget is the minimum thinking budget accepted by Anthropic.
+const minThinkingBudget = 1024
+
+func encodeThinking(
+	params *anthropic.BetaMessageNewParams, modelID string, opts api.CallOptions,
+) ([]api.CallWarning, error) {
 	var warnings []api.CallWarning
 
+	// The thinking settings in the metadata take precedence over the
+	// provider-neutral reasoning options.
+	var budget int
 	metadata := GetMetadata(&opts)
-	thinkingEnabled := metadata != nil && metadata.Thinking.Enabled
-
-	if !thinkingEnabled {
+	switch {
+	case metadata != nil && metadata.Thinking.Enabled:
+		if metadata.Thinking.BudgetTokens == 0 {
+			return warnings, fmt.Errorf("thinking requires a budget")
+		}
+		budget = metadata.Thinking.BudgetTokens
+	case opts.Reasoning != nil && !supportsThinking(modelID):
+		warnings = append(warnings, api.CallWarning{
+			Type:    "unsupported-setting",
+			Setting: "Reasoning",
+			Details: fmt.Sprintf("Thinking is not supported by model %s", modelID),
+		})
+		return warnings, nil
+	case opts.Reasoning != nil:
+		// Claude models always return a summary of their thinking, so
+		// IncludeSummary doesn't need to be mapped.
+		budget = max(opts.Reasoning.ResolvedBudget(), minThinkingBudget)
+	default:
 		return warnings, nil
-	}
-
-	if metadata.Thinking.BudgetTokens == 0 {
-		return warnings, fmt.Errorf("thinking requires a budget")
 	}
 
 	// Configure thinking parameters
-	params.Thinking = anthropic.BetaThinkingConfigParamOfEnabled(int64(metadata.Thinking.BudgetTokens))
+	params.Thinking = anthropic.BetaThinkingConfigParamOfEnabled(int64(budget))
 
 	// Adjust max tokens to account for thinking budget
-	params.MaxTokens = params.MaxTokens + int64(metadata.Thinking.BudgetTokens)
+	params.MaxTokens = params.MaxTokens + int64(budget)
 
 	// Add warnings for unsupported settings when thinking is enabled
 	if opts.Temperature != nil {
@@ -169,3 +189,16 @@ func encodeThinking(params *anthropic.BetaMessageNewParams, opts api.CallOptions
 
 	return warnings, nil
 }
+
+// supportsThinking reports whether the model supports extended thinking,
+// which is the case for Claude 3.7 Sonnet and later models.
+func supportsThinking(modelID string) bool {
+	for _, prefix := range []string{
+		"claude-3-5-", "claude-3-opus", "claude-3-sonnet", "claude-3-haiku", "claude-2", "claude-instant",
+	} {
+		if strings.HasPrefix(modelID, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestEncodeThinking(t *testing.T) {
+	tests := []struct {
+		name          string
+		modelID       string
+		opts          api.CallOptions
+		wantBudget    int64
+		wantMaxTokens int64
+		wantWarnings  []api.CallWarning
+	}{
+		{
+			name:          "disabled by default",
+			modelID:       "claude-sonnet-4-0",
+			wantMaxTokens: 4096,
+		},
+		{
+			name:    "thinking metadata",
+			modelID: "claude-sonnet-4-0",
+			opts: api.CallOptions{
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					"anthropic": &Metadata{Thinking: ThinkingConfig{Enabled: true, BudgetTokens: 2000}},
+				}),
+			},
+			wantBudget:    2000,
+			wantMaxTokens: 6096,
+		},
+		{
+			name:    "metadata takes precedence over reasoning options",
+			modelID: "claude-sonnet-4-0",
+			opts: api.CallOptions{
+				Reasoning: &api.ReasoningOptions{BudgetTokens: 8000},
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					"anthropic": &Metadata{Thinking: ThinkingConfig{Enabled: true, BudgetTokens: 2000}},
+				}),
+			},
+			wantBudget:    2000,
+			wantMaxTokens: 6096,
+		},
+		{
+			name:    "reasoning effort",
+			modelID: "claude-opus-4-1-20250805",
+			opts: api.CallOptions{
+				MaxOutputTokens: 1000,
+				Reasoning:       &api.ReasoningOptions{Effort: api.ReasoningEffortLow},
+			},
+			wantBudget:    4096,
+			wantMaxTokens: 5096,
+		},
+		{
+			name:          "reasoning budget below the minimum",
+			modelID:       "claude-3-7-sonnet-latest",
+			opts:          api.CallOptions{Reasoning: &api.ReasoningOptions{BudgetTokens: 500}},
+			wantBudget:    1024,
+			wantMaxTokens: 5120,
+		},
+		{
+			name:          "model without thinking",
+			modelID:       "claude-3-5-haiku-latest",
+			opts:          api.CallOptions{Reasoning: &api.ReasoningOptions{Effort: api.ReasoningEffortHigh}},
+			wantMaxTokens: 4096,
+			wantWarnings: []api.CallWarning{{
+				Type:    "unsupported-setting",
+				Setting: "Reasoning",
+				Details: "Thinking is not supported by model claude-3-5-haiku-latest",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, warnings, err := encodeCallOptions(tt.modelID, tt.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantMaxTokens, params.MaxTokens)
+			assert.Equal(t, tt.wantWarnings, warnings)
+			if tt.wantBudget == 0 {
+				assert.Nil(t, params.Thinking.OfEnabled)
+				return
+			}
+			require.NotNil(t, params.Thinking.OfEnabled)
+			assert.Equal(t, tt.wantBudget, params.Thinking.OfEnabled.BudgetTokens)
+		})
+	}
+}
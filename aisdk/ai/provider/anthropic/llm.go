@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 	"go.jetify.com/ai/api"
 	"go.jetify.com/ai/provider/anthropic/codec"
 )
@@ -12,6 +13,10 @@ import (
 type ModelOption func(*LanguageModel)
 
 // WithClient returns a ModelOption that sets the client.
+//
+// Calls made through the ai package are retried by the ai package itself (see
+// ai.WithMaxRetries), so create the client with option.WithMaxRetries(0) to
+// avoid retrying every attempt again in the client.
 func WithClient(client anthropic.Client) ModelOption {
 	// TODO: Instead of only supporting an anthropic.Client, we can "flatten"
 	// the options supported by the Anthropic SDK.
@@ -26,14 +31,18 @@ type LanguageModel struct {
 	client  anthropic.Client
 }
 
-var _ api.LanguageModel = &LanguageModel{}
+var (
+	_ api.LanguageModel                = &LanguageModel{}
+	_ api.ObjectGenerationModeProvider = &LanguageModel{}
+)
 
 // NewLanguageModel creates a new Anthropic language model.
 func NewLanguageModel(modelID string, opts ...ModelOption) *LanguageModel {
 	// Create model with default settings
 	model := &LanguageModel{
 		modelID: modelID,
-		client:  anthropic.NewClient(), // Default client
+		// Default client. Retries are left to the ai package.
+		client: anthropic.NewClient(option.WithMaxRetries(0)),
 	}
 
 	// Apply options
@@ -52,6 +61,13 @@ func (m *LanguageModel) ModelID() string {
 	return m.modelID
 }
 
+// DefaultObjectGenerationMode returns the default mode for object generation.
+// Anthropic does not support a JSON response format, so objects are generated
+// through a forced tool call.
+func (m *LanguageModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	return api.ObjectGenerationModeTool
+}
+
 func (m *LanguageModel) SupportedUrls() []api.SupportedURL {
 	// TODO: Make configurable via the constructor.
 	return []api.SupportedURL{
@@ -74,7 +90,7 @@ func (m *LanguageModel) Generate(
 
 	message, err := m.client.Beta.Messages.New(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, codec.DecodeError(err)
 	}
 
 	response, err := codec.DecodeResponse(message)
@@ -89,5 +105,43 @@ func (m *LanguageModel) Generate(
 func (m *LanguageModel) Stream(
 	ctx context.Context, prompt []api.Message, opts api.CallOptions,
 ) (*api.StreamResponse, error) {
-	return nil, api.NewUnsupportedFunctionalityError("streaming generation", "")
+	params, warnings, err := codec.EncodeParams(m.modelID, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := m.client.Beta.Messages.NewStreaming(ctx, params)
+	response, err := codec.DecodeStream(stream, codec.DecodeStreamOptions{
+		Warnings:         warnings,
+		IncludeRawChunks: opts.IncludeRawChunks,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// CountTokens counts the input tokens of the prompt using Anthropic's token
+// counting API. It can be used as an ai.TokenCounter.
+func (m *LanguageModel) CountTokens(ctx context.Context, prompt []api.Message) (int, error) {
+	encoded, err := codec.EncodePrompt(prompt)
+	if err != nil {
+		return 0, err
+	}
+
+	params := anthropic.BetaMessageCountTokensParams{
+		Model:    anthropic.Model(m.modelID),
+		Messages: encoded.Messages,
+		Betas:    encoded.Betas,
+	}
+	if len(encoded.System) > 0 {
+		params.System = anthropic.BetaMessageCountTokensParamsSystemUnion{OfBetaTextBlockArray: encoded.System}
+	}
+
+	count, err := m.client.Beta.Messages.CountTokens(ctx, params)
+	if err != nil {
+		return 0, codec.DecodeError(err)
+	}
+	return int(count.InputTokens), nil
 }
@@ -1,6 +1,8 @@
 package anthropic
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -8,7 +10,10 @@ import (
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/stretchr/testify/require"
 	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+	"go.jetify.com/ai/provider/anthropic/codec"
 	"go.jetify.com/pkg/httpmock"
+	"go.jetify.com/sse"
 )
 
 func TestGenerate(t *testing.T) {
@@ -145,3 +150,385 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func eventsToString(events []sse.Event) string {
+	var buf bytes.Buffer
+	enc := sse.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.EncodeEvent(&event); err != nil {
+			panic(fmt.Sprintf("failed to encode event: %v", err))
+		}
+	}
+	return buf.String()
+}
+
+// toolCallStreamEvents is an Anthropic SSE stream containing reasoning, text
+// and a tool call.
+var toolCallStreamEvents = []sse.Event{
+	{
+		Event: "message_start",
+		Data: map[string]any{
+			"type": "message_start",
+			"message": map[string]any{
+				"id":    "msg_01",
+				"type":  "message",
+				"role":  "assistant",
+				"model": "claude-sonnet-4-0",
+				"usage": map[string]any{"input_tokens": 25, "output_tokens": 1},
+			},
+		},
+	},
+	{Event: "ping", Data: map[string]any{"type": "ping"}},
+	{
+		Event: "content_block_start",
+		Data: map[string]any{
+			"type":          "content_block_start",
+			"index":         0,
+			"content_block": map[string]any{"type": "thinking", "thinking": ""},
+		},
+	},
+	{
+		Event: "content_block_delta",
+		Data: map[string]any{
+			"type":  "content_block_delta",
+			"index": 0,
+			"delta": map[string]any{"type": "thinking_delta", "thinking": "Need the weather."},
+		},
+	},
+	{
+		Event: "content_block_delta",
+		Data: map[string]any{
+			"type":  "content_block_delta",
+			"index": 0,
+			"delta": map[string]any{"type": "signature_delta", "signature": "sig_123"},
+		},
+	},
+	{Event: "content_block_stop", Data: map[string]any{"type": "content_block_stop", "index": 0}},
+	{
+		Event: "content_block_start",
+		Data: map[string]any{
+			"type":          "content_block_start",
+			"index":         1,
+			"content_block": map[string]any{"type": "text", "text": ""},
+		},
+	},
+	{
+		Event: "content_block_delta",
+		Data: map[string]any{
+			"type":  "content_block_delta",
+			"index": 1,
+			"delta": map[string]any{"type": "text_delta", "text": "Let me check."},
+		},
+	},
+	{Event: "content_block_stop", Data: map[string]any{"type": "content_block_stop", "index": 1}},
+	{
+		Event: "content_block_start",
+		Data: map[string]any{
+			"type":  "content_block_start",
+			"index": 2,
+			"content_block": map[string]any{
+				"type":  "tool_use",
+				"id":    "toolu_01",
+				"name":  "get_weather",
+				"input": map[string]any{},
+			},
+		},
+	},
+	{
+		Event: "content_block_delta",
+		Data: map[string]any{
+			"type":  "content_block_delta",
+			"index": 2,
+			"delta": map[string]any{"type": "input_json_delta", "partial_json": `{"location":`},
+		},
+	},
+	{
+		Event: "content_block_delta",
+		Data: map[string]any{
+			"type":  "content_block_delta",
+			"index": 2,
+			"delta": map[string]any{"type": "input_json_delta", "partial_json": `"Paris"}`},
+		},
+	},
+	{Event: "content_block_stop", Data: map[string]any{"type": "content_block_stop", "index": 2}},
+	{
+		Event: "message_delta",
+		Data: map[string]any{
+			"type":  "message_delta",
+			"delta": map[string]any{"stop_reason": "tool_use"},
+			"usage": map[string]any{"output_tokens": 40},
+		},
+	},
+	{Event: "message_stop", Data: map[string]any{"type": "message_stop"}},
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		exchanges      []httpmock.Exchange
+		expectedEvents []api.StreamEvent
+		expectError    string
+	}{
+		{
+			name: "stream with reasoning, text and tool call",
+			exchanges: []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/v1/messages",
+					},
+					Response: httpmock.Response{
+						StatusCode: http.StatusOK,
+						Headers:    map[string]string{"Content-Type": "text/event-stream"},
+						Body:       eventsToString(toolCallStreamEvents),
+					},
+				},
+			},
+			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{ID: "msg_01", ModelID: "claude-sonnet-4-0"},
+				&api.ReasoningEvent{TextDelta: "Need the weather."},
+				&api.ReasoningSignatureEvent{Signature: "sig_123"},
+				&api.TextDeltaEvent{TextDelta: "Let me check."},
+				&api.ToolCallDeltaEvent{
+					ToolCallID: "toolu_01",
+					ToolName:   "get_weather",
+					ArgsDelta:  []byte(`{"location":`),
+				},
+				&api.ToolCallDeltaEvent{
+					ToolCallID: "toolu_01",
+					ToolName:   "get_weather",
+					ArgsDelta:  []byte(`"Paris"}`),
+				},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonToolCalls,
+					Usage: api.Usage{
+						InputTokens:  25,
+						OutputTokens: 40,
+						TotalTokens:  65,
+					},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"anthropic": &codec.Metadata{
+							Usage: codec.Usage{
+								InputTokens:  25,
+								OutputTokens: 40,
+							},
+						},
+					}),
+				},
+			},
+		},
+		{
+			name: "api error",
+			exchanges: []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/v1/messages",
+					},
+					Response: httpmock.Response{
+						StatusCode: http.StatusInternalServerError,
+						Body:       map[string]any{"error": "internal server error"},
+					},
+				},
+			},
+			expectError: "500 Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, tt.exchanges)
+			defer server.Close()
+
+			client := anthropic.NewClient(
+				option.WithBaseURL(server.BaseURL()),
+				option.WithAPIKey("test-key"),
+				option.WithMaxRetries(0), // Disable retries
+			)
+			model := NewLanguageModel("claude-3", WithClient(client))
+
+			prompt := []api.Message{
+				&api.UserMessage{Content: api.ContentFromText("What's the weather in Paris?")},
+			}
+			resp, err := model.Stream(t.Context(), prompt, api.CallOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+
+			var gotEvents []api.StreamEvent
+			for event := range resp.Stream {
+				gotEvents = append(gotEvents, event)
+			}
+
+			if tt.expectError != "" {
+				require.Len(t, gotEvents, 3)
+				require.IsType(t, &api.StreamStartEvent{}, gotEvents[0])
+				errEvent, ok := gotEvents[1].(*api.ErrorEvent)
+				require.True(t, ok, "expected an error after the stream start, got %T", gotEvents[1])
+				require.Contains(t, errEvent.Error(), tt.expectError)
+				return
+			}
+
+			require.Equal(t, tt.expectedEvents, gotEvents)
+		})
+	}
+}
+
+// TestStreamMatchesGenerate verifies that building a response from the stream
+// produces the same content as the equivalent non-streaming call.
+func TestStreamMatchesGenerate(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/v1/messages"},
+			Response: httpmock.Response{
+				StatusCode: http.StatusOK,
+				Body: map[string]any{
+					"id":          "msg_01",
+					"type":        "message",
+					"role":        "assistant",
+					"model":       "claude-sonnet-4-0",
+					"stop_reason": "tool_use",
+					"content": []any{
+						map[string]any{"type": "thinking", "thinking": "Need the weather.", "signature": "sig_123"},
+						map[string]any{"type": "text", "text": "Let me check."},
+						map[string]any{
+							"type":  "tool_use",
+							"id":    "toolu_01",
+							"name":  "get_weather",
+							"input": map[string]any{"location": "Paris"},
+						},
+					},
+					"usage": map[string]any{"input_tokens": 25, "output_tokens": 40},
+				},
+			},
+		},
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/v1/messages"},
+			Response: httpmock.Response{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "text/event-stream"},
+				Body:       eventsToString(toolCallStreamEvents),
+			},
+		},
+	})
+	defer server.Close()
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(server.BaseURL()),
+		option.WithAPIKey("test-key"),
+		option.WithMaxRetries(0),
+	)
+	model := NewLanguageModel("claude-3", WithClient(client))
+	prompt := []api.Message{
+		&api.UserMessage{Content: api.ContentFromText("What's the weather in Paris?")},
+	}
+
+	generated, err := model.Generate(t.Context(), prompt, api.CallOptions{})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(t.Context(), prompt, api.CallOptions{})
+	require.NoError(t, err)
+	streamed, err := builder.StreamToResponse(stream)
+	require.NoError(t, err)
+
+	require.Equal(t, generated.Content, streamed.Content)
+	require.Equal(t, generated.FinishReason, streamed.FinishReason)
+	require.Equal(t, generated.Usage, streamed.Usage)
+	require.Equal(t, generated.ProviderMetadata, streamed.ProviderMetadata)
+	require.Equal(t, generated.ResponseInfo.ID, streamed.ResponseInfo.ID)
+	require.Equal(t, generated.ResponseInfo.ModelID, streamed.ResponseInfo.ModelID)
+}
+
+func TestStream_APICallError(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/v1/messages"},
+			Response: httpmock.Response{
+				StatusCode: 529,
+				Body:       `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`,
+			},
+		},
+	})
+	defer server.Close()
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(server.BaseURL()),
+		option.WithAPIKey("test-key"),
+		option.WithMaxRetries(0),
+	)
+	model := NewLanguageModel("claude-3", WithClient(client))
+
+	stream, err := model.Stream(t.Context(), []api.Message{
+		&api.UserMessage{Content: api.ContentFromText("Hello")},
+	}, api.CallOptions{})
+	require.NoError(t, err)
+
+	_, err = builder.StreamToResponse(stream)
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	require.Equal(t, 529, callErr.StatusCode)
+	require.True(t, callErr.IsRetryable())
+}
+
+func TestGenerate_DefaultClientDoesNotRetry(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/v1/messages"},
+			Response: httpmock.Response{
+				StatusCode: 529,
+				Headers:    map[string]string{"retry-after-ms": "1"},
+				Body:       `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`,
+			},
+		},
+	})
+	defer server.Close()
+	t.Setenv("ANTHROPIC_BASE_URL", server.BaseURL())
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	// Retries are left to the ai package, so the call is only made once.
+	model := NewLanguageModel("claude-3")
+	_, err := model.Generate(t.Context(), []api.Message{
+		&api.UserMessage{Content: api.ContentFromText("Hello")},
+	}, api.CallOptions{})
+
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	require.Equal(t, 529, callErr.StatusCode)
+}
+
+func TestCountTokens(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{
+				Method: http.MethodPost,
+				Path:   "/v1/messages/count_tokens",
+				Body: map[string]any{
+					"model":  "claude-3",
+					"system": []any{map[string]any{"type": "text", "text": "Be brief."}},
+					"messages": []any{
+						map[string]any{
+							"role":    "user",
+							"content": []any{map[string]any{"type": "text", "text": "Hello"}},
+						},
+					},
+				},
+			},
+			Response: httpmock.Response{Body: map[string]any{"input_tokens": 14}},
+		},
+	})
+	defer server.Close()
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(server.BaseURL()),
+		option.WithAPIKey("test-key"),
+		option.WithMaxRetries(0),
+	)
+	model := NewLanguageModel("claude-3", WithClient(client))
+
+	tokens, err := model.CountTokens(t.Context(), []api.Message{
+		&api.SystemMessage{Content: "Be brief."},
+		&api.UserMessage{Content: api.ContentFromText("Hello")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 14, tokens)
+}
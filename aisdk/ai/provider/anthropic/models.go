@@ -0,0 +1,65 @@
+package anthropic
+
+import "go.jetify.com/ai/api"
+
+// Last updated: 2025-09-01
+// Prices are in USD per million tokens: https://www.anthropic.com/pricing
+//
+// Tokens written to the prompt cache are charged at 1.25 times the input
+// price, the price of the default 5-minute cache.
+
+var (
+	textAndImages = []api.Modality{api.ModalityText, api.ModalityImage}
+	textAndFiles  = []api.Modality{api.ModalityText, api.ModalityImage, api.ModalityFile}
+)
+
+var catalog = api.NewModelCatalog(ProviderName, []api.ModelSpec{
+	{
+		IDs: []string{
+			ModelClaudeOpus4_1_20250805, ModelClaudeOpus4_0, ModelClaudeOpus4_20250514,
+			ModelClaude4Opus20250514,
+		},
+		ContextWindow: 200_000, MaxOutputTokens: 32_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 1.50, CacheWriteInput: 18.75, Output: 75, CachedInputExcluded: true},
+	},
+	{
+		IDs: []string{
+			ModelClaudeSonnet4_0, ModelClaudeSonnet4_20250514, ModelClaude4Sonnet20250514,
+			ModelClaude3_7SonnetLatest, ModelClaude3_7Sonnet20250219,
+		},
+		ContextWindow: 200_000, MaxOutputTokens: 64_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 3, CachedInput: 0.30, CacheWriteInput: 3.75, Output: 15, CachedInputExcluded: true},
+	},
+	{
+		IDs: []string{
+			ModelClaude3_5SonnetLatest, ModelClaude3_5Sonnet20241022, ModelClaude_3_5_Sonnet_20240620,
+		},
+		ContextWindow: 200_000, MaxOutputTokens: 8_192, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 3, CachedInput: 0.30, CacheWriteInput: 3.75, Output: 15, CachedInputExcluded: true},
+	},
+	{
+		IDs:           []string{ModelClaude3_5HaikuLatest, ModelClaude3_5Haiku20241022},
+		ContextWindow: 200_000, MaxOutputTokens: 8_192, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.80, CachedInput: 0.08, CacheWriteInput: 1, Output: 4, CachedInputExcluded: true},
+	},
+	{
+		IDs:           []string{ModelClaude3OpusLatest, ModelClaude_3_Opus_20240229},
+		ContextWindow: 200_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 1.50, CacheWriteInput: 18.75, Output: 75, CachedInputExcluded: true},
+	},
+	{
+		IDs:           []string{ModelClaude_3_Haiku_20240307},
+		ContextWindow: 200_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 0.25, CachedInput: 0.03, CacheWriteInput: 0.30, Output: 1.25, CachedInputExcluded: true},
+	},
+})
+
+func init() {
+	api.RegisterModelCatalog(ProviderName, catalog)
+}
+
+// ModelInfo returns the capabilities, limits and price of the given Anthropic
+// model. It returns false for models that are not in the catalog.
+func ModelInfo(modelID string) (api.ModelInfo, bool) {
+	return catalog(modelID)
+}
@@ -0,0 +1,38 @@
+package anthropic
+
+import "go.jetify.com/ai/api"
+
+// Provider gives access to the Anthropic language models.
+type Provider struct {
+	opts []ModelOption
+}
+
+var _ api.Provider = &Provider{}
+
+// NewProvider creates a new Anthropic provider. The options are applied to
+// every model returned by the provider.
+func NewProvider(opts ...ModelOption) *Provider {
+	return &Provider{opts: opts}
+}
+
+// LanguageModel returns the language model with the given ID. Any model ID is
+// accepted, since Anthropic releases new models frequently. It returns a
+// NoSuchModelError if the ID is empty.
+func (p *Provider) LanguageModel(modelID string) (api.LanguageModel, error) {
+	if modelID == "" {
+		return nil, api.NewNoSuchModelError(modelID, api.LanguageModelType)
+	}
+	return NewLanguageModel(modelID, p.opts...), nil
+}
+
+// TextEmbeddingModel always returns a NoSuchModelError: Anthropic doesn't
+// provide embedding models.
+func (p *Provider) TextEmbeddingModel(modelID string) (api.EmbeddingModel[string], error) {
+	return nil, api.NewNoSuchModelError(modelID, api.TextEmbeddingModelType)
+}
+
+// ImageModel always returns a NoSuchModelError: Anthropic doesn't provide
+// image models.
+func (p *Provider) ImageModel(modelID string) (api.ImageModel, error) {
+	return nil, api.NewNoSuchModelError(modelID, api.ImageModelType)
+}
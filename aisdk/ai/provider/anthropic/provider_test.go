@@ -0,0 +1,29 @@
+package anthropic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestProvider(t *testing.T) {
+	provider := NewProvider()
+
+	model, err := provider.LanguageModel(ModelClaudeSonnet4_0)
+	require.NoError(t, err)
+	assert.Equal(t, ModelClaudeSonnet4_0, model.ModelID())
+
+	var noSuchModel *api.NoSuchModelError
+	_, err = provider.LanguageModel("")
+	require.ErrorAs(t, err, &noSuchModel)
+
+	_, err = provider.TextEmbeddingModel("embedding")
+	require.ErrorAs(t, err, &noSuchModel)
+	assert.Equal(t, api.TextEmbeddingModelType, noSuchModel.ModelType)
+
+	_, err = provider.ImageModel("image")
+	require.ErrorAs(t, err, &noSuchModel)
+	assert.Equal(t, api.ImageModelType, noSuchModel.ModelType)
+}
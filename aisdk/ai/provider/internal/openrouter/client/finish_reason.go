@@ -1,9 +0,0 @@
-package client
-
-const (
-	FinishReasonStop          = "stop"
-	FinishReasonLength        = "length"
-	FinishReasonContentFilter = "content_filter"
-	FinishReasonFunctionCall  = "function_call"
-	FinishReasonToolCalls     = "tool_calls"
-)
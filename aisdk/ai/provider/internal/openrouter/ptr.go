@@ -1,6 +0,0 @@
-package openrouter
-
-// Helper functions for creating pointers
-func strPtr(s string) *string {
-	return &s
-}
@@ -24,6 +24,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
 
 	"github.com/stretchr/testify/assert"
@@ -42,6 +43,9 @@ type GenerateModel struct {
 	streamCallCount atomic.Int32
 	providerName    string
 	modelID         string
+
+	mu    sync.Mutex
+	calls []Call
 }
 
 // T is an interface that captures the testing.T methods we need
@@ -106,6 +110,10 @@ func (m *GenerateModel) SupportedUrls() []api.SupportedURL {
 func (m *GenerateModel) Generate(
 	ctx context.Context, prompt []api.Message, opts api.CallOptions,
 ) (*api.Response, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Prompt: prompt, Options: opts})
+	m.mu.Unlock()
+
 	// Atomically increment and get the new count
 	newCount := m.callCount.Add(1)
 	index := newCount - 1
@@ -124,6 +132,13 @@ func (m *GenerateModel) Stream(
 	return nil, errors.New("Stream: not implemented")
 }
 
+// Calls returns the prompts and options of every Generate call, in order.
+func (m *GenerateModel) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
 // AssertCount verifies that all expected results have been consumed
 // and that Stream was never called.
 // It fails the test if there are unused results or if Stream was called.
@@ -239,3 +239,14 @@ func TestGenerateModelOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateModel_Calls(t *testing.T) {
+	prompt := []api.Message{&api.UserMessage{Content: []api.ContentBlock{&api.TextBlock{Text: "test"}}}}
+	opts := api.CallOptions{MaxOutputTokens: 100}
+
+	model := NewGenerateModel([]MockResult{{Response: &api.Response{}}})
+	_, err := model.Generate(context.Background(), prompt, opts)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Call{{Prompt: prompt, Options: opts}}, model.Calls())
+}
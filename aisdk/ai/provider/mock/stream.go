@@ -0,0 +1,189 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.jetify.com/ai/api"
+)
+
+// StreamResult represents a scripted stream, or an error returned by Stream
+// before any event is sent.
+type StreamResult struct {
+	// Events are yielded in order by the stream. ErrorEvents can be included
+	// at any position to simulate errors in the middle of the stream.
+	Events []api.StreamEvent
+
+	// Delay is waited before each event is yielded.
+	Delay time.Duration
+
+	// StreamError, if set, is yielded as an ErrorEvent after all the events.
+	StreamError error
+
+	// Error, if set, is returned by Stream instead of a stream.
+	Error error
+}
+
+// Call records the arguments a mock model was called with.
+type Call struct {
+	Prompt  []api.Message
+	Options api.CallOptions
+}
+
+// StreamModel is a mock language model that returns scripted streams.
+//
+// Example usage:
+//
+//	model := mock.NewStreamModel([]mock.StreamResult{
+//		{Events: []api.StreamEvent{
+//			&api.TextDeltaEvent{TextDelta: "Hello"},
+//			&api.FinishEvent{FinishReason: api.FinishReasonStop},
+//		}},
+//		{Error: errors.New("rate limit exceeded")},
+//	})
+//
+//	resp, err := model.Stream(ctx, messages, opts) // streams "Hello"
+//	_, err = model.Stream(ctx, messages, opts)     // returns the error
+//
+//	model.AssertCount(t)
+//	calls := model.Calls() // the prompts and options of both calls
+//
+// If the context is canceled while the stream is being consumed, the stream
+// yields an ErrorEvent with the context error and ends.
+type StreamModel struct {
+	results         []StreamResult
+	streamCallCount atomic.Int32
+	callCount       atomic.Int32
+	providerName    string
+	modelID         string
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ api.LanguageModel = (*StreamModel)(nil)
+
+// NewStreamModel creates a new mock StreamModel with the given results.
+// The results will be returned in order as Stream is called. Once they are
+// exhausted, Stream returns an empty stream.
+// The same options as NewGenerateModel can be used to customize the provider
+// name and model ID.
+func NewStreamModel(results []StreamResult, opts ...GenerateModelOption) *StreamModel {
+	if results == nil {
+		results = []StreamResult{}
+	}
+
+	// Apply the options to a GenerateModel to reuse its defaults
+	base := NewGenerateModel(nil, opts...)
+
+	return &StreamModel{
+		results:      results,
+		providerName: base.providerName,
+		modelID:      base.modelID,
+	}
+}
+
+func (m *StreamModel) ProviderName() string {
+	return m.providerName
+}
+
+func (m *StreamModel) ModelID() string {
+	return m.modelID
+}
+
+func (m *StreamModel) SupportedUrls() []api.SupportedURL {
+	return nil
+}
+
+func (m *StreamModel) Generate(
+	ctx context.Context, prompt []api.Message, opts api.CallOptions,
+) (*api.Response, error) {
+	m.callCount.Add(1)
+	return nil, errors.New("Generate: not implemented")
+}
+
+func (m *StreamModel) Stream(
+	ctx context.Context, prompt []api.Message, opts api.CallOptions,
+) (*api.StreamResponse, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Prompt: prompt, Options: opts})
+	m.mu.Unlock()
+
+	// Atomically increment and get the new count
+	newCount := m.streamCallCount.Add(1)
+	index := newCount - 1
+
+	if int(index) >= len(m.results) {
+		return &api.StreamResponse{Stream: func(yield func(api.StreamEvent) bool) {}}, nil
+	}
+
+	result := m.results[index]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &api.StreamResponse{Stream: scriptedStream(ctx, result)}, nil
+}
+
+// Calls returns the prompts and options of every Stream call, in order.
+func (m *StreamModel) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// AssertCount verifies that all expected results have been consumed
+// and that Generate was never called.
+// It fails the test if there are unused results or if Generate was called.
+func (m *StreamModel) AssertCount(t T) {
+	t.Helper()
+	streamCallCount := int(m.streamCallCount.Load())
+	callCount := int(m.callCount.Load())
+	assert.Equal(t, len(m.results), streamCallCount,
+		"StreamModel: expected %d Stream calls, but got %d", len(m.results), streamCallCount)
+	assert.Equal(t, 0, callCount,
+		"StreamModel: expected 0 Generate calls, but got %d", callCount)
+}
+
+func scriptedStream(ctx context.Context, result StreamResult) iter.Seq[api.StreamEvent] {
+	return func(yield func(api.StreamEvent) bool) {
+		events := result.Events
+		if result.StreamError != nil {
+			events = append(events[:len(events):len(events)], &api.ErrorEvent{Err: result.StreamError})
+		}
+
+		for _, event := range events {
+			if err := wait(ctx, result.Delay); err != nil {
+				yield(&api.ErrorEvent{Err: err})
+				return
+			}
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+// wait waits for the given delay, or until the context is done.
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestStreamModel(t *testing.T) {
+	streamErr := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		result   StreamResult
+		expected []api.StreamEvent
+		err      error
+	}{
+		{
+			name: "scripted events",
+			result: StreamResult{Events: []api.StreamEvent{
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.FinishEvent{FinishReason: api.FinishReasonStop},
+			}},
+			expected: []api.StreamEvent{
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.FinishEvent{FinishReason: api.FinishReasonStop},
+			},
+		},
+		{
+			name: "events with delay",
+			result: StreamResult{
+				Events: []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hello"}},
+				Delay:  time.Millisecond,
+			},
+			expected: []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hello"}},
+		},
+		{
+			name: "mid-stream error",
+			result: StreamResult{
+				Events:      []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hel"}},
+				StreamError: streamErr,
+			},
+			expected: []api.StreamEvent{
+				&api.TextDeltaEvent{TextDelta: "Hel"},
+				&api.ErrorEvent{Err: streamErr},
+			},
+		},
+		{
+			name:   "error before the stream",
+			result: StreamResult{Error: streamErr},
+			err:    streamErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewStreamModel([]StreamResult{tt.result})
+
+			resp, err := model.Stream(context.Background(), nil, api.CallOptions{})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, resp)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, slices.Collect(resp.Stream))
+			}
+			model.AssertCount(t)
+		})
+	}
+}
+
+func TestStreamModel_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	model := NewStreamModel([]StreamResult{{
+		Events: []api.StreamEvent{
+			&api.TextDeltaEvent{TextDelta: "Hello"},
+			&api.TextDeltaEvent{TextDelta: "World"},
+		},
+		Delay: time.Hour,
+	}})
+
+	resp, err := model.Stream(ctx, nil, api.CallOptions{})
+	require.NoError(t, err)
+
+	cancel()
+	events := slices.Collect(resp.Stream)
+	assert.Equal(t, []api.StreamEvent{&api.ErrorEvent{Err: context.Canceled}}, events)
+
+	_, err = model.Stream(ctx, nil, api.CallOptions{})
+	assert.NoError(t, err, "calls beyond the scripted results return an empty stream")
+}
+
+func TestStreamModel_AssertCount(t *testing.T) {
+	ctx := context.Background()
+	results := []StreamResult{
+		{Events: []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "First"}}},
+		{Events: []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Second"}}},
+	}
+
+	model := NewStreamModel(results)
+	for i := range len(results) + 1 {
+		mockT := &mockTestingT{}
+		model.AssertCount(mockT)
+		assert.Equal(t, i != len(results), mockT.failed, "AssertCount after %d calls", i)
+
+		_, err := model.Stream(ctx, nil, api.CallOptions{})
+		require.NoError(t, err)
+	}
+
+	t.Run("fails when Generate is called", func(t *testing.T) {
+		model := NewStreamModel(nil)
+		_, err := model.Generate(ctx, nil, api.CallOptions{})
+		assert.ErrorContains(t, err, "not implemented")
+
+		mockT := &mockTestingT{}
+		model.AssertCount(mockT)
+		assert.True(t, mockT.failed)
+	})
+}
+
+func TestStreamModel_Calls(t *testing.T) {
+	first := []api.Message{&api.UserMessage{Content: []api.ContentBlock{&api.TextBlock{Text: "first"}}}}
+	second := []api.Message{&api.UserMessage{Content: []api.ContentBlock{&api.TextBlock{Text: "second"}}}}
+	temperature := 0.5
+
+	model := NewStreamModel([]StreamResult{{}, {}}, WithProviderName("openai"), WithModelID("gpt-4"))
+	assert.Equal(t, "openai", model.ProviderName())
+	assert.Equal(t, "gpt-4", model.ModelID())
+
+	_, err := model.Stream(context.Background(), first, api.CallOptions{Temperature: &temperature})
+	require.NoError(t, err)
+	_, err = model.Stream(context.Background(), second, api.CallOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Call{
+		{Prompt: first, Options: api.CallOptions{Temperature: &temperature}},
+		{Prompt: second},
+	}, model.Calls())
+}
@@ -75,3 +75,17 @@ const (
 	ResponsesModelComputerUsePreview           = shared.ResponsesModelComputerUsePreview
 	ResponsesModelComputerUsePreview2025_03_11 = shared.ResponsesModelComputerUsePreview2025_03_11
 )
+
+// Embedding models
+const (
+	EmbeddingModelTextEmbedding3Small = "text-embedding-3-small"
+	EmbeddingModelTextEmbedding3Large = "text-embedding-3-large"
+	EmbeddingModelTextEmbeddingAda002 = "text-embedding-ada-002"
+)
+
+// Image models
+const (
+	ImageModelDallE2    = "dall-e-2"
+	ImageModelDallE3    = "dall-e-3"
+	ImageModelGPTImage1 = "gpt-image-1"
+)
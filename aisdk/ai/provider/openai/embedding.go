@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v2"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openai/internal/codec"
+)
+
+// maxEmbeddingsPerCall is the maximum number of inputs accepted by the OpenAI
+// embeddings API in a single request.
+const maxEmbeddingsPerCall = 2048
+
+// EmbeddingModel represents an OpenAI text embedding model.
+type EmbeddingModel struct {
+	modelID string
+	client  openai.Client
+}
+
+var _ api.EmbeddingModel[string] = &EmbeddingModel{}
+
+// NewEmbeddingModel creates a new OpenAI text embedding model.
+func NewEmbeddingModel(modelID string, opts ...ModelOption) *EmbeddingModel {
+	options := buildModelOptions(opts)
+	return &EmbeddingModel{
+		modelID: modelID,
+		client:  options.client,
+	}
+}
+
+func (m *EmbeddingModel) ProviderName() string {
+	return ProviderName
+}
+
+func (m *EmbeddingModel) ModelID() string {
+	return m.modelID
+}
+
+func (m *EmbeddingModel) MaxEmbeddingsPerCall() int {
+	return maxEmbeddingsPerCall
+}
+
+func (m *EmbeddingModel) SupportsParallelCalls() bool {
+	return true
+}
+
+func (m *EmbeddingModel) Embed(
+	ctx context.Context, values []string, opts api.EmbeddingOptions,
+) (*api.EmbeddingResponse, error) {
+	if len(values) > maxEmbeddingsPerCall {
+		anyValues := make([]any, len(values))
+		for i, value := range values {
+			anyValues[i] = value
+		}
+		return nil, api.NewTooManyEmbeddingValuesForCallError(
+			ProviderName, m.modelID, maxEmbeddingsPerCall, anyValues)
+	}
+
+	params := codec.EncodeEmbedding(m.modelID, values, opts)
+	resp, err := m.client.Embeddings.New(ctx, params, headerOptions(opts.Headers)...)
+	if err != nil {
+		return nil, codec.DecodeError(err)
+	}
+
+	return codec.DecodeEmbedding(resp, len(values))
+}
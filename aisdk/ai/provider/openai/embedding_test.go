@@ -0,0 +1,116 @@
+package openai
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openai/internal/codec"
+	"go.jetify.com/pkg/httpmock"
+)
+
+func TestEmbed(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		options  api.EmbeddingOptions
+		exchange httpmock.Exchange
+		want     *api.EmbeddingResponse
+		wantErr  bool
+	}{
+		{
+			name:   "embeddings in input order",
+			values: []string{"sunny day", "rainy day"},
+			options: api.EmbeddingOptions{
+				Headers: http.Header{"X-Custom": []string{"value"}},
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					ProviderName: &codec.Metadata{Dimensions: 2, User: "user-1"},
+				}),
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method:  http.MethodPost,
+					Path:    "/embeddings",
+					Headers: map[string]string{"X-Custom": "value"},
+					Body: `{
+						"model": "text-embedding-3-small",
+						"input": ["sunny day", "rainy day"],
+						"encoding_format": "float",
+						"dimensions": 2,
+						"user": "user-1"
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"object": "list",
+						"model": "text-embedding-3-small",
+						"data": [
+							{"object": "embedding", "index": 1, "embedding": [0.3, 0.4]},
+							{"object": "embedding", "index": 0, "embedding": [0.1, 0.2]}
+						],
+						"usage": {"prompt_tokens": 6, "total_tokens": 6}
+					}`,
+				},
+			},
+			want: &api.EmbeddingResponse{
+				Embeddings:   []api.Embedding{{0.1, 0.2}, {0.3, 0.4}},
+				Usage:        api.EmbeddingUsage{Tokens: 6},
+				ResponseInfo: &api.ResponseInfo{ModelID: "text-embedding-3-small"},
+			},
+		},
+		{
+			name:   "missing embeddings",
+			values: []string{"sunny day", "rainy day"},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/embeddings"},
+				Response: httpmock.Response{
+					Body: `{
+						"object": "list",
+						"model": "text-embedding-3-small",
+						"data": [{"object": "embedding", "index": 0, "embedding": [0.1, 0.2]}],
+						"usage": {"prompt_tokens": 6, "total_tokens": 6}
+					}`,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{tt.exchange})
+			defer server.Close()
+
+			client := openai.NewClient(
+				option.WithBaseURL(server.BaseURL()),
+				option.WithAPIKey("test-key"),
+				option.WithMaxRetries(0),
+			)
+			model := NewEmbeddingModel(EmbeddingModelTextEmbedding3Small, WithClient(client))
+
+			resp, err := model.Embed(t.Context(), tt.values, tt.options)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestEmbed_TooManyValues(t *testing.T) {
+	model := NewEmbeddingModel(EmbeddingModelTextEmbedding3Small)
+	values := strings.Split(strings.Repeat("a", model.MaxEmbeddingsPerCall()+1), "")
+
+	_, err := model.Embed(t.Context(), values, api.EmbeddingOptions{})
+
+	var tooMany *api.TooManyEmbeddingValuesForCallError
+	require.ErrorAs(t, err, &tooMany)
+	require.Equal(t, 2048, tooMany.MaxEmbeddingsPerCall)
+	require.Len(t, tooMany.Values, 2049)
+}
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v2"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openai/internal/codec"
+)
+
+// maxImagesPerCall is the maximum number of images that each model can
+// generate in a single request. Other models generate one image per request.
+var maxImagesPerCall = map[string]int{
+	ImageModelDallE2:    10,
+	ImageModelDallE3:    1,
+	ImageModelGPTImage1: 10,
+}
+
+// ImageModel represents an OpenAI image generation model.
+type ImageModel struct {
+	modelID string
+	client  openai.Client
+}
+
+var _ api.ImageModel = &ImageModel{}
+
+// NewImageModel creates a new OpenAI image generation model.
+func NewImageModel(modelID string, opts ...ModelOption) *ImageModel {
+	options := buildModelOptions(opts)
+	return &ImageModel{
+		modelID: modelID,
+		client:  options.client,
+	}
+}
+
+func (m *ImageModel) ProviderName() string {
+	return ProviderName
+}
+
+func (m *ImageModel) ModelID() string {
+	return m.modelID
+}
+
+func (m *ImageModel) MaxImagesPerCall() int {
+	if limit, ok := maxImagesPerCall[m.modelID]; ok {
+		return limit
+	}
+	return 1
+}
+
+func (m *ImageModel) Generate(
+	ctx context.Context, prompt string, opts api.ImageCallOptions,
+) (*api.ImageResponse, error) {
+	params, warnings := codec.EncodeImage(m.modelID, prompt, opts)
+
+	resp, err := m.client.Images.Generate(ctx, params, headerOptions(opts.Headers)...)
+	if err != nil {
+		return nil, codec.DecodeError(err)
+	}
+
+	response, err := codec.DecodeImage(m.modelID, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Warnings = append(response.Warnings, warnings...)
+	return response, nil
+}
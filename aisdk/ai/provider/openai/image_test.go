@@ -0,0 +1,188 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openai/internal/codec"
+	"go.jetify.com/pkg/httpmock"
+)
+
+func TestGenerateImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		modelID  string
+		prompt   string
+		options  api.ImageCallOptions
+		exchange httpmock.Exchange
+		want     *api.ImageResponse
+		wantErr  bool
+	}{
+		{
+			name:    "dall-e returns base64 images",
+			modelID: ImageModelDallE3,
+			prompt:  "a lighthouse",
+			options: api.ImageCallOptions{
+				N:           1,
+				Size:        "1024x1024",
+				AspectRatio: "1:1",
+				Headers:     http.Header{"X-Custom": []string{"value"}},
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					ProviderName: &codec.Metadata{ImageQuality: "hd", ImageStyle: "vivid"},
+				}),
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method:  http.MethodPost,
+					Path:    "/images/generations",
+					Headers: map[string]string{"X-Custom": "value"},
+					Body: `{
+						"model": "dall-e-3",
+						"prompt": "a lighthouse",
+						"n": 1,
+						"size": "1024x1024",
+						"quality": "hd",
+						"style": "vivid",
+						"response_format": "b64_json"
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"created": 1700000000,
+						"data": [{"b64_json": "aGVsbG8=", "revised_prompt": "a tall lighthouse"}]
+					}`,
+				},
+			},
+			want: &api.ImageResponse{
+				Images: []*api.ImageBlock{{
+					Data:      []byte("hello"),
+					MediaType: "image/png",
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						ProviderName: &codec.Metadata{RevisedPrompt: "a tall lighthouse"},
+					}),
+				}},
+				Warnings: []api.CallWarning{{
+					Type:    "unsupported-setting",
+					Setting: "AspectRatio",
+					Details: "This model does not support aspect ratio. Use Size instead.",
+				}},
+				ResponseInfo: &api.ResponseInfo{
+					ModelID:   "dall-e-3",
+					Timestamp: time.Unix(1700000000, 0).UTC(),
+				},
+			},
+		},
+		{
+			name:    "gpt-image uses the output format",
+			modelID: ImageModelGPTImage1,
+			prompt:  "a lighthouse",
+			options: api.ImageCallOptions{
+				N:    2,
+				Seed: 42,
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					ProviderName: &codec.Metadata{ImageOutputFormat: "webp"},
+				}),
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/images/generations",
+					Body: `{
+						"model": "gpt-image-1",
+						"prompt": "a lighthouse",
+						"n": 2,
+						"output_format": "webp"
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"created": 1700000000,
+						"output_format": "webp",
+						"data": [{"b64_json": "YQ=="}, {"b64_json": "Yg=="}],
+						"usage": {
+							"input_tokens": 10,
+							"output_tokens": 20,
+							"total_tokens": 30,
+							"input_tokens_details": {"image_tokens": 0, "text_tokens": 10}
+						}
+					}`,
+				},
+			},
+			want: &api.ImageResponse{
+				Images: []*api.ImageBlock{
+					{Data: []byte("a"), MediaType: "image/webp"},
+					{Data: []byte("b"), MediaType: "image/webp"},
+				},
+				Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "Seed"}},
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					ProviderName: &codec.Metadata{
+						Usage: codec.Usage{InputTokens: 10, OutputTokens: 20},
+					},
+				}),
+				ResponseInfo: &api.ResponseInfo{
+					ModelID:   "gpt-image-1",
+					Timestamp: time.Unix(1700000000, 0).UTC(),
+				},
+			},
+		},
+		{
+			name:    "invalid base64 data",
+			modelID: ImageModelDallE2,
+			prompt:  "a lighthouse",
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/images/generations"},
+				Response: httpmock.Response{
+					Body: `{"created": 1700000000, "data": [{"b64_json": "not base64!"}]}`,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "api error",
+			modelID: ImageModelDallE2,
+			prompt:  "a lighthouse",
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/images/generations"},
+				Response: httpmock.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       `{"error": {"message": "invalid prompt", "type": "invalid_request_error"}}`,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{tt.exchange})
+			defer server.Close()
+
+			client := openai.NewClient(
+				option.WithBaseURL(server.BaseURL()),
+				option.WithAPIKey("test-key"),
+				option.WithMaxRetries(0),
+			)
+			model := NewImageModel(tt.modelID, WithClient(client))
+
+			resp, err := model.Generate(t.Context(), tt.prompt, tt.options)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestImageModel_MaxImagesPerCall(t *testing.T) {
+	require.Equal(t, 10, NewImageModel(ImageModelDallE2).MaxImagesPerCall())
+	require.Equal(t, 1, NewImageModel(ImageModelDallE3).MaxImagesPerCall())
+	require.Equal(t, 10, NewImageModel(ImageModelGPTImage1).MaxImagesPerCall())
+	require.Equal(t, 1, NewImageModel("unknown-model").MaxImagesPerCall())
+}
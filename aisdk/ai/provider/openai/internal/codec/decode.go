@@ -2,10 +2,12 @@ package codec
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/responses"
 	"go.jetify.com/ai/api"
 )
@@ -279,3 +281,21 @@ func decodeProviderMetadata(msg *responses.Response) *api.ProviderMetadata {
 		},
 	})
 }
+
+// DecodeError converts errors returned by the OpenAI API into an
+// [api.APICallError], so that callers can inspect the status code and decide
+// whether to retry. Other errors are returned unchanged.
+func DecodeError(err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	message := apiErr.Message
+	if message == "" {
+		message = err.Error()
+	}
+	callErr := api.NewAPICallError(message, apiErr.Request, apiErr.Response, err)
+	callErr.StatusCode = apiErr.StatusCode
+	return callErr
+}
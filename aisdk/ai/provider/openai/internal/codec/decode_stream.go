@@ -21,15 +21,27 @@ type StreamReader interface {
 	Err() error
 }
 
+// DecodeStreamOptions configures DecodeStream.
+type DecodeStreamOptions struct {
+	// Warnings are sent in the StreamStartEvent that starts the stream.
+	Warnings []api.CallWarning
+
+	// IncludeRawChunks sends a RawChunkEvent with the JSON of every event
+	// received from OpenAI, before the events decoded from it.
+	IncludeRawChunks bool
+}
+
 // DecodeStream converts an OpenAI SSE stream to our API's StreamResponse.
 // This is the main entry point for decoding OpenAI streams.
-func DecodeStream(stream StreamReader) (*api.StreamResponse, error) {
-	decoder := &streamDecoder{}
+func DecodeStream(stream StreamReader, opts DecodeStreamOptions) (*api.StreamResponse, error) {
+	decoder := &streamDecoder{opts: opts}
 	return decoder.DecodeStream(stream)
 }
 
 // streamDecoder maintains state while decoding a stream of OpenAI events.
 type streamDecoder struct {
+	opts DecodeStreamOptions
+
 	// Map from output index to tool call information
 	ongoingToolCalls map[int64]toolCallInfo
 
@@ -75,10 +87,19 @@ func (d *streamDecoder) DecodeStream(stream StreamReader) (*api.StreamResponse,
 // decodeEvents returns an iterator that yields events from the OpenAI stream.
 func (d *streamDecoder) decodeEvents(stream StreamReader) iter.Seq[api.StreamEvent] {
 	return func(yield func(api.StreamEvent) bool) {
+		if !yield(&api.StreamStartEvent{Warnings: d.opts.Warnings}) {
+			return
+		}
+
 		// Process all events directly in the iterator function
 		for stream.Next() {
 			// Get the current event
 			event := stream.Current()
+			if raw := event.RawJSON(); d.opts.IncludeRawChunks && raw != "" {
+				if !yield(&api.RawChunkEvent{Data: json.RawMessage(raw)}) {
+					return
+				}
+			}
 
 			// Process the event
 			decodedEvent := d.decodeEvent(event)
@@ -95,7 +116,7 @@ func (d *streamDecoder) decodeEvents(stream StreamReader) iter.Seq[api.StreamEve
 		// Check if we encountered an error from the underlying stream
 		if err := stream.Err(); err != nil && !errors.Is(err, io.EOF) {
 			// Yield this as a final error event
-			if !yield(&api.ErrorEvent{Err: err}) {
+			if !yield(&api.ErrorEvent{Err: DecodeError(err)}) {
 				// Consumer doesn't want more, even this error
 				return
 			}
@@ -8,6 +8,7 @@ import (
 	"github.com/openai/openai-go/v2/responses"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/aitesting"
 	"go.jetify.com/ai/api"
 )
 
@@ -15,8 +16,30 @@ func TestDecodeStreamEvents(t *testing.T) {
 	tests := []struct {
 		name       string
 		eventJSONs []string
+		opts       DecodeStreamOptions
 		want       []api.StreamEvent
 	}{
+		{
+			name: "warnings and raw chunks",
+			eventJSONs: []string{
+				`{"type": "response.output_text.delta", "delta": "Hi"}`,
+			},
+			opts: DecodeStreamOptions{
+				Warnings:         []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}},
+				IncludeRawChunks: true,
+			},
+			want: []api.StreamEvent{
+				&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}}},
+				&api.RawChunkEvent{Data: json.RawMessage(`{"type": "response.output_text.delta", "delta": "Hi"}`)},
+				&api.TextDeltaEvent{TextDelta: "Hi"},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonStop,
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						"openai": &Metadata{},
+					}),
+				},
+			},
+		},
 		{
 			name: "simple text stream",
 			eventJSONs: []string{
@@ -25,6 +48,7 @@ func TestDecodeStreamEvents(t *testing.T) {
 				`{"type": "response.completed", "response": {"usage": {"input_tokens": 10, "output_tokens": 5}}}`,
 			},
 			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_123",
 					Timestamp: time.Date(2025, 3, 6, 13, 50, 19, 0, time.UTC),
@@ -60,6 +84,7 @@ func TestDecodeStreamEvents(t *testing.T) {
 				`{"type": "response.completed", "response": {"usage": {"input_tokens": 15, "output_tokens": 8}}}`,
 			},
 			want: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_456",
 					Timestamp: time.Date(2025, 3, 6, 13, 50, 19, 0, time.UTC),
@@ -106,21 +131,59 @@ func TestDecodeStreamEvents(t *testing.T) {
 			stream := newMockStreamReader(events)
 
 			// Decode the stream
-			result, err := DecodeStream(stream)
+			result, err := DecodeStream(stream, testCase.opts)
 			require.NoError(t, err)
 
-			// Collect all events from the stream
-			var got []api.StreamEvent
-			for event := range result.Stream {
-				got = append(got, event)
-			}
-
-			// Compare events using deep equality
+			got := aitesting.CollectEvents(result)
+			aitesting.StreamValid(t, got)
 			assert.Equal(t, testCase.want, got)
 		})
 	}
 }
 
+func TestDecodeStream_MatchesDecodeResponse(t *testing.T) {
+	var msg responses.Response
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "resp_789",
+		"created_at": 1741269019,
+		"model": "gpt-4o",
+		"status": "completed",
+		"usage": {"input_tokens": 12, "output_tokens": 9, "total_tokens": 21},
+		"output": [
+			{"type": "message", "id": "msg_1", "role": "assistant", "content": [
+				{"type": "output_text", "text": "Checking the weather.", "annotations": []}
+			]},
+			{"type": "function_call", "id": "fc_1", "call_id": "call_1", "name": "get_weather", "arguments": "{\"location\": \"Paris\"}"}
+		]
+	}`), &msg))
+	generated, err := DecodeResponse(&msg)
+	require.NoError(t, err)
+
+	var events []responses.ResponseStreamEventUnion
+	for _, eventJSON := range []string{
+		`{"type": "response.created", "response": {"id": "resp_789", "created_at": 1741269019, "model": "gpt-4o"}}`,
+		`{"type": "response.output_item.added", "output_index": 0, "item": {"type": "message", "id": "msg_1", "role": "assistant", "content": []}}`,
+		`{"type": "response.output_text.delta", "output_index": 0, "delta": "Checking "}`,
+		`{"type": "response.output_text.delta", "output_index": 0, "delta": "the weather."}`,
+		`{"type": "response.output_item.added", "output_index": 1, "item": {"type": "function_call", "id": "fc_1", "call_id": "call_1", "name": "get_weather", "arguments": ""}}`,
+		`{"type": "response.function_call_arguments.delta", "output_index": 1, "delta": "{\"location\":"}`,
+		`{"type": "response.function_call_arguments.delta", "output_index": 1, "delta": " \"Paris\"}"}`,
+		`{"type": "response.output_item.done", "output_index": 1, "item": {"type": "function_call", "id": "fc_1", "call_id": "call_1", "name": "get_weather", "arguments": "{\"location\": \"Paris\"}"}}`,
+		`{"type": "response.completed", "response": {"id": "resp_789", "usage": {"input_tokens": 12, "output_tokens": 9, "total_tokens": 21}}}`,
+	} {
+		var event responses.ResponseStreamEventUnion
+		require.NoError(t, json.Unmarshal([]byte(eventJSON), &event))
+		events = append(events, event)
+	}
+	result, err := DecodeStream(newMockStreamReader(events), DecodeStreamOptions{})
+	require.NoError(t, err)
+
+	got := aitesting.CollectEvents(result)
+	aitesting.StreamValid(t, got)
+	aitesting.StreamMatchesResponse(t, generated, got)
+	aitesting.GoldenEvents(t, "testdata/stream_tool_call.yaml", got)
+}
+
 // mockStreamReader implements the StreamReader interface for testing
 type mockStreamReader struct {
 	events []responses.ResponseStreamEventUnion
@@ -0,0 +1,55 @@
+package codec
+
+import (
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+	"go.jetify.com/ai/api"
+)
+
+// EncodeEmbedding converts the values to embed and the embedding options into
+// OpenAI embedding parameters.
+func EncodeEmbedding(modelID string, values []string, opts api.EmbeddingOptions) openai.EmbeddingNewParams {
+	params := openai.EmbeddingNewParams{
+		Model:          modelID,
+		Input:          openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: values},
+		EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat,
+	}
+
+	if metadata := GetMetadata(&opts); metadata != nil {
+		if metadata.Dimensions > 0 {
+			params.Dimensions = param.NewOpt(int64(metadata.Dimensions))
+		}
+		if metadata.User != "" {
+			params.User = param.NewOpt(metadata.User)
+		}
+	}
+	return params
+}
+
+// DecodeEmbedding converts an OpenAI embedding response into an AI SDK
+// embedding response, with the embeddings in the same order as the input values.
+func DecodeEmbedding(resp *openai.CreateEmbeddingResponse, count int) (*api.EmbeddingResponse, error) {
+	if resp == nil {
+		return nil, api.NewEmptyResponseBodyError("response from OpenAI embeddings API is nil")
+	}
+	if len(resp.Data) != count {
+		return nil, api.NewInvalidResponseDataError(resp.Data,
+			"expected one embedding per input value from OpenAI embeddings API")
+	}
+
+	embeddings := make([]api.Embedding, count)
+	for _, data := range resp.Data {
+		if data.Index < 0 || int(data.Index) >= count {
+			return nil, api.NewInvalidResponseDataError(data, "embedding index out of range")
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return &api.EmbeddingResponse{
+		Embeddings: embeddings,
+		Usage:      api.EmbeddingUsage{Tokens: int(resp.Usage.PromptTokens)},
+		ResponseInfo: &api.ResponseInfo{
+			ModelID: resp.Model,
+		},
+	}, nil
+}
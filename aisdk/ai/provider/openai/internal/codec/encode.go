@@ -77,6 +77,14 @@ func getModelConfig(modelID string) modelConfig {
 			SystemMessageMode:      "developer",
 			RequiredAutoTruncation: false,
 		}
+	} else if strings.HasPrefix(modelID, "gpt-5") && !strings.HasPrefix(modelID, "gpt-5-chat") {
+		// gpt-5 models reason, except for the non-reasoning chat variant
+		return modelConfig{
+			IsReasoningModel:       true,
+			SystemMessageMode:      "developer",
+			RequiredAutoTruncation: false,
+			SupportsMinimalEffort:  true,
+		}
 	} else if len(modelID) > 0 && strings.HasPrefix(modelID, shared.ResponsesModelComputerUsePreview) {
 		return modelConfig{
 			IsReasoningModel:       true,
@@ -122,7 +130,7 @@ func applyCallOptions(params *responses.ResponseNewParams, opts api.CallOptions,
 	applyTruncationSettings(params, opts, modelConfig)
 
 	// Apply reasoning settings and handle unsupported options for reasoning models
-	reasoningWarnings := applyReasoningSettings(params, opts, modelConfig)
+	reasoningWarnings := applyReasoningSettings(params, opts, modelConfig, params.Model)
 	warnings = append(warnings, reasoningWarnings...)
 
 	return warnings, nil
@@ -204,22 +212,37 @@ func applyTruncationSettings(params *responses.ResponseNewParams, opts api.CallO
 // applyReasoningSettings applies settings specific to reasoning models
 // and handles unsupported options
 func applyReasoningSettings(params *responses.ResponseNewParams, opts api.CallOptions,
-	modelConfig modelConfig,
+	modelConfig modelConfig, modelID string,
 ) []api.CallWarning {
 	var warnings []api.CallWarning
 
-	// Apply reasoning settings for reasoning models
+	// Apply reasoning settings for reasoning models. The provider-neutral
+	// options are applied first, so that the metadata takes precedence.
 	if modelConfig.IsReasoningModel {
-		metadata := GetMetadata(&opts)
-		if metadata != nil && (metadata.ReasoningEffort != "" || metadata.ReasoningSummary != "") {
-			params.Reasoning = shared.ReasoningParam{}
-			if metadata.ReasoningEffort != "" {
-				params.Reasoning.Effort = shared.ReasoningEffort(metadata.ReasoningEffort)
+		if opts.Reasoning != nil {
+			effort := opts.Reasoning.ResolvedEffort()
+			if effort == api.ReasoningEffortMinimal && !modelConfig.SupportsMinimalEffort {
+				effort = api.ReasoningEffortLow
 			}
-			if metadata.ReasoningSummary != "" {
-				params.Reasoning.Summary = shared.ReasoningSummary(metadata.ReasoningSummary)
+			params.Reasoning.Effort = shared.ReasoningEffort(effort)
+			if opts.Reasoning.IncludeSummary {
+				params.Reasoning.Summary = shared.ReasoningSummaryAuto
 			}
 		}
+
+		metadata := GetMetadata(&opts)
+		if metadata != nil && metadata.ReasoningEffort != "" {
+			params.Reasoning.Effort = shared.ReasoningEffort(metadata.ReasoningEffort)
+		}
+		if metadata != nil && metadata.ReasoningSummary != "" {
+			params.Reasoning.Summary = shared.ReasoningSummary(metadata.ReasoningSummary)
+		}
+	} else if opts.Reasoning != nil {
+		warnings = append(warnings, api.CallWarning{
+			Type:    "unsupported-setting",
+			Setting: "Reasoning",
+			Details: fmt.Sprintf("Reasoning is not supported by model %s", modelID),
+		})
 	}
 
 	// Handle unsupported settings for reasoning models
@@ -15,6 +15,10 @@ type modelConfig struct {
 	IsReasoningModel       bool   `json:"isReasoningModel"`
 	SystemMessageMode      string `json:"systemMessageMode"`
 	RequiredAutoTruncation bool   `json:"requiredAutoTruncation"`
+
+	// SupportsMinimalEffort indicates that the model accepts the "minimal"
+	// reasoning effort. Other reasoning models use "low" instead.
+	SupportsMinimalEffort bool `json:"supportsMinimalEffort"`
 }
 
 type OpenAIPrompt struct {
@@ -329,6 +333,11 @@ func EncodeAssistantMessage(message *api.AssistantMessage) ([]responses.Response
 			if item != nil {
 				items = append(items, *item)
 			}
+		case *api.ReasoningBlock:
+			// Reasoning items can only be sent back by referencing the ID of the
+			// original output item, which we don't keep. Since the model doesn't
+			// need them to continue the conversation, we skip them.
+			continue
 		default:
 			return nil, fmt.Errorf("unsupported content block type in assistant message: %T", block)
 		}
@@ -613,6 +613,33 @@ var assistantMessageTests = []testCase{
 			}`,
 		},
 	},
+	{
+		name: "assistant message with reasoning is skipped",
+		input: []api.Message{
+			&api.AssistantMessage{
+				Content: []api.ContentBlock{
+					&api.ReasoningBlock{
+						Text: "Thinking...",
+					},
+					&api.TextBlock{
+						Text: "Hello",
+					},
+				},
+			},
+		},
+		expectedMessages: []string{
+			`{
+				"role": "assistant",
+				"content": [
+					{
+						"type": "output_text",
+						"text": "Hello"
+					}
+				],
+				"type": "message"
+			}`,
+		},
+	},
 	{
 		name: "assistant message with text and tool call",
 		input: []api.Message{
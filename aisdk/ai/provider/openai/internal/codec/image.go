@@ -0,0 +1,121 @@
+package codec
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+	"go.jetify.com/ai/api"
+)
+
+// EncodeImage converts an image prompt and the image call options into OpenAI
+// image generation parameters. It returns warnings for settings that are not
+// supported.
+func EncodeImage(modelID string, prompt string, opts api.ImageCallOptions) (openai.ImageGenerateParams, []api.CallWarning) {
+	params := openai.ImageGenerateParams{
+		Model:  modelID,
+		Prompt: prompt,
+	}
+	if opts.N > 0 {
+		params.N = param.NewOpt(int64(opts.N))
+	}
+	if opts.Size != "" {
+		params.Size = openai.ImageGenerateParamsSize(opts.Size)
+	}
+	// gpt-image models always return base64-encoded images, and reject the
+	// response_format parameter.
+	if strings.HasPrefix(modelID, "dall-e") {
+		params.ResponseFormat = openai.ImageGenerateParamsResponseFormatB64JSON
+	}
+
+	var warnings []api.CallWarning
+	if opts.AspectRatio != "" {
+		warnings = append(warnings, api.CallWarning{
+			Type:    "unsupported-setting",
+			Setting: "AspectRatio",
+			Details: "This model does not support aspect ratio. Use Size instead.",
+		})
+	}
+	if opts.Seed != 0 {
+		warnings = append(warnings, api.CallWarning{
+			Type:    "unsupported-setting",
+			Setting: "Seed",
+		})
+	}
+
+	if metadata := GetMetadata(&opts); metadata != nil {
+		if metadata.User != "" {
+			params.User = param.NewOpt(metadata.User)
+		}
+		if metadata.ImageQuality != "" {
+			params.Quality = openai.ImageGenerateParamsQuality(metadata.ImageQuality)
+		}
+		if metadata.ImageStyle != "" {
+			params.Style = openai.ImageGenerateParamsStyle(metadata.ImageStyle)
+		}
+		if metadata.ImageBackground != "" {
+			params.Background = openai.ImageGenerateParamsBackground(metadata.ImageBackground)
+		}
+		if metadata.ImageOutputFormat != "" {
+			params.OutputFormat = openai.ImageGenerateParamsOutputFormat(metadata.ImageOutputFormat)
+		}
+	}
+
+	return params, warnings
+}
+
+// DecodeImage converts an OpenAI image generation response into an AI SDK
+// image response.
+func DecodeImage(modelID string, resp *openai.ImagesResponse) (*api.ImageResponse, error) {
+	if resp == nil {
+		return nil, api.NewEmptyResponseBodyError("response from OpenAI images API is nil")
+	}
+
+	mediaType := "image/png"
+	if resp.OutputFormat != "" {
+		mediaType = "image/" + string(resp.OutputFormat)
+	}
+
+	images := make([]*api.ImageBlock, 0, len(resp.Data))
+	for _, image := range resp.Data {
+		block := &api.ImageBlock{MediaType: mediaType}
+		if image.B64JSON != "" {
+			data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+			if err != nil {
+				return nil, api.NewInvalidResponseDataError(image.B64JSON, "invalid base64 image data")
+			}
+			block.Data = data
+		} else {
+			block.URL = image.URL
+		}
+		if image.RevisedPrompt != "" {
+			block.ProviderMetadata = api.NewProviderMetadata(map[string]any{
+				ProviderName: &Metadata{RevisedPrompt: image.RevisedPrompt},
+			})
+		}
+		images = append(images, block)
+	}
+
+	info := &api.ResponseInfo{ModelID: modelID}
+	if resp.Created != 0 {
+		info.Timestamp = time.Unix(resp.Created, 0).UTC()
+	}
+
+	response := &api.ImageResponse{
+		Images:       images,
+		ResponseInfo: info,
+	}
+	if resp.Usage.TotalTokens > 0 {
+		response.ProviderMetadata = api.NewProviderMetadata(map[string]any{
+			ProviderName: &Metadata{
+				Usage: Usage{
+					InputTokens:  int(resp.Usage.InputTokens),
+					OutputTokens: int(resp.Usage.OutputTokens),
+				},
+			},
+		})
+	}
+	return response, nil
+}
@@ -76,6 +76,28 @@ type Metadata struct {
 	//   the request will fail with a 400 error.
 	Truncation string `json:"truncation,omitempty"`
 
+	// Dimensions is the number of dimensions of the embeddings generated by
+	// embedding models. Only supported by text-embedding-3 and later models.
+	Dimensions int `json:"dimensions,omitempty"`
+
+	// ImageQuality is the quality of the images generated by image models.
+	// Supported values depend on the model, e.g. `standard` and `hd` for
+	// dall-e-3, or `low`, `medium` and `high` for gpt-image-1.
+	ImageQuality string `json:"image_quality,omitempty"`
+
+	// ImageStyle is the style of the images generated by dall-e-3.
+	// Supported values are `vivid` and `natural`.
+	ImageStyle string `json:"image_style,omitempty"`
+
+	// ImageBackground sets the transparency of the background of the images
+	// generated by gpt-image-1. Supported values are `transparent`, `opaque`
+	// and `auto`.
+	ImageBackground string `json:"image_background,omitempty"`
+
+	// ImageOutputFormat is the format of the images generated by gpt-image-1.
+	// Supported values are `png` (default), `jpeg` and `webp`.
+	ImageOutputFormat string `json:"image_output_format,omitempty"`
+
 	// --- Used in blocks ---
 
 	// ImageDetail indicates the level of detail that should be used when processing
@@ -99,6 +121,10 @@ type Metadata struct {
 
 	// ComputerSafetyChecks is a list of pending safety checks for the computer call.
 	ComputerSafetyChecks []ComputerSafetyCheck `json:"computer_safety_checks,omitempty"`
+
+	// RevisedPrompt is the prompt that was used to generate an image, when
+	// the model revised the original prompt.
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
 }
 
 func GetMetadata(source api.MetadataSource) *Metadata {
@@ -8,40 +8,24 @@ import (
 	"go.jetify.com/ai/provider/openai/internal/codec"
 )
 
-// ModelOption is a function type that modifies a LanguageModel.
-type ModelOption func(*LanguageModel)
-
-// WithClient returns a ModelOption that sets the client.
-func WithClient(client openai.Client) ModelOption {
-	// TODO: Instead of only supporting a single client, we can "flatten"
-	// the options supported by the OpenAI SDK.
-	return func(m *LanguageModel) {
-		m.client = client
-	}
-}
-
 // LanguageModel represents an OpenAI language model.
 type LanguageModel struct {
 	modelID string
 	client  openai.Client
 }
 
-var _ api.LanguageModel = &LanguageModel{}
+var (
+	_ api.LanguageModel                = &LanguageModel{}
+	_ api.ObjectGenerationModeProvider = &LanguageModel{}
+)
 
 // NewLanguageModel creates a new OpenAI language model.
 func NewLanguageModel(modelID string, opts ...ModelOption) *LanguageModel {
-	// Create model with default settings
-	model := &LanguageModel{
+	options := buildModelOptions(opts)
+	return &LanguageModel{
 		modelID: modelID,
-		client:  openai.NewClient(), // Default client
+		client:  options.client,
 	}
-
-	// Apply options
-	for _, opt := range opts {
-		opt(model)
-	}
-
-	return model
 }
 
 func (m *LanguageModel) ProviderName() string {
@@ -52,6 +36,12 @@ func (m *LanguageModel) ModelID() string {
 	return m.modelID
 }
 
+// DefaultObjectGenerationMode returns the default mode for object generation.
+// OpenAI supports structured outputs natively.
+func (m *LanguageModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	return api.ObjectGenerationModeJSON
+}
+
 func (m *LanguageModel) SupportedUrls() []api.SupportedURL {
 	// TODO: Make configurable via the constructor.
 	return []api.SupportedURL{
@@ -74,7 +64,7 @@ func (m *LanguageModel) Generate(
 
 	openaiResponse, err := m.client.Responses.New(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, codec.DecodeError(err)
 	}
 
 	response, err := codec.DecodeResponse(openaiResponse)
@@ -89,18 +79,37 @@ func (m *LanguageModel) Generate(
 func (m *LanguageModel) Stream(
 	ctx context.Context, prompt []api.Message, opts api.CallOptions,
 ) (*api.StreamResponse, error) {
-	// TODO: add warnings to the stream response by adding an initial StreamStart event
-	// (it could happen inside of codec.Encode)
-	params, _, err := codec.Encode(m.modelID, prompt, opts)
+	params, warnings, err := codec.Encode(m.modelID, prompt, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	stream := m.client.Responses.NewStreaming(ctx, params)
-	response, err := codec.DecodeStream(stream)
+	response, err := codec.DecodeStream(stream, codec.DecodeStreamOptions{
+		Warnings:         warnings,
+		IncludeRawChunks: opts.IncludeRawChunks,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
+
+// tokenEstimator follows the token accounting of OpenAI's chat models: each
+// message costs 3 tokens, the reply is primed with 3 more, and a high detail
+// 1024x1024 image costs 765 tokens.
+var tokenEstimator = api.TokenEstimator{
+	CharsPerToken: 4,
+	MessageTokens: 3,
+	PromptTokens:  3,
+	ImageTokens:   765,
+	FileTokens:    1_500,
+}
+
+// CountTokens estimates the input tokens of the prompt, since the API has no
+// token counting endpoint. The estimate is approximate, so leave some margin
+// in token budgets. It can be used as an ai.TokenCounter.
+func (m *LanguageModel) CountTokens(ctx context.Context, prompt []api.Message) (int, error) {
+	return tokenEstimator.Estimate(prompt), nil
+}
@@ -716,6 +716,139 @@ func TestGenerate(t *testing.T) {
 				Warnings: []api.CallWarning{},
 			},
 		},
+		{
+			name:    "maps reasoning options to reasoning effort and summary",
+			modelID: "o3",
+			prompt:  standardPrompt,
+			options: api.CallOptions{
+				Reasoning: &api.ReasoningOptions{Effort: api.ReasoningEffortMinimal, IncludeSummary: true},
+			},
+			exchanges: []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/responses",
+						Body: `{
+							"model": "o3",
+							"input": [
+								{
+									"role": "user",
+									"content": [
+										{
+											"type": "input_text",
+											"text": "Hello"
+										}
+									]
+								}
+							],
+							"reasoning": {
+								"effort": "low",
+								"summary": "auto"
+							}
+						}`,
+					},
+					Response: httpmock.Response{
+						StatusCode: http.StatusOK,
+						Body:       standardResponseBody,
+					},
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.TextBlock{Text: "answer text"},
+				},
+				Warnings: []api.CallWarning{},
+			},
+		},
+		{
+			name:    "maps reasoning budget to reasoning effort",
+			modelID: "gpt-5",
+			prompt:  standardPrompt,
+			options: api.CallOptions{
+				Reasoning: &api.ReasoningOptions{BudgetTokens: 1000},
+			},
+			exchanges: []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/responses",
+						Body: `{
+							"model": "gpt-5",
+							"input": [
+								{
+									"role": "user",
+									"content": [
+										{
+											"type": "input_text",
+											"text": "Hello"
+										}
+									]
+								}
+							],
+							"reasoning": {
+								"effort": "minimal"
+							}
+						}`,
+					},
+					Response: httpmock.Response{
+						StatusCode: http.StatusOK,
+						Body:       standardResponseBody,
+					},
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.TextBlock{Text: "answer text"},
+				},
+				Warnings: []api.CallWarning{},
+			},
+		},
+		{
+			name:    "warns about reasoning options for non-reasoning models",
+			modelID: "gpt-4o",
+			prompt:  standardPrompt,
+			options: api.CallOptions{
+				Reasoning: &api.ReasoningOptions{Effort: api.ReasoningEffortHigh},
+			},
+			exchanges: []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/responses",
+						Body: `{
+							"model": "gpt-4o",
+							"input": [
+								{
+									"role": "user",
+									"content": [
+										{
+											"type": "input_text",
+											"text": "Hello"
+										}
+									]
+								}
+							]
+						}`,
+					},
+					Response: httpmock.Response{
+						StatusCode: http.StatusOK,
+						Body:       standardResponseBody,
+					},
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.TextBlock{Text: "answer text"},
+				},
+				Warnings: []api.CallWarning{
+					{
+						Type:    "unsupported-setting",
+						Setting: "Reasoning",
+						Details: "Reasoning is not supported by model gpt-4o",
+					},
+				},
+			},
+		},
 		{
 			name:    "sends metadata provider option with user_123",
 			modelID: "gpt-4o",
@@ -1858,6 +1991,7 @@ func TestStream(t *testing.T) {
 				},
 			},
 			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_67c9a81b6a048190a9ee441c5755a4e8",
 					ModelID:   "gpt-4o-2024-07-18",
@@ -2044,6 +2178,7 @@ func TestStream(t *testing.T) {
 				},
 			},
 			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_67c9a81b6a048190a9ee441c5755a4e8",
 					ModelID:   "gpt-4o-2024-07-18",
@@ -2325,6 +2460,7 @@ func TestStream(t *testing.T) {
 				},
 			},
 			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_67cb13a755c08190acbe3839a49632fc",
 					ModelID:   "gpt-4o-2024-07-18",
@@ -2751,6 +2887,7 @@ func TestStream(t *testing.T) {
 				},
 			},
 			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_67cf3390786881908b27489d7e8cfb6b",
 					ModelID:   "gpt-4o-mini-2024-07-18",
@@ -2912,6 +3049,7 @@ func TestStream(t *testing.T) {
 				},
 			},
 			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
 				&api.ResponseMetadataEvent{
 					ID:        "resp_67c9a81b6a048190a9ee441c5755a4e8",
 					ModelID:   "o3-mini-2025-01-31",
@@ -3068,3 +3206,72 @@ func runStreamTests(t *testing.T, tests []struct {
 		})
 	}
 }
+
+func TestGenerate_APICallError(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/responses"},
+			Response: httpmock.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    map[string]string{"retry-after-ms": "250"},
+				Body:       `{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`,
+			},
+		},
+	})
+	defer server.Close()
+
+	client := openai.NewClient(
+		option.WithBaseURL(server.BaseURL()),
+		option.WithAPIKey("test-key"),
+		option.WithMaxRetries(0),
+	)
+	model := NewLanguageModel("gpt-4o", WithClient(client))
+
+	_, err := model.Generate(t.Context(), []api.Message{&api.UserMessage{Content: api.ContentFromText("Hello")}}, api.CallOptions{})
+
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	require.Equal(t, http.StatusTooManyRequests, callErr.StatusCode)
+	require.True(t, callErr.IsRetryable())
+	require.Equal(t, "Rate limit reached", callErr.Message)
+	require.Equal(t, "250", callErr.Response.Header.Get("retry-after-ms"))
+}
+
+func TestGenerate_DefaultClientDoesNotRetry(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/responses"},
+			Response: httpmock.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    map[string]string{"retry-after-ms": "1"},
+				Body:       `{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`,
+			},
+		},
+	})
+	defer server.Close()
+	t.Setenv("OPENAI_BASE_URL", server.BaseURL())
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	// Retries are left to the ai package, so the call is only made once.
+	model := NewLanguageModel("gpt-4o")
+	_, err := model.Generate(t.Context(), []api.Message{&api.UserMessage{Content: api.ContentFromText("Hello")}}, api.CallOptions{})
+
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	require.Equal(t, http.StatusTooManyRequests, callErr.StatusCode)
+}
+
+func TestCountTokens(t *testing.T) {
+	model := NewLanguageModel("gpt-4o")
+	tokens, err := model.CountTokens(t.Context(), []api.Message{
+		&api.SystemMessage{Content: "You are a helpful assistant."},
+		&api.UserMessage{Content: []api.ContentBlock{
+			&api.TextBlock{Text: "What is in this image?"},
+			&api.ImageBlock{URL: "https://example.com/cat.png"},
+		}},
+	})
+	require.NoError(t, err)
+	// 3 tokens to prime the reply, 3 per message, the text at 4 characters
+	// per token and the image.
+	require.Equal(t, 3+3+7+3+6+765, tokens)
+}
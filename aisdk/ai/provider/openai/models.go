@@ -0,0 +1,153 @@
+package openai
+
+import "go.jetify.com/ai/api"
+
+// Last updated: 2025-09-01
+// Prices are in USD per million tokens: https://platform.openai.com/docs/pricing
+
+var (
+	textOnly      = []api.Modality{api.ModalityText}
+	textAndImages = []api.Modality{api.ModalityText, api.ModalityImage}
+	textAndFiles  = []api.Modality{api.ModalityText, api.ModalityImage, api.ModalityFile}
+	textAndAudio  = []api.Modality{api.ModalityText, api.ModalityAudio}
+)
+
+var catalog = api.NewModelCatalog(ProviderName, []api.ModelSpec{
+	{
+		IDs:           []string{ChatModelGPT5, ChatModelGPT5_2025_08_07},
+		ContextWindow: 400_000, MaxOutputTokens: 128_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 1.25, CachedInput: 0.125, Output: 10},
+	},
+	{
+		IDs:           []string{ChatModelGPT5Mini, ChatModelGPT5Mini2025_08_07},
+		ContextWindow: 400_000, MaxOutputTokens: 128_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.25, CachedInput: 0.025, Output: 2},
+	},
+	{
+		IDs:           []string{ChatModelGPT5Nano, ChatModelGPT5Nano2025_08_07},
+		ContextWindow: 400_000, MaxOutputTokens: 128_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.05, CachedInput: 0.005, Output: 0.40},
+	},
+	{
+		IDs:           []string{ChatModelGPT5ChatLatest},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 1.25, CachedInput: 0.125, Output: 10},
+	},
+	{
+		IDs:           []string{ChatModelGPT4_1, ChatModelGPT4_1_2025_04_14},
+		ContextWindow: 1_047_576, MaxOutputTokens: 32_768, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 2, CachedInput: 0.50, Output: 8},
+	},
+	{
+		IDs:           []string{ChatModelGPT4_1Mini, ChatModelGPT4_1Mini2025_04_14},
+		ContextWindow: 1_047_576, MaxOutputTokens: 32_768, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.40, CachedInput: 0.10, Output: 1.60},
+	},
+	{
+		IDs:           []string{ChatModelGPT4_1Nano, ChatModelGPT4_1Nano2025_04_14},
+		ContextWindow: 1_047_576, MaxOutputTokens: 32_768, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.10, CachedInput: 0.025, Output: 0.40},
+	},
+	{
+		IDs:           []string{ChatModelO4Mini, ChatModelO4Mini2025_04_16},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 1.10, CachedInput: 0.275, Output: 4.40},
+	},
+	{
+		IDs:           []string{ChatModelO3, ChatModelO3_2025_04_16},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 2, CachedInput: 0.50, Output: 8},
+	},
+	{
+		IDs:           []string{ChatModelO3Mini, ChatModelO3Mini2025_01_31},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	},
+	{
+		IDs:           []string{ChatModelO1, ChatModelO1_2024_12_17},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 7.50, Output: 60},
+	},
+	{
+		IDs:           []string{ChatModelO1Preview, ChatModelO1Preview2024_09_12},
+		ContextWindow: 128_000, MaxOutputTokens: 32_768, Input: textOnly,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 7.50, Output: 60},
+	},
+	{
+		IDs:           []string{ChatModelO1Mini, ChatModelO1Mini2024_09_12},
+		ContextWindow: 128_000, MaxOutputTokens: 65_536, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	},
+	{
+		IDs:           []string{ChatModelCodexMiniLatest},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 1.50, CachedInput: 0.375, Output: 6},
+	},
+	{
+		IDs:           []string{ChatModelGPT4o, ChatModelGPT4o2024_11_20, ChatModelGPT4o2024_08_06},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 2.50, CachedInput: 1.25, Output: 10},
+	},
+	{
+		IDs:           []string{ChatModelGPT4o2024_05_13},
+		ContextWindow: 128_000, MaxOutputTokens: 4_096, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 5, Output: 15},
+	},
+	{
+		IDs:           []string{ChatModelChatgpt4oLatest},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 5, Output: 15},
+	},
+	{
+		IDs:           []string{ChatModelGPT4oMini, ChatModelGPT4oMini2024_07_18},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.15, CachedInput: 0.075, Output: 0.60},
+	},
+	{
+		// Audio tokens are priced separately from text tokens, which Usage
+		// doesn't distinguish, so the pricing is left unknown.
+		IDs: []string{
+			ChatModelGPT4oAudioPreview, ChatModelGPT4oAudioPreview2024_10_01,
+			ChatModelGPT4oAudioPreview2024_12_17, ChatModelGPT4oAudioPreview2025_06_03,
+		},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndAudio, Output: textAndAudio,
+	},
+	{
+		IDs: []string{
+			ChatModelGPT4Turbo, ChatModelGPT4Turbo2024_04_09, ChatModelGPT4TurboPreview,
+			ChatModelGPT4_0125Preview, ChatModelGPT4_1106Preview,
+		},
+		ContextWindow: 128_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 10, Output: 30},
+	},
+	{
+		IDs:           []string{ChatModelGPT4, ChatModelGPT4_0314, ChatModelGPT4_0613},
+		ContextWindow: 8_192, MaxOutputTokens: 8_192, Input: textOnly,
+		Pricing: &api.Pricing{Input: 30, Output: 60},
+	},
+	{
+		IDs:           []string{ChatModelGPT4_32k, ChatModelGPT4_32k0314, ChatModelGPT4_32k0613},
+		ContextWindow: 32_768, MaxOutputTokens: 32_768, Input: textOnly,
+		Pricing: &api.Pricing{Input: 60, Output: 120},
+	},
+	{
+		IDs:           []string{ChatModelGPT3_5Turbo, ChatModelGPT3_5Turbo0125},
+		ContextWindow: 16_385, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 0.50, Output: 1.50},
+	},
+	{
+		IDs:           []string{ChatModelGPT3_5Turbo1106},
+		ContextWindow: 16_385, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1, Output: 2},
+	},
+})
+
+func init() {
+	api.RegisterModelCatalog(ProviderName, catalog)
+}
+
+// ModelInfo returns the capabilities, limits and price of the given OpenAI
+// language model. It returns false for models that are not in the catalog.
+func ModelInfo(modelID string) (api.ModelInfo, bool) {
+	return catalog(modelID)
+}
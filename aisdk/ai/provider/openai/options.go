@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"net/http"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// ModelOption is a function type that configures the models created by this
+// package.
+type ModelOption func(*modelOptions)
+
+// modelOptions contains the settings shared by all model types.
+type modelOptions struct {
+	client openai.Client
+}
+
+// WithClient returns a ModelOption that sets the client.
+//
+// Calls made through the ai package are retried by the ai package itself (see
+// ai.WithMaxRetries), so create the client with option.WithMaxRetries(0) to
+// avoid retrying every attempt again in the client.
+func WithClient(client openai.Client) ModelOption {
+	// TODO: Instead of only supporting a single client, we can "flatten"
+	// the options supported by the OpenAI SDK.
+	return func(o *modelOptions) {
+		o.client = client
+	}
+}
+
+func buildModelOptions(opts []ModelOption) modelOptions {
+	// Create options with default settings
+	options := modelOptions{
+		// Default client. Retries are left to the ai package.
+		client: openai.NewClient(option.WithMaxRetries(0)),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// headerOptions returns request options that add the given headers to a request.
+func headerOptions(headers http.Header) []option.RequestOption {
+	var opts []option.RequestOption
+	for key, values := range headers {
+		for _, value := range values {
+			opts = append(opts, option.WithHeaderAdd(key, value))
+		}
+	}
+	return opts
+}
@@ -0,0 +1,44 @@
+package openai
+
+import "go.jetify.com/ai/api"
+
+// Provider gives access to the OpenAI language, embedding and image models.
+type Provider struct {
+	opts []ModelOption
+}
+
+var _ api.Provider = &Provider{}
+
+// NewProvider creates a new OpenAI provider. The options are applied to every
+// model returned by the provider.
+func NewProvider(opts ...ModelOption) *Provider {
+	return &Provider{opts: opts}
+}
+
+// LanguageModel returns the language model with the given ID. Any model ID is
+// accepted, including fine-tuned models, since OpenAI releases new models
+// frequently. It returns a NoSuchModelError if the ID is empty.
+func (p *Provider) LanguageModel(modelID string) (api.LanguageModel, error) {
+	if modelID == "" {
+		return nil, api.NewNoSuchModelError(modelID, api.LanguageModelType)
+	}
+	return NewLanguageModel(modelID, p.opts...), nil
+}
+
+// TextEmbeddingModel returns the embedding model with the given ID. It returns
+// a NoSuchModelError if the ID is empty.
+func (p *Provider) TextEmbeddingModel(modelID string) (api.EmbeddingModel[string], error) {
+	if modelID == "" {
+		return nil, api.NewNoSuchModelError(modelID, api.TextEmbeddingModelType)
+	}
+	return NewEmbeddingModel(modelID, p.opts...), nil
+}
+
+// ImageModel returns the image model with the given ID. It returns a
+// NoSuchModelError if the ID is empty.
+func (p *Provider) ImageModel(modelID string) (api.ImageModel, error) {
+	if modelID == "" {
+		return nil, api.NewNoSuchModelError(modelID, api.ImageModelType)
+	}
+	return NewImageModel(modelID, p.opts...), nil
+}
@@ -0,0 +1,29 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestProvider(t *testing.T) {
+	provider := NewProvider()
+
+	languageModel, err := provider.LanguageModel(ChatModelGPT5)
+	require.NoError(t, err)
+	assert.Equal(t, ChatModelGPT5, languageModel.ModelID())
+
+	embeddingModel, err := provider.TextEmbeddingModel(EmbeddingModelTextEmbedding3Small)
+	require.NoError(t, err)
+	assert.Equal(t, EmbeddingModelTextEmbedding3Small, embeddingModel.ModelID())
+
+	imageModel, err := provider.ImageModel(ImageModelGPTImage1)
+	require.NoError(t, err)
+	assert.Equal(t, ImageModelGPTImage1, imageModel.ModelID())
+
+	var noSuchModel *api.NoSuchModelError
+	_, err = provider.LanguageModel("")
+	require.ErrorAs(t, err, &noSuchModel)
+}
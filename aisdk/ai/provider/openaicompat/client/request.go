@@ -0,0 +1,172 @@
+// Package client contains the request and response types of the OpenAI Chat
+// Completions API, as implemented by OpenAI-compatible servers.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Role constants for message types
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Content part type constants
+const (
+	ContentTypeText       = "text"
+	ContentTypeImageURL   = "image_url"
+	ContentTypeInputAudio = "input_audio"
+	ContentTypeFile       = "file"
+)
+
+// Request is the body of a chat completions request.
+type Request struct {
+	Model         string         `json:"model"`
+	Messages      []Message      `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	MaxTokens        int      `json:"max_tokens,omitempty"`
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             float64  `json:"top_p,omitempty"`
+	FrequencyPenalty float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64  `json:"presence_penalty,omitempty"`
+	Seed             int      `json:"seed,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+
+	ResponseFormat    *ResponseFormat `json:"response_format,omitempty"`
+	Tools             []Tool          `json:"tools,omitempty"`
+	ToolChoice        any             `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool           `json:"parallel_tool_calls,omitempty"`
+
+	LogitBias   map[int]float64 `json:"logit_bias,omitempty"`
+	Logprobs    bool            `json:"logprobs,omitempty"`
+	TopLogprobs int             `json:"top_logprobs,omitempty"`
+
+	User            string `json:"user,omitempty"`
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+
+	// Extra contains the fields added to the request by servers that extend
+	// the Chat Completions API, like OpenRouter. It must encode as a JSON
+	// object, whose fields are sent along with the ones above.
+	Extra any `json:"-"`
+}
+
+// MarshalJSON encodes the request, adding the fields of Extra to the
+// top-level object.
+func (r *Request) MarshalJSON() ([]byte, error) {
+	type request Request
+	data, err := json.Marshal((*request)(r))
+	if err != nil || r.Extra == nil {
+		return data, err
+	}
+
+	extra, err := json.Marshal(r.Extra)
+	if err != nil {
+		return nil, err
+	}
+	extra = bytes.TrimSpace(extra)
+	if len(extra) < 2 || extra[0] != '{' {
+		return nil, fmt.Errorf("extra request fields must be a JSON object, got %s", extra)
+	}
+	if bytes.Equal(extra, []byte("{}")) {
+		return data, nil
+	}
+
+	// Both values are JSON objects, so they are merged by replacing the
+	// closing brace of the request with the fields of the extra object.
+	merged := append(data[:len(data)-1], ',')
+	return append(merged, extra[1:]...), nil
+}
+
+// StreamOptions configures a streaming request.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// Message is a message of the prompt. Content is either a string, a slice of
+// ContentParts, or nil for assistant messages that only contain tool calls.
+//
+// Reasoning is only set for servers that accept the reasoning of previous
+// assistant messages, like OpenRouter.
+type Message struct {
+	Role       string     `json:"role"`
+	Content    any        `json:"content"`
+	Reasoning  string     `json:"reasoning,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// ContentPart is a part of the content of a user message.
+type ContentPart struct {
+	Type       string      `json:"type"`
+	Text       string      `json:"text,omitempty"`
+	ImageURL   *ImageURL   `json:"image_url,omitempty"`
+	InputAudio *InputAudio `json:"input_audio,omitempty"`
+	File       *File       `json:"file,omitempty"`
+}
+
+// ImageURL is the URL of an image, which can be a data URL.
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// InputAudio is base64-encoded audio data.
+type InputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+// File is a file sent inline as a data URL.
+type File struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data"`
+}
+
+// ToolCall is a tool call requested by the model.
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"` // always "function"
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function called by a tool call. Arguments is a JSON
+// object encoded as a string.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Tool is a tool that the model may call.
+type Tool struct {
+	Type     string       `json:"type"` // always "function"
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction describes a function tool.
+type ToolFunction struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Parameters  *jsonschema.Schema `json:"parameters,omitempty"`
+}
+
+// ResponseFormat requests JSON output, optionally following a schema.
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is the schema of a json_schema response format.
+type JSONSchema struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema,omitempty"`
+	Strict      bool               `json:"strict,omitempty"`
+}
@@ -0,0 +1,112 @@
+package client
+
+// Finish reasons returned by the API.
+const (
+	FinishReasonStop          = "stop"
+	FinishReasonLength        = "length"
+	FinishReasonContentFilter = "content_filter"
+	FinishReasonToolCalls     = "tool_calls"
+	FinishReasonFunctionCall  = "function_call"
+)
+
+// Response is the body of a chat completions response.
+type Response struct {
+	ID                string   `json:"id"`
+	Created           int64    `json:"created"`
+	Model             string   `json:"model"`
+	Choices           []Choice `json:"choices"`
+	Usage             *Usage   `json:"usage,omitempty"`
+	SystemFingerprint string   `json:"system_fingerprint,omitempty"`
+}
+
+// Choice is a completion choice.
+type Choice struct {
+	Index        int             `json:"index"`
+	Message      ResponseMessage `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// ResponseMessage is the message generated by the model.
+//
+// Servers that expose the reasoning of the model use either the
+// reasoning_content field (e.g. vLLM, llama.cpp and DeepSeek) or the
+// reasoning field (e.g. Ollama).
+type ResponseMessage struct {
+	Role             string     `json:"role"`
+	Content          string     `json:"content"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	Reasoning        string     `json:"reasoning,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Usage contains the token usage of a request.
+type Usage struct {
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	TotalTokens             int                      `json:"total_tokens"`
+	PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the prompt tokens.
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// CompletionTokensDetails breaks down the completion tokens.
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// Chunk is a chunk of a streaming chat completions response.
+type Chunk struct {
+	ID                string        `json:"id"`
+	Created           int64         `json:"created"`
+	Model             string        `json:"model"`
+	Choices           []ChunkChoice `json:"choices"`
+	Usage             *Usage        `json:"usage,omitempty"`
+	SystemFingerprint string        `json:"system_fingerprint,omitempty"`
+
+	// Error is set by servers that report errors inside the stream.
+	Error *Error `json:"error,omitempty"`
+}
+
+// ChunkChoice is a choice of a streaming chunk.
+type ChunkChoice struct {
+	Index        int    `json:"index"`
+	Delta        Delta  `json:"delta"`
+	FinishReason string `json:"finish_reason"`
+}
+
+// Delta is the part of the message generated since the previous chunk.
+type Delta struct {
+	Role             string          `json:"role,omitempty"`
+	Content          string          `json:"content,omitempty"`
+	ReasoningContent string          `json:"reasoning_content,omitempty"`
+	Reasoning        string          `json:"reasoning,omitempty"`
+	ToolCalls        []ToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// ToolCallDelta is a part of a tool call. The ID and function name are only
+// sent in the first delta of each tool call.
+type ToolCallDelta struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// Error is an error returned by the API.
+type Error struct {
+	Message string `json:"message"`
+	Type    string `json:"type,omitempty"`
+	Param   any    `json:"param,omitempty"`
+
+	// Code is usually the HTTP status code of the error, but some servers
+	// use a string code instead.
+	Code any `json:"code,omitempty"`
+
+	// Metadata contains additional details about the error, e.g. the reasons
+	// for which OpenRouter flagged a prompt.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+)
+
+// MetadataDecoder decodes the provider metadata of servers that extend the
+// Chat Completions responses with their own fields, like OpenRouter. A new
+// decoder is used for every response.
+type MetadataDecoder interface {
+	// Decode is called with the body of a response, or with the data of
+	// every chunk of a streaming response.
+	Decode(data json.RawMessage) error
+
+	// ProviderMetadata returns the metadata decoded so far. It is set on the
+	// response, or on the finish event of a stream.
+	ProviderMetadata() *api.ProviderMetadata
+}
+
+// DecodeResponse converts the body of a chat completions response into an AI
+// SDK response. The provider metadata is decoded by the given decoder, if it
+// is not nil.
+func DecodeResponse(body []byte, metadata MetadataDecoder) (*api.Response, error) {
+	var response client.Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, api.NewJSONParseError(string(body), err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, api.NewNoContentGeneratedError("no choices in response")
+	}
+	choice := response.Choices[0]
+
+	var content []api.ContentBlock
+	if reasoning := reasoningText(choice.Message.ReasoningContent, choice.Message.Reasoning); reasoning != "" {
+		content = append(content, &api.ReasoningBlock{Text: reasoning})
+	}
+	if choice.Message.Content != "" {
+		content = append(content, &api.TextBlock{Text: choice.Message.Content})
+	}
+	for _, toolCall := range choice.Message.ToolCalls {
+		content = append(content, &api.ToolCallBlock{
+			ToolCallID: toolCall.ID,
+			ToolName:   toolCall.Function.Name,
+			Args:       decodeToolCallArgs(toolCall.Function.Arguments),
+		})
+	}
+
+	result := &api.Response{
+		Content:      content,
+		FinishReason: DecodeFinishReason(choice.FinishReason),
+		Usage:        decodeUsage(response.Usage),
+		ResponseInfo: &api.ResponseInfo{
+			ID:        response.ID,
+			Timestamp: decodeTimestamp(response.Created),
+			ModelID:   response.Model,
+		},
+	}
+	if metadata != nil {
+		if err := metadata.Decode(body); err != nil {
+			return nil, err
+		}
+		result.ProviderMetadata = metadata.ProviderMetadata()
+	}
+	return result, nil
+}
+
+// reasoningText returns the reasoning of a message or delta, which servers
+// send either as reasoning_content or as reasoning.
+func reasoningText(reasoningContent, reasoning string) string {
+	if reasoningContent != "" {
+		return reasoningContent
+	}
+	return reasoning
+}
+
+func decodeUsage(usage *client.Usage) api.Usage {
+	if usage == nil {
+		return api.Usage{}
+	}
+
+	result := api.Usage{
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+		TotalTokens:  usage.TotalTokens,
+	}
+	if result.TotalTokens == 0 {
+		result.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if usage.PromptTokensDetails != nil {
+		result.CachedInputTokens = usage.PromptTokensDetails.CachedTokens
+	}
+	if usage.CompletionTokensDetails != nil {
+		result.ReasoningTokens = usage.CompletionTokensDetails.ReasoningTokens
+	}
+	return result
+}
+
+// decodeToolCallArgs returns the arguments of a tool call as JSON. Models
+// sometimes return empty arguments for tools without parameters.
+func decodeToolCallArgs(args string) json.RawMessage {
+	if args == "" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(args)
+}
+
+func decodeTimestamp(created int64) time.Time {
+	if created == 0 {
+		return time.Time{}
+	}
+	return time.Unix(created, 0).UTC()
+}
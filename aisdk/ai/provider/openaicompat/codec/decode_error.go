@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+)
+
+// errorData matches the JSON structure of OpenAI-style error responses.
+type errorData struct {
+	Error client.Error `json:"error"`
+}
+
+// parseErrorJSON attempts to unmarshal the body into errorData.
+func parseErrorJSON(body []byte) (*errorData, error) {
+	var parsed errorData
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, api.NewJSONParseError(string(body), err)
+	}
+	return &parsed, nil
+}
+
+// DecodeErrorResponse constructs an APICallError from a non-2xx response.
+// Servers that don't return OpenAI-style errors, e.g. ones that return a
+// plain text body, produce an error with the HTTP status as message.
+func DecodeErrorResponse(resp *http.Response, rawBody []byte) error {
+	parsed, err := parseErrorJSON(rawBody)
+	if err == nil && parsed.Error.Message != "" {
+		callErr := api.NewAPICallError(parsed.Error.Message, resp.Request, resp, nil)
+		callErr.Data = parsed
+		return callErr
+	}
+
+	// Fallback if we cannot parse the error JSON
+	return api.NewAPICallError(fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)), resp.Request, resp, err)
+}
+
+// decodeStreamError constructs an APICallError from an error sent after the
+// stream has started.
+func decodeStreamError(streamErr *client.Error) error {
+	callErr := api.NewAPICallError(streamErr.Message, nil, nil, nil)
+	if code, ok := streamErr.Code.(float64); ok {
+		callErr.StatusCode = int(code)
+	}
+	callErr.Data = &errorData{Error: *streamErr}
+	return callErr
+}
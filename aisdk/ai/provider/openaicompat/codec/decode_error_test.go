@@ -1,4 +1,4 @@
-package openrouter
+package codec
 
 import (
 	"bytes"
@@ -10,13 +10,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
 )
 
-func TestParseOpenRouterErrorJSON(t *testing.T) {
+func TestParseErrorJSON(t *testing.T) {
 	tests := []struct {
 		name    string
 		json    string
-		want    *openRouterErrorData
+		want    *errorData
 		wantErr bool
 	}{
 		{
@@ -29,17 +30,12 @@ func TestParseOpenRouterErrorJSON(t *testing.T) {
 					"code": "model_not_found"
 				}
 			}`,
-			want: &openRouterErrorData{
-				Error: struct {
-					Message string  `json:"message"`
-					Type    string  `json:"type"`
-					Param   any     `json:"param"`
-					Code    *string `json:"code"`
-				}{
+			want: &errorData{
+				Error: client.Error{
 					Message: "invalid request",
 					Type:    "invalid_request_error",
 					Param:   "model",
-					Code:    strPtr("model_not_found"),
+					Code:    "model_not_found",
 				},
 			},
 		},
@@ -53,17 +49,27 @@ func TestParseOpenRouterErrorJSON(t *testing.T) {
 					"code": null
 				}
 			}`,
-			want: &openRouterErrorData{
-				Error: struct {
-					Message string  `json:"message"`
-					Type    string  `json:"type"`
-					Param   any     `json:"param"`
-					Code    *string `json:"code"`
-				}{
+			want: &errorData{
+				Error: client.Error{
 					Message: "rate limited",
 					Type:    "rate_limit_error",
-					Param:   nil,
-					Code:    nil,
+				},
+			},
+		},
+		{
+			name: "numeric code and metadata",
+			json: `{
+				"error": {
+					"code": 403,
+					"message": "Input flagged",
+					"metadata": {"reasons": ["violence"]}
+				}
+			}`,
+			want: &errorData{
+				Error: client.Error{
+					Message:  "Input flagged",
+					Code:     float64(403),
+					Metadata: map[string]any{"reasons": []any{"violence"}},
 				},
 			},
 		},
@@ -75,13 +81,13 @@ func TestParseOpenRouterErrorJSON(t *testing.T) {
 		{
 			name: "empty json",
 			json: `{}`,
-			want: &openRouterErrorData{},
+			want: &errorData{},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseOpenRouterErrorJSON([]byte(tt.json))
+			got, err := parseErrorJSON([]byte(tt.json))
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -92,7 +98,7 @@ func TestParseOpenRouterErrorJSON(t *testing.T) {
 	}
 }
 
-func TestOpenRouterFailedResponseHandler(t *testing.T) {
+func TestDecodeErrorResponse(t *testing.T) {
 	tests := []struct {
 		name        string
 		statusCode  int
@@ -142,7 +148,7 @@ func TestOpenRouterFailedResponseHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a mock response
-			url := &url.URL{Scheme: "https", Host: "api.openrouter.ai", Path: "/api/v1/chat/completions"}
+			url := &url.URL{Scheme: "http", Host: "localhost:8000", Path: "/v1/chat/completions"}
 			req := &http.Request{URL: url}
 			resp := &http.Response{
 				StatusCode: tt.statusCode,
@@ -152,7 +158,7 @@ func TestOpenRouterFailedResponseHandler(t *testing.T) {
 			}
 
 			// Call the handler
-			err := OpenRouterFailedResponseHandler(resp, []byte(tt.body), nil)
+			err := DecodeErrorResponse(resp, []byte(tt.body))
 
 			// Use errors.As instead of type assertion
 			var apiErr *api.APICallError
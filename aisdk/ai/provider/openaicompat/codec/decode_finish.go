@@ -2,11 +2,11 @@ package codec
 
 import (
 	"go.jetify.com/ai/api"
-	"go.jetify.com/ai/provider/internal/openrouter/client"
+	"go.jetify.com/ai/provider/openaicompat/client"
 )
 
-// DecodeFinishReason converts an OpenRouter finish reason to an AI SDK FinishReason type.
-// It handles nil/empty values by returning FinishReasonUnknown.
+// DecodeFinishReason converts a chat completions finish reason to an AI SDK
+// FinishReason. It returns FinishReasonUnknown for empty or unknown values.
 func DecodeFinishReason(finishReason string) api.FinishReason {
 	switch finishReason {
 	case client.FinishReasonStop:
@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	"go.jetify.com/ai/api"
-	"go.jetify.com/ai/provider/internal/openrouter/client"
+	"go.jetify.com/ai/provider/openaicompat/client"
 )
 
 func TestDecodeFinishReason(t *testing.T) {
@@ -0,0 +1,214 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+	"go.jetify.com/sse"
+)
+
+// DecodeStreamOptions configures DecodeStream.
+type DecodeStreamOptions struct {
+	// Warnings are sent in the StreamStartEvent that starts the stream.
+	Warnings []api.CallWarning
+
+	// IncludeRawChunks sends a RawChunkEvent with the data of every chunk,
+	// before the events decoded from it.
+	IncludeRawChunks bool
+
+	// Metadata decodes the provider metadata sent in the FinishEvent. No
+	// metadata is sent if it is nil.
+	Metadata MetadataDecoder
+}
+
+// DecodeStream converts the server-sent events of a streaming chat
+// completions response into AI SDK stream events. The body is closed once the
+// stream has been consumed.
+//
+// The stream starts with a StreamStartEvent carrying the given warnings.
+func DecodeStream(body io.ReadCloser, opts DecodeStreamOptions) iter.Seq[api.StreamEvent] {
+	return func(yield func(api.StreamEvent) bool) {
+		defer func() { _ = body.Close() }()
+
+		if !yield(&api.StreamStartEvent{Warnings: opts.Warnings}) {
+			return
+		}
+
+		decoder := &streamDecoder{metadata: opts.Metadata}
+		events := sse.NewDecoder(body)
+		for {
+			var event sse.Event
+			err := events.Decode(&event)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				yield(&api.ErrorEvent{Err: err})
+				return
+			}
+
+			if raw, ok := event.Data.(sse.Raw); ok && string(raw) == "[DONE]" {
+				break
+			}
+
+			raw, err := chunkData(event.Data)
+			if err != nil {
+				yield(&api.ErrorEvent{Err: err})
+				return
+			}
+			if len(raw) == 0 {
+				continue
+			}
+			if opts.IncludeRawChunks && !yield(&api.RawChunkEvent{Data: raw}) {
+				return
+			}
+
+			chunk, err := decodeChunk(raw)
+			if err != nil {
+				yield(&api.ErrorEvent{Err: err})
+				return
+			}
+			if chunk.Error != nil {
+				yield(&api.ErrorEvent{Err: decodeStreamError(chunk.Error)})
+				return
+			}
+			if opts.Metadata != nil {
+				if err := opts.Metadata.Decode(raw); err != nil {
+					yield(&api.ErrorEvent{Err: err})
+					return
+				}
+			}
+			if !decoder.decodeChunk(chunk, yield) {
+				return
+			}
+		}
+
+		decoder.finish(yield)
+	}
+}
+
+// chunkData returns the data of an event as JSON. It returns nil for events
+// without data. The SSE decoder parses JSON data, so the returned JSON is
+// equivalent to the data that was sent, but its formatting may differ.
+func chunkData(data any) (json.RawMessage, error) {
+	switch data := data.(type) {
+	case nil:
+		return nil, nil
+	case sse.Raw:
+		return json.RawMessage(data), nil
+	default:
+		return json.Marshal(data)
+	}
+}
+
+// decodeChunk converts the data of an event into a chunk.
+func decodeChunk(raw json.RawMessage) (*client.Chunk, error) {
+	var chunk client.Chunk
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return nil, api.NewJSONParseError(string(raw), err)
+	}
+	return &chunk, nil
+}
+
+// streamDecoder accumulates the state of a stream across chunks.
+type streamDecoder struct {
+	sentMetadata bool
+	toolCalls    []*client.ToolCall
+	finishReason string
+	usage        *client.Usage
+	metadata     MetadataDecoder
+}
+
+func (d *streamDecoder) decodeChunk(chunk *client.Chunk, yield func(api.StreamEvent) bool) bool {
+	if !d.sentMetadata && chunk.ID != "" {
+		d.sentMetadata = true
+		if !yield(&api.ResponseMetadataEvent{
+			ID:        chunk.ID,
+			Timestamp: decodeTimestamp(chunk.Created),
+			ModelID:   chunk.Model,
+		}) {
+			return false
+		}
+	}
+	if chunk.Usage != nil {
+		d.usage = chunk.Usage
+	}
+
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		if !d.decodeDelta(choice.Delta, yield) {
+			return false
+		}
+		if choice.FinishReason != "" {
+			d.finishReason = choice.FinishReason
+		}
+	}
+	return true
+}
+
+func (d *streamDecoder) decodeDelta(delta client.Delta, yield func(api.StreamEvent) bool) bool {
+	if reasoning := reasoningText(delta.ReasoningContent, delta.Reasoning); reasoning != "" {
+		if !yield(&api.ReasoningEvent{TextDelta: reasoning}) {
+			return false
+		}
+	}
+	if delta.Content != "" {
+		if !yield(&api.TextDeltaEvent{TextDelta: delta.Content}) {
+			return false
+		}
+	}
+
+	for _, toolCallDelta := range delta.ToolCalls {
+		for len(d.toolCalls) <= toolCallDelta.Index {
+			d.toolCalls = append(d.toolCalls, &client.ToolCall{Type: "function"})
+		}
+		toolCall := d.toolCalls[toolCallDelta.Index]
+		if toolCallDelta.ID != "" {
+			toolCall.ID = toolCallDelta.ID
+		}
+		if toolCallDelta.Function.Name != "" {
+			toolCall.Function.Name = toolCallDelta.Function.Name
+		}
+		if toolCallDelta.Function.Arguments == "" {
+			continue
+		}
+
+		toolCall.Function.Arguments += toolCallDelta.Function.Arguments
+		if !yield(&api.ToolCallDeltaEvent{
+			ToolCallID: toolCall.ID,
+			ToolName:   toolCall.Function.Name,
+			ArgsDelta:  []byte(toolCallDelta.Function.Arguments),
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// finish emits the completed tool calls and the finish event.
+func (d *streamDecoder) finish(yield func(api.StreamEvent) bool) {
+	for _, toolCall := range d.toolCalls {
+		if !yield(&api.ToolCallEvent{
+			ToolCallID: toolCall.ID,
+			ToolName:   toolCall.Function.Name,
+			Args:       decodeToolCallArgs(toolCall.Function.Arguments),
+		}) {
+			return
+		}
+	}
+
+	finish := &api.FinishEvent{
+		FinishReason: DecodeFinishReason(d.finishReason),
+		Usage:        decodeUsage(d.usage),
+	}
+	if d.metadata != nil {
+		finish.ProviderMetadata = d.metadata.ProviderMetadata()
+	}
+	yield(finish)
+}
@@ -0,0 +1,123 @@
+package codec
+
+import (
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+)
+
+// Encode converts an AI SDK prompt and call options into a chat completions
+// request. It returns warnings for settings that are not supported.
+func Encode(modelID string, prompt []api.Message, opts api.CallOptions) (*client.Request, []api.CallWarning, error) {
+	messages, err := EncodePrompt(prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := &client.Request{
+		Model:            modelID,
+		Messages:         messages,
+		MaxTokens:        opts.MaxOutputTokens,
+		Temperature:      opts.Temperature,
+		TopP:             opts.TopP,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		PresencePenalty:  opts.PresencePenalty,
+		Seed:             opts.Seed,
+		Stop:             opts.StopSequences,
+	}
+
+	var warnings []api.CallWarning
+	if opts.TopK != 0 {
+		warnings = append(warnings, api.CallWarning{
+			Type:    "unsupported-setting",
+			Setting: "TopK",
+		})
+	}
+
+	tools, toolWarnings := encodeTools(opts.Tools)
+	warnings = append(warnings, toolWarnings...)
+	req.Tools = tools
+	req.ToolChoice = encodeToolChoice(opts.ToolChoice)
+	req.ResponseFormat = encodeResponseFormat(opts.ResponseFormat)
+
+	// Whether the model supports reasoning is only known to the server, so the
+	// effort is sent as is.
+	if opts.Reasoning != nil {
+		req.ReasoningEffort = string(opts.Reasoning.ResolvedEffort())
+	}
+
+	if metadata := GetMetadata(&opts); metadata != nil {
+		req.User = metadata.User
+		req.ParallelToolCalls = metadata.ParallelToolCalls
+		if metadata.ReasoningEffort != "" {
+			req.ReasoningEffort = metadata.ReasoningEffort
+		}
+	}
+
+	return req, warnings, nil
+}
+
+func encodeTools(tools []api.ToolDefinition) ([]client.Tool, []api.CallWarning) {
+	var encoded []client.Tool
+	var warnings []api.CallWarning
+	for _, tool := range tools {
+		functionTool, ok := tool.(*api.FunctionTool)
+		if !ok {
+			warnings = append(warnings, api.CallWarning{
+				Type: "unsupported-tool",
+				Tool: tool,
+			})
+			continue
+		}
+		encoded = append(encoded, client.Tool{
+			Type: "function",
+			Function: client.ToolFunction{
+				Name:        functionTool.Name,
+				Description: functionTool.Description,
+				Parameters:  functionTool.InputSchema,
+			},
+		})
+	}
+	return encoded, warnings
+}
+
+func encodeToolChoice(choice *api.ToolChoice) any {
+	if choice == nil {
+		return nil
+	}
+	switch choice.Type {
+	case "auto", "none", "required":
+		return choice.Type
+	case "tool":
+		return map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name": choice.ToolName,
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+func encodeResponseFormat(format *api.ResponseFormat) *client.ResponseFormat {
+	if format == nil || format.Type != "json" {
+		return nil
+	}
+	if format.Schema == nil {
+		return &client.ResponseFormat{Type: "json_object"}
+	}
+
+	name := format.Name
+	if name == "" {
+		name = "response"
+	}
+	return &client.ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &client.JSONSchema{
+			Name:        name,
+			Description: format.Description,
+			Schema:      format.Schema,
+			Strict:      true,
+		},
+	}
+}
@@ -0,0 +1,211 @@
+package codec
+
+// Functions that convert AI SDK prompts to chat completions messages.
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+)
+
+// EncodePrompt converts an AI SDK prompt into chat completions messages.
+func EncodePrompt(prompt []api.Message) ([]client.Message, error) {
+	// Pre-allocate with extra space for potential tool message expansion
+	messages := make([]client.Message, 0, len(prompt)*2)
+
+	for _, msg := range prompt {
+		encoded, err := encodeMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, encoded...)
+	}
+
+	return messages, nil
+}
+
+func encodeMessage(msg api.Message) ([]client.Message, error) {
+	switch msg := msg.(type) {
+	case *api.SystemMessage:
+		return []client.Message{{Role: client.RoleSystem, Content: msg.Content}}, nil
+	case *api.UserMessage:
+		encoded, err := encodeUserMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		return []client.Message{encoded}, nil
+	case *api.AssistantMessage:
+		encoded, err := encodeAssistantMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		return []client.Message{encoded}, nil
+	case *api.ToolMessage:
+		return encodeToolMessage(msg)
+	default:
+		return nil, fmt.Errorf("unsupported message type: %T", msg)
+	}
+}
+
+func encodeUserMessage(msg *api.UserMessage) (client.Message, error) {
+	// A single text block is sent as a plain string, which is supported by
+	// every server, including the ones that don't accept content parts.
+	if len(msg.Content) == 1 {
+		if textBlock, ok := msg.Content[0].(*api.TextBlock); ok {
+			return client.Message{Role: client.RoleUser, Content: textBlock.Text}, nil
+		}
+	}
+
+	parts := make([]client.ContentPart, 0, len(msg.Content))
+	for _, block := range msg.Content {
+		part, err := encodeUserContentBlock(block)
+		if err != nil {
+			return client.Message{}, err
+		}
+		parts = append(parts, part)
+	}
+	return client.Message{Role: client.RoleUser, Content: parts}, nil
+}
+
+func encodeUserContentBlock(block api.ContentBlock) (client.ContentPart, error) {
+	switch block := block.(type) {
+	case *api.TextBlock:
+		return client.ContentPart{Type: client.ContentTypeText, Text: block.Text}, nil
+	case *api.ImageBlock:
+		return encodeImageBlock(block), nil
+	case *api.FileBlock:
+		return encodeFileBlock(block)
+	default:
+		return client.ContentPart{}, fmt.Errorf("unsupported content block type: %T", block)
+	}
+}
+
+func encodeImageBlock(block *api.ImageBlock) client.ContentPart {
+	url := block.URL
+	if url == "" {
+		mediaType := block.MediaType
+		if mediaType == "" {
+			mediaType = "image/jpeg" // Default to JPEG if no media type is specified
+		}
+		url = dataURL(mediaType, block.Data)
+	}
+	return client.ContentPart{
+		Type:     client.ContentTypeImageURL,
+		ImageURL: &client.ImageURL{URL: url},
+	}
+}
+
+func encodeFileBlock(block *api.FileBlock) (client.ContentPart, error) {
+	if strings.HasPrefix(block.MediaType, "image/") {
+		return encodeImageBlock(&api.ImageBlock{
+			URL:       block.URL,
+			Data:      block.Data,
+			MediaType: block.MediaType,
+		}), nil
+	}
+	if block.URL != "" {
+		return client.ContentPart{}, api.NewUnsupportedFunctionalityError("file URLs", "")
+	}
+
+	switch block.MediaType {
+	case "audio/wav", "audio/x-wav":
+		return encodeAudio(block.Data, "wav"), nil
+	case "audio/mp3", "audio/mpeg":
+		return encodeAudio(block.Data, "mp3"), nil
+	case "application/pdf":
+		filename := block.Filename
+		if filename == "" {
+			filename = "document.pdf"
+		}
+		return client.ContentPart{
+			Type: client.ContentTypeFile,
+			File: &client.File{
+				Filename: filename,
+				FileData: dataURL(block.MediaType, block.Data),
+			},
+		}, nil
+	}
+	if strings.HasPrefix(block.MediaType, "text/") {
+		return client.ContentPart{Type: client.ContentTypeText, Text: string(block.Data)}, nil
+	}
+	return client.ContentPart{}, api.NewUnsupportedFunctionalityError(
+		fmt.Sprintf("files of type %q", block.MediaType), "")
+}
+
+func encodeAudio(data []byte, format string) client.ContentPart {
+	return client.ContentPart{
+		Type: client.ContentTypeInputAudio,
+		InputAudio: &client.InputAudio{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	}
+}
+
+func dataURL(mediaType string, data []byte) string {
+	return fmt.Sprintf("data:%s;base64,%s", mediaType, base64.StdEncoding.EncodeToString(data))
+}
+
+// encodeAssistantMessage concatenates the text of the message and collects its
+// tool calls. Reasoning is not sent back to the model: most servers reject or
+// ignore it in requests.
+func encodeAssistantMessage(msg *api.AssistantMessage) (client.Message, error) {
+	var text strings.Builder
+	var toolCalls []client.ToolCall
+	for _, block := range msg.Content {
+		switch block := block.(type) {
+		case *api.TextBlock:
+			text.WriteString(block.Text)
+		case *api.ReasoningBlock:
+			// Skipped, see above.
+		case *api.ToolCallBlock:
+			args, err := json.Marshal(block.Args)
+			if err != nil {
+				return client.Message{}, fmt.Errorf("failed to marshal tool call args: %w", err)
+			}
+			toolCalls = append(toolCalls, client.ToolCall{
+				ID:   block.ToolCallID,
+				Type: "function",
+				Function: client.FunctionCall{
+					Name:      block.ToolName,
+					Arguments: string(args),
+				},
+			})
+		default:
+			return client.Message{}, fmt.Errorf("unsupported assistant content block type: %T", block)
+		}
+	}
+
+	encoded := client.Message{Role: client.RoleAssistant, ToolCalls: toolCalls}
+	// Messages that only contain tool calls are sent with a null content.
+	if text.Len() > 0 || len(toolCalls) == 0 {
+		encoded.Content = text.String()
+	}
+	return encoded, nil
+}
+
+// encodeToolMessage converts each tool result into a separate tool message.
+// String results are sent as they are, other results are encoded as JSON.
+func encodeToolMessage(msg *api.ToolMessage) ([]client.Message, error) {
+	messages := make([]client.Message, 0, len(msg.Content))
+	for _, result := range msg.Content {
+		content, ok := result.Result.(string)
+		if !ok {
+			resultJSON, err := json.Marshal(result.Result)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal tool result: %w", err)
+			}
+			content = string(resultJSON)
+		}
+		messages = append(messages, client.Message{
+			Role:       client.RoleTool,
+			Content:    content,
+			ToolCallID: result.ToolCallID,
+		})
+	}
+	return messages, nil
+}
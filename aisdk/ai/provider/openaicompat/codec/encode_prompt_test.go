@@ -0,0 +1,147 @@
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/aitesting"
+	"go.jetify.com/ai/api"
+)
+
+func TestEncodePrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   []api.Message
+		expected string // JSON string of expected output
+	}{
+		{
+			name: "system and user text",
+			prompt: []api.Message{
+				&api.SystemMessage{Content: "be brief"},
+				&api.UserMessage{Content: api.ContentFromText("hello")},
+			},
+			expected: `[
+				{"role":"system","content":"be brief"},
+				{"role":"user","content":"hello"}
+			]`,
+		},
+		{
+			name: "user message with media",
+			prompt: []api.Message{
+				&api.UserMessage{
+					Content: []api.ContentBlock{
+						&api.TextBlock{Text: "describe"},
+						&api.ImageBlock{Data: []byte{0, 1, 2, 3}, MediaType: "image/png"},
+						&api.ImageBlock{URL: "https://example.com/cat.jpg"},
+						&api.FileBlock{Data: []byte{0, 1, 2, 3}, MediaType: "audio/wav"},
+						&api.FileBlock{Data: []byte{0, 1, 2, 3}, MediaType: "application/pdf", Filename: "doc.pdf"},
+						&api.FileBlock{Data: []byte("notes"), MediaType: "text/plain"},
+					},
+				},
+			},
+			expected: `[{"role":"user","content":[
+				{"type":"text","text":"describe"},
+				{"type":"image_url","image_url":{"url":"data:image/png;base64,AAECAw=="}},
+				{"type":"image_url","image_url":{"url":"https://example.com/cat.jpg"}},
+				{"type":"input_audio","input_audio":{"data":"AAECAw==","format":"wav"}},
+				{"type":"file","file":{"filename":"doc.pdf","file_data":"data:application/pdf;base64,AAECAw=="}},
+				{"type":"text","text":"notes"}
+			]}]`,
+		},
+		{
+			name: "assistant message with reasoning and tool calls",
+			prompt: []api.Message{
+				&api.AssistantMessage{
+					Content: []api.ContentBlock{
+						&api.ReasoningBlock{Text: "thinking"},
+						&api.TextBlock{Text: "Let me "},
+						&api.TextBlock{Text: "check."},
+						&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+					},
+				},
+			},
+			expected: `[{"role":"assistant","content":"Let me check.","tool_calls":[
+				{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Paris\"}"}}
+			]}]`,
+		},
+		{
+			name: "assistant message with only tool calls",
+			prompt: []api.Message{
+				&api.AssistantMessage{
+					Content: []api.ContentBlock{
+						&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "time", Args: json.RawMessage(`{}`)},
+					},
+				},
+			},
+			expected: `[{"role":"assistant","content":null,"tool_calls":[
+				{"id":"call_1","type":"function","function":{"name":"time","arguments":"{}"}}
+			]}]`,
+		},
+		{
+			name: "tool results",
+			prompt: []api.Message{
+				&api.ToolMessage{
+					Content: []api.ToolResultBlock{
+						{ToolCallID: "call_1", ToolName: "weather", Result: map[string]any{"temp": 20}},
+						{ToolCallID: "call_2", ToolName: "time", Result: "12:00"},
+					},
+				},
+			},
+			expected: `[
+				{"role":"tool","content":"{\"temp\":20}","tool_call_id":"call_1"},
+				{"role":"tool","content":"12:00","tool_call_id":"call_2"}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := EncodePrompt(tt.prompt)
+			require.NoError(t, err)
+
+			actual, err := json.Marshal(messages)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestEncodePrompt_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		prompt        []api.Message
+		expectedError string
+	}{
+		{
+			name: "file URL",
+			prompt: []api.Message{
+				&api.UserMessage{Content: []api.ContentBlock{api.FileBlockFromURL("https://example.com/doc.pdf")}},
+			},
+			expectedError: "file URLs",
+		},
+		{
+			name: "unsupported file type",
+			prompt: []api.Message{
+				&api.UserMessage{Content: []api.ContentBlock{&api.FileBlock{Data: []byte{0}, MediaType: "video/mp4"}}},
+			},
+			expectedError: "video/mp4",
+		},
+		{
+			name: "unsupported message type",
+			prompt: []api.Message{
+				&aitesting.MockUnsupportedMessage{},
+			},
+			expectedError: "unsupported message type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EncodePrompt(tt.prompt)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package codec
+
+import "go.jetify.com/ai/api"
+
+// ProviderName is the name used to store OpenAI-compatible metadata.
+const ProviderName = "openaicompat"
+
+// Metadata contains request options that are part of the Chat Completions API
+// but have no equivalent in the AI SDK call options. Servers ignore or reject
+// the options they don't support.
+type Metadata struct {
+	// User is a unique identifier representing the end-user.
+	User string `json:"user,omitempty"`
+
+	// ParallelToolCalls enables parallel function calling during tool use.
+	// When not specified (nil), the server default is used.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
+
+	// ReasoningEffort constrains the effort spent on reasoning by models that
+	// support it, e.g. "low", "medium" or "high".
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+}
+
+func GetMetadata(source api.MetadataSource) *Metadata {
+	return api.GetMetadata[Metadata](ProviderName, source)
+}
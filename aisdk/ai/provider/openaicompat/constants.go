@@ -0,0 +1,7 @@
+package openaicompat
+
+import "go.jetify.com/ai/provider/openaicompat/codec"
+
+// ProviderName is the default name of the provider, and the key under which
+// OpenAI-compatible metadata is stored.
+const ProviderName = codec.ProviderName
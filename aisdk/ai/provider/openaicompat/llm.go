@@ -0,0 +1,178 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+	"go.jetify.com/ai/provider/openaicompat/codec"
+)
+
+// LanguageModel represents a chat model served by an OpenAI-compatible server.
+type LanguageModel struct {
+	modelID  string
+	provider *Provider
+}
+
+var (
+	_ api.LanguageModel                = &LanguageModel{}
+	_ api.ObjectGenerationModeProvider = &LanguageModel{}
+)
+
+// NewLanguageModel creates a new OpenAI-compatible language model. The base
+// URL of the server must be set using WithBaseURL.
+//
+//	model := openaicompat.NewLanguageModel("llama3.2",
+//		openaicompat.WithName("ollama"),
+//		openaicompat.WithBaseURL("http://localhost:11434/v1"),
+//	)
+func NewLanguageModel(modelID string, opts ...ProviderOption) *LanguageModel {
+	return &LanguageModel{
+		modelID:  modelID,
+		provider: NewProvider(opts...),
+	}
+}
+
+func (m *LanguageModel) ProviderName() string {
+	return m.provider.name
+}
+
+func (m *LanguageModel) ModelID() string {
+	return m.modelID
+}
+
+// DefaultObjectGenerationMode returns the default mode for object generation.
+// JSON response formats are supported by the common OpenAI-compatible
+// servers, while tool calls depend on the chat template of the model.
+func (m *LanguageModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	return api.ObjectGenerationModeJSON
+}
+
+func (m *LanguageModel) SupportedUrls() []api.SupportedURL {
+	return []api.SupportedURL{
+		{
+			MediaType: "image/*",
+			URLPatterns: []string{
+				"^https?://.*",
+			},
+		},
+	}
+}
+
+func (m *LanguageModel) Generate(
+	ctx context.Context, prompt []api.Message, opts api.CallOptions,
+) (*api.Response, error) {
+	request, warnings, err := m.encode(prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBody, resp, err := m.post(ctx, request, opts.Headers)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, codec.DecodeErrorResponse(resp, body)
+	}
+
+	response, err := codec.DecodeResponse(body, m.newMetadataDecoder())
+	if err != nil {
+		return nil, err
+	}
+
+	response.RequestInfo = &api.RequestInfo{Body: requestBody}
+	response.ResponseInfo.Headers = resp.Header
+	response.ResponseInfo.Body = body
+	response.ResponseInfo.Status = resp.Status
+	response.ResponseInfo.StatusCode = resp.StatusCode
+	response.Warnings = append(response.Warnings, warnings...)
+	return response, nil
+}
+
+func (m *LanguageModel) Stream(
+	ctx context.Context, prompt []api.Message, opts api.CallOptions,
+) (*api.StreamResponse, error) {
+	request, warnings, err := m.encode(prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	request.StreamOptions = &client.StreamOptions{IncludeUsage: true}
+
+	requestBody, resp, err := m.post(ctx, request, opts.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("read response body: %w", err)
+		}
+		return nil, codec.DecodeErrorResponse(resp, body)
+	}
+
+	return &api.StreamResponse{
+		Stream: codec.DecodeStream(resp.Body, codec.DecodeStreamOptions{
+			Warnings:         warnings,
+			IncludeRawChunks: opts.IncludeRawChunks,
+			Metadata:         m.newMetadataDecoder(),
+		}),
+		RequestInfo: &api.RequestInfo{Body: requestBody},
+		ResponseInfo: &api.ResponseInfo{
+			Headers:    resp.Header,
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+		},
+	}, nil
+}
+
+// CountTokens estimates the input tokens of the prompt with the estimator set
+// by WithTokenEstimator, since the Chat Completions API has no token counting
+// endpoint. It can be used as an ai.TokenCounter.
+func (m *LanguageModel) CountTokens(ctx context.Context, prompt []api.Message) (int, error) {
+	return m.provider.tokenEstimator.Estimate(prompt), nil
+}
+
+// encode converts the prompt and call options into a request, using the
+// encoder of the provider extension if there is one.
+func (m *LanguageModel) encode(prompt []api.Message, opts api.CallOptions) (*client.Request, []api.CallWarning, error) {
+	if encode := m.provider.extension.Encode; encode != nil {
+		return encode(m.modelID, prompt, opts)
+	}
+	return codec.Encode(m.modelID, prompt, opts)
+}
+
+func (m *LanguageModel) newMetadataDecoder() codec.MetadataDecoder {
+	if newDecoder := m.provider.extension.NewMetadataDecoder; newDecoder != nil {
+		return newDecoder()
+	}
+	return nil
+}
+
+// post sends the request to the chat completions endpoint and returns the
+// encoded request body along with the response.
+func (m *LanguageModel) post(
+	ctx context.Context, request *client.Request, headers http.Header,
+) ([]byte, *http.Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal request body: %w", err)
+	}
+
+	resp, err := m.provider.doJSONRequest(ctx, "/chat/completions", body, headers)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp, nil
+}
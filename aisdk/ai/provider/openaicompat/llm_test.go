@@ -0,0 +1,520 @@
+package openaicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/aitesting"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/pkg/httpmock"
+	"go.jetify.com/pkg/pointer"
+	"go.jetify.com/sse"
+)
+
+const testModelID = "llama3.2"
+
+var standardPrompt = []api.Message{
+	&api.UserMessage{
+		Content: api.ContentFromText("Hello"),
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      api.CallOptions
+		exchange     httpmock.Exchange
+		wantErr      bool
+		expectedResp *api.Response
+	}{
+		{
+			name: "text response",
+			options: api.CallOptions{
+				MaxOutputTokens: 100,
+				Temperature:     pointer.Float64(0.5),
+				StopSequences:   []string{"END"},
+				Headers:         http.Header{"X-Request-Id": []string{"req-1"}},
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/chat/completions",
+					Headers: map[string]string{
+						"Authorization": "Bearer test-key",
+						"X-Custom":      "custom",
+						"X-Request-Id":  "req-1",
+					},
+					Body: `{
+						"model": "llama3.2",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"max_tokens": 100,
+						"temperature": 0.5,
+						"stop": ["END"]
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "chatcmpl-123",
+						"created": 1741257730,
+						"model": "llama3.2",
+						"choices": [{
+							"index": 0,
+							"message": {"role": "assistant", "content": "Hi there!"},
+							"finish_reason": "stop"
+						}],
+						"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content:      []api.ContentBlock{&api.TextBlock{Text: "Hi there!"}},
+				FinishReason: api.FinishReasonStop,
+				Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+				ResponseInfo: &api.ResponseInfo{
+					ID:        "chatcmpl-123",
+					ModelID:   "llama3.2",
+					Timestamp: time.Unix(1741257730, 0).UTC(),
+				},
+			},
+		},
+		{
+			name: "reasoning and tool calls",
+			options: api.CallOptions{
+				Tools: []api.ToolDefinition{
+					&api.FunctionTool{
+						Name:        "weather",
+						Description: "Get the weather",
+						InputSchema: &jsonschema.Schema{Type: "object"},
+					},
+				},
+				ToolChoice: &api.ToolChoice{Type: "required"},
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					ProviderName: &Metadata{ParallelToolCalls: pointer.Bool(false), ReasoningEffort: "low"},
+				}),
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/chat/completions",
+					Body: `{
+						"model": "llama3.2",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"tools": [{
+							"type": "function",
+							"function": {"name": "weather", "description": "Get the weather", "parameters": {"type": "object"}}
+						}],
+						"tool_choice": "required",
+						"parallel_tool_calls": false,
+						"reasoning_effort": "low"
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "chatcmpl-456",
+						"model": "llama3.2",
+						"choices": [{
+							"index": 0,
+							"message": {
+								"role": "assistant",
+								"content": null,
+								"reasoning_content": "The user wants the weather.",
+								"tool_calls": [
+									{"id": "call_1", "type": "function", "function": {"name": "weather", "arguments": "{\"location\":\"Paris\"}"}}
+								]
+							},
+							"finish_reason": "tool_calls"
+						}]
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.ReasoningBlock{Text: "The user wants the weather."},
+					&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{"location":"Paris"}`)},
+				},
+				FinishReason: api.FinishReasonToolCalls,
+			},
+		},
+		{
+			name: "reasoning options",
+			options: api.CallOptions{
+				Reasoning: &api.ReasoningOptions{BudgetTokens: 16000},
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/chat/completions",
+					Body: `{
+						"model": "llama3.2",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"reasoning_effort": "high"
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "chatcmpl-789",
+						"model": "llama3.2",
+						"choices": [{
+							"index": 0,
+							"message": {"role": "assistant", "content": "Hi"},
+							"finish_reason": "stop"
+						}]
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content:      []api.ContentBlock{&api.TextBlock{Text: "Hi"}},
+				FinishReason: api.FinishReasonStop,
+			},
+		},
+		{
+			name: "json response format",
+			options: api.CallOptions{
+				ResponseFormat: &api.ResponseFormat{Type: "json"},
+				TopK:           10,
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/v1/chat/completions",
+					Body: `{
+						"model": "llama3.2",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"response_format": {"type": "json_object"}
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "chatcmpl-789",
+						"choices": [{
+							"index": 0,
+							"message": {"role": "assistant", "content": "{\"greeting\":\"Hi\"}", "reasoning": "Answer in JSON."},
+							"finish_reason": "stop"
+						}]
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.ReasoningBlock{Text: "Answer in JSON."},
+					&api.TextBlock{Text: `{"greeting":"Hi"}`},
+				},
+				FinishReason: api.FinishReasonStop,
+				Warnings:     []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}},
+			},
+		},
+		{
+			name: "no choices",
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/v1/chat/completions"},
+				Response: httpmock.Response{
+					Body: `{"id": "chatcmpl-000", "choices": []}`,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{tt.exchange})
+			defer server.Close()
+
+			model := NewLanguageModel(testModelID,
+				WithBaseURL(server.BaseURL()+"/v1/"),
+				WithAPIKey("test-key"),
+				WithHeaders(http.Header{"X-Custom": []string{"custom"}}),
+			)
+			resp, err := model.Generate(t.Context(), standardPrompt, tt.options)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			aitesting.ResponseContains(t, tt.expectedResp, resp)
+			require.NotEmpty(t, resp.RequestInfo.Body)
+			require.Equal(t, http.StatusOK, resp.ResponseInfo.StatusCode)
+		})
+	}
+}
+
+func TestGenerate_APICallError(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    httpmock.Response
+		wantMessage string
+	}{
+		{
+			name: "openai error",
+			response: httpmock.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    map[string]string{"Retry-After": "2"},
+				Body:       `{"error": {"message": "Rate limit exceeded", "type": "rate_limit_error"}}`,
+			},
+			wantMessage: "Rate limit exceeded",
+		},
+		{
+			name: "plain text error",
+			response: httpmock.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Body:       "model is loading",
+			},
+			wantMessage: "429 Too Many Requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{
+				{
+					Request:  httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+					Response: tt.response,
+				},
+			})
+			defer server.Close()
+
+			model := NewLanguageModel(testModelID, WithBaseURL(server.BaseURL()))
+			_, err := model.Generate(t.Context(), standardPrompt, api.CallOptions{})
+
+			var callErr *api.APICallError
+			require.ErrorAs(t, err, &callErr)
+			require.Equal(t, http.StatusTooManyRequests, callErr.StatusCode)
+			require.True(t, callErr.IsRetryable())
+			require.Equal(t, tt.wantMessage, callErr.Message)
+		})
+	}
+}
+
+func TestGenerate_NoAPIKey(t *testing.T) {
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Values("Authorization")
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "Hi"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	model := NewLanguageModel(testModelID, WithBaseURL(server.URL))
+	_, err := model.Generate(t.Context(), standardPrompt, api.CallOptions{})
+	require.NoError(t, err)
+	require.Empty(t, authorization)
+}
+
+func TestGenerate_MissingBaseURL(t *testing.T) {
+	model := NewLanguageModel(testModelID)
+	_, err := model.Generate(t.Context(), standardPrompt, api.CallOptions{})
+
+	var settingErr *api.LoadSettingError
+	require.ErrorAs(t, err, &settingErr)
+}
+
+func TestCountTokens(t *testing.T) {
+	prompt := []api.Message{&api.UserMessage{Content: api.ContentFromText("Hello, world")}}
+
+	tokens, err := NewLanguageModel(testModelID).CountTokens(t.Context(), prompt)
+	require.NoError(t, err)
+	require.Equal(t, api.DefaultTokenEstimator.Estimate(prompt), tokens)
+
+	model := NewLanguageModel(testModelID, WithTokenEstimator(api.TokenEstimator{CharsPerToken: 3, MessageTokens: 5}))
+	tokens, err = model.CountTokens(t.Context(), prompt)
+	require.NoError(t, err)
+	require.Equal(t, 5+4, tokens)
+}
+
+// chunksToString converts a list of chunks into the body of a streaming
+// response, terminated by the [DONE] marker.
+func chunksToString(chunks ...string) string {
+	var buf bytes.Buffer
+	enc := sse.NewEncoder(&buf)
+	for _, chunk := range chunks {
+		if err := enc.EncodeEvent(&sse.Event{Data: sse.Raw(chunk)}); err != nil {
+			panic(fmt.Sprintf("failed to encode event: %v", err))
+		}
+	}
+	buf.WriteString("data: [DONE]\n\n")
+	return buf.String()
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedEvents []api.StreamEvent
+	}{
+		{
+			name: "text and reasoning",
+			body: chunksToString(
+				`{"id":"chatcmpl-1","created":1741257730,"model":"llama3.2","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Thinking"}}]}`,
+				`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"reasoning":" more"}}]}`,
+				`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+				`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":", world!"},"finish_reason":"stop"}]}`,
+				`{"id":"chatcmpl-1","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+			),
+			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{
+					ID:        "chatcmpl-1",
+					Timestamp: time.Unix(1741257730, 0).UTC(),
+					ModelID:   "llama3.2",
+				},
+				&api.ReasoningEvent{TextDelta: "Thinking"},
+				&api.ReasoningEvent{TextDelta: " more"},
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.TextDeltaEvent{TextDelta: ", world!"},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonStop,
+					Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+				},
+			},
+		},
+		{
+			name: "tool calls",
+			body: chunksToString(
+				`{"id":"chatcmpl-2","model":"llama3.2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":""}}]}}]}`,
+				`{"id":"chatcmpl-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":"}}]}}]}`,
+				`{"id":"chatcmpl-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+				`{"id":"chatcmpl-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"time"}}]},"finish_reason":"tool_calls"}]}`,
+			),
+			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{ID: "chatcmpl-2", ModelID: "llama3.2"},
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "weather", ArgsDelta: []byte(`{"location":`)},
+				&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "weather", ArgsDelta: []byte(`"Paris"}`)},
+				&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{"location":"Paris"}`)},
+				&api.ToolCallEvent{ToolCallID: "call_2", ToolName: "time", Args: json.RawMessage(`{}`)},
+				&api.FinishEvent{FinishReason: api.FinishReasonToolCalls},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/chat/completions",
+						Body: `{
+							"model": "llama3.2",
+							"messages": [{"role": "user", "content": "Hello"}],
+							"stream": true,
+							"stream_options": {"include_usage": true}
+						}`,
+					},
+					Response: httpmock.Response{
+						Headers: map[string]string{"Content-Type": "text/event-stream"},
+						Body:    tt.body,
+					},
+				},
+			})
+			defer server.Close()
+
+			model := NewLanguageModel(testModelID, WithBaseURL(server.BaseURL()))
+			resp, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{})
+			require.NoError(t, err)
+
+			var events []api.StreamEvent
+			for event := range resp.Stream {
+				events = append(events, event)
+			}
+			require.Equal(t, tt.expectedEvents, events)
+		})
+	}
+}
+
+func TestStream_WarningsAndRawChunks(t *testing.T) {
+	chunk := `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":"stop"}]}`
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+			Response: httpmock.Response{
+				Headers: map[string]string{"Content-Type": "text/event-stream"},
+				Body:    chunksToString(chunk),
+			},
+		},
+	})
+	defer server.Close()
+
+	model := NewLanguageModel(testModelID, WithBaseURL(server.BaseURL()))
+	resp, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{TopK: 10, IncludeRawChunks: true})
+	require.NoError(t, err)
+
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+	require.Len(t, events, 5)
+	rawChunk, ok := events[1].(*api.RawChunkEvent)
+	require.True(t, ok, "expected a raw chunk, got %T", events[1])
+	require.JSONEq(t, chunk, string(rawChunk.Data))
+	require.Equal(t, []api.StreamEvent{
+		&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}}},
+		rawChunk,
+		&api.ResponseMetadataEvent{ID: "chatcmpl-1"},
+		&api.TextDeltaEvent{TextDelta: "Hi"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop},
+	}, events)
+}
+
+func TestStream_Errors(t *testing.T) {
+	t.Run("error response", func(t *testing.T) {
+		server := httpmock.NewServer(t, []httpmock.Exchange{
+			{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+				Response: httpmock.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       `{"error": {"message": "model \"unknown\" not found", "type": "not_found_error"}}`,
+				},
+			},
+		})
+		defer server.Close()
+
+		model := NewLanguageModel("unknown", WithBaseURL(server.BaseURL()))
+		_, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{})
+
+		var callErr *api.APICallError
+		require.ErrorAs(t, err, &callErr)
+		require.Equal(t, http.StatusNotFound, callErr.StatusCode)
+		require.Equal(t, `model "unknown" not found`, callErr.Message)
+	})
+
+	t.Run("error chunk", func(t *testing.T) {
+		server := httpmock.NewServer(t, []httpmock.Exchange{
+			{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+				Response: httpmock.Response{
+					Headers: map[string]string{"Content-Type": "text/event-stream"},
+					Body: chunksToString(
+						`{"id":"chatcmpl-3","choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+						`{"error":{"code":500,"message":"context length exceeded"}}`,
+					),
+				},
+			},
+		})
+		defer server.Close()
+
+		model := NewLanguageModel(testModelID, WithBaseURL(server.BaseURL()))
+		resp, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{})
+		require.NoError(t, err)
+
+		var events []api.StreamEvent
+		for event := range resp.Stream {
+			events = append(events, event)
+		}
+		require.Len(t, events, 4)
+
+		errEvent, ok := events[3].(*api.ErrorEvent)
+		require.True(t, ok)
+		var callErr *api.APICallError
+		require.ErrorAs(t, errEvent, &callErr)
+		require.Equal(t, http.StatusInternalServerError, callErr.StatusCode)
+		require.Equal(t, "context length exceeded", callErr.Message)
+	})
+}
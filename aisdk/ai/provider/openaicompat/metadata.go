@@ -0,0 +1,23 @@
+package openaicompat
+
+import (
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/codec"
+)
+
+// Metadata contains Chat Completions request options that have no equivalent
+// in the AI SDK call options.
+//
+// Set it on the call options under the ProviderName key, even when the
+// provider was given a different name using WithName:
+//
+//	api.NewProviderMetadata(map[string]any{
+//		openaicompat.ProviderName: &openaicompat.Metadata{ReasoningEffort: "low"},
+//	})
+type Metadata = codec.Metadata
+
+// GetMetadata returns the OpenAI-compatible metadata of a response, block or
+// call options, or nil if there is none.
+func GetMetadata(source api.MetadataSource) *Metadata {
+	return codec.GetMetadata(source)
+}
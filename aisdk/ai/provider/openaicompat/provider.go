@@ -0,0 +1,182 @@
+// Package openaicompat provides language models served by OpenAI-compatible
+// servers through the Chat Completions API, such as vLLM, llama.cpp, Ollama
+// and LM Studio.
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat/client"
+	"go.jetify.com/ai/provider/openaicompat/codec"
+)
+
+// Provider gives access to the models of an OpenAI-compatible server.
+type Provider struct {
+	name    string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	headers http.Header
+
+	extension      Extension
+	tokenEstimator api.TokenEstimator
+}
+
+var _ api.Provider = &Provider{}
+
+// ProviderOption configures the OpenAI-compatible provider.
+type ProviderOption func(*Provider)
+
+// WithName sets the name reported by the models of the provider, e.g.
+// "ollama". Defaults to ProviderName.
+func WithName(name string) ProviderOption {
+	return func(p *Provider) {
+		p.name = name
+	}
+}
+
+// WithBaseURL sets the base URL of the API, including the version prefix,
+// e.g. "http://localhost:11434/v1" for Ollama. It is required.
+func WithBaseURL(baseURL string) ProviderOption {
+	return func(p *Provider) {
+		p.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithAPIKey sets the API key, sent as a bearer token. No Authorization header
+// is sent if it is not set, as most local servers don't require one.
+func WithAPIKey(apiKey string) ProviderOption {
+	return func(p *Provider) {
+		p.apiKey = apiKey
+	}
+}
+
+// WithClient sets a custom HTTP client.
+func WithClient(client *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithHeaders sets custom headers for API requests.
+func WithHeaders(headers http.Header) ProviderOption {
+	return func(p *Provider) {
+		for k, values := range headers {
+			for _, v := range values {
+				p.headers.Add(k, v)
+			}
+		}
+	}
+}
+
+// Extension adapts the models of the provider to a server that extends the
+// Chat Completions API with its own request and response fields, like
+// OpenRouter.
+type Extension struct {
+	// Encode converts a prompt and call options into a request, usually by
+	// calling codec.Encode and setting the Extra fields of the request.
+	// Defaults to codec.Encode.
+	Encode func(modelID string, prompt []api.Message, opts api.CallOptions) (*client.Request, []api.CallWarning, error)
+
+	// NewMetadataDecoder returns the decoder for the provider metadata of a
+	// response. No metadata is decoded if it is nil.
+	NewMetadataDecoder func() codec.MetadataDecoder
+}
+
+// WithExtension sets the extension used by the models of the provider.
+func WithExtension(extension Extension) ProviderOption {
+	return func(p *Provider) {
+		p.extension = extension
+	}
+}
+
+// WithTokenEstimator sets the estimator used by LanguageModel.CountTokens,
+// since the Chat Completions API has no token counting endpoint. Defaults to
+// api.DefaultTokenEstimator.
+func WithTokenEstimator(estimator api.TokenEstimator) ProviderOption {
+	return func(p *Provider) {
+		p.tokenEstimator = estimator
+	}
+}
+
+// NewProvider creates a new OpenAI-compatible provider.
+func NewProvider(opts ...ProviderOption) *Provider {
+	p := &Provider{
+		name:           ProviderName,
+		client:         http.DefaultClient,
+		headers:        make(http.Header),
+		tokenEstimator: api.DefaultTokenEstimator,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// LanguageModel returns the language model with the given ID. Since the
+// models depend on the server, any non-empty ID is accepted.
+func (p *Provider) LanguageModel(modelID string) (api.LanguageModel, error) {
+	if modelID == "" {
+		return nil, api.NewNoSuchModelError(modelID, api.LanguageModelType)
+	}
+	return &LanguageModel{modelID: modelID, provider: p}, nil
+}
+
+// TextEmbeddingModel always returns a NoSuchModelError: embedding models are
+// not supported by this provider yet.
+func (p *Provider) TextEmbeddingModel(modelID string) (api.EmbeddingModel[string], error) {
+	return nil, api.NewNoSuchModelError(modelID, api.TextEmbeddingModelType)
+}
+
+// ImageModel always returns a NoSuchModelError: image models are not
+// supported by this provider yet.
+func (p *Provider) ImageModel(modelID string) (api.ImageModel, error) {
+	return nil, api.NewNoSuchModelError(modelID, api.ImageModelType)
+}
+
+// doJSONRequest posts a JSON request to the API.
+func (p *Provider) doJSONRequest(ctx context.Context, path string, body []byte, extraHeaders http.Header) (*http.Response, error) {
+	if p.baseURL == "" {
+		return nil, api.NewLoadSettingError(
+			"Base URL of the OpenAI-compatible server is missing. Pass it using the WithBaseURL option.")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	// Set default headers
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	// Set provider headers
+	for k, values := range p.headers {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	// Set request-specific headers
+	for k, values := range extraHeaders {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,68 @@
+package openaicompat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestProvider_LanguageModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ProviderOption
+		modelID  string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "any model",
+			modelID:  "llama3.2",
+			wantName: ProviderName,
+		},
+		{
+			name:     "custom name",
+			opts:     []ProviderOption{WithName("ollama")},
+			modelID:  "qwen3:8b",
+			wantName: "ollama",
+		},
+		{
+			name:    "empty model ID",
+			modelID: "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(tt.opts...)
+			llm, err := provider.LanguageModel(tt.modelID)
+			if tt.wantErr {
+				var noSuchModel *api.NoSuchModelError
+				require.ErrorAs(t, err, &noSuchModel)
+				require.Equal(t, api.LanguageModelType, noSuchModel.ModelType)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.modelID, llm.ModelID())
+			require.Equal(t, tt.wantName, llm.ProviderName())
+		})
+	}
+}
+
+func TestProvider_TextEmbeddingModel(t *testing.T) {
+	_, err := NewProvider().TextEmbeddingModel("nomic-embed-text")
+
+	var noSuchModel *api.NoSuchModelError
+	require.ErrorAs(t, err, &noSuchModel)
+	require.Equal(t, api.TextEmbeddingModelType, noSuchModel.ModelType)
+}
+
+func TestProvider_ImageModel(t *testing.T) {
+	_, err := NewProvider().ImageModel("flux")
+
+	var noSuchModel *api.NoSuchModelError
+	require.ErrorAs(t, err, &noSuchModel)
+	require.Equal(t, api.ImageModelType, noSuchModel.ModelType)
+}
@@ -0,0 +1,93 @@
+package client
+
+// Request contains the fields that OpenRouter adds to chat completions
+// requests. It is sent as the Extra fields of an openaicompat request.
+type Request struct {
+	// Models is a list of fallback models that are tried in order if the
+	// primary model fails.
+	Models []string `json:"models,omitempty"`
+
+	TopK int `json:"top_k,omitempty"`
+
+	IncludeReasoning *bool              `json:"include_reasoning,omitempty"`
+	Reasoning        *ReasoningSettings `json:"reasoning,omitempty"`
+
+	// Route is the routing strategy used with Models, e.g. "fallback".
+	Route string `json:"route,omitempty"`
+
+	// Provider configures how OpenRouter selects the upstream provider.
+	Provider *ProviderPreferences `json:"provider,omitempty"`
+
+	// Transforms is the list of prompt transforms to apply, e.g. "middle-out".
+	// An empty, non-nil list disables the transforms applied by default.
+	Transforms []string `json:"transforms,omitzero"`
+
+	Usage *UsageSettings `json:"usage,omitempty"`
+}
+
+// ReasoningSettings configures the reasoning tokens generated by models that
+// support them. Effort and MaxTokens are mutually exclusive.
+type ReasoningSettings struct {
+	// Effort is one of "low", "medium" or "high".
+	Effort string `json:"effort,omitempty"`
+
+	// MaxTokens is the maximum number of tokens to use for reasoning.
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Exclude makes the model reason without returning the reasoning in the
+	// response.
+	Exclude bool `json:"exclude,omitempty"`
+
+	// Enabled enables reasoning with the default settings of the model.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ProviderPreferences configures provider routing. See
+// https://openrouter.ai/docs/features/provider-routing
+type ProviderPreferences struct {
+	// Order is the list of providers to try, in order, e.g. ["Anthropic", "OpenAI"].
+	Order []string `json:"order,omitempty"`
+
+	// AllowFallbacks controls whether other providers can be used when the
+	// preferred ones are unavailable. Defaults to true.
+	AllowFallbacks *bool `json:"allow_fallbacks,omitempty"`
+
+	// RequireParameters restricts routing to providers that support all the
+	// parameters in the request.
+	RequireParameters *bool `json:"require_parameters,omitempty"`
+
+	// DataCollection is either "allow" or "deny". When "deny", only providers
+	// that don't store user data are used.
+	DataCollection string `json:"data_collection,omitempty"`
+
+	// Only restricts routing to the given providers.
+	Only []string `json:"only,omitempty"`
+
+	// Ignore excludes the given providers.
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Quantizations restricts routing to providers serving the model with the
+	// given quantization levels, e.g. "fp8".
+	Quantizations []string `json:"quantizations,omitempty"`
+
+	// Sort orders providers by "price", "throughput" or "latency".
+	Sort string `json:"sort,omitempty"`
+
+	// MaxPrice is the maximum price, in USD per million tokens, that the
+	// request is allowed to cost.
+	MaxPrice *MaxPrice `json:"max_price,omitempty"`
+}
+
+// MaxPrice limits the price of the providers used for a request.
+type MaxPrice struct {
+	Prompt     float64 `json:"prompt,omitempty"`
+	Completion float64 `json:"completion,omitempty"`
+	Request    float64 `json:"request,omitempty"`
+	Image      float64 `json:"image,omitempty"`
+}
+
+// UsageSettings configures the usage accounting returned in responses.
+type UsageSettings struct {
+	// Include returns the cost and detailed token counts of the request.
+	Include bool `json:"include"`
+}
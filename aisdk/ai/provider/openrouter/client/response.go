@@ -0,0 +1,23 @@
+package client
+
+// Response contains the fields that OpenRouter adds to chat completions
+// responses and to the chunks of streaming responses.
+type Response struct {
+	// Provider is the name of the provider that served the request.
+	Provider string   `json:"provider,omitempty"`
+	Choices  []Choice `json:"choices"`
+	Usage    *Usage   `json:"usage,omitempty"`
+}
+
+// Choice contains the log probabilities of a completion choice.
+type Choice struct {
+	Index    int       `json:"index"`
+	LogProbs *LogProbs `json:"logprobs,omitempty"`
+}
+
+// Usage contains the cost of a request.
+type Usage struct {
+	// Cost is the cost of the request in credits. Only returned when usage
+	// accounting is enabled.
+	Cost float64 `json:"cost,omitempty"`
+}
@@ -0,0 +1,104 @@
+package codec
+
+import (
+	"go.jetify.com/ai/api"
+	compatclient "go.jetify.com/ai/provider/openaicompat/client"
+	compatcodec "go.jetify.com/ai/provider/openaicompat/codec"
+	"go.jetify.com/ai/provider/openrouter/client"
+)
+
+// Encode converts an AI SDK prompt and call options into an OpenRouter chat
+// completions request. The request is encoded by openaicompat, with the
+// OpenRouter-specific fields set in its Extra fields. It returns warnings for
+// settings that are not supported.
+func Encode(modelID string, prompt []api.Message, opts api.CallOptions) (*compatclient.Request, []api.CallWarning, error) {
+	// TopK and the reasoning options are sent in OpenRouter fields.
+	compatOpts := opts
+	compatOpts.TopK = 0
+	compatOpts.Reasoning = nil
+	req, warnings, err := compatcodec.Encode(modelID, prompt, compatOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	encodeReasoningHistory(prompt, req.Messages)
+
+	extra := &client.Request{
+		TopK:      opts.TopK,
+		Reasoning: encodeReasoning(opts.Reasoning),
+	}
+	if metadata := GetMetadata(&opts); metadata != nil {
+		encodeMetadata(req, extra, metadata)
+	}
+	req.Extra = extra
+
+	return req, warnings, nil
+}
+
+// encodeReasoningHistory sends the reasoning of the assistant messages of the
+// prompt back to OpenRouter, which passes it to the models that need it.
+// Every assistant message is encoded as exactly one message, in order.
+func encodeReasoningHistory(prompt []api.Message, messages []compatclient.Message) {
+	i := 0
+	for _, msg := range prompt {
+		assistant, ok := msg.(*api.AssistantMessage)
+		if !ok {
+			continue
+		}
+		for i < len(messages) && messages[i].Role != compatclient.RoleAssistant {
+			i++
+		}
+		if i == len(messages) {
+			return
+		}
+		for _, block := range assistant.Content {
+			if reasoning, ok := block.(*api.ReasoningBlock); ok {
+				messages[i].Reasoning += reasoning.Text
+			}
+		}
+		i++
+	}
+}
+
+func encodeMetadata(req *compatclient.Request, extra *client.Request, metadata *Metadata) {
+	extra.Models = metadata.Models
+	extra.Route = metadata.Route
+	extra.Provider = metadata.Provider
+	extra.Transforms = metadata.Transforms
+	if metadata.Reasoning != nil {
+		extra.Reasoning = metadata.Reasoning
+	}
+	extra.IncludeReasoning = metadata.IncludeReasoning
+	if metadata.IncludeUsage {
+		extra.Usage = &client.UsageSettings{Include: true}
+	}
+
+	req.LogitBias = metadata.LogitBias
+	req.ParallelToolCalls = metadata.ParallelToolCalls
+	req.User = metadata.User
+	if metadata.Logprobs != nil && (metadata.Logprobs.Enabled || metadata.Logprobs.TopK > 0) {
+		req.Logprobs = true
+		req.TopLogprobs = metadata.Logprobs.TopK
+	}
+}
+
+// encodeReasoning maps the provider-neutral reasoning options to the
+// OpenRouter reasoning settings, which OpenRouter translates for the model
+// that serves the request. A token budget takes precedence over the effort.
+// OpenRouter returns the reasoning by default, so IncludeSummary doesn't need
+// to be mapped.
+func encodeReasoning(options *api.ReasoningOptions) *client.ReasoningSettings {
+	if options == nil {
+		return nil
+	}
+	settings := &client.ReasoningSettings{}
+	switch {
+	case options.BudgetTokens > 0:
+		settings.MaxTokens = options.BudgetTokens
+	case options.ResolvedEffort() == api.ReasoningEffortMinimal:
+		// OpenRouter doesn't support the minimal effort.
+		settings.Effort = string(api.ReasoningEffortLow)
+	default:
+		settings.Effort = string(options.ResolvedEffort())
+	}
+	return settings
+}
@@ -1,4 +1,4 @@
-package openrouter
+package codec
 
 import (
 	"strings"
@@ -20,9 +20,9 @@ type CompletionPromptOptions struct {
 	Assistant   string // defaults to "assistant" if empty
 }
 
-// ConvertToOpenRouterCompletionPrompt converts an AI SDK prompt into OpenRouter's completion format.
+// EncodeCompletionPrompt converts an AI SDK prompt into the prompt string used by OpenRouter's completions API.
 // It returns the formatted prompt string and optional stop sequences.
-func ConvertToOpenRouterCompletionPrompt(opts CompletionPromptOptions) (string, []string, error) {
+func EncodeCompletionPrompt(opts CompletionPromptOptions) (string, []string, error) {
 	if opts.User == "" {
 		opts.User = "user"
 	}
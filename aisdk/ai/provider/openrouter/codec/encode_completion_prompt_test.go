@@ -1,4 +1,4 @@
-package openrouter
+package codec
 
 import (
 	"encoding/json"
@@ -8,7 +8,7 @@ import (
 	"go.jetify.com/ai/api"
 )
 
-func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
+func TestEncodeCompletionPrompt(t *testing.T) {
 	t.Run("direct prompt", func(t *testing.T) {
 		prompt := []api.Message{
 			&api.UserMessage{
@@ -18,7 +18,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		text, stop, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		text, stop, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatPrompt,
 		})
@@ -38,7 +38,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		text, stop, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		text, stop, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 		})
@@ -67,7 +67,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		text, stop, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		text, stop, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 		})
@@ -91,7 +91,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		text, stop, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		text, stop, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 			User:        "Human",
@@ -112,7 +112,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		_, _, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		_, _, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 		})
@@ -131,7 +131,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		_, _, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		_, _, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 		})
@@ -154,7 +154,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			},
 		}
 
-		_, _, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		_, _, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 		})
@@ -174,7 +174,7 @@ func TestConvertToOpenRouterCompletionPrompt(t *testing.T) {
 			&api.SystemMessage{Content: "unexpected system"},
 		}
 
-		_, _, err := ConvertToOpenRouterCompletionPrompt(CompletionPromptOptions{
+		_, _, err := EncodeCompletionPrompt(CompletionPromptOptions{
 			Prompt:      prompt,
 			InputFormat: InputFormatMessages,
 		})
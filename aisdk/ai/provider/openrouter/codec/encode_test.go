@@ -0,0 +1,88 @@
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name         string
+		prompt       []api.Message
+		opts         api.CallOptions
+		wantBody     string
+		wantWarnings []api.CallWarning
+	}{
+		{
+			name:   "top k",
+			prompt: []api.Message{&api.UserMessage{Content: api.ContentFromText("Hi")}},
+			opts:   api.CallOptions{TopK: 40},
+			wantBody: `{
+				"model": "openai/gpt-4o",
+				"messages": [{"role": "user", "content": "Hi"}],
+				"top_k": 40
+			}`,
+		},
+		{
+			name: "reasoning of assistant messages",
+			prompt: []api.Message{
+				&api.UserMessage{Content: api.ContentFromText("Hi")},
+				&api.AssistantMessage{Content: []api.ContentBlock{
+					&api.ReasoningBlock{Text: "A greeting."},
+					&api.TextBlock{Text: "Hello!"},
+				}},
+				&api.ToolMessage{Content: []api.ToolResultBlock{
+					{ToolCallID: "call_1", Result: "sunny"},
+					{ToolCallID: "call_2", Result: "rainy"},
+				}},
+				&api.AssistantMessage{Content: []api.ContentBlock{
+					&api.ReasoningBlock{Text: "Both "},
+					&api.ReasoningBlock{Text: "are known."},
+					&api.TextBlock{Text: "Done."},
+				}},
+				&api.AssistantMessage{Content: []api.ContentBlock{&api.TextBlock{Text: "Anything else?"}}},
+			},
+			wantBody: `{
+				"model": "openai/gpt-4o",
+				"messages": [
+					{"role": "user", "content": "Hi"},
+					{"role": "assistant", "content": "Hello!", "reasoning": "A greeting."},
+					{"role": "tool", "content": "sunny", "tool_call_id": "call_1"},
+					{"role": "tool", "content": "rainy", "tool_call_id": "call_2"},
+					{"role": "assistant", "content": "Done.", "reasoning": "Both are known."},
+					{"role": "assistant", "content": "Anything else?"}
+				]
+			}`,
+		},
+		{
+			name:   "unsupported tool",
+			prompt: []api.Message{&api.UserMessage{Content: api.ContentFromText("Hi")}},
+			opts: api.CallOptions{
+				Tools: []api.ToolDefinition{&api.ProviderDefinedTool{ID: "openai.web_search", Name: "web_search"}},
+			},
+			wantBody: `{
+				"model": "openai/gpt-4o",
+				"messages": [{"role": "user", "content": "Hi"}]
+			}`,
+			wantWarnings: []api.CallWarning{{
+				Type: "unsupported-tool",
+				Tool: &api.ProviderDefinedTool{ID: "openai.web_search", Name: "web_search"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, warnings, err := Encode("openai/gpt-4o", tt.prompt, tt.opts)
+			require.NoError(t, err)
+			body, err := json.Marshal(req)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantBody, string(body))
+			assert.Equal(t, tt.wantWarnings, warnings)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"go.jetify.com/ai/api"
+	compatcodec "go.jetify.com/ai/provider/openaicompat/codec"
+	"go.jetify.com/ai/provider/openrouter/client"
+)
+
+// ProviderName is the name used to store OpenRouter metadata.
+const ProviderName = "openrouter"
+
+// For now we are using a single type for all metadata.
+// TODO: Decide if we will need different types for different metadata.
+type Metadata struct {
+	// --- Used in requests ---
+
+	// Models is a list of fallback models that are tried in order if the
+	// requested model is unavailable, rate limited or refuses to respond.
+	// See https://openrouter.ai/docs/features/model-routing
+	Models []string `json:"models,omitempty"`
+
+	// Route is the routing strategy used with Models. Defaults to "fallback".
+	Route string `json:"route,omitempty"`
+
+	// Provider configures how OpenRouter routes the request between the
+	// providers that serve the model.
+	// See https://openrouter.ai/docs/features/provider-routing
+	Provider *client.ProviderPreferences `json:"provider,omitempty"`
+
+	// Transforms is the list of prompt transforms to apply, e.g. "middle-out"
+	// to compress prompts that don't fit in the context window. Set it to an
+	// empty, non-nil slice to disable the transforms that are applied by
+	// default.
+	// See https://openrouter.ai/docs/features/message-transforms
+	Transforms []string `json:"transforms,omitzero"`
+
+	// Reasoning configures the reasoning tokens of models that support them.
+	Reasoning *client.ReasoningSettings `json:"reasoning,omitempty"`
+
+	// IncludeReasoning requests the model to return its reasoning in the
+	// response, if the model supports it.
+	IncludeReasoning *bool `json:"include_reasoning,omitempty"`
+
+	// LogitBias modifies the likelihood of the given token IDs appearing in
+	// the completion, with values from -100 to 100.
+	LogitBias map[int]float64 `json:"logit_bias,omitempty"`
+
+	// Logprobs requests the log probabilities of the generated tokens.
+	Logprobs *client.LogprobSettings `json:"logprobs,omitempty"`
+
+	// ParallelToolCalls enables parallel function calling during tool use.
+	// When not specified (nil), OpenRouter defaults this to true.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
+
+	// User is a unique identifier representing the end-user, which helps
+	// OpenRouter monitor and detect abuse.
+	User string `json:"user,omitempty"`
+
+	// IncludeUsage requests the cost of the call, returned in the Cost field
+	// of the response metadata.
+	IncludeUsage bool `json:"include_usage,omitempty"`
+
+	// --- Used in responses ---
+
+	// ServedBy is the name of the provider that served the request,
+	// e.g. "Anthropic".
+	ServedBy string `json:"served_by,omitempty"`
+
+	// Cost is the cost of the call in OpenRouter credits. It is only set
+	// when IncludeUsage is enabled.
+	Cost float64 `json:"cost,omitzero"`
+
+	// LogProbs contains the log probabilities of the generated tokens when
+	// Logprobs is enabled.
+	LogProbs api.LogProbs `json:"token_logprobs,omitempty"`
+}
+
+func GetMetadata(source api.MetadataSource) *Metadata {
+	return api.GetMetadata[Metadata](ProviderName, source)
+}
+
+// NewMetadataDecoder returns a decoder for the OpenRouter metadata of a
+// response or stream: the provider that served the request, its cost and the
+// log probabilities of the generated tokens.
+func NewMetadataDecoder() compatcodec.MetadataDecoder {
+	return &metadataDecoder{}
+}
+
+type metadataDecoder struct {
+	metadata Metadata
+}
+
+func (d *metadataDecoder) Decode(data json.RawMessage) error {
+	var response client.Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return api.NewJSONParseError(string(data), err)
+	}
+
+	if response.Provider != "" {
+		d.metadata.ServedBy = response.Provider
+	}
+	if response.Usage != nil {
+		d.metadata.Cost = response.Usage.Cost
+	}
+	for _, choice := range response.Choices {
+		if choice.Index == 0 && choice.LogProbs != nil {
+			d.metadata.LogProbs = append(d.metadata.LogProbs, DecodeLogProbs(choice.LogProbs)...)
+		}
+	}
+	return nil
+}
+
+func (d *metadataDecoder) ProviderMetadata() *api.ProviderMetadata {
+	metadata := d.metadata
+	return api.NewProviderMetadata(map[string]any{ProviderName: &metadata})
+}
@@ -0,0 +1,12 @@
+package openrouter
+
+import "go.jetify.com/ai/provider/openrouter/codec"
+
+// ProviderName is the name of the OpenRouter provider.
+const ProviderName = codec.ProviderName
+
+// DefaultBaseURL is the base URL of the OpenRouter API.
+const DefaultBaseURL = "https://openrouter.ai/api/v1"
+
+// APIKeyEnvVar is the environment variable that contains the default API key.
+const APIKeyEnvVar = "OPENROUTER_API_KEY"
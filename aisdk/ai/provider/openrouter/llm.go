@@ -0,0 +1,79 @@
+package openrouter
+
+import (
+	"context"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat"
+)
+
+// LanguageModel represents a chat model served through OpenRouter.
+//
+// OpenRouter implements the OpenAI Chat Completions API, so requests are sent
+// by an openaicompat model that adds the OpenRouter request fields and
+// decodes the OpenRouter metadata of the responses.
+type LanguageModel struct {
+	chat     *openaicompat.LanguageModel
+	provider *Provider
+}
+
+var (
+	_ api.LanguageModel                = &LanguageModel{}
+	_ api.ObjectGenerationModeProvider = &LanguageModel{}
+)
+
+// NewLanguageModel creates a new OpenRouter language model. Unlike
+// Provider.LanguageModel, it accepts any model ID.
+func NewLanguageModel(modelID string, opts ...ProviderOption) *LanguageModel {
+	return newLanguageModel(modelID, NewProvider(opts...))
+}
+
+func newLanguageModel(modelID string, provider *Provider) *LanguageModel {
+	return &LanguageModel{
+		chat:     openaicompat.NewLanguageModel(modelID, provider.chatOptions()...),
+		provider: provider,
+	}
+}
+
+func (m *LanguageModel) ProviderName() string {
+	return ProviderName
+}
+
+func (m *LanguageModel) ModelID() string {
+	return m.chat.ModelID()
+}
+
+// DefaultObjectGenerationMode returns the default mode for object generation.
+// Not every model served by OpenRouter supports structured outputs, but most
+// of them support tool calls.
+func (m *LanguageModel) DefaultObjectGenerationMode() api.ObjectGenerationMode {
+	return api.ObjectGenerationModeTool
+}
+
+func (m *LanguageModel) SupportedUrls() []api.SupportedURL {
+	return m.chat.SupportedUrls()
+}
+
+func (m *LanguageModel) Generate(
+	ctx context.Context, prompt []api.Message, opts api.CallOptions,
+) (*api.Response, error) {
+	if err := m.provider.checkAPIKey(); err != nil {
+		return nil, err
+	}
+	return m.chat.Generate(ctx, prompt, opts)
+}
+
+func (m *LanguageModel) Stream(
+	ctx context.Context, prompt []api.Message, opts api.CallOptions,
+) (*api.StreamResponse, error) {
+	if err := m.provider.checkAPIKey(); err != nil {
+		return nil, err
+	}
+	return m.chat.Stream(ctx, prompt, opts)
+}
+
+// CountTokens estimates the input tokens of the prompt, since OpenRouter has
+// no token counting endpoint. It can be used as an ai.TokenCounter.
+func (m *LanguageModel) CountTokens(ctx context.Context, prompt []api.Message) (int, error) {
+	return m.chat.CountTokens(ctx, prompt)
+}
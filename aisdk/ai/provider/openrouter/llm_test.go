@@ -0,0 +1,496 @@
+package openrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/aitesting"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openrouter/model"
+	"go.jetify.com/pkg/httpmock"
+	"go.jetify.com/pkg/pointer"
+	"go.jetify.com/sse"
+)
+
+var standardPrompt = []api.Message{
+	&api.UserMessage{
+		Content: api.ContentFromText("Hello"),
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      api.CallOptions
+		exchange     httpmock.Exchange
+		wantErr      bool
+		expectedResp *api.Response
+		wantMetadata *Metadata
+	}{
+		{
+			name: "text response",
+			options: api.CallOptions{
+				MaxOutputTokens: 100,
+				Temperature:     pointer.Float64(0.5),
+				StopSequences:   []string{"END"},
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/chat/completions",
+					Headers: map[string]string{
+						"Authorization": "Bearer test-key",
+					},
+					Body: `{
+						"model": "openai/gpt-4o",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"max_tokens": 100,
+						"temperature": 0.5,
+						"stop": ["END"]
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "gen-123",
+						"created": 1741257730,
+						"model": "openai/gpt-4o",
+						"provider": "OpenAI",
+						"choices": [{
+							"index": 0,
+							"message": {"role": "assistant", "content": "Hi there!"},
+							"finish_reason": "stop"
+						}],
+						"usage": {
+							"prompt_tokens": 10,
+							"completion_tokens": 5,
+							"total_tokens": 15,
+							"prompt_tokens_details": {"cached_tokens": 4},
+							"cost": 0.00012
+						}
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content:      []api.ContentBlock{&api.TextBlock{Text: "Hi there!"}},
+				FinishReason: api.FinishReasonStop,
+				Usage: api.Usage{
+					InputTokens:       10,
+					OutputTokens:      5,
+					TotalTokens:       15,
+					CachedInputTokens: 4,
+				},
+				ResponseInfo: &api.ResponseInfo{
+					ID:        "gen-123",
+					ModelID:   "openai/gpt-4o",
+					Timestamp: time.Unix(1741257730, 0).UTC(),
+				},
+			},
+			wantMetadata: &Metadata{ServedBy: "OpenAI", Cost: 0.00012},
+		},
+		{
+			name: "reasoning and tool calls",
+			options: api.CallOptions{
+				Tools: []api.ToolDefinition{
+					&api.FunctionTool{
+						Name:        "weather",
+						Description: "Get the weather",
+						InputSchema: &jsonschema.Schema{Type: "object"},
+					},
+				},
+				ToolChoice: &api.ToolChoice{Type: "tool", ToolName: "weather"},
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/chat/completions",
+					Body: `{
+						"model": "openai/gpt-4o",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"tools": [{
+							"type": "function",
+							"function": {"name": "weather", "description": "Get the weather", "parameters": {"type": "object"}}
+						}],
+						"tool_choice": {"type": "function", "function": {"name": "weather"}}
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "gen-456",
+						"model": "openai/gpt-4o",
+						"choices": [{
+							"index": 0,
+							"message": {
+								"role": "assistant",
+								"content": null,
+								"reasoning": "The user wants the weather.",
+								"tool_calls": [
+									{"id": "call_1", "type": "function", "function": {"name": "weather", "arguments": "{\"location\":\"Paris\"}"}},
+									{"id": "call_2", "type": "function", "function": {"name": "weather", "arguments": ""}}
+								]
+							},
+							"finish_reason": "tool_calls"
+						}],
+						"usage": {
+							"prompt_tokens": 20,
+							"completion_tokens": 30,
+							"total_tokens": 50,
+							"completion_tokens_details": {"reasoning_tokens": 12}
+						}
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.ReasoningBlock{Text: "The user wants the weather."},
+					&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{"location":"Paris"}`)},
+					&api.ToolCallBlock{ToolCallID: "call_2", ToolName: "weather", Args: json.RawMessage(`{}`)},
+				},
+				FinishReason: api.FinishReasonToolCalls,
+				Usage: api.Usage{
+					InputTokens:     20,
+					OutputTokens:    30,
+					TotalTokens:     50,
+					ReasoningTokens: 12,
+				},
+			},
+		},
+		{
+			name: "routing, fallbacks and transforms",
+			options: api.CallOptions{
+				ProviderMetadata: api.NewProviderMetadata(map[string]any{
+					ProviderName: &Metadata{
+						Models: []string{model.AnthropicClaude35Sonnet},
+						Route:  "fallback",
+						Provider: &ProviderPreferences{
+							Order:          []string{"OpenAI", "Azure"},
+							AllowFallbacks: pointer.Bool(false),
+							DataCollection: "deny",
+							Sort:           "price",
+							MaxPrice:       &MaxPrice{Prompt: 1, Completion: 2},
+						},
+						Transforms:   []string{},
+						Reasoning:    &ReasoningSettings{Effort: "high"},
+						Logprobs:     &LogprobSettings{Enabled: true, TopK: 2},
+						User:         "user-1",
+						IncludeUsage: true,
+					},
+				}),
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/chat/completions",
+					Body: `{
+						"model": "openai/gpt-4o",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"models": ["anthropic/claude-3.5-sonnet"],
+						"route": "fallback",
+						"provider": {
+							"order": ["OpenAI", "Azure"],
+							"allow_fallbacks": false,
+							"data_collection": "deny",
+							"sort": "price",
+							"max_price": {"prompt": 1, "completion": 2}
+						},
+						"transforms": [],
+						"reasoning": {"effort": "high"},
+						"logprobs": true,
+						"top_logprobs": 2,
+						"user": "user-1",
+						"usage": {"include": true}
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "gen-789",
+						"model": "anthropic/claude-3.5-sonnet",
+						"provider": "Anthropic",
+						"choices": [{
+							"index": 0,
+							"message": {"role": "assistant", "content": "Hi"},
+							"logprobs": {"content": [{"token": "Hi", "logprob": -0.1, "top_logprobs": []}]},
+							"finish_reason": "stop"
+						}],
+						"usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2, "cost": 0.5}
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{&api.TextBlock{Text: "Hi"}},
+				ResponseInfo: &api.ResponseInfo{
+					ID:      "gen-789",
+					ModelID: "anthropic/claude-3.5-sonnet",
+				},
+			},
+			wantMetadata: &Metadata{
+				ServedBy: "Anthropic",
+				Cost:     0.5,
+				LogProbs: api.LogProbs{{Token: "Hi", LogProb: -0.1, TopLogProbs: []api.TokenLogProb{}}},
+			},
+		},
+		{
+			name: "reasoning options",
+			options: api.CallOptions{
+				Reasoning: &api.ReasoningOptions{BudgetTokens: 2000, IncludeSummary: true},
+			},
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{
+					Method: http.MethodPost,
+					Path:   "/chat/completions",
+					Body: `{
+						"model": "openai/gpt-4o",
+						"messages": [{"role": "user", "content": "Hello"}],
+						"reasoning": {"max_tokens": 2000}
+					}`,
+				},
+				Response: httpmock.Response{
+					Body: `{
+						"id": "gen-456",
+						"model": "openai/gpt-4o",
+						"choices": [{
+							"index": 0,
+							"message": {"role": "assistant", "content": "Hi", "reasoning": "Greeting."},
+							"finish_reason": "stop"
+						}],
+						"usage": {
+							"prompt_tokens": 1,
+							"completion_tokens": 9,
+							"total_tokens": 10,
+							"completion_tokens_details": {"reasoning_tokens": 8}
+						}
+					}`,
+				},
+			},
+			expectedResp: &api.Response{
+				Content: []api.ContentBlock{
+					&api.ReasoningBlock{Text: "Greeting."},
+					&api.TextBlock{Text: "Hi"},
+				},
+				Usage: api.Usage{InputTokens: 1, OutputTokens: 9, TotalTokens: 10, ReasoningTokens: 8},
+			},
+		},
+		{
+			name: "no choices",
+			exchange: httpmock.Exchange{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+				Response: httpmock.Response{
+					Body: `{"id": "gen-000", "choices": []}`,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{tt.exchange})
+			defer server.Close()
+
+			model := NewLanguageModel(model.OpenAIGPT4o, WithBaseURL(server.BaseURL()), WithAPIKey("test-key"))
+			resp, err := model.Generate(t.Context(), standardPrompt, tt.options)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			aitesting.ResponseContains(t, tt.expectedResp, resp)
+			require.NotEmpty(t, resp.RequestInfo.Body)
+			require.Equal(t, http.StatusOK, resp.ResponseInfo.StatusCode)
+			if tt.wantMetadata != nil {
+				require.Equal(t, tt.wantMetadata, GetMetadata(resp))
+			}
+		})
+	}
+}
+
+func TestGenerate_APICallError(t *testing.T) {
+	server := httpmock.NewServer(t, []httpmock.Exchange{
+		{
+			Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+			Response: httpmock.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    map[string]string{"Retry-After": "2"},
+				Body:       `{"error": {"code": 429, "message": "Rate limit exceeded"}}`,
+			},
+		},
+	})
+	defer server.Close()
+
+	model := NewLanguageModel(model.OpenAIGPT4o, WithBaseURL(server.BaseURL()), WithAPIKey("test-key"))
+	_, err := model.Generate(t.Context(), standardPrompt, api.CallOptions{})
+
+	var callErr *api.APICallError
+	require.ErrorAs(t, err, &callErr)
+	require.Equal(t, http.StatusTooManyRequests, callErr.StatusCode)
+	require.True(t, callErr.IsRetryable())
+	require.Equal(t, "Rate limit exceeded", callErr.Message)
+	require.Equal(t, "2", callErr.Response.Header.Get("Retry-After"))
+}
+
+func TestGenerate_MissingAPIKey(t *testing.T) {
+	t.Setenv(APIKeyEnvVar, "")
+
+	model := NewLanguageModel(model.OpenAIGPT4o)
+	_, err := model.Generate(t.Context(), standardPrompt, api.CallOptions{})
+
+	var keyErr *api.LoadAPIKeyError
+	require.ErrorAs(t, err, &keyErr)
+}
+
+// chunksToString converts a list of chunks into the body of a streaming
+// response, terminated by the [DONE] marker.
+func chunksToString(chunks ...string) string {
+	var buf bytes.Buffer
+	enc := sse.NewEncoder(&buf)
+	for _, chunk := range chunks {
+		if err := enc.EncodeEvent(&sse.Event{Data: sse.Raw(chunk)}); err != nil {
+			panic(fmt.Sprintf("failed to encode event: %v", err))
+		}
+	}
+	buf.WriteString("data: [DONE]\n\n")
+	return buf.String()
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedEvents []api.StreamEvent
+		golden         string
+	}{
+		{
+			name: "text and reasoning",
+			body: chunksToString(
+				`{"id":"gen-1","created":1741257730,"model":"openai/gpt-4o","provider":"OpenAI","choices":[{"index":0,"delta":{"role":"assistant","reasoning":"Thinking"}}]}`,
+				`{"id":"gen-1","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+				`{"id":"gen-1","choices":[{"index":0,"delta":{"content":", world!"},"finish_reason":"stop"}]}`,
+				`{"id":"gen-1","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15,"cost":0.25}}`,
+			),
+			expectedEvents: []api.StreamEvent{
+				&api.StreamStartEvent{},
+				&api.ResponseMetadataEvent{
+					ID:        "gen-1",
+					Timestamp: time.Unix(1741257730, 0).UTC(),
+					ModelID:   "openai/gpt-4o",
+				},
+				&api.ReasoningEvent{TextDelta: "Thinking"},
+				&api.TextDeltaEvent{TextDelta: "Hello"},
+				&api.TextDeltaEvent{TextDelta: ", world!"},
+				&api.FinishEvent{
+					FinishReason: api.FinishReasonStop,
+					Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+					ProviderMetadata: api.NewProviderMetadata(map[string]any{
+						ProviderName: &Metadata{ServedBy: "OpenAI", Cost: 0.25},
+					}),
+				},
+			},
+		},
+		{
+			name: "tool calls",
+			body: chunksToString(
+				`{"id":"gen-2","model":"openai/gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":""}}]}}]}`,
+				`{"id":"gen-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":"}}]}}]}`,
+				`{"id":"gen-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+				`{"id":"gen-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"time"}}]},"finish_reason":"tool_calls"}]}`,
+			),
+			golden: "testdata/stream_tool_calls.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httpmock.NewServer(t, []httpmock.Exchange{
+				{
+					Request: httpmock.Request{
+						Method: http.MethodPost,
+						Path:   "/chat/completions",
+						Body: `{
+							"model": "openai/gpt-4o",
+							"messages": [{"role": "user", "content": "Hello"}],
+							"stream": true,
+							"stream_options": {"include_usage": true}
+						}`,
+					},
+					Response: httpmock.Response{
+						Headers: map[string]string{"Content-Type": "text/event-stream"},
+						Body:    tt.body,
+					},
+				},
+			})
+			defer server.Close()
+
+			model := NewLanguageModel(model.OpenAIGPT4o, WithBaseURL(server.BaseURL()), WithAPIKey("test-key"))
+			resp, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{})
+			require.NoError(t, err)
+
+			events := aitesting.CollectEvents(resp)
+			aitesting.StreamValid(t, events)
+			if tt.golden != "" {
+				aitesting.GoldenEvents(t, tt.golden, events)
+			} else {
+				require.Equal(t, tt.expectedEvents, events)
+			}
+		})
+	}
+}
+
+func TestStream_Errors(t *testing.T) {
+	t.Run("error response", func(t *testing.T) {
+		server := httpmock.NewServer(t, []httpmock.Exchange{
+			{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+				Response: httpmock.Response{
+					StatusCode: http.StatusBadGateway,
+					Body:       `{"error": {"code": 502, "message": "Provider unavailable"}}`,
+				},
+			},
+		})
+		defer server.Close()
+
+		model := NewLanguageModel(model.OpenAIGPT4o, WithBaseURL(server.BaseURL()), WithAPIKey("test-key"))
+		_, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{})
+
+		var callErr *api.APICallError
+		require.ErrorAs(t, err, &callErr)
+		require.Equal(t, http.StatusBadGateway, callErr.StatusCode)
+		require.Equal(t, "Provider unavailable", callErr.Message)
+	})
+
+	t.Run("error chunk", func(t *testing.T) {
+		server := httpmock.NewServer(t, []httpmock.Exchange{
+			{
+				Request: httpmock.Request{Method: http.MethodPost, Path: "/chat/completions"},
+				Response: httpmock.Response{
+					Headers: map[string]string{"Content-Type": "text/event-stream"},
+					Body: chunksToString(
+						`{"id":"gen-3","choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+						`{"id":"gen-3","error":{"code":502,"message":"Provider disconnected"},"choices":[]}`,
+					),
+				},
+			},
+		})
+		defer server.Close()
+
+		model := NewLanguageModel(model.OpenAIGPT4o, WithBaseURL(server.BaseURL()), WithAPIKey("test-key"))
+		resp, err := model.Stream(t.Context(), standardPrompt, api.CallOptions{})
+		require.NoError(t, err)
+
+		events := aitesting.CollectEvents(resp)
+		aitesting.StreamValid(t, events)
+		require.Len(t, events, 4)
+
+		errEvent, ok := events[3].(*api.ErrorEvent)
+		require.True(t, ok)
+		var callErr *api.APICallError
+		require.ErrorAs(t, errEvent, &callErr)
+		require.Equal(t, http.StatusBadGateway, callErr.StatusCode)
+		require.Equal(t, "Provider disconnected", callErr.Message)
+	})
+}
@@ -0,0 +1,40 @@
+package openrouter
+
+import (
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openrouter/client"
+	"go.jetify.com/ai/provider/openrouter/codec"
+)
+
+// Metadata contains OpenRouter-specific request options, such as model
+// fallbacks, provider routing and prompt transforms, and response information,
+// such as the provider that served the request and its cost.
+//
+// Set it on the call options under the ProviderName key:
+//
+//	api.NewProviderMetadata(map[string]any{
+//		openrouter.ProviderName: &openrouter.Metadata{
+//			Models:   []string{model.AnthropicClaude35Sonnet},
+//			Provider: &openrouter.ProviderPreferences{Sort: "price"},
+//		},
+//	})
+type Metadata = codec.Metadata
+
+// ProviderPreferences configures how OpenRouter routes requests between the
+// providers that serve a model.
+type ProviderPreferences = client.ProviderPreferences
+
+// MaxPrice limits the price of the providers used for a request.
+type MaxPrice = client.MaxPrice
+
+// ReasoningSettings configures the reasoning tokens of models that support them.
+type ReasoningSettings = client.ReasoningSettings
+
+// LogprobSettings configures the log probabilities returned for the generated tokens.
+type LogprobSettings = client.LogprobSettings
+
+// GetMetadata returns the OpenRouter metadata of a response, block or call
+// options, or nil if there is none.
+func GetMetadata(source api.MetadataSource) *Metadata {
+	return codec.GetMetadata(source)
+}
@@ -0,0 +1,239 @@
+package model
+
+// IDs lists the IDs of all the models defined in this package.
+var IDs = []string{
+	Dolphin30R1Mistral24bFree,
+	Dolphin30Mistral24bFree,
+	LlamaGuard38b,
+	OpenAIO3MiniHigh,
+	Llama31Tulu3405b,
+	DeepSeekR1DistillLlama8B,
+	GoogleGeminiFlash20,
+	GoogleGeminiFlashLite20PreviewFree,
+	GoogleGeminiPro20ExperimentalFree,
+	QwenQwenVLPlusFree,
+	AionLabsAion10,
+	AionLabsAion10Mini,
+	AionLabsAionRP108B,
+	QwenQwenTurbo,
+	QwenQwen25VL72BInstructFree,
+	QwenQwenPlus,
+	QwenQwenMax,
+	OpenAIO3Mini,
+	DeepSeekR1DistillQwen15B,
+	MistralMistralSmall3Free,
+	MistralMistralSmall3,
+	DeepSeekR1DistillQwen32B,
+	DeepSeekR1DistillQwen14B,
+	PerplexitySonarReasoning,
+	PerplexitySonar,
+	LiquidLFM7B,
+	LiquidLFM3B,
+	DeepSeekR1DistillLlama70BFree,
+	DeepSeekR1DistillLlama70B,
+	GoogleGemini20FlashThinkingExperimental0121Free,
+	DeepSeekR1Free,
+	DeepSeekR1,
+	RogueRose103BV02Free,
+	MiniMaxMiniMax01,
+	MistralCodestral2501,
+	MicrosoftPhi4,
+	Sao10KLlama3170BHanamiX1,
+	DeepSeekDeepSeekV3Free,
+	DeepSeekDeepSeekV3,
+	QwenQvQ72BPreview,
+	GoogleGemini20FlashThinkingExperimentalFree,
+	Sao10KLlama33Euryale70B,
+	OpenAIO1,
+	EVALlama33370b,
+	XAIGrok2Vision1212,
+	XAIGrok21212,
+	CohereCommandR7B122024,
+	GoogleGeminiFlash20ExperimentalFree,
+	GoogleGeminiExperimental1206Free,
+	MetaLlama3370BInstructFree,
+	MetaLlama3370BInstruct,
+	AmazonNovaLite10,
+	AmazonNovaMicro10,
+	AmazonNovaPro10,
+	QwenQwQ32BPreview,
+	GoogleLearnLM15ProExperimentalFree,
+	EVAQwen2572B,
+	OpenAIGPT4o20241120,
+	MistralLarge2411,
+	MistralLarge2407,
+	MistralPixtralLarge2411,
+	XAIGrokVisionBeta,
+	InfermaticMistralNemoInferor12B,
+	Qwen25Coder32BInstruct,
+	SorcererLM8x22B,
+	EVAQwen2532B,
+	Unslopnemo12b,
+	AnthropicClaude35Haiku20241022SelfModerated,
+	AnthropicClaude35Haiku20241022,
+	AnthropicClaude35HaikuSelfModerated,
+	AnthropicClaude35Haiku,
+	NeverSleepLumimaidV0270B,
+	MagnumV472B,
+	AnthropicClaude35Sonnet,
+	AnthropicClaude35SonnetSelfModerated,
+	XAIGrokBeta,
+	MistralMinistral8B,
+	MistralMinistral3B,
+	Qwen257BInstruct,
+	NVIDIALlama31Nemotron70BInstructFree,
+	NVIDIALlama31Nemotron70BInstruct,
+	InflectionInflection3Pi,
+	InflectionInflection3Productivity,
+	GoogleGeminiFlash158B,
+	MagnumV272B,
+	LiquidLFM40BMoE,
+	Rocinante12B,
+	MetaLlama323BInstruct,
+	MetaLlama321BInstruct,
+	MetaLlama3290BVisionInstruct,
+	MetaLlama3211BVisionInstructFree,
+	MetaLlama3211BVisionInstruct,
+	Qwen2572BInstruct,
+	Qwen2VL72BInstruct,
+	NeverSleepLumimaidV028B,
+	OpenAIO1Mini20240912,
+	OpenAIO1Preview,
+	OpenAIO1Preview20240912,
+	OpenAIO1Mini,
+	MistralPixtral12B,
+	CohereCommandR082024,
+	CohereCommandR082024Plus,
+	Qwen2VL7BInstruct,
+	Sao10KLlama31Euryale70BV22,
+	GoogleGeminiFlash158BExperimental,
+	AI21Jamba15Large,
+	AI21Jamba15Mini,
+	MicrosoftPhi35Mini128KInstruct,
+	NousHermes370BInstruct,
+	NousHermes3405BInstruct,
+	PerplexityLlama31Sonar405BOnline,
+	OpenAIChatGPT4o,
+	Sao10KLlama38BLunaris,
+	AetherwiingStarcannon12B,
+	OpenAIGPT4o20240806,
+	MetaLlama31405BBase,
+	MistralNemo12BCeleste,
+	PerplexityLlama31Sonar8B,
+	PerplexityLlama31Sonar70B,
+	PerplexityLlama31Sonar70BOnline,
+	PerplexityLlama31Sonar8BOnline,
+	MetaLlama31405BInstruct,
+	MetaLlama318BInstruct,
+	MetaLlama3170BInstruct,
+	MistralMistralNemoFree,
+	MistralMistralNemo,
+	MistralCodestralMamba,
+	OpenAIGPT4oMini,
+	OpenAIGPT4oMini20240718,
+	Qwen27BInstructFree,
+	Qwen27BInstruct,
+	GoogleGemma227B,
+	Magnum72B,
+	GoogleGemma29BFree,
+	GoogleGemma29B,
+	O1AIYiLarge,
+	AI21JambaInstruct,
+	AnthropicClaude35Sonnet20240620SelfModerated,
+	AnthropicClaude35Sonnet20240620,
+	Sao10kLlama3Euryale70BV21,
+	Dolphin292Mixtral8x22B,
+	Qwen272BInstruct,
+	MistralMistral7BInstructFree,
+	MistralMistral7BInstruct,
+	MistralMistral7BInstructV03,
+	NousResearchHermes2ProLlama38B,
+	MicrosoftPhi3Mini128KInstructFree,
+	MicrosoftPhi3Mini128KInstruct,
+	MicrosoftPhi3Medium128KInstructFree,
+	MicrosoftPhi3Medium128KInstruct,
+	NeverSleepLlama3Lumimaid70B,
+	GoogleGeminiFlash15,
+	DeepSeekV25,
+	OpenAIGPT4o20240513,
+	MetaLlamaGuard28B,
+	OpenAIGPT4o,
+	OpenAIGPT4oExtended,
+	NeverSleepLlama3Lumimaid8BExtended,
+	NeverSleepLlama3Lumimaid8B,
+	Fimbulvetr11BV2,
+	MetaLlama38BInstructFree,
+	MetaLlama38BInstruct,
+	MetaLlama370BInstruct,
+	MistralMixtral8x22BInstruct,
+	WizardLM28x22B,
+	WizardLM27B,
+	GoogleGeminiPro15,
+	OpenAIGPT4Turbo,
+	CohereCommandRPlus,
+	CohereCommandR042024,
+	DatabricksDBRX132BInstruct,
+	MidnightRose70B,
+	CohereCommand,
+	CohereCommandR,
+	AnthropicClaude3HaikuSelfModerated,
+	AnthropicClaude3Haiku,
+	AnthropicClaude3OpusSelfModerated,
+	AnthropicClaude3Opus,
+	AnthropicClaude3SonnetSelfModerated,
+	AnthropicClaude3Sonnet,
+	CohereCommandR032024,
+	MistralLarge,
+	GoogleGemma7B,
+	OpenAIGPT35TurboOlderV0613,
+	OpenAIGPT4TurboPreview,
+	NousHermes2Mixtral8x7BDPO,
+	MistralSmall,
+	MistralTiny,
+	MistralMedium,
+	Dolphin26Mixtral8x7B,
+	GoogleGeminiProVision10,
+	GoogleGeminiPro10,
+	MistralMixtral8x7BBase,
+	MistralMixtral8x7BInstruct,
+	OpenChat357BFree,
+	OpenChat357B,
+	Noromaid20B,
+	AnthropicClaudeV2SelfModerated,
+	AnthropicClaudeV2,
+	AnthropicClaudeV21SelfModerated,
+	AnthropicClaudeV21,
+	OpenHermes25Mistral7B,
+	ToppyM7BFree,
+	ToppyM7B,
+	Goliath120B,
+	AutoRouter,
+	OpenAIGPT35Turbo16kOlderV1106,
+	OpenAIGPT4TurboOlderV1106,
+	GooglePaLM2Chat32k,
+	GooglePaLM2CodeChat32k,
+	Airoboros70B,
+	Xwin70B,
+	OpenAIGPT35TurboInstruct,
+	MistralMistral7BInstructV01,
+	PygmalionMythalion13B,
+	OpenAIGPT35Turbo16k,
+	OpenAIGPT432k,
+	OpenAIGPT432kOlderV0314,
+	NousHermes13B,
+	MancerWeaverAlpha,
+	HuggingFaceZephyr7BFree,
+	AnthropicClaudeV20SelfModerated,
+	AnthropicClaudeV20,
+	ReMMSLERP13B,
+	GooglePaLM2Chat,
+	GooglePaLM2CodeChat,
+	MythoMax13BFree,
+	MythoMax13B,
+	MetaLlama213BChat,
+	MetaLlama270BChat,
+	OpenAIGPT35Turbo,
+	OpenAIGPT35Turbo0125,
+	OpenAIGPT4,
+	OpenAIGPT4OlderV0314,
+}
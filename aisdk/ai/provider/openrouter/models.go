@@ -0,0 +1,161 @@
+package openrouter
+
+import (
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openrouter/model"
+)
+
+// Last updated: 2025-02-13
+// Prices are in USD per million tokens: https://openrouter.ai/models
+//
+// The catalog covers the OpenAI and Anthropic models in the model package,
+// which OpenRouter serves at the price of the original provider. OpenRouter
+// counts cached tokens as part of the input tokens for all providers.
+
+var (
+	textOnly      = []api.Modality{api.ModalityText}
+	textAndImages = []api.Modality{api.ModalityText, api.ModalityImage}
+	textAndFiles  = []api.Modality{api.ModalityText, api.ModalityImage, api.ModalityFile}
+)
+
+var catalog = api.NewModelCatalog(ProviderName, []api.ModelSpec{
+	{
+		IDs:           []string{model.OpenAIO3Mini, model.OpenAIO3MiniHigh},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	},
+	{
+		IDs:           []string{model.OpenAIO1},
+		ContextWindow: 200_000, MaxOutputTokens: 100_000, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 7.50, Output: 60},
+	},
+	{
+		IDs:           []string{model.OpenAIO1Preview, model.OpenAIO1Preview20240912},
+		ContextWindow: 128_000, MaxOutputTokens: 32_768, Input: textOnly,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 7.50, Output: 60},
+	},
+	{
+		IDs:           []string{model.OpenAIO1Mini, model.OpenAIO1Mini20240912},
+		ContextWindow: 128_000, MaxOutputTokens: 65_536, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT4o, model.OpenAIGPT4o20241120, model.OpenAIGPT4o20240806},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 2.50, CachedInput: 1.25, Output: 10},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT4o20240513},
+		ContextWindow: 128_000, MaxOutputTokens: 4_096, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 5, Output: 15},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT4oExtended},
+		ContextWindow: 128_000, MaxOutputTokens: 64_000, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 6, Output: 18},
+	},
+	{
+		IDs:           []string{model.OpenAIChatGPT4o},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 5, Output: 15},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT4oMini, model.OpenAIGPT4oMini20240718},
+		ContextWindow: 128_000, MaxOutputTokens: 16_384, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.15, CachedInput: 0.075, Output: 0.60},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT4Turbo, model.OpenAIGPT4TurboPreview, model.OpenAIGPT4TurboOlderV1106},
+		ContextWindow: 128_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 10, Output: 30},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT4, model.OpenAIGPT4OlderV0314},
+		ContextWindow: 8_191, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 30, Output: 60},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT432k, model.OpenAIGPT432kOlderV0314},
+		ContextWindow: 32_767, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 60, Output: 120},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT35Turbo, model.OpenAIGPT35Turbo0125},
+		ContextWindow: 16_385, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 0.50, Output: 1.50},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT35Turbo16kOlderV1106},
+		ContextWindow: 16_385, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1, Output: 2},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT35Turbo16k},
+		ContextWindow: 16_385, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 3, Output: 4},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT35TurboOlderV0613},
+		ContextWindow: 4_095, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1, Output: 2},
+	},
+	{
+		IDs:           []string{model.OpenAIGPT35TurboInstruct},
+		ContextWindow: 4_095, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 1.50, Output: 2},
+	},
+	{
+		IDs: []string{
+			model.AnthropicClaude35Sonnet, model.AnthropicClaude35SonnetSelfModerated,
+			model.AnthropicClaude35Sonnet20240620, model.AnthropicClaude35Sonnet20240620SelfModerated,
+		},
+		ContextWindow: 200_000, MaxOutputTokens: 8_192, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 3, CachedInput: 0.30, Output: 15},
+	},
+	{
+		IDs: []string{
+			model.AnthropicClaude35Haiku, model.AnthropicClaude35HaikuSelfModerated,
+			model.AnthropicClaude35Haiku20241022, model.AnthropicClaude35Haiku20241022SelfModerated,
+		},
+		ContextWindow: 200_000, MaxOutputTokens: 8_192, Input: textAndFiles,
+		Pricing: &api.Pricing{Input: 0.80, CachedInput: 0.08, Output: 4},
+	},
+	{
+		IDs:           []string{model.AnthropicClaude3Opus, model.AnthropicClaude3OpusSelfModerated},
+		ContextWindow: 200_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 15, CachedInput: 1.50, Output: 75},
+	},
+	{
+		IDs:           []string{model.AnthropicClaude3Sonnet, model.AnthropicClaude3SonnetSelfModerated},
+		ContextWindow: 200_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 3, CachedInput: 0.30, Output: 15},
+	},
+	{
+		IDs:           []string{model.AnthropicClaude3Haiku, model.AnthropicClaude3HaikuSelfModerated},
+		ContextWindow: 200_000, MaxOutputTokens: 4_096, Input: textAndImages,
+		Pricing: &api.Pricing{Input: 0.25, CachedInput: 0.03, Output: 1.25},
+	},
+	{
+		IDs: []string{
+			model.AnthropicClaudeV2, model.AnthropicClaudeV2SelfModerated,
+			model.AnthropicClaudeV21, model.AnthropicClaudeV21SelfModerated,
+		},
+		ContextWindow: 200_000, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 8, Output: 24},
+	},
+	{
+		IDs:           []string{model.AnthropicClaudeV20, model.AnthropicClaudeV20SelfModerated},
+		ContextWindow: 100_000, MaxOutputTokens: 4_096, Input: textOnly,
+		Pricing: &api.Pricing{Input: 8, Output: 24},
+	},
+})
+
+func init() {
+	api.RegisterModelCatalog(ProviderName, catalog)
+}
+
+// ModelInfo returns the capabilities, limits and price of the given OpenRouter
+// model. It returns false for models that are not in the catalog.
+func ModelInfo(modelID string) (api.ModelInfo, bool) {
+	return catalog(modelID)
+}
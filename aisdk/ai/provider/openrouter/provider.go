@@ -0,0 +1,138 @@
+package openrouter
+
+import (
+	"net/http"
+	"os"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openaicompat"
+	"go.jetify.com/ai/provider/openrouter/codec"
+	"go.jetify.com/ai/provider/openrouter/model"
+)
+
+// Provider gives access to the models available through OpenRouter.
+type Provider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	headers http.Header
+	models  map[string]bool
+}
+
+var _ api.Provider = &Provider{}
+
+// ProviderOption configures the OpenRouter provider.
+type ProviderOption func(*Provider)
+
+// WithAPIKey sets the API key. Defaults to the value of the
+// OPENROUTER_API_KEY environment variable.
+func WithAPIKey(apiKey string) ProviderOption {
+	return func(p *Provider) {
+		p.apiKey = apiKey
+	}
+}
+
+// WithBaseURL sets the base URL of the API. Defaults to DefaultBaseURL.
+func WithBaseURL(baseURL string) ProviderOption {
+	return func(p *Provider) {
+		p.baseURL = baseURL
+	}
+}
+
+// WithClient sets a custom HTTP client.
+func WithClient(client *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithHeaders sets custom headers for API requests, e.g. the HTTP-Referer and
+// X-Title headers used by OpenRouter to attribute requests to an app.
+func WithHeaders(headers http.Header) ProviderOption {
+	return func(p *Provider) {
+		for k, values := range headers {
+			for _, v := range values {
+				p.headers.Add(k, v)
+			}
+		}
+	}
+}
+
+// WithModels registers additional model IDs that LanguageModel accepts, on
+// top of the ones defined in the model package. Use it for models that were
+// added to OpenRouter after the model package was last updated.
+func WithModels(modelIDs ...string) ProviderOption {
+	return func(p *Provider) {
+		for _, id := range modelIDs {
+			p.models[id] = true
+		}
+	}
+}
+
+// NewProvider creates a new OpenRouter provider.
+func NewProvider(opts ...ProviderOption) *Provider {
+	p := &Provider{
+		baseURL: DefaultBaseURL,
+		apiKey:  os.Getenv(APIKeyEnvVar),
+		client:  http.DefaultClient,
+		headers: make(http.Header),
+		models:  make(map[string]bool, len(model.IDs)),
+	}
+	for _, id := range model.IDs {
+		p.models[id] = true
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// LanguageModel returns the language model with the given ID. It returns a
+// NoSuchModelError if the model is not defined in the model package or
+// registered using WithModels.
+func (p *Provider) LanguageModel(modelID string) (api.LanguageModel, error) {
+	if !p.models[modelID] {
+		return nil, api.NewNoSuchModelError(modelID, api.LanguageModelType)
+	}
+	return newLanguageModel(modelID, p), nil
+}
+
+// TextEmbeddingModel always returns a NoSuchModelError: embedding models are
+// not supported by this provider yet.
+func (p *Provider) TextEmbeddingModel(modelID string) (api.EmbeddingModel[string], error) {
+	return nil, api.NewNoSuchModelError(modelID, api.TextEmbeddingModelType)
+}
+
+// ImageModel always returns a NoSuchModelError: image models are not
+// supported by this provider yet.
+func (p *Provider) ImageModel(modelID string) (api.ImageModel, error) {
+	return nil, api.NewNoSuchModelError(modelID, api.ImageModelType)
+}
+
+// checkAPIKey returns a LoadAPIKeyError if the API key is missing.
+func (p *Provider) checkAPIKey() error {
+	if p.apiKey == "" {
+		return api.NewLoadAPIKeyError(
+			"OpenRouter API key is missing. Pass it using the WithAPIKey option or the " +
+				APIKeyEnvVar + " environment variable.")
+	}
+	return nil
+}
+
+// chatOptions returns the options of the openaicompat provider used to call
+// the chat completions API of OpenRouter.
+func (p *Provider) chatOptions() []openaicompat.ProviderOption {
+	return []openaicompat.ProviderOption{
+		openaicompat.WithName(ProviderName),
+		openaicompat.WithBaseURL(p.baseURL),
+		openaicompat.WithAPIKey(p.apiKey),
+		openaicompat.WithClient(p.client),
+		openaicompat.WithHeaders(p.headers),
+		openaicompat.WithExtension(openaicompat.Extension{
+			Encode:             codec.Encode,
+			NewMetadataDecoder: codec.NewMetadataDecoder,
+		}),
+	}
+}
@@ -0,0 +1,67 @@
+package openrouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/openrouter/model"
+)
+
+func TestProvider_LanguageModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ProviderOption
+		modelID string
+		wantErr bool
+	}{
+		{
+			name:    "known model",
+			modelID: model.OpenAIGPT4o,
+		},
+		{
+			name:    "unknown model",
+			modelID: "acme/unknown-model",
+			wantErr: true,
+		},
+		{
+			name:    "registered model",
+			opts:    []ProviderOption{WithModels("acme/new-model")},
+			modelID: "acme/new-model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(tt.opts...)
+			llm, err := provider.LanguageModel(tt.modelID)
+			if tt.wantErr {
+				var noSuchModel *api.NoSuchModelError
+				require.ErrorAs(t, err, &noSuchModel)
+				require.Equal(t, tt.modelID, noSuchModel.ModelID)
+				require.Equal(t, api.LanguageModelType, noSuchModel.ModelType)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.modelID, llm.ModelID())
+			require.Equal(t, ProviderName, llm.ProviderName())
+		})
+	}
+}
+
+func TestProvider_TextEmbeddingModel(t *testing.T) {
+	_, err := NewProvider().TextEmbeddingModel("openai/text-embedding-3-small")
+
+	var noSuchModel *api.NoSuchModelError
+	require.ErrorAs(t, err, &noSuchModel)
+	require.Equal(t, api.TextEmbeddingModelType, noSuchModel.ModelType)
+}
+
+func TestProvider_ImageModel(t *testing.T) {
+	_, err := NewProvider().ImageModel("openai/dall-e-3")
+
+	var noSuchModel *api.NoSuchModelError
+	require.ErrorAs(t, err, &noSuchModel)
+	require.Equal(t, api.ImageModelType, noSuchModel.ModelType)
+}
@@ -0,0 +1,191 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/anthropic"
+	"go.jetify.com/ai/provider/openai"
+)
+
+// DefaultSeparator separates the provider name from the model ID in the
+// model IDs resolved by a Registry, e.g. "openai:gpt-5".
+const DefaultSeparator = ":"
+
+// Registry resolves model IDs of the form "provider:model" into models, using
+// the providers registered under each name. It implements [api.Provider], so
+// a registry can be used wherever a provider is expected.
+//
+// The NoSuchModelErrors returned by a registry always report the full model
+// ID, e.g. "openai:gpt-5", including those returned by the providers.
+//
+// Aliases map a short name to a full model ID, so that configuration can
+// refer to models by their role:
+//
+//	registry := ai.NewRegistry(
+//		ai.WithRegistryProvider("openai", openai.NewProvider()),
+//		ai.WithRegistryAlias("fast", "openai:gpt-5-mini"),
+//	)
+//	model, err := registry.LanguageModel("fast")
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	separator string
+
+	mu        sync.RWMutex
+	providers map[string]api.Provider
+	aliases   map[string]string
+}
+
+var _ api.Provider = &Registry{}
+
+// RegistryOption configures a Registry.
+type RegistryOption func(*Registry)
+
+// WithRegistryProvider registers a provider under the given name.
+func WithRegistryProvider(name string, provider api.Provider) RegistryOption {
+	return func(r *Registry) {
+		r.providers[name] = provider
+	}
+}
+
+// WithRegistryAlias registers an alias for a full model ID, e.g. "fast" for
+// "openai:gpt-5-mini".
+func WithRegistryAlias(alias, modelID string) RegistryOption {
+	return func(r *Registry) {
+		r.aliases[alias] = modelID
+	}
+}
+
+// WithRegistrySeparator sets the separator between the provider name and the
+// model ID. Defaults to DefaultSeparator.
+func WithRegistrySeparator(separator string) RegistryOption {
+	return func(r *Registry) {
+		r.separator = separator
+	}
+}
+
+// NewRegistry creates a new registry with the given providers and aliases.
+func NewRegistry(opts ...RegistryOption) *Registry {
+	registry := &Registry{
+		separator: DefaultSeparator,
+		providers: map[string]api.Provider{},
+		aliases:   map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(registry)
+	}
+	return registry
+}
+
+// RegisterProvider registers a provider under the given name, replacing any
+// provider previously registered under the same name.
+func (r *Registry) RegisterProvider(name string, provider api.Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = provider
+}
+
+// RegisterAlias registers an alias for a full model ID, replacing any
+// previous alias with the same name.
+func (r *Registry) RegisterAlias(alias, modelID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[alias] = modelID
+}
+
+// LanguageModel returns the language model with the given ID, which is either
+// an alias or a model ID of the form "provider:model". It returns a
+// NoSuchModelError if the provider is not registered or doesn't have the model.
+func (r *Registry) LanguageModel(id string) (api.LanguageModel, error) {
+	provider, fullID, modelID, err := r.resolve(id, api.LanguageModelType)
+	if err != nil {
+		return nil, err
+	}
+	model, err := provider.LanguageModel(modelID)
+	if err != nil {
+		return nil, qualifyModelError(err, fullID)
+	}
+	return model, nil
+}
+
+// TextEmbeddingModel returns the embedding model with the given ID, which is
+// either an alias or a model ID of the form "provider:model". It returns a
+// NoSuchModelError if the provider is not registered or doesn't have the model.
+func (r *Registry) TextEmbeddingModel(id string) (api.EmbeddingModel[string], error) {
+	provider, fullID, modelID, err := r.resolve(id, api.TextEmbeddingModelType)
+	if err != nil {
+		return nil, err
+	}
+	model, err := provider.TextEmbeddingModel(modelID)
+	if err != nil {
+		return nil, qualifyModelError(err, fullID)
+	}
+	return model, nil
+}
+
+// ImageModel returns the image model with the given ID, which is either an
+// alias or a model ID of the form "provider:model". It returns a
+// NoSuchModelError if the provider is not registered or doesn't have the model.
+func (r *Registry) ImageModel(id string) (api.ImageModel, error) {
+	provider, fullID, modelID, err := r.resolve(id, api.ImageModelType)
+	if err != nil {
+		return nil, err
+	}
+	model, err := provider.ImageModel(modelID)
+	if err != nil {
+		return nil, qualifyModelError(err, fullID)
+	}
+	return model, nil
+}
+
+// resolve expands aliases and splits the ID into the provider and the model ID
+// within the provider. Only the first separator is significant, so model IDs
+// may contain the separator themselves. It also returns the full model ID that
+// the alias expanded to, which is reported in errors.
+func (r *Registry) resolve(id string, modelType api.ModelType) (api.Provider, string, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fullID := id
+	if target, ok := r.aliases[id]; ok {
+		fullID = target
+	}
+
+	providerName, modelID, ok := strings.Cut(fullID, r.separator)
+	if !ok {
+		return nil, "", "", api.NewNoSuchModelError(fullID, modelType)
+	}
+	provider, ok := r.providers[providerName]
+	if !ok {
+		return nil, "", "", api.NewNoSuchModelError(fullID, modelType)
+	}
+	return provider, fullID, modelID, nil
+}
+
+// qualifyModelError replaces a NoSuchModelError returned by a provider, which
+// reports the model ID within the provider, with one that reports the full
+// model ID, so that the errors of the registry always use the same format.
+func qualifyModelError(err error, fullID string) error {
+	var noSuchModel *api.NoSuchModelError
+	if errors.As(err, &noSuchModel) {
+		return api.NewNoSuchModelError(fullID, noSuchModel.ModelType)
+	}
+	return err
+}
+
+// defaultRegistry is used to resolve the model IDs set with WithModelID.
+var defaultRegistry = NewRegistry(
+	WithRegistryProvider(openai.ProviderName, openai.NewProvider()),
+	WithRegistryProvider(anthropic.ProviderName, anthropic.NewProvider()),
+)
+
+// DefaultRegistry returns the registry used to resolve the model IDs set with
+// WithModelID. It has the "openai" and "anthropic" providers registered with
+// their default settings. Register additional providers and aliases with
+// RegisterProvider and RegisterAlias.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
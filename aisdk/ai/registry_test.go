@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/anthropic"
+	"go.jetify.com/ai/provider/openai"
+)
+
+// fakeProvider returns a mockLanguageModel named after the model ID, and
+// fails for the IDs in unknown.
+type fakeProvider struct {
+	unknown map[string]bool
+}
+
+func (p *fakeProvider) LanguageModel(modelID string) (api.LanguageModel, error) {
+	if p.unknown[modelID] {
+		return nil, api.NewNoSuchModelError(modelID, api.LanguageModelType)
+	}
+	return &mockLanguageModel{name: modelID}, nil
+}
+
+func (p *fakeProvider) TextEmbeddingModel(modelID string) (api.EmbeddingModel[string], error) {
+	return &fakeEmbeddingModel{}, nil
+}
+
+func (p *fakeProvider) ImageModel(modelID string) (api.ImageModel, error) {
+	return &fakeImageModel{}, nil
+}
+
+func TestRegistry_LanguageModel(t *testing.T) {
+	registry := NewRegistry(
+		WithRegistryProvider("fake", &fakeProvider{unknown: map[string]bool{"missing": true}}),
+		WithRegistryAlias("fast", "fake:small"),
+		WithRegistryAlias("broken", "other:model"),
+	)
+
+	tests := []struct {
+		name      string
+		id        string
+		wantModel string
+		// wantErrID is the model ID reported by the NoSuchModelError.
+		wantErrID string
+	}{
+		{name: "provider and model", id: "fake:large", wantModel: "large"},
+		{name: "model ID with separator", id: "fake:ft:large:org", wantModel: "ft:large:org"},
+		{name: "alias", id: "fast", wantModel: "small"},
+		{name: "unknown provider", id: "other:large", wantErrID: "other:large"},
+		{name: "unknown model", id: "fake:missing", wantErrID: "fake:missing"},
+		{name: "missing separator", id: "large", wantErrID: "large"},
+		{name: "alias to unknown provider", id: "broken", wantErrID: "other:model"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := registry.LanguageModel(tt.id)
+			if tt.wantErrID != "" {
+				var noSuchModel *api.NoSuchModelError
+				require.ErrorAs(t, err, &noSuchModel)
+				assert.Equal(t, tt.wantErrID, noSuchModel.ModelID)
+				assert.Equal(t, api.LanguageModelType, noSuchModel.ModelType)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantModel, model.(*mockLanguageModel).name)
+		})
+	}
+}
+
+func TestRegistry_CustomSeparator(t *testing.T) {
+	registry := NewRegistry(WithRegistrySeparator(" > "))
+	registry.RegisterProvider("fake", &fakeProvider{})
+	registry.RegisterAlias("default", "fake > large")
+
+	model, err := registry.LanguageModel("fake > small")
+	require.NoError(t, err)
+	assert.Equal(t, "small", model.(*mockLanguageModel).name)
+
+	model, err = registry.LanguageModel("default")
+	require.NoError(t, err)
+	assert.Equal(t, "large", model.(*mockLanguageModel).name)
+
+	_, err = registry.LanguageModel("fake:small")
+	require.Error(t, err)
+}
+
+func TestRegistry_OtherModelTypes(t *testing.T) {
+	registry := NewRegistry(WithRegistryProvider("fake", &fakeProvider{}))
+
+	embeddingModel, err := registry.TextEmbeddingModel("fake:embed")
+	require.NoError(t, err)
+	assert.NotNil(t, embeddingModel)
+
+	imageModel, err := registry.ImageModel("fake:image")
+	require.NoError(t, err)
+	assert.NotNil(t, imageModel)
+
+	var noSuchModel *api.NoSuchModelError
+	_, err = registry.TextEmbeddingModel("other:embed")
+	require.ErrorAs(t, err, &noSuchModel)
+	assert.Equal(t, api.TextEmbeddingModelType, noSuchModel.ModelType)
+
+	_, err = registry.ImageModel("other:image")
+	require.ErrorAs(t, err, &noSuchModel)
+	assert.Equal(t, api.ImageModelType, noSuchModel.ModelType)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	model, err := DefaultRegistry().LanguageModel("openai:" + openai.ChatModelGPT5)
+	require.NoError(t, err)
+	assert.Equal(t, "openai", model.ProviderName())
+	assert.Equal(t, openai.ChatModelGPT5, model.ModelID())
+
+	model, err = DefaultRegistry().LanguageModel("anthropic:" + anthropic.ModelClaudeSonnet4_0)
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", model.ProviderName())
+	assert.Equal(t, anthropic.ModelClaudeSonnet4_0, model.ModelID())
+
+	_, err = DefaultRegistry().TextEmbeddingModel("anthropic:embed")
+	require.Error(t, err)
+}
+
+func TestWithModelID(t *testing.T) {
+	config, err := buildGenerateConfig([]GenerateOption{WithModelID("anthropic:" + anthropic.ModelClaudeSonnet4_0)})
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", config.Model.ProviderName())
+	assert.Equal(t, anthropic.ModelClaudeSonnet4_0, config.Model.ModelID())
+
+	// The last model option wins.
+	model := &mockLanguageModel{name: "explicit"}
+	config, err = buildGenerateConfig([]GenerateOption{WithModelID("openai:" + openai.ChatModelGPT5), WithModel(model)})
+	require.NoError(t, err)
+	assert.Same(t, model, config.Model)
+
+	_, err = GenerateTextStr(context.Background(), "hello", WithModelID("unknown:model"))
+	var noSuchModel *api.NoSuchModelError
+	require.ErrorAs(t, err, &noSuchModel)
+	assert.Equal(t, "unknown:model", noSuchModel.ModelID)
+}
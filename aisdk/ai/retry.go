@@ -0,0 +1,270 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.jetify.com/ai/api"
+)
+
+// defaultMaxRetries is the number of retries used when WithMaxRetries is not set.
+const defaultMaxRetries = 2
+
+// maxRetryAfter is the longest delay requested by a Retry-After header that is
+// honored even when it exceeds the backoff delay.
+const maxRetryAfter = time.Minute
+
+// BackoffPolicy decides how long to wait before retrying a failed call.
+type BackoffPolicy interface {
+	// Delay returns the delay before the given retry, starting at 1.
+	Delay(retry int) time.Duration
+}
+
+// ExponentialBackoff is a BackoffPolicy whose delay grows exponentially with
+// every retry, with random jitter to avoid retrying many calls at once.
+//
+// Zero values use the defaults: an initial delay of 2s, a multiplier of 2,
+// a maximum delay of 1m and a jitter of 0.2.
+type ExponentialBackoff struct {
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+
+	// Multiplier is the factor by which the delay grows with each retry.
+	Multiplier float64
+
+	// MaxDelay caps the delay before any retry.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction of the delay that is randomized: a jitter of 0.2
+	// returns delays within ±20% of the exponential delay.
+	Jitter float64
+}
+
+// Delay returns the delay before the given retry, starting at 1.
+func (b ExponentialBackoff) Delay(retry int) time.Duration {
+	initial := cmpOr(b.InitialDelay, 2*time.Second)
+	multiplier := cmpOr(b.Multiplier, 2)
+	maxDelay := cmpOr(b.MaxDelay, time.Minute)
+	jitter := cmpOr(b.Jitter, 0.2)
+
+	delay := float64(initial) * math.Pow(multiplier, float64(max(retry-1, 0)))
+	delay *= 1 + jitter*(2*rand.Float64()-1)
+	return min(time.Duration(delay), maxDelay)
+}
+
+// cmpOr returns value, or fallback if value is not positive.
+func cmpOr[T time.Duration | float64](value, fallback T) T {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// retrier retries calls that fail with a retryable error.
+type retrier struct {
+	maxRetries int
+	backoff    BackoffPolicy
+}
+
+func newRetrier(maxRetries int, backoff BackoffPolicy) *retrier {
+	if backoff == nil {
+		backoff = ExponentialBackoff{}
+	}
+	return &retrier{maxRetries: maxRetries, backoff: backoff}
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// retryable, or the maximum number of retries is reached.
+//
+// If the call fails on the first attempt with an error that is not
+// retryable, or retries are disabled, the error is returned as is. Otherwise,
+// an [api.RetryError] with the error of every attempt is returned.
+func retry[T any](ctx context.Context, r *retrier, fn func() (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	for attempt := 0; ; attempt++ {
+		result, err := fn()
+		if err == nil {
+			return result, nil
+		}
+		if r.maxRetries <= 0 {
+			return zero, err
+		}
+
+		errs = append(errs, err)
+		if !isRetryable(err) {
+			if len(errs) == 1 {
+				return zero, err
+			}
+			return zero, api.NewRetryError(
+				fmt.Sprintf("Failed after %d attempts with non-retryable error: %v", len(errs), err),
+				api.RetryReasonErrorNotRetryable, errs)
+		}
+		if attempt >= r.maxRetries {
+			return zero, api.NewRetryError(
+				fmt.Sprintf("Failed after %d attempts. Last error: %v", len(errs), err),
+				api.RetryReasonMaxRetriesExceeded, errs)
+		}
+
+		timer := time.NewTimer(r.delay(attempt+1, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			errs = append(errs, ctx.Err())
+			return zero, api.NewRetryError(
+				fmt.Sprintf("Canceled after %d attempts: %v", len(errs)-1, ctx.Err()),
+				api.RetryReasonAbort, errs)
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns how long to wait before the given retry. The delay requested
+// by the provider through the Retry-After headers takes precedence, as long
+// as it is reasonable.
+func (r *retrier) delay(retry int, err error) time.Duration {
+	delay := r.backoff.Delay(retry)
+	if retryAfter, ok := retryAfterDelay(err); ok && (retryAfter < maxRetryAfter || retryAfter < delay) {
+		return retryAfter
+	}
+	return delay
+}
+
+// isRetryable reports whether the error is an [api.APICallError] that can be
+// retried.
+func isRetryable(err error) bool {
+	var callErr *api.APICallError
+	return errors.As(err, &callErr) && callErr.IsRetryable()
+}
+
+// retryAfterDelay returns the delay requested by the provider in the
+// retry-after-ms or Retry-After headers of the failed response.
+func retryAfterDelay(err error) (time.Duration, bool) {
+	var callErr *api.APICallError
+	if !errors.As(err, &callErr) || callErr.Response == nil {
+		return 0, false
+	}
+	return parseRetryAfter(callErr.Response.Header)
+}
+
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	if value := header.Get("retry-after-ms"); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// streamWithRetries opens a stream, retrying if the call fails with a
+// retryable error, either when opening the stream or before the first content
+// event of the stream. Errors that occur after that are not retried.
+func streamWithRetries(
+	ctx context.Context, r *retrier, model api.LanguageModel, prompt []api.Message, opts api.CallOptions,
+) (*api.StreamResponse, error) {
+	if r.maxRetries <= 0 {
+		return model.Stream(ctx, prompt, opts)
+	}
+	return retry(ctx, r, func() (*api.StreamResponse, error) {
+		resp, err := model.Stream(ctx, prompt, opts)
+		if err != nil {
+			return nil, err
+		}
+		return peekStream(ctx, resp, isRetryable)
+	})
+}
+
+// peekStream waits for the first content event of the stream, skipping the
+// events of its preamble. If one of the events read is an error event whose
+// error satisfies fail, the stream is stopped and the error is returned.
+// Otherwise a stream that yields all the events, including the peeked ones,
+// is returned.
+//
+// The rest of the stream is read by pulling it, which keeps the underlying
+// stream open until the returned stream is consumed or ctx is done. Callers
+// that neither consume the stream nor cancel ctx leak it.
+func peekStream(ctx context.Context, resp *api.StreamResponse, fail func(error) bool) (*api.StreamResponse, error) {
+	pull, stopPull := iter.Pull(resp.Stream)
+
+	// The pulled stream is stopped when ctx is done, even if the returned
+	// stream is never consumed. next and stop must not be called concurrently.
+	var mu sync.Mutex
+	next := func() (api.StreamEvent, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		return pull()
+	}
+	stop := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopPull()
+	}
+	release := context.AfterFunc(ctx, stop)
+
+	var peeked []api.StreamEvent
+	for {
+		event, ok := next()
+		if !ok {
+			break
+		}
+		if errEvent, isErr := event.(*api.ErrorEvent); isErr {
+			if err := errEvent.AsError(); fail(err) {
+				release()
+				stop()
+				return nil, err
+			}
+		}
+		peeked = append(peeked, event)
+		if !isStreamPreamble(event) {
+			break
+		}
+	}
+
+	result := *resp
+	result.Stream = func(yield func(api.StreamEvent) bool) {
+		defer stop()
+		defer release()
+		for _, event := range peeked {
+			if !yield(event) {
+				return
+			}
+		}
+		for {
+			event, ok := next()
+			if !ok || !yield(event) {
+				return
+			}
+		}
+	}
+	return &result, nil
+}
+
+// isStreamPreamble reports whether the event can precede the content of a
+// stream without carrying any content itself.
+func isStreamPreamble(event api.StreamEvent) bool {
+	switch event.(type) {
+	case *api.StreamStartEvent, *api.ResponseMetadataEvent, *api.RawChunkEvent:
+		return true
+	default:
+		return false
+	}
+}
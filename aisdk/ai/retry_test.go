@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+// noBackoff retries immediately.
+type noBackoff struct{}
+
+func (noBackoff) Delay(int) time.Duration { return 0 }
+
+func apiCallError(status int, header http.Header) *api.APICallError {
+	return api.NewAPICallError(http.StatusText(status), nil, &http.Response{StatusCode: status, Header: header}, nil)
+}
+
+func TestGenerateText_Retries(t *testing.T) {
+	rateLimited := apiCallError(http.StatusTooManyRequests, nil)
+	badRequest := apiCallError(http.StatusBadRequest, nil)
+
+	tests := []struct {
+		name       string
+		results    []mock.MockResult
+		maxRetries int
+		wantReason api.RetryReason
+		wantErr    error
+		wantErrs   int
+	}{
+		{
+			name: "succeeds after retries",
+			results: []mock.MockResult{
+				{Error: rateLimited},
+				{Error: rateLimited},
+				{Response: textResponse("Hello", api.Usage{})},
+			},
+			maxRetries: 2,
+		},
+		{
+			name: "max retries exceeded",
+			results: []mock.MockResult{
+				{Error: rateLimited},
+				{Error: rateLimited},
+				{Error: rateLimited},
+			},
+			maxRetries: 2,
+			wantReason: api.RetryReasonMaxRetriesExceeded,
+			wantErrs:   3,
+		},
+		{
+			name: "non-retryable error after retries",
+			results: []mock.MockResult{
+				{Error: rateLimited},
+				{Error: badRequest},
+			},
+			maxRetries: 2,
+			wantReason: api.RetryReasonErrorNotRetryable,
+			wantErrs:   2,
+		},
+		{
+			name:       "non-retryable error is returned as is",
+			results:    []mock.MockResult{{Error: badRequest}},
+			maxRetries: 2,
+			wantErr:    badRequest,
+		},
+		{
+			name:       "retries disabled",
+			results:    []mock.MockResult{{Error: rateLimited}},
+			maxRetries: 0,
+			wantErr:    rateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := mock.NewGenerateModel(tt.results)
+			resp, err := GenerateTextStr(t.Context(), "Hi",
+				WithModel(model), WithMaxRetries(tt.maxRetries), WithBackoff(noBackoff{}))
+			model.AssertCount(t)
+
+			switch {
+			case tt.wantErr != nil:
+				assert.Same(t, tt.wantErr, err)
+			case tt.wantReason != "":
+				var retryErr *api.RetryError
+				require.ErrorAs(t, err, &retryErr)
+				assert.Equal(t, tt.wantReason, retryErr.Reason)
+				assert.Len(t, retryErr.Errors, tt.wantErrs)
+				assert.ErrorIs(t, err, rateLimited)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "Hello", resp.Content[0].(*api.TextBlock).Text)
+			}
+		})
+	}
+}
+
+func TestGenerateText_RetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Error: apiCallError(http.StatusServiceUnavailable, nil)},
+	})
+
+	go cancel()
+	_, err := GenerateTextStr(ctx, "Hi", WithModel(model), WithBackoff(ExponentialBackoff{InitialDelay: time.Hour}))
+
+	var retryErr *api.RetryError
+	require.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, api.RetryReasonAbort, retryErr.Reason)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryDelay(t *testing.T) {
+	backoff := ExponentialBackoff{InitialDelay: time.Second}
+	tests := []struct {
+		name   string
+		header http.Header
+		retry  int
+		want   time.Duration
+	}{
+		{name: "retry-after-ms", header: http.Header{"Retry-After-Ms": {"1500"}}, retry: 1, want: 1500 * time.Millisecond},
+		{name: "retry-after seconds", header: http.Header{"Retry-After": {"3"}}, retry: 1, want: 3 * time.Second},
+		{name: "retry-after-ms takes precedence", header: http.Header{"Retry-After-Ms": {"10"}, "Retry-After": {"3"}}, retry: 1, want: 10 * time.Millisecond},
+		{name: "long retry-after is ignored", header: http.Header{"Retry-After": {"120"}}, retry: 1, want: time.Second},
+		{name: "invalid header is ignored", header: http.Header{"Retry-After": {"soon"}}, retry: 1, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &retrier{maxRetries: 2, backoff: ExponentialBackoff{InitialDelay: time.Second, Jitter: 1e-9}}
+			got := r.delay(tt.retry, apiCallError(http.StatusTooManyRequests, tt.header))
+			assert.InDelta(t, tt.want, got, float64(time.Millisecond))
+		})
+	}
+
+	t.Run("http date", func(t *testing.T) {
+		date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+		delay, ok := parseRetryAfter(http.Header{"Retry-After": {date}})
+		require.True(t, ok)
+		assert.InDelta(t, 5*time.Second, delay, float64(time.Second))
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		backoff.Jitter = 1e-9
+		assert.InDelta(t, time.Second, backoff.Delay(1), float64(time.Millisecond))
+		assert.InDelta(t, 2*time.Second, backoff.Delay(2), float64(time.Millisecond))
+		assert.InDelta(t, 4*time.Second, backoff.Delay(3), float64(time.Millisecond))
+		assert.Equal(t, time.Minute, backoff.Delay(20))
+	})
+}
+
+func TestStreamText_Retries(t *testing.T) {
+	rateLimited := apiCallError(http.StatusTooManyRequests, nil)
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{&api.StreamStartEvent{}, &api.ResponseMetadataEvent{ID: "resp_1"}, &api.ErrorEvent{Err: rateLimited}, &api.FinishEvent{}},
+			{&api.StreamStartEvent{}, &api.ResponseMetadataEvent{ID: "resp_2"}, &api.TextDeltaEvent{TextDelta: "Hello"}, &api.FinishEvent{FinishReason: api.FinishReasonStop}},
+		},
+	}
+
+	resp, err := StreamTextStr(t.Context(), "Hi", WithModel(model), WithBackoff(noBackoff{}))
+	require.NoError(t, err)
+	assert.Len(t, model.prompts, 2)
+
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+	assert.Equal(t, []api.StreamEvent{
+		&api.StreamStartEvent{},
+		&api.ResponseMetadataEvent{ID: "resp_2"},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop},
+	}, events)
+}
+
+func TestPeekStream_StopsWhenContextIsDone(t *testing.T) {
+	done := make(chan struct{})
+	resp := &api.StreamResponse{Stream: func(yield func(api.StreamEvent) bool) {
+		defer close(done)
+		for _, event := range []api.StreamEvent{
+			&api.StreamStartEvent{},
+			&api.TextDeltaEvent{TextDelta: "Hello"},
+			&api.FinishEvent{FinishReason: api.FinishReasonStop},
+		} {
+			if !yield(event) {
+				return
+			}
+		}
+	}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	_, err := peekStream(ctx, resp, isRetryable)
+	require.NoError(t, err)
+
+	// The stream is never consumed, but it is released once ctx is done.
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream was not stopped")
+	}
+}
+
+func TestStreamText_NonRetryableStreamError(t *testing.T) {
+	streamErr := errors.New("boom")
+	model := &streamModel{
+		streams: [][]api.StreamEvent{
+			{&api.ErrorEvent{Err: streamErr}, &api.FinishEvent{}},
+		},
+	}
+
+	resp, err := StreamTextStr(t.Context(), "Hi", WithModel(model), WithBackoff(noBackoff{}))
+	require.NoError(t, err)
+
+	var events []api.StreamEvent
+	for event := range resp.Stream {
+		events = append(events, event)
+	}
+	require.NotEmpty(t, events)
+	assert.Equal(t, &api.ErrorEvent{Err: streamErr}, events[0])
+	assert.Len(t, model.prompts, 1)
+}
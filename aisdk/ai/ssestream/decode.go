@@ -0,0 +1,97 @@
+package ssestream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/sse"
+)
+
+// DecodeEvent converts an SSE event into a stream event. It returns io.EOF
+// for the event that ends the stream.
+func DecodeEvent(event *sse.Event) (api.StreamEvent, error) {
+	if event.Event == EventDone {
+		return nil, io.EOF
+	}
+
+	var data []byte
+	switch value := event.Data.(type) {
+	case sse.Raw:
+		data = value
+	default:
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	decoded, err := api.UnmarshalStreamEvent(api.EventType(event.Event), data)
+	if err != nil {
+		return nil, fmt.Errorf("ssestream: invalid %q event: %w", event.Event, err)
+	}
+	return decoded, nil
+}
+
+// Decoder reads stream events sent using the protocol described in the
+// package documentation.
+type Decoder struct {
+	events *sse.Decoder
+	done   bool
+}
+
+// NewDecoder returns a decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{events: sse.NewDecoder(r)}
+}
+
+// Decode returns the next stream event. It returns io.EOF once the event that
+// ends the stream has been read, and io.ErrUnexpectedEOF if the stream ends
+// without it.
+func (d *Decoder) Decode() (api.StreamEvent, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+
+	var event sse.Event
+	if err := d.events.Decode(&event); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	decoded, err := DecodeEvent(&event)
+	if errors.Is(err, io.EOF) {
+		d.done = true
+	}
+	return decoded, err
+}
+
+// DecodeStream returns the stream events read from body, which is closed once
+// the stream has been consumed. Errors while reading the stream, including a
+// stream that ends without the event that ends it, are yielded as error
+// events.
+func DecodeStream(body io.ReadCloser) iter.Seq[api.StreamEvent] {
+	return func(yield func(api.StreamEvent) bool) {
+		defer func() { _ = body.Close() }()
+
+		decoder := NewDecoder(body)
+		for {
+			event, err := decoder.Decode()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(&api.ErrorEvent{Err: err})
+				return
+			}
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
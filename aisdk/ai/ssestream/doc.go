@@ -0,0 +1,40 @@
+// Package ssestream sends AI SDK streams to clients as Server-Sent Events and
+// decodes them back into stream events.
+//
+// # Wire protocol
+//
+// Every [api.StreamEvent] is sent as an SSE event whose type is the
+// [api.EventType] of the stream event, and whose data is the JSON encoding of
+// the stream event:
+//
+//	event: stream-start
+//	data: {}
+//
+//	event: text-delta
+//	data: {"text_delta":"Hello"}
+//
+//	event: tool-call
+//	data: {"tool_call_id":"call_1","tool_name":"weather","args":{"city":"Paris"}}
+//
+//	event: finish
+//	data: {"usage":{"input_tokens":10,"output_tokens":5},"finish_reason":"stop"}
+//
+// Errors are sent as "error" events whose data contains the error message:
+//
+//	event: error
+//	data: {"error":"rate limit exceeded"}
+//
+// While the stream is idle, the server sends ": keep-alive" comments so that
+// proxies don't close the connection. Clients must ignore comments.
+//
+// Once all the events have been sent, the server ends the stream with a "done"
+// event. A stream that ends without it was interrupted:
+//
+//	event: done
+//	data: [DONE]
+//
+// Browsers can consume the stream with an EventSource, or by reading the body
+// of a fetch response, adding a listener for each event type. Go clients can
+// use [NewDecoder] or [DecodeStream], whose events can be passed to
+// builder.ResponseBuilder as usual.
+package ssestream
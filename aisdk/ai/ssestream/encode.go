@@ -0,0 +1,82 @@
+package ssestream
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"time"
+
+	"go.jetify.com/ai/api"
+	"go.jetify.com/sse"
+)
+
+const (
+	// EventDone is the type of the event that ends the stream.
+	EventDone = "done"
+
+	// DoneData is the data of the event that ends the stream.
+	DoneData = "[DONE]"
+
+	// DefaultHeartbeatInterval is the interval between the heartbeats sent by
+	// Serve, unless overridden with sse.WithHeartbeatInterval.
+	DefaultHeartbeatInterval = 15 * time.Second
+)
+
+// doneEvent is the event that ends the stream.
+var doneEvent = &sse.Event{Event: EventDone, Data: sse.Raw(DoneData)}
+
+// EncodeEvent converts a stream event into an SSE event.
+func EncodeEvent(event api.StreamEvent) (*sse.Event, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &sse.Event{Event: string(event.Type()), Data: sse.Raw(data)}, nil
+}
+
+// Send sends the events of the stream over the connection, followed by the
+// event that ends the stream. Events that can't be encoded are sent as error
+// events.
+//
+// It stops consuming the stream and returns an error if an event can't be
+// sent, e.g. because the client disconnected.
+func Send(ctx context.Context, conn *sse.Conn, stream iter.Seq[api.StreamEvent]) error {
+	for event := range stream {
+		encoded, err := EncodeEvent(event)
+		if err != nil {
+			encoded, err = EncodeEvent(&api.ErrorEvent{Err: err})
+			if err != nil {
+				return err
+			}
+		}
+		if err := conn.SendEvent(ctx, encoded); err != nil {
+			return err
+		}
+	}
+	return conn.SendEvent(ctx, doneEvent)
+}
+
+// Serve upgrades the request to an SSE stream and sends the events of the
+// stream, as described in the package documentation. Heartbeats are sent
+// every DefaultHeartbeatInterval, unless the given options configure a
+// different interval.
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		resp, err := ai.StreamTextStr(r.Context(), r.FormValue("prompt"))
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusInternalServerError)
+//			return
+//		}
+//		_ = ssestream.Serve(w, r, resp.Stream)
+//	}
+func Serve(w http.ResponseWriter, r *http.Request, stream iter.Seq[api.StreamEvent], opts ...sse.Option) error {
+	opts = append([]sse.Option{sse.WithHeartbeatInterval(DefaultHeartbeatInterval)}, opts...)
+	conn, err := sse.Upgrade(r.Context(), w, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	return Send(r.Context(), conn, stream)
+}
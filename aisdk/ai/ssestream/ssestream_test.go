@@ -0,0 +1,146 @@
+package ssestream
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+)
+
+func TestServe_RoundTrip(t *testing.T) {
+	events := []api.StreamEvent{
+		&api.StreamStartEvent{Warnings: []api.CallWarning{{Type: "unsupported-setting", Setting: "TopK"}}},
+		&api.ResponseMetadataEvent{ID: "resp_1", ModelID: "test-model"},
+		&api.ReasoningEvent{TextDelta: "Thinking"},
+		&api.ReasoningSignatureEvent{Signature: "sig"},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.TextDeltaEvent{TextDelta: ", world"},
+		&api.SourceEvent{Source: api.Source{SourceType: "url", ID: "src_1", URL: "https://example.com"}},
+		&api.FileEvent{MediaType: "image/png", Data: []byte{0x89, 0x50, 0x4e, 0x47}},
+		&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "weather", ArgsDelta: []byte(`{"city":`)},
+		&api.ToolCallDeltaEvent{ToolCallID: "call_1", ToolName: "weather", ArgsDelta: []byte(`"Paris"}`)},
+		&api.ToolCallEvent{ToolCallID: "call_1", ToolName: "weather", Args: []byte(`{"city":"Paris"}`)},
+		&api.FinishEvent{
+			FinishReason: api.FinishReasonToolCalls,
+			Usage:        api.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, Serve(w, r, slices.Values(events)))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	got := slices.Collect(DecodeStream(resp.Body))
+	assert.Equal(t, events, got)
+
+	built, err := builder.StreamToResponse(&api.StreamResponse{Stream: slices.Values(got)})
+	require.NoError(t, err)
+	expected, err := builder.StreamToResponse(&api.StreamResponse{Stream: slices.Values(events)})
+	require.NoError(t, err)
+	assert.Equal(t, expected, built)
+}
+
+func TestServe_Error(t *testing.T) {
+	events := []api.StreamEvent{
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.ErrorEvent{Err: errors.New("rate limit exceeded")},
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, Serve(rec, req, slices.Values(events)))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "event: error\ndata: {\"error\":\"rate limit exceeded\"}\n\n")
+	assert.True(t, strings.HasSuffix(body, "event: done\ndata: [DONE]\n\n"), body)
+
+	got := slices.Collect(DecodeStream(io.NopCloser(strings.NewReader(body))))
+	require.Len(t, got, 2)
+	assert.Equal(t, events[0], got[0])
+	require.IsType(t, &api.ErrorEvent{}, got[1])
+	assert.EqualError(t, got[1].(*api.ErrorEvent).Err.(error), "rate limit exceeded")
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []api.StreamEvent
+		err      error
+		errMsg   string
+	}{
+		{
+			name: "heartbeats are ignored",
+			body: ": keep-alive\n\n" +
+				"event: text-delta\ndata: {\"text_delta\":\"Hi\"}\n\n" +
+				": keep-alive\n\n" +
+				"event: done\ndata: [DONE]\n\n",
+			expected: []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hi"}},
+			err:      io.EOF,
+		},
+		{
+			name:     "missing end-of-stream marker",
+			body:     "event: text-delta\ndata: {\"text_delta\":\"Hi\"}\n\n",
+			expected: []api.StreamEvent{&api.TextDeltaEvent{TextDelta: "Hi"}},
+			err:      io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "events after the end-of-stream marker are ignored",
+			body:     "event: done\ndata: [DONE]\n\nevent: text-delta\ndata: {\"text_delta\":\"Hi\"}\n\n",
+			expected: nil,
+			err:      io.EOF,
+		},
+		{
+			name:   "unknown event type",
+			body:   "event: unknown\ndata: {}\n\n",
+			errMsg: `ssestream: invalid "unknown" event`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tt.body))
+
+			var got []api.StreamEvent
+			var err error
+			for {
+				var event api.StreamEvent
+				event, err = decoder.Decode()
+				if err != nil {
+					break
+				}
+				got = append(got, event)
+			}
+
+			assert.Equal(t, tt.expected, got)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeStream_UnexpectedEOF(t *testing.T) {
+	body := "event: text-delta\ndata: {\"text_delta\":\"Hi\"}\n\n"
+	got := slices.Collect(DecodeStream(io.NopCloser(strings.NewReader(body))))
+
+	require.Len(t, got, 2)
+	assert.Equal(t, &api.TextDeltaEvent{TextDelta: "Hi"}, got[0])
+	require.IsType(t, &api.ErrorEvent{}, got[1])
+	assert.ErrorIs(t, got[1].(*api.ErrorEvent).Err.(error), io.ErrUnexpectedEOF)
+}
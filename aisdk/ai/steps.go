@@ -0,0 +1,208 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.jetify.com/ai/api"
+)
+
+// defaultMaxSteps is the maximum number of steps used when executable tools
+// are provided but WithMaxSteps is not set.
+const defaultMaxSteps = 10
+
+// ErrStopSteps can be returned by a StepCallback to stop the generation loop
+// after the current step. It is not returned as an error by GenerateText or
+// StreamText.
+var ErrStopSteps = errors.New("stop steps")
+
+// Step contains the details of a single model call made during a
+// multi-step generation.
+type Step struct {
+	// Number is the zero-based index of the step.
+	Number int
+
+	// Prompt is the list of messages that was sent to the model in this step.
+	Prompt []api.Message
+
+	// CallOptions are the options that were sent to the model in this step.
+	CallOptions api.CallOptions
+
+	// Response is the response returned by the model in this step.
+	Response *api.Response
+
+	// ToolResults contains the results of the tools that were executed after
+	// the model responded, in the same order as the tool calls.
+	ToolResults []api.ToolResultBlock
+}
+
+// ToolCalls returns the tool calls requested by the model in this step.
+func (s *Step) ToolCalls() []*api.ToolCallBlock {
+	if s.Response == nil {
+		return nil
+	}
+	return toolCalls(s.Response.Content)
+}
+
+// StepCallback is called after each step of a multi-step generation.
+// Returning ErrStopSteps stops the loop gracefully, while any other error
+// aborts the generation and is returned to the caller.
+type StepCallback func(ctx context.Context, step *Step) error
+
+// StopCondition decides whether a multi-step generation should stop after
+// the given steps. It is only consulted when the last step contains tool
+// calls that were executed by the SDK.
+type StopCondition func(steps []*Step) bool
+
+// StepCountIs returns a StopCondition that stops the loop once n steps
+// have been completed.
+func StepCountIs(n int) StopCondition {
+	return func(steps []*Step) bool {
+		return len(steps) >= n
+	}
+}
+
+// HasToolCall returns a StopCondition that stops the loop once the model
+// calls any of the given tools.
+func HasToolCall(toolNames ...string) StopCondition {
+	return func(steps []*Step) bool {
+		if len(steps) == 0 {
+			return false
+		}
+		for _, call := range steps[len(steps)-1].ToolCalls() {
+			if slices.Contains(toolNames, call.ToolName) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// stepRunner holds the state shared by the generate and stream loops.
+type stepRunner struct {
+	opts        GenerateOptions
+	callOptions api.CallOptions
+	messages    []api.Message
+	steps       []*Step
+	totalUsage  api.Usage
+}
+
+func newStepRunner(prompt []api.Message, opts GenerateOptions) *stepRunner {
+	callOptions := opts.CallOptions
+	if len(opts.ExecutableTools) > 0 {
+		callOptions.Tools = slices.Clone(callOptions.Tools)
+		for _, tool := range opts.ExecutableTools {
+			callOptions.Tools = append(callOptions.Tools, tool.Definition)
+		}
+	}
+	return &stepRunner{
+		opts:        opts,
+		callOptions: callOptions,
+		messages:    slices.Clone(prompt),
+	}
+}
+
+// maxSteps returns the maximum number of model calls allowed.
+func (r *stepRunner) maxSteps() int {
+	if r.opts.MaxSteps > 0 {
+		return r.opts.MaxSteps
+	}
+	if len(r.opts.ExecutableTools) > 0 {
+		return defaultMaxSteps
+	}
+	return 1
+}
+
+// newStep creates the step for the next model call.
+func (r *stepRunner) newStep() *Step {
+	return &Step{
+		Number:      len(r.steps),
+		Prompt:      slices.Clone(r.messages),
+		CallOptions: r.callOptions,
+	}
+}
+
+// finishStep validates the tool calls of the model response, records it,
+// executes any tool calls and reports whether another step should be run.
+//
+// Without executable tools, an invalid tool call fails the step. Otherwise
+// the validation error is sent back to the model as the result of the call.
+func (r *stepRunner) finishStep(ctx context.Context, step *Step, resp *api.Response) (bool, error) {
+	var invalid map[*api.ToolCallBlock]error
+	if !r.opts.SkipToolCallValidation {
+		var err error
+		resp, invalid, err = validateToolCalls(ctx, resp, step, r.opts.RepairToolCall)
+		if err != nil {
+			return false, err
+		}
+	}
+	if len(invalid) > 0 && len(r.opts.ExecutableTools) == 0 {
+		for _, call := range toolCalls(resp.Content) {
+			if err, ok := invalid[call]; ok {
+				return false, err
+			}
+		}
+	}
+	step.Response = resp
+	r.steps = append(r.steps, step)
+	r.totalUsage = addUsage(r.totalUsage, resp.Usage)
+
+	calls := toolCalls(resp.Content)
+	executed := false
+	if len(calls) > 0 && len(r.opts.ExecutableTools) > 0 {
+		step.ToolResults, executed = executeToolCalls(ctx, calls, r.opts.ExecutableTools, invalid)
+	}
+
+	if r.opts.OnStepFinish != nil {
+		err := r.opts.OnStepFinish(ctx, step)
+		if errors.Is(err, ErrStopSteps) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// Only continue if the model is waiting for the results of tools that
+	// we executed ourselves.
+	if !executed || len(r.steps) >= r.maxSteps() {
+		return false, nil
+	}
+	for _, stop := range r.opts.StopConditions {
+		if stop(r.steps) {
+			return false, nil
+		}
+	}
+
+	r.messages = append(r.messages,
+		&api.AssistantMessage{Content: responseMessageContent(resp.Content)},
+		&api.ToolMessage{Content: step.ToolResults},
+	)
+	return true, nil
+}
+
+// responseMessageContent returns the blocks of a response that should be sent
+// back to the model as part of the assistant message.
+func responseMessageContent(content []api.ContentBlock) []api.ContentBlock {
+	result := make([]api.ContentBlock, 0, len(content))
+	for _, block := range content {
+		switch block.(type) {
+		case *api.TextBlock, *api.ReasoningBlock, *api.ToolCallBlock:
+			result = append(result, block)
+		}
+	}
+	return result
+}
+
+// addUsage returns the sum of two usage statistics.
+func addUsage(a, b api.Usage) api.Usage {
+	return api.Usage{
+		InputTokens:           a.InputTokens + b.InputTokens,
+		OutputTokens:          a.OutputTokens + b.OutputTokens,
+		TotalTokens:           a.TotalTokens + b.TotalTokens,
+		ReasoningTokens:       a.ReasoningTokens + b.ReasoningTokens,
+		CachedInputTokens:     a.CachedInputTokens + b.CachedInputTokens,
+		CacheWriteInputTokens: a.CacheWriteInputTokens + b.CacheWriteInputTokens,
+	}
+}
@@ -0,0 +1,165 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.jetify.com/ai/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of the OpenTelemetry semantic conventions for generative AI.
+// They are defined here instead of importing a semconv package, since the
+// GenAI conventions are still in development and change between versions.
+const (
+	keyOperationName      = attribute.Key("gen_ai.operation.name")
+	keyProviderName       = attribute.Key("gen_ai.provider.name")
+	keyRequestModel       = attribute.Key("gen_ai.request.model")
+	keyRequestMaxTokens   = attribute.Key("gen_ai.request.max_tokens")
+	keyRequestTemperature = attribute.Key("gen_ai.request.temperature")
+	keyRequestTopP        = attribute.Key("gen_ai.request.top_p")
+	keyRequestTopK        = attribute.Key("gen_ai.request.top_k")
+	keyRequestStop        = attribute.Key("gen_ai.request.stop_sequences")
+	keyRequestSeed        = attribute.Key("gen_ai.request.seed")
+	keyRequestPresence    = attribute.Key("gen_ai.request.presence_penalty")
+	keyRequestFrequency   = attribute.Key("gen_ai.request.frequency_penalty")
+	keyResponseID         = attribute.Key("gen_ai.response.id")
+	keyResponseModel      = attribute.Key("gen_ai.response.model")
+	keyResponseFinish     = attribute.Key("gen_ai.response.finish_reasons")
+	keyUsageInputTokens   = attribute.Key("gen_ai.usage.input_tokens")
+	keyUsageOutputTokens  = attribute.Key("gen_ai.usage.output_tokens")
+	keyTokenType          = attribute.Key("gen_ai.token.type")
+	keyInputMessages      = attribute.Key("gen_ai.input.messages")
+	keyOutputMessages     = attribute.Key("gen_ai.output.messages")
+	keyToolName           = attribute.Key("gen_ai.tool.name")
+	keyToolDescription    = attribute.Key("gen_ai.tool.description")
+	keyToolType           = attribute.Key("gen_ai.tool.type")
+	keyToolCallArguments  = attribute.Key("gen_ai.tool.call.arguments")
+	keyToolCallResult     = attribute.Key("gen_ai.tool.call.result")
+	keyErrorType          = attribute.Key("error.type")
+
+	// keyStreamIncomplete is specific to this package: it marks the spans of
+	// streams that the consumer stopped iterating before they finished.
+	keyStreamIncomplete = attribute.Key("jetify.ai.stream.incomplete")
+)
+
+// Attribute values of the semantic conventions. The generate_text and
+// stream_text operations are specific to this package: they wrap the chat and
+// execute_tool operations of a multi-step generation.
+const (
+	operationChat         = "chat"
+	operationExecuteTool  = "execute_tool"
+	operationGenerateText = "generate_text"
+	operationStreamText   = "stream_text"
+	tokenTypeInput        = "input"
+	tokenTypeOutput       = "output"
+	toolTypeFunction      = "function"
+)
+
+// modelAttributes identifies the operation and the model that handles it.
+func modelAttributes(operation string, model api.LanguageModel) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		keyOperationName.String(operation),
+		keyProviderName.String(model.ProviderName()),
+		keyRequestModel.String(model.ModelID()),
+	}
+}
+
+// requestAttributes describes the settings of a model call.
+func requestAttributes(opts api.CallOptions) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if opts.MaxOutputTokens > 0 {
+		attrs = append(attrs, keyRequestMaxTokens.Int(opts.MaxOutputTokens))
+	}
+	if opts.Temperature != nil {
+		attrs = append(attrs, keyRequestTemperature.Float64(*opts.Temperature))
+	}
+	if opts.TopP != 0 {
+		attrs = append(attrs, keyRequestTopP.Float64(opts.TopP))
+	}
+	if opts.TopK != 0 {
+		attrs = append(attrs, keyRequestTopK.Int(opts.TopK))
+	}
+	if len(opts.StopSequences) > 0 {
+		attrs = append(attrs, keyRequestStop.StringSlice(opts.StopSequences))
+	}
+	if opts.Seed != 0 {
+		attrs = append(attrs, keyRequestSeed.Int(opts.Seed))
+	}
+	if opts.PresencePenalty != 0 {
+		attrs = append(attrs, keyRequestPresence.Float64(opts.PresencePenalty))
+	}
+	if opts.FrequencyPenalty != 0 {
+		attrs = append(attrs, keyRequestFrequency.Float64(opts.FrequencyPenalty))
+	}
+	return attrs
+}
+
+// responseAttributes describes the result of a model call.
+func responseAttributes(info responseInfo, finishReason api.FinishReason, usage api.Usage) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		keyUsageInputTokens.Int(usage.InputTokens),
+		keyUsageOutputTokens.Int(usage.OutputTokens),
+	}
+	if finishReason != "" {
+		attrs = append(attrs, keyResponseFinish.StringSlice([]string{string(finishReason)}))
+	}
+	if info.id != "" {
+		attrs = append(attrs, keyResponseID.String(info.id))
+	}
+	if info.modelID != "" {
+		attrs = append(attrs, keyResponseModel.String(info.modelID))
+	}
+	return attrs
+}
+
+// incompleteAttributes describes a stream that the consumer stopped before it
+// finished, whose finish reason and usage are unknown.
+func incompleteAttributes(info responseInfo) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{keyStreamIncomplete.Bool(true)}
+	if info.id != "" {
+		attrs = append(attrs, keyResponseID.String(info.id))
+	}
+	if info.modelID != "" {
+		attrs = append(attrs, keyResponseModel.String(info.modelID))
+	}
+	return attrs
+}
+
+// responseInfo contains the response fields that are reported by both
+// generate and stream calls.
+type responseInfo struct {
+	id      string
+	modelID string
+}
+
+func newResponseInfo(info *api.ResponseInfo) responseInfo {
+	if info == nil {
+		return responseInfo{}
+	}
+	return responseInfo{id: info.ID, modelID: info.ModelID}
+}
+
+// jsonAttribute returns an attribute with the JSON encoding of the value, as
+// the semantic conventions recommend for structured content.
+func jsonAttribute(key attribute.Key, value any) attribute.KeyValue {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return key.String(fmt.Sprintf("%v", value))
+	}
+	return key.String(string(data))
+}
+
+// errorType returns the value of the error.type attribute for the given error.
+func errorType(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+// recordError marks the span as failed with the given error.
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(keyErrorType.String(errorType(err)))
+}
@@ -0,0 +1,138 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GenerateText calls ai.GenerateText inside a "generate_text" span. The model
+// calls and tool calls of every step are recorded as child spans.
+func (t *Telemetry) GenerateText(ctx context.Context, prompt []api.Message, opts ...ai.GenerateOption) (*api.Response, error) {
+	ctx, span := t.tracer.Start(ctx, operationGenerateText, trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+	if t.recordContent {
+		span.SetAttributes(jsonAttribute(keyInputMessages, prompt))
+	}
+
+	resp, err := ai.GenerateTextSteps(ctx, prompt, t.withInstrumentation(opts, span, operationGenerateText)...)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	t.finishGeneration(span, resp.Response, resp.TotalUsage)
+	return resp.Response, nil
+}
+
+// GenerateTextStr is like GenerateText, for a string prompt.
+func (t *Telemetry) GenerateTextStr(ctx context.Context, prompt string, opts ...ai.GenerateOption) (*api.Response, error) {
+	return t.GenerateText(ctx, userPrompt(prompt), opts...)
+}
+
+// StreamText calls ai.StreamText inside a "stream_text" span. The model calls
+// and tool calls of every step are recorded as child spans.
+//
+// The span ends when the stream is fully consumed or the consumer stops
+// iterating, so always consume the stream. A stream that the consumer stopped
+// is marked as incomplete.
+func (t *Telemetry) StreamText(ctx context.Context, prompt []api.Message, opts ...ai.GenerateOption) (*api.StreamResponse, error) {
+	ctx, span := t.tracer.Start(ctx, operationStreamText, trace.WithSpanKind(trace.SpanKindInternal))
+	if t.recordContent {
+		span.SetAttributes(jsonAttribute(keyInputMessages, prompt))
+	}
+
+	resp, err := ai.StreamTextSteps(ctx, prompt, t.withInstrumentation(opts, span, operationStreamText)...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	stream := resp.Stream
+	resp.Stream = func(yield func(api.StreamEvent) bool) {
+		defer span.End()
+
+		var streamErr error
+		stopped := false
+		for event := range stream {
+			if errEvent, ok := event.(*api.ErrorEvent); ok {
+				streamErr = errEvent.AsError()
+			}
+			if !yield(event) {
+				stopped = true
+				break
+			}
+		}
+
+		if streamErr != nil {
+			recordError(span, streamErr)
+			return
+		}
+		if stopped {
+			span.SetAttributes(incompleteAttributes(responseInfo{})...)
+			return
+		}
+		if len(resp.Steps) > 0 {
+			t.finishGeneration(span, resp.Steps[len(resp.Steps)-1].Response, resp.TotalUsage)
+		}
+	}
+	return resp.StreamResponse, nil
+}
+
+// StreamTextStr is like StreamText, for a string prompt.
+func (t *Telemetry) StreamTextStr(ctx context.Context, prompt string, opts ...ai.GenerateOption) (*api.StreamResponse, error) {
+	return t.StreamText(ctx, userPrompt(prompt), opts...)
+}
+
+func userPrompt(prompt string) []api.Message {
+	return []api.Message{
+		&api.UserMessage{Content: []api.ContentBlock{&api.TextBlock{Text: prompt}}},
+	}
+}
+
+// withInstrumentation returns the given options followed by an option that
+// instruments the model and the executable tools. It goes last so that it
+// sees the model and tools set by the other options.
+func (t *Telemetry) withInstrumentation(opts []ai.GenerateOption, span trace.Span, operation string) []ai.GenerateOption {
+	instrument := func(o *ai.GenerateOptions) {
+		if o.ModelID != "" {
+			model, err := ai.DefaultRegistry().LanguageModel(o.ModelID)
+			if err != nil {
+				// Leave the model ID unresolved so that the call reports the error.
+				return
+			}
+			o.Model = model
+			o.ModelID = ""
+		}
+		if o.Model != nil {
+			span.SetName(operation + " " + o.Model.ModelID())
+			span.SetAttributes(modelAttributes(operation, o.Model)...)
+			span.SetAttributes(requestAttributes(o.CallOptions)...)
+			o.Model = ai.WrapLanguageModel(o.Model, t.Middleware())
+		}
+
+		tools := make([]*ai.Tool, len(o.ExecutableTools))
+		for i, tool := range o.ExecutableTools {
+			tools[i] = t.WrapTool(tool)
+		}
+		o.ExecutableTools = tools
+	}
+	return append(opts[:len(opts):len(opts)], instrument)
+}
+
+// finishGeneration records the final response and the total usage of a
+// generation in its span.
+func (t *Telemetry) finishGeneration(span trace.Span, final *api.Response, totalUsage api.Usage) {
+	var finishReason api.FinishReason
+	info := responseInfo{}
+	if final != nil {
+		finishReason = final.FinishReason
+		info = newResponseInfo(final.ResponseInfo)
+		if t.recordContent {
+			span.SetAttributes(jsonAttribute(keyOutputMessages, final.Content))
+		}
+	}
+	span.SetAttributes(responseAttributes(info, finishReason, totalUsage)...)
+}
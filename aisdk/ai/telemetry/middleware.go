@@ -0,0 +1,201 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/builder"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns a middleware that creates a "chat" span for every call
+// to the model and records its duration, token usage and, for streams, its
+// time to first token.
+//
+// GenerateText and StreamText apply it automatically. Use it directly to
+// instrument models called in other ways:
+//
+//	model := ai.WrapLanguageModel(openai.NewLanguageModel(openai.ChatModelGPT5),
+//		tel.Middleware(),
+//	)
+//
+// The span of a stream ends when the stream is fully consumed or the consumer
+// stops iterating. A stream that the consumer stopped is marked as incomplete,
+// and its partial token usage is not recorded.
+func (t *Telemetry) Middleware() *ai.Middleware {
+	return &ai.Middleware{
+		WrapGenerate: t.wrapGenerate,
+		WrapStream:   t.wrapStream,
+	}
+}
+
+func (t *Telemetry) startChat(
+	ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions,
+) (context.Context, trace.Span, []attribute.KeyValue) {
+	attrs := modelAttributes(operationChat, model)
+	ctx, span := t.tracer.Start(ctx, operationChat+" "+model.ModelID(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(requestAttributes(opts)...),
+	)
+	if t.recordContent {
+		span.SetAttributes(jsonAttribute(keyInputMessages, prompt))
+	}
+	return ctx, span, attrs
+}
+
+func (t *Telemetry) wrapGenerate(
+	ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions,
+) (*api.Response, error) {
+	ctx, span, attrs := t.startChat(ctx, model, prompt, opts)
+	defer span.End()
+
+	start := time.Now()
+	resp, err := model.Generate(ctx, prompt, opts)
+	if err != nil {
+		recordError(span, err)
+		t.recordDuration(ctx, start, attrs, err)
+		return nil, err
+	}
+
+	t.finishChat(ctx, span, attrs, newResponseInfo(resp.ResponseInfo), resp)
+	t.recordDuration(ctx, start, attrs, nil)
+	return resp, nil
+}
+
+func (t *Telemetry) wrapStream(
+	ctx context.Context, model api.LanguageModel, prompt []api.Message, opts api.CallOptions,
+) (*api.StreamResponse, error) {
+	ctx, span, attrs := t.startChat(ctx, model, prompt, opts)
+
+	start := time.Now()
+	resp, err := model.Stream(ctx, prompt, opts)
+	if err != nil {
+		recordError(span, err)
+		t.recordDuration(ctx, start, attrs, err)
+		span.End()
+		return nil, err
+	}
+
+	result := *resp
+	result.Stream = func(yield func(api.StreamEvent) bool) {
+		defer span.End()
+
+		info := newResponseInfo(resp.ResponseInfo)
+		response := builder.NewResponseBuilder()
+		firstContent := true
+		var streamErr error
+		stopped := false
+		for event := range resp.Stream {
+			switch event := event.(type) {
+			case *api.StreamStartEvent, *api.RawChunkEvent:
+				// Not content: they don't count towards the time to first token.
+			case *api.ResponseMetadataEvent:
+				if event.ID != "" {
+					info.id = event.ID
+				}
+				if event.ModelID != "" {
+					info.modelID = event.ModelID
+				}
+			case *api.ErrorEvent:
+				streamErr = event.AsError()
+			default:
+				if firstContent {
+					firstContent = false
+					t.timeToFirstToken.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+				}
+			}
+			_ = response.AddEvent(event)
+
+			if !yield(event) {
+				stopped = true
+				break
+			}
+		}
+
+		if streamErr != nil {
+			recordError(span, streamErr)
+			t.recordDuration(ctx, start, attrs, streamErr)
+			return
+		}
+		if stopped {
+			span.SetAttributes(incompleteAttributes(info)...)
+			t.recordDuration(ctx, start, attrs, nil)
+			return
+		}
+		built, _ := response.Build()
+		t.finishChat(ctx, span, attrs, info, built)
+		t.recordDuration(ctx, start, attrs, nil)
+	}
+	return &result, nil
+}
+
+// finishChat records the response of a model call in its span and metrics.
+func (t *Telemetry) finishChat(
+	ctx context.Context, span trace.Span, attrs []attribute.KeyValue, info responseInfo, resp *api.Response,
+) {
+	span.SetAttributes(responseAttributes(info, resp.FinishReason, resp.Usage)...)
+	if t.recordContent {
+		span.SetAttributes(jsonAttribute(keyOutputMessages, resp.Content))
+	}
+
+	if info.modelID != "" {
+		attrs = append(attrs[:len(attrs):len(attrs)], keyResponseModel.String(info.modelID))
+	}
+	t.tokenUsage.Record(ctx, int64(resp.Usage.InputTokens),
+		metric.WithAttributes(attrs...), metric.WithAttributes(keyTokenType.String(tokenTypeInput)))
+	t.tokenUsage.Record(ctx, int64(resp.Usage.OutputTokens),
+		metric.WithAttributes(attrs...), metric.WithAttributes(keyTokenType.String(tokenTypeOutput)))
+}
+
+// recordDuration records the duration of an operation that started at the
+// given time.
+func (t *Telemetry) recordDuration(ctx context.Context, start time.Time, attrs []attribute.KeyValue, err error) {
+	if err != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], keyErrorType.String(errorType(err)))
+	}
+	t.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// WrapTool returns a copy of the tool that creates an "execute_tool" span for
+// every call. GenerateText and StreamText apply it to the executable tools
+// automatically.
+func (t *Telemetry) WrapTool(tool *ai.Tool) *ai.Tool {
+	execute := tool.Execute
+	name := tool.Name()
+	return &ai.Tool{
+		Definition: tool.Definition,
+		Execute: func(ctx context.Context, args json.RawMessage) (any, error) {
+			ctx, span := t.tracer.Start(ctx, operationExecuteTool+" "+name,
+				trace.WithSpanKind(trace.SpanKindInternal),
+				trace.WithAttributes(
+					keyOperationName.String(operationExecuteTool),
+					keyToolName.String(name),
+					keyToolType.String(toolTypeFunction),
+				),
+			)
+			defer span.End()
+			if tool.Definition.Description != "" {
+				span.SetAttributes(keyToolDescription.String(tool.Definition.Description))
+			}
+			if t.recordContent {
+				span.SetAttributes(keyToolCallArguments.String(string(args)))
+			}
+
+			output, err := execute(ctx, args)
+			if err != nil {
+				recordError(span, err)
+				return nil, err
+			}
+			if t.recordContent {
+				span.SetAttributes(jsonAttribute(keyToolCallResult, output))
+			}
+			return output, nil
+		},
+	}
+}
@@ -0,0 +1,131 @@
+// Package telemetry instruments language model calls with OpenTelemetry
+// traces and metrics, following the OpenTelemetry semantic conventions for
+// generative AI: https://opentelemetry.io/docs/specs/semconv/gen-ai/
+//
+// It is a wrapper around the ai package in its own module, so that programs
+// that don't use OpenTelemetry don't depend on it:
+//
+//	tel, err := telemetry.New()
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := tel.GenerateText(ctx, prompt,
+//		ai.WithModel(model),
+//		ai.WithExecutableTools(weatherTool),
+//	)
+//
+// This creates a span for the whole generation, with a child span for every
+// model call (one per step) and for every tool call.
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "go.jetify.com/ai/telemetry"
+
+// Option configures the telemetry.
+type Option func(*options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	recordContent  bool
+}
+
+// WithTracerProvider sets the tracer provider used to create spans. Defaults
+// to the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider used to record metrics. Defaults
+// to the global meter provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = provider
+	}
+}
+
+// WithRecordContent records the prompts, completions, tool arguments and
+// tool results in the spans. They are not recorded by default, since they
+// may contain sensitive data and can be large.
+func WithRecordContent() Option {
+	return func(o *options) {
+		o.recordContent = true
+	}
+}
+
+// Telemetry creates spans and records metrics for language model calls. It is
+// safe for concurrent use.
+type Telemetry struct {
+	tracer        trace.Tracer
+	recordContent bool
+
+	duration         metric.Float64Histogram
+	timeToFirstToken metric.Float64Histogram
+	tokenUsage       metric.Int64Histogram
+}
+
+// durationBuckets are the bucket boundaries, in seconds, recommended by the
+// semantic conventions for gen_ai.client.operation.duration.
+var durationBuckets = []float64{
+	0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92,
+}
+
+// tokenBuckets are the bucket boundaries recommended by the semantic
+// conventions for gen_ai.client.token.usage.
+var tokenBuckets = []float64{
+	1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864,
+}
+
+// New creates a Telemetry with the given options.
+func New(opts ...Option) (*Telemetry, error) {
+	options := options{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	meter := options.meterProvider.Meter(instrumentationName)
+	duration, err := meter.Float64Histogram("gen_ai.client.operation.duration",
+		metric.WithDescription("Duration of GenAI operations."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// The semantic conventions only define time to first token as a server
+	// metric, so this mirrors gen_ai.server.time_to_first_token.
+	timeToFirstToken, err := meter.Float64Histogram("gen_ai.client.time_to_first_token",
+		metric.WithDescription("Time to receive the first content of streaming GenAI operations."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tokenUsage, err := meter.Int64Histogram("gen_ai.client.token.usage",
+		metric.WithDescription("Number of input and output tokens used by GenAI operations."),
+		metric.WithUnit("{token}"),
+		metric.WithExplicitBucketBoundaries(tokenBuckets...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Telemetry{
+		tracer:           options.tracerProvider.Tracer(instrumentationName),
+		recordContent:    options.recordContent,
+		duration:         duration,
+		timeToFirstToken: timeToFirstToken,
+		tokenUsage:       tokenUsage,
+	}, nil
+}
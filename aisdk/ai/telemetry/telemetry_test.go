@@ -0,0 +1,289 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testTelemetry struct {
+	*Telemetry
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+}
+
+func newTestTelemetry(t *testing.T, opts ...Option) *testTelemetry {
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	opts = append(opts,
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	tel, err := New(opts...)
+	require.NoError(t, err)
+	return &testTelemetry{Telemetry: tel, spans: spans, reader: reader}
+}
+
+// span returns the ended span with the given name.
+func (tt *testTelemetry) span(t *testing.T, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range tt.spans.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no span named %q", name)
+	return tracetest.SpanStub{}
+}
+
+// histogram returns the data points of the histogram with the given name.
+func (tt *testTelemetry) histogram(t *testing.T, name string) []metricdata.HistogramDataPoint[float64] {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	require.NoError(t, tt.reader.Collect(t.Context(), &data))
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m.Data.(metricdata.Histogram[float64]).DataPoints
+			}
+		}
+	}
+	return nil
+}
+
+// tokenUsage returns the sum of the token usage histogram, by token type.
+func (tt *testTelemetry) tokenUsage(t *testing.T) map[string]int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	require.NoError(t, tt.reader.Collect(t.Context(), &data))
+	usage := map[string]int64{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "gen_ai.client.token.usage" {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Histogram[int64]).DataPoints {
+				tokenType, _ := point.Attributes.Value(keyTokenType)
+				usage[tokenType.AsString()] += point.Sum
+			}
+		}
+	}
+	return usage
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	result := map[attribute.Key]attribute.Value{}
+	for _, attr := range span.Attributes {
+		result[attr.Key] = attr.Value
+	}
+	return result
+}
+
+func TestGenerateText(t *testing.T) {
+	tel := newTestTelemetry(t, WithRecordContent())
+
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: &api.Response{
+			Content: []api.ContentBlock{&api.ToolCallBlock{
+				ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{"city":"Paris"}`),
+			}},
+			FinishReason: api.FinishReasonToolCalls,
+			Usage:        api.Usage{InputTokens: 10, OutputTokens: 5},
+			ResponseInfo: &api.ResponseInfo{ID: "resp_1", ModelID: "gpt-5-2025-08-07"},
+		}},
+		{Response: &api.Response{
+			Content:      []api.ContentBlock{&api.TextBlock{Text: "Sunny."}},
+			FinishReason: api.FinishReasonStop,
+			Usage:        api.Usage{InputTokens: 20, OutputTokens: 3},
+			ResponseInfo: &api.ResponseInfo{ID: "resp_2", ModelID: "gpt-5-2025-08-07"},
+		}},
+	}, mock.WithProviderName("openai"), mock.WithModelID("gpt-5"))
+
+	weather := ai.NewTool("weather", "Get the weather", nil,
+		func(ctx context.Context, args json.RawMessage) (any, error) {
+			return "sunny", nil
+		})
+
+	_, err := tel.GenerateTextStr(t.Context(), "Weather in Paris?",
+		ai.WithModel(model),
+		ai.WithExecutableTools(weather),
+		ai.WithMaxOutputTokens(100),
+	)
+	require.NoError(t, err)
+
+	spans := tel.spans.GetSpans()
+	require.Len(t, spans, 4)
+
+	root := tel.span(t, "generate_text gpt-5")
+	assert.False(t, root.Parent.IsValid())
+	rootAttrs := attributes(root)
+	assert.Equal(t, "openai", rootAttrs[keyProviderName].AsString())
+	assert.Equal(t, int64(100), rootAttrs[keyRequestMaxTokens].AsInt64())
+	assert.Equal(t, int64(30), rootAttrs[keyUsageInputTokens].AsInt64())
+	assert.Equal(t, int64(8), rootAttrs[keyUsageOutputTokens].AsInt64())
+	assert.Equal(t, []string{"stop"}, rootAttrs[keyResponseFinish].AsStringSlice())
+	assert.Equal(t, "resp_2", rootAttrs[keyResponseID].AsString())
+	assert.JSONEq(t, `[{"type":"text","text":"Sunny."}]`, rootAttrs[keyOutputMessages].AsString())
+
+	var chats []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == "chat gpt-5" {
+			chats = append(chats, span)
+		}
+	}
+	require.Len(t, chats, 2)
+	for _, chat := range chats {
+		assert.Equal(t, root.SpanContext.SpanID(), chat.Parent.SpanID())
+	}
+	firstChat := attributes(chats[0])
+	assert.Equal(t, "chat", firstChat[keyOperationName].AsString())
+	assert.Equal(t, "gpt-5", firstChat[keyRequestModel].AsString())
+	assert.Equal(t, "gpt-5-2025-08-07", firstChat[keyResponseModel].AsString())
+	assert.Equal(t, "resp_1", firstChat[keyResponseID].AsString())
+	assert.Equal(t, int64(10), firstChat[keyUsageInputTokens].AsInt64())
+	assert.Equal(t, []string{"tool-calls"}, firstChat[keyResponseFinish].AsStringSlice())
+	assert.Contains(t, firstChat[keyInputMessages].AsString(), "Weather in Paris?")
+
+	tool := tel.span(t, "execute_tool weather")
+	assert.Equal(t, root.SpanContext.SpanID(), tool.Parent.SpanID())
+	toolAttrs := attributes(tool)
+	assert.Equal(t, "weather", toolAttrs[keyToolName].AsString())
+	assert.Equal(t, `{"city":"Paris"}`, toolAttrs[keyToolCallArguments].AsString())
+	assert.Equal(t, `"sunny"`, toolAttrs[keyToolCallResult].AsString())
+
+	durations := tel.histogram(t, "gen_ai.client.operation.duration")
+	require.Len(t, durations, 1)
+	assert.Equal(t, uint64(2), durations[0].Count)
+	assert.Equal(t, map[string]int64{"input": 30, "output": 8}, tel.tokenUsage(t))
+}
+
+func TestGenerateText_NoContentByDefault(t *testing.T) {
+	tel := newTestTelemetry(t)
+	model := mock.NewGenerateModel([]mock.MockResult{
+		{Response: &api.Response{Content: []api.ContentBlock{&api.TextBlock{Text: "Hi"}}}},
+	})
+
+	_, err := tel.GenerateTextStr(t.Context(), "Hello", ai.WithModel(model))
+	require.NoError(t, err)
+
+	for _, span := range tel.spans.GetSpans() {
+		attrs := attributes(span)
+		assert.NotContains(t, attrs, keyInputMessages, span.Name)
+		assert.NotContains(t, attrs, keyOutputMessages, span.Name)
+	}
+}
+
+func TestGenerateText_Error(t *testing.T) {
+	tel := newTestTelemetry(t)
+	model := mock.NewGenerateModel([]mock.MockResult{{Error: errors.New("boom")}})
+
+	_, err := tel.GenerateTextStr(t.Context(), "Hello", ai.WithModel(model), ai.WithMaxRetries(0))
+	require.Error(t, err)
+
+	for _, name := range []string{"generate_text mock-model", "chat mock-model"} {
+		span := tel.span(t, name)
+		assert.Equal(t, codes.Error, span.Status.Code, name)
+		assert.Equal(t, "*errors.errorString", attributes(span)[keyErrorType].AsString(), name)
+	}
+
+	durations := tel.histogram(t, "gen_ai.client.operation.duration")
+	require.Len(t, durations, 1)
+	errType, ok := durations[0].Attributes.Value(keyErrorType)
+	assert.True(t, ok)
+	assert.Equal(t, "*errors.errorString", errType.AsString())
+}
+
+func TestStreamText(t *testing.T) {
+	tel := newTestTelemetry(t)
+	model := mock.NewStreamModel([]mock.StreamResult{{Events: []api.StreamEvent{
+		&api.StreamStartEvent{},
+		&api.ResponseMetadataEvent{ID: "resp_1", ModelID: "claude-sonnet-4-0-20250514"},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.TextDeltaEvent{TextDelta: " world"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop, Usage: api.Usage{InputTokens: 7, OutputTokens: 2}},
+	}}}, mock.WithProviderName("anthropic"), mock.WithModelID("claude-sonnet-4-0"))
+
+	resp, err := tel.StreamTextStr(t.Context(), "Hello", ai.WithModel(model))
+	require.NoError(t, err)
+
+	// The spans end when the stream is consumed.
+	assert.Empty(t, tel.spans.GetSpans())
+	for range resp.Stream {
+	}
+	require.Len(t, tel.spans.GetSpans(), 2)
+
+	root := attributes(tel.span(t, "stream_text claude-sonnet-4-0"))
+	assert.Equal(t, "stream_text", root[keyOperationName].AsString())
+	assert.Equal(t, int64(7), root[keyUsageInputTokens].AsInt64())
+
+	chat := attributes(tel.span(t, "chat claude-sonnet-4-0"))
+	assert.Equal(t, "resp_1", chat[keyResponseID].AsString())
+	assert.Equal(t, "claude-sonnet-4-0-20250514", chat[keyResponseModel].AsString())
+	assert.Equal(t, int64(2), chat[keyUsageOutputTokens].AsInt64())
+	assert.Equal(t, []string{"stop"}, chat[keyResponseFinish].AsStringSlice())
+
+	ttft := tel.histogram(t, "gen_ai.client.time_to_first_token")
+	require.Len(t, ttft, 1)
+	assert.Equal(t, uint64(1), ttft[0].Count)
+	assert.Equal(t, map[string]int64{"input": 7, "output": 2}, tel.tokenUsage(t))
+}
+
+func TestStreamText_Stopped(t *testing.T) {
+	tel := newTestTelemetry(t)
+	model := mock.NewStreamModel([]mock.StreamResult{{Events: []api.StreamEvent{
+		&api.ResponseMetadataEvent{ID: "resp_1"},
+		&api.TextDeltaEvent{TextDelta: "Hello"},
+		&api.FinishEvent{FinishReason: api.FinishReasonStop, Usage: api.Usage{InputTokens: 7, OutputTokens: 2}},
+	}}})
+
+	resp, err := tel.StreamTextStr(t.Context(), "Hello", ai.WithModel(model))
+	require.NoError(t, err)
+	for event := range resp.Stream {
+		if _, ok := event.(*api.TextDeltaEvent); ok {
+			break
+		}
+	}
+
+	for _, name := range []string{"stream_text mock-model", "chat mock-model"} {
+		span := tel.span(t, name)
+		assert.Equal(t, codes.Unset, span.Status.Code, name)
+		attrs := attributes(span)
+		assert.True(t, attrs[keyStreamIncomplete].AsBool(), name)
+		assert.NotContains(t, attrs, keyUsageInputTokens, name)
+		assert.NotContains(t, attrs, keyResponseFinish, name)
+	}
+	assert.Equal(t, "resp_1", attributes(tel.span(t, "chat mock-model"))[keyResponseID].AsString())
+	assert.Empty(t, tel.tokenUsage(t))
+	assert.Len(t, tel.histogram(t, "gen_ai.client.operation.duration"), 1)
+}
+
+func TestStreamText_Error(t *testing.T) {
+	tel := newTestTelemetry(t)
+	model := mock.NewStreamModel([]mock.StreamResult{{Events: []api.StreamEvent{
+		&api.TextDeltaEvent{TextDelta: "Hel"},
+		&api.ErrorEvent{Err: errors.New("connection reset")},
+	}}})
+
+	resp, err := tel.StreamTextStr(t.Context(), "Hello", ai.WithModel(model))
+	require.NoError(t, err)
+	for range resp.Stream {
+	}
+
+	chat := tel.span(t, "chat mock-model")
+	assert.Equal(t, codes.Error, chat.Status.Code)
+	assert.Equal(t, "connection reset", chat.Status.Description)
+	assert.Equal(t, codes.Error, tel.span(t, "stream_text mock-model").Status.Code)
+}
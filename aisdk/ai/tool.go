@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"go.jetify.com/ai/api"
+)
+
+// ToolFunc executes a tool call requested by the model.
+//
+// The args parameter contains the JSON arguments generated by the model. The
+// returned value is sent back to the model as the tool result: it must be
+// JSON-serializable, or a []api.ContentBlock for rich results (e.g. images).
+//
+// If ToolFunc returns an error, the error message is sent back to the model as
+// an error result so that it can try to recover.
+type ToolFunc func(ctx context.Context, args json.RawMessage) (any, error)
+
+// Tool is a function tool that the SDK can execute on behalf of the model.
+// It pairs the tool definition sent to the model with the Go function that
+// handles its calls.
+type Tool struct {
+	// Definition is the tool definition sent to the model.
+	Definition *api.FunctionTool
+
+	// Execute is called whenever the model calls the tool.
+	Execute ToolFunc
+}
+
+// NewTool creates a new executable tool.
+func NewTool(name, description string, inputSchema *jsonschema.Schema, execute ToolFunc) *Tool {
+	return &Tool{
+		Definition: &api.FunctionTool{
+			Name:        name,
+			Description: description,
+			InputSchema: inputSchema,
+		},
+		Execute: execute,
+	}
+}
+
+// Name returns the name of the tool.
+func (t *Tool) Name() string {
+	return t.Definition.Name
+}
+
+// toolCalls returns the tool call blocks in the given content.
+func toolCalls(content []api.ContentBlock) []*api.ToolCallBlock {
+	var calls []*api.ToolCallBlock
+	for _, block := range content {
+		if call, ok := block.(*api.ToolCallBlock); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// executeToolCalls runs the given tool calls concurrently and returns their
+// results in the same order as the calls. The boolean result is false if
+// any of the valid calls does not have a matching executable tool, e.g. a
+// tool that is executed by the client, in which case no tools are executed
+// and the caller is expected to handle the calls.
+//
+// Calls that failed validation, including calls to tools that don't exist,
+// are not executed: their result is the validation error, so that the model
+// can correct them in the next step.
+func executeToolCalls(
+	ctx context.Context, calls []*api.ToolCallBlock, tools []*Tool, invalid map[*api.ToolCallBlock]error,
+) ([]api.ToolResultBlock, bool) {
+	byName := make(map[string]*Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name()] = tool
+	}
+	for _, call := range calls {
+		if _, ok := invalid[call]; ok {
+			continue
+		}
+		if _, ok := byName[call.ToolName]; !ok {
+			return nil, false
+		}
+	}
+
+	results := make([]api.ToolResultBlock, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		if err, ok := invalid[call]; ok {
+			results[i] = api.ToolResultBlock{
+				ToolCallID: call.ToolCallID,
+				ToolName:   call.ToolName,
+				Result:     err.Error(),
+				IsError:    true,
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = executeToolCall(ctx, call, byName[call.ToolName])
+		}()
+	}
+	wg.Wait()
+	return results, true
+}
+
+// executeToolCall runs a single tool call and converts its output into a
+// tool result block.
+func executeToolCall(ctx context.Context, call *api.ToolCallBlock, tool *Tool) (result api.ToolResultBlock) {
+	result = api.ToolResultBlock{
+		ToolCallID: call.ToolCallID,
+		ToolName:   call.ToolName,
+	}
+
+	// A panicking tool should not take down the whole generation loop.
+	defer func() {
+		if r := recover(); r != nil {
+			result.Result = fmt.Sprintf("tool %q panicked: %v", call.ToolName, r)
+			result.IsError = true
+		}
+	}()
+
+	output, err := tool.Execute(ctx, call.Args)
+	if err != nil {
+		result.Result = err.Error()
+		result.IsError = true
+		return result
+	}
+
+	if content, ok := output.([]api.ContentBlock); ok {
+		result.Content = content
+	} else {
+		result.Result = output
+	}
+	return result
+}
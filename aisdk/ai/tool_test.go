@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.jetify.com/ai/api"
+)
+
+func TestExecuteToolCalls(t *testing.T) {
+	echo := NewTool("echo", "", nil, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return string(args), nil
+	})
+	fail := NewTool("fail", "", nil, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return nil, errors.New("tool failed")
+	})
+	panics := NewTool("panics", "", nil, func(ctx context.Context, args json.RawMessage) (any, error) {
+		panic("oops")
+	})
+	image := NewTool("image", "", nil, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return []api.ContentBlock{api.ImageBlockFromURL("https://example.com/cat.png")}, nil
+	})
+	tools := []*Tool{echo, fail, panics, image}
+	invalidCall := &api.ToolCallBlock{ToolCallID: "2", ToolName: "echo", Args: json.RawMessage(`{`)}
+	unknownCall := &api.ToolCallBlock{ToolCallID: "2", ToolName: "unknown"}
+
+	tests := []struct {
+		name     string
+		calls    []*api.ToolCallBlock
+		invalid  map[*api.ToolCallBlock]error
+		want     []api.ToolResultBlock
+		executed bool
+	}{
+		{
+			name: "results keep the order of the calls",
+			calls: []*api.ToolCallBlock{
+				{ToolCallID: "1", ToolName: "echo", Args: json.RawMessage(`"a"`)},
+				{ToolCallID: "2", ToolName: "echo", Args: json.RawMessage(`"b"`)},
+			},
+			want: []api.ToolResultBlock{
+				{ToolCallID: "1", ToolName: "echo", Result: `"a"`},
+				{ToolCallID: "2", ToolName: "echo", Result: `"b"`},
+			},
+			executed: true,
+		},
+		{
+			name: "errors and panics become error results",
+			calls: []*api.ToolCallBlock{
+				{ToolCallID: "1", ToolName: "fail"},
+				{ToolCallID: "2", ToolName: "panics"},
+			},
+			want: []api.ToolResultBlock{
+				{ToolCallID: "1", ToolName: "fail", Result: "tool failed", IsError: true},
+				{ToolCallID: "2", ToolName: "panics", Result: `tool "panics" panicked: oops`, IsError: true},
+			},
+			executed: true,
+		},
+		{
+			name: "content block results",
+			calls: []*api.ToolCallBlock{
+				{ToolCallID: "1", ToolName: "image"},
+			},
+			want: []api.ToolResultBlock{
+				{
+					ToolCallID: "1",
+					ToolName:   "image",
+					Content:    []api.ContentBlock{api.ImageBlockFromURL("https://example.com/cat.png")},
+				},
+			},
+			executed: true,
+		},
+		{
+			name: "invalid calls are not executed",
+			calls: []*api.ToolCallBlock{
+				{ToolCallID: "1", ToolName: "echo", Args: json.RawMessage(`"a"`)},
+				invalidCall,
+			},
+			invalid: map[*api.ToolCallBlock]error{invalidCall: errors.New("invalid arguments")},
+			want: []api.ToolResultBlock{
+				{ToolCallID: "1", ToolName: "echo", Result: `"a"`},
+				{ToolCallID: "2", ToolName: "echo", Result: "invalid arguments", IsError: true},
+			},
+			executed: true,
+		},
+		{
+			name: "tool without an executable is not executed",
+			calls: []*api.ToolCallBlock{
+				{ToolCallID: "1", ToolName: "echo"},
+				{ToolCallID: "2", ToolName: "client_side_tool"},
+			},
+			executed: false,
+		},
+		{
+			name: "invalid call to an unknown tool",
+			calls: []*api.ToolCallBlock{
+				{ToolCallID: "1", ToolName: "echo", Args: json.RawMessage(`"a"`)},
+				unknownCall,
+			},
+			invalid: map[*api.ToolCallBlock]error{unknownCall: errors.New("no such tool")},
+			want: []api.ToolResultBlock{
+				{ToolCallID: "1", ToolName: "echo", Result: `"a"`},
+				{ToolCallID: "2", ToolName: "unknown", Result: "no such tool", IsError: true},
+			},
+			executed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, executed := executeToolCalls(t.Context(), tt.calls, tools, tt.invalid)
+			assert.Equal(t, tt.executed, executed)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.jetify.com/ai/api"
+)
+
+// ToolCallRepairRequest describes a tool call generated by the model that
+// failed validation.
+type ToolCallRepairRequest struct {
+	// Call is the invalid tool call.
+	Call *api.ToolCallBlock
+
+	// Tools are the tools that were available to the model.
+	Tools []api.ToolDefinition
+
+	// Prompt is the prompt that was sent to the model.
+	Prompt []api.Message
+
+	// Err is the validation error: an [api.InvalidToolArgumentsError] or an
+	// [api.NoSuchToolError].
+	Err error
+}
+
+// ToolCallRepairFunc fixes a tool call that failed validation. It returns the
+// repaired call, which is validated again, or nil if the call can't be
+// repaired, in which case the validation error is returned to the caller.
+type ToolCallRepairFunc func(ctx context.Context, request ToolCallRepairRequest) (*api.ToolCallBlock, error)
+
+// WithToolCallRepair sets a function that repairs the tool calls generated by
+// the model that fail validation, before they are returned or executed.
+//
+// Calls that are still invalid are handled as if there was no repair
+// function: when executable tools are provided, an invalid call is not
+// executed, and its validation error is sent back to the model as an error
+// result so that it can correct the call in the next step. Without
+// executable tools, GenerateText and StreamText fail with an
+// [api.InvalidToolArgumentsError] or an [api.NoSuchToolError]. See
+// [RepairToolCallWithModel] for a repair function that asks a model to fix
+// the arguments.
+//
+// StreamText validates the tool calls once the step has been streamed: the
+// tool call events are forwarded as generated by the model, and a validation
+// error is reported as an [api.ErrorEvent]. The repaired calls are available in
+// the steps of the response.
+func WithToolCallRepair(repair ToolCallRepairFunc) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.RepairToolCall = repair
+	}
+}
+
+// WithoutToolCallValidation disables the validation of the tool calls
+// generated by the model: they are returned, and executed, as generated.
+// It is useful to evaluate the tool calls of a model, e.g. with the eval
+// package, where invalid calls should be scored instead of failing the call.
+func WithoutToolCallValidation() GenerateOption {
+	return func(o *GenerateOptions) {
+		o.SkipToolCallValidation = true
+	}
+}
+
+// ValidateToolCall checks that the tool call refers to one of the tools, and
+// that its arguments match the input schema of the tool. It returns an
+// [api.NoSuchToolError] or an [api.InvalidToolArgumentsError] otherwise.
+//
+// Empty arguments are treated as an empty object. Calls to provider-defined
+// tools are not validated, since their schema is only known to the provider.
+func ValidateToolCall(call *api.ToolCallBlock, tools []api.ToolDefinition) error {
+	var names []string
+	for _, tool := range tools {
+		switch t := tool.(type) {
+		case *api.FunctionTool:
+			if t.Name == call.ToolName {
+				return validateToolArgs(call, t)
+			}
+			names = append(names, t.Name)
+		case *api.ProviderDefinedTool:
+			if t.Name == call.ToolName {
+				return nil
+			}
+			names = append(names, t.Name)
+		}
+	}
+	return api.NewNoSuchToolError(call.ToolName, names)
+}
+
+func validateToolArgs(call *api.ToolCallBlock, tool *api.FunctionTool) error {
+	args := call.Args
+	if len(bytes.TrimSpace(args)) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	var value any
+	if err := json.Unmarshal(args, &value); err != nil {
+		return api.NewInvalidToolArgumentsError(call.ToolName, call.Args, api.NewJSONParseError(string(args), err))
+	}
+	if tool.InputSchema == nil {
+		return nil
+	}
+	resolved, err := tool.InputSchema.Resolve(nil)
+	if err != nil {
+		// Schemas that the validator doesn't support are left to the
+		// provider and the tool itself.
+		return nil
+	}
+	if err := resolved.Validate(value); err != nil {
+		return api.NewInvalidToolArgumentsError(call.ToolName, call.Args, err)
+	}
+	return nil
+}
+
+// validateToolCalls validates the tool calls of a response, repairing the
+// invalid ones with the repair function if there is one. It returns a copy of
+// the response with the repaired calls, and the validation errors of the calls
+// that are still invalid, by call. An error is only returned if the repair
+// function fails.
+func validateToolCalls(
+	ctx context.Context, resp *api.Response, step *Step, repair ToolCallRepairFunc,
+) (*api.Response, map[*api.ToolCallBlock]error, error) {
+	var content []api.ContentBlock
+	var invalid map[*api.ToolCallBlock]error
+	for i, block := range resp.Content {
+		call, ok := block.(*api.ToolCallBlock)
+		if !ok {
+			continue
+		}
+		err := ValidateToolCall(call, step.CallOptions.Tools)
+		if err == nil {
+			continue
+		}
+
+		if repair != nil {
+			repaired, repairErr := repair(ctx, ToolCallRepairRequest{
+				Call:   call,
+				Tools:  step.CallOptions.Tools,
+				Prompt: step.Prompt,
+				Err:    err,
+			})
+			if repairErr != nil {
+				return nil, nil, repairErr
+			}
+			if repaired != nil {
+				err = ValidateToolCall(repaired, step.CallOptions.Tools)
+			}
+			if repaired != nil && err == nil {
+				if content == nil {
+					content = slices.Clone(resp.Content)
+				}
+				content[i] = repaired
+				continue
+			}
+		}
+
+		if invalid == nil {
+			invalid = map[*api.ToolCallBlock]error{}
+		}
+		invalid[call] = err
+	}
+
+	if content == nil {
+		return resp, invalid, nil
+	}
+	result := *resp
+	result.Content = content
+	return &result, invalid, nil
+}
+
+// RepairToolCallWithModel returns a ToolCallRepairFunc that asks the given
+// model to fix the arguments of invalid tool calls, by sending it the invalid
+// arguments, the validation error and the input schema of the tool. Calls to
+// unknown tools are not repaired.
+func RepairToolCallWithModel(model api.LanguageModel) ToolCallRepairFunc {
+	return func(ctx context.Context, request ToolCallRepairRequest) (*api.ToolCallBlock, error) {
+		var argsErr *api.InvalidToolArgumentsError
+		if !errors.As(request.Err, &argsErr) {
+			return nil, nil
+		}
+		var tool *api.FunctionTool
+		for _, definition := range request.Tools {
+			if t, ok := definition.(*api.FunctionTool); ok && t.Name == request.Call.ToolName {
+				tool = t
+			}
+		}
+		if tool == nil {
+			return nil, nil
+		}
+
+		schema, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return nil, err
+		}
+		prompt := fmt.Sprintf(
+			"The model tried to call the tool %q with the following arguments:\n%s\n\n"+
+				"The arguments are invalid: %v\n\n"+
+				"Respond with only the corrected arguments, as a JSON object that matches this schema:\n%s",
+			tool.Name, request.Call.Args, argsErr.Cause, schema)
+		resp, err := model.Generate(ctx, []api.Message{
+			&api.UserMessage{Content: api.ContentFromText(prompt)},
+		}, api.CallOptions{
+			ResponseFormat: &api.ResponseFormat{Type: "json", Schema: tool.InputSchema, Name: tool.Name},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var text strings.Builder
+		for _, block := range resp.Content {
+			if textBlock, ok := block.(*api.TextBlock); ok {
+				text.WriteString(textBlock.Text)
+			}
+		}
+		repaired := *request.Call
+		repaired.Args = json.RawMessage(text.String())
+		return &repaired, nil
+	}
+}
@@ -0,0 +1,259 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+	"go.jetify.com/ai/provider/mock"
+)
+
+var searchTool = &api.FunctionTool{
+	Name: "search",
+	InputSchema: &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"query": {Type: "string"},
+			"limit": {Type: "integer"},
+		},
+		Required: []string{"query"},
+	},
+}
+
+func TestValidateToolCall(t *testing.T) {
+	tools := []api.ToolDefinition{
+		searchTool,
+		&api.FunctionTool{Name: "now"},
+		&api.ProviderDefinedTool{ID: "openai.web_search", Name: "web_search"},
+	}
+
+	tests := []struct {
+		name    string
+		call    *api.ToolCallBlock
+		wantErr any
+	}{
+		{
+			name: "valid arguments",
+			call: &api.ToolCallBlock{ToolName: "search", Args: json.RawMessage(`{"query":"cats","limit":3}`)},
+		},
+		{
+			name: "empty arguments without schema",
+			call: &api.ToolCallBlock{ToolName: "now"},
+		},
+		{
+			name: "provider-defined tool",
+			call: &api.ToolCallBlock{ToolName: "web_search", Args: json.RawMessage(`not json`)},
+		},
+		{
+			name:    "malformed JSON",
+			call:    &api.ToolCallBlock{ToolName: "search", Args: json.RawMessage(`{"query":`)},
+			wantErr: new(*api.JSONParseError),
+		},
+		{
+			name:    "missing required field",
+			call:    &api.ToolCallBlock{ToolName: "search", Args: json.RawMessage(`{"limit":3}`)},
+			wantErr: new(*api.InvalidToolArgumentsError),
+		},
+		{
+			name:    "wrong type",
+			call:    &api.ToolCallBlock{ToolName: "search", Args: json.RawMessage(`{"query":"cats","limit":"3"}`)},
+			wantErr: new(*api.InvalidToolArgumentsError),
+		},
+		{
+			name:    "empty arguments with required fields",
+			call:    &api.ToolCallBlock{ToolName: "search"},
+			wantErr: new(*api.InvalidToolArgumentsError),
+		},
+		{
+			name:    "unknown tool",
+			call:    &api.ToolCallBlock{ToolName: "delete_files", Args: json.RawMessage(`{}`)},
+			wantErr: new(*api.NoSuchToolError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToolCall(tt.call, tools)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorAs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateToolCall_NoSuchTool(t *testing.T) {
+	err := ValidateToolCall(&api.ToolCallBlock{ToolName: "delete_files"}, []api.ToolDefinition{searchTool})
+
+	var noSuchTool *api.NoSuchToolError
+	require.ErrorAs(t, err, &noSuchTool)
+	assert.Equal(t, "delete_files", noSuchTool.ToolName)
+	assert.Equal(t, []string{"search"}, noSuchTool.AvailableTools)
+	assert.EqualError(t, err,
+		"Model tried to call unavailable tool 'delete_files'. Available tools: search.")
+}
+
+func invalidSearchResponse() *api.Response {
+	return &api.Response{
+		Content: []api.ContentBlock{
+			&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{"q":"cats"}`)},
+		},
+		FinishReason: api.FinishReasonToolCalls,
+	}
+}
+
+func TestGenerateText_InvalidToolCall(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{{Response: invalidSearchResponse()}})
+
+	_, err := GenerateTextStr(t.Context(), "Find cats", WithModel(model), WithTools(searchTool))
+
+	var argsErr *api.InvalidToolArgumentsError
+	require.ErrorAs(t, err, &argsErr)
+	assert.Equal(t, "search", argsErr.ToolName)
+	assert.JSONEq(t, `{"q":"cats"}`, string(argsErr.ToolArgs))
+}
+
+func TestGenerateText_WithoutToolCallValidation(t *testing.T) {
+	model := mock.NewGenerateModel([]mock.MockResult{{Response: invalidSearchResponse()}})
+
+	resp, err := GenerateTextStr(t.Context(), "Find cats", WithModel(model), WithTools(searchTool), WithoutToolCallValidation())
+	require.NoError(t, err)
+	assert.Equal(t, invalidSearchResponse().Content, resp.Content)
+}
+
+func TestGenerateText_RepairToolCall(t *testing.T) {
+	var executedArgs json.RawMessage
+	search := &Tool{
+		Definition: searchTool,
+		Execute: func(ctx context.Context, args json.RawMessage) (any, error) {
+			executedArgs = args
+			return "3 results", nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		repair     ToolCallRepairFunc
+		wantArgs   string
+		wantResult string
+		errIs      error
+	}{
+		{
+			name: "repaired",
+			repair: func(ctx context.Context, request ToolCallRepairRequest) (*api.ToolCallBlock, error) {
+				call := *request.Call
+				call.Args = json.RawMessage(`{"query":"cats"}`)
+				return &call, nil
+			},
+			wantArgs: `{"query":"cats"}`,
+		},
+		{
+			name: "not repaired",
+			repair: func(ctx context.Context, request ToolCallRepairRequest) (*api.ToolCallBlock, error) {
+				return nil, nil
+			},
+			wantArgs:   `{"q":"cats"}`,
+			wantResult: `Invalid arguments for tool search`,
+		},
+		{
+			name: "still invalid after repair",
+			repair: func(ctx context.Context, request ToolCallRepairRequest) (*api.ToolCallBlock, error) {
+				call := *request.Call
+				call.ToolName = "find"
+				return &call, nil
+			},
+			wantArgs:   `{"q":"cats"}`,
+			wantResult: `unavailable tool 'find'`,
+		},
+		{
+			name: "repair fails",
+			repair: func(ctx context.Context, request ToolCallRepairRequest) (*api.ToolCallBlock, error) {
+				return nil, context.DeadlineExceeded
+			},
+			errIs: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executedArgs = nil
+			model := mock.NewGenerateModel([]mock.MockResult{
+				{Response: invalidSearchResponse()},
+				{Response: textResponse("Found 3 cats.", api.Usage{})},
+			})
+
+			resp, err := GenerateTextSteps(t.Context(), userPrompt("Find cats"),
+				WithModel(model),
+				WithExecutableTools(search),
+				WithToolCallRepair(tt.repair),
+			)
+			if tt.errIs != nil {
+				require.ErrorIs(t, err, tt.errIs)
+				return
+			}
+			require.NoError(t, err)
+			model.AssertCount(t)
+			if tt.wantResult != "" {
+				// Calls that are still invalid are not executed, and the
+				// validation error is sent back to the model.
+				assert.Nil(t, executedArgs)
+				require.Len(t, resp.Steps[0].ToolResults, 1)
+				result := resp.Steps[0].ToolResults[0]
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Result, tt.wantResult)
+			} else {
+				assert.JSONEq(t, tt.wantArgs, string(executedArgs))
+			}
+
+			// The repaired call is recorded in the step and sent back to the
+			// model.
+			require.Len(t, resp.Steps, 2)
+			calls := resp.Steps[0].ToolCalls()
+			require.Len(t, calls, 1)
+			assert.JSONEq(t, tt.wantArgs, string(calls[0].Args))
+			assistant := model.Calls()[1].Prompt[1].(*api.AssistantMessage)
+			assert.Equal(t, calls[0], assistant.Content[0])
+		})
+	}
+}
+
+func TestRepairToolCallWithModel(t *testing.T) {
+	repairModel := mock.NewGenerateModel([]mock.MockResult{
+		{Response: textResponse(`{"query":"cats"}`, api.Usage{})},
+	})
+	repair := RepairToolCallWithModel(repairModel)
+	call := &api.ToolCallBlock{ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{"q":"cats"}`)}
+	tools := []api.ToolDefinition{searchTool}
+
+	repaired, err := repair(t.Context(), ToolCallRepairRequest{
+		Call:  call,
+		Tools: tools,
+		Err:   ValidateToolCall(call, tools),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &api.ToolCallBlock{
+		ToolCallID: "call_1", ToolName: "search", Args: json.RawMessage(`{"query":"cats"}`),
+	}, repaired)
+
+	calls := repairModel.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, searchTool.InputSchema, calls[0].Options.ResponseFormat.Schema)
+	prompt := calls[0].Prompt[0].(*api.UserMessage).Content[0].(*api.TextBlock).Text
+	assert.Contains(t, prompt, `{"q":"cats"}`)
+	assert.Contains(t, prompt, "query")
+
+	// Calls to unknown tools are not repaired.
+	repaired, err = repair(t.Context(), ToolCallRepairRequest{
+		Call: &api.ToolCallBlock{ToolName: "find"},
+		Err:  errors.New("unknown"),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, repaired)
+	repairModel.AssertCount(t)
+}
@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"go.jetify.com/ai/api"
+)
+
+// TokenCounter counts the tokens of a prompt, as seen by a model.
+//
+// The language models of the anthropic, openai, openaicompat and openrouter
+// providers implement it: anthropic.LanguageModel uses Anthropic's token
+// counting API, and the others estimate the tokens with an
+// api.TokenEstimator tuned to the provider.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, prompt []api.Message) (int, error)
+}
+
+// TokenCounterFunc adapts a function to the TokenCounter interface.
+type TokenCounterFunc func(ctx context.Context, prompt []api.Message) (int, error)
+
+func (f TokenCounterFunc) CountTokens(ctx context.Context, prompt []api.Message) (int, error) {
+	return f(ctx, prompt)
+}
+
+// HeuristicTokenCounter is a TokenCounter that estimates the tokens of a
+// prompt without calling a tokenizer, using api.DefaultTokenEstimator: text is
+// counted as 4 characters per token, and images and non-text files at a fixed
+// cost. It is fast but only approximate, so leave some margin in token
+// budgets.
+var HeuristicTokenCounter TokenCounter = TokenCounterFunc(
+	func(ctx context.Context, prompt []api.Message) (int, error) {
+		return api.DefaultTokenEstimator.Estimate(prompt), nil
+	},
+)
+
+// TrimOptions configures TrimMessages.
+type TrimOptions struct {
+	// MaxTokens is the token budget of the trimmed prompt. Leave room for the
+	// tools and the output of the model. Required.
+	MaxTokens int
+
+	// Counter counts the tokens of the prompt. Defaults to
+	// HeuristicTokenCounter.
+	Counter TokenCounter
+
+	// Summarize, if set, is called with the messages that are removed, and
+	// returns a message that replaces them at the start of the conversation,
+	// e.g. a summary generated by a model. If the summary doesn't fit in the
+	// budget, more messages are removed: the number of removed turns is
+	// binary searched, so it is called a logarithmic number of times.
+	Summarize func(ctx context.Context, removed []api.Message) (api.Message, error)
+}
+
+// TrimResult is the result of TrimMessages.
+type TrimResult struct {
+	// Messages is the trimmed prompt.
+	Messages []api.Message
+
+	// Removed contains the messages that were removed, in order. It is empty
+	// if the prompt already fit in the budget.
+	Removed []api.Message
+
+	// Summary is the message returned by TrimOptions.Summarize to replace the
+	// removed messages, or nil if none was added.
+	Summary api.Message
+
+	// Tokens is the number of tokens of the trimmed prompt, according to the
+	// token counter.
+	Tokens int
+}
+
+// TrimMessages removes the oldest turns of a conversation until the prompt
+// fits in a token budget, so that long-running chats don't overflow the
+// context window of the model.
+//
+// System messages are always kept, at the start of the prompt. The rest of
+// the conversation is split into turns that start with a user message, and
+// whole turns are removed, so that tool calls are never separated from their
+// results and the remaining conversation still starts with a user message.
+// The last turn is always kept: if it doesn't fit in the budget by itself, an
+// InvalidPromptError is returned. If a summary doesn't fit even when all the
+// other turns are removed, it is omitted.
+//
+//	result, err := ai.TrimMessages(ctx, history, ai.TrimOptions{
+//		MaxTokens: info.ContextWindow - info.MaxOutputTokens,
+//		Counter:   anthropic.NewLanguageModel(anthropic.ModelClaudeSonnet4_0),
+//	})
+func TrimMessages(ctx context.Context, messages []api.Message, options TrimOptions) (*TrimResult, error) {
+	if options.MaxTokens <= 0 {
+		return nil, api.NewInvalidArgumentError("MaxTokens must be greater than zero", "MaxTokens", nil)
+	}
+	counter := options.Counter
+	if counter == nil {
+		counter = HeuristicTokenCounter
+	}
+
+	tokens, err := counter.CountTokens(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	if tokens <= options.MaxTokens {
+		return &TrimResult{Messages: messages, Tokens: tokens}, nil
+	}
+
+	system, turns := splitTurns(messages)
+	if len(turns) <= 1 {
+		return nil, trimError(messages, tokens, options.MaxTokens)
+	}
+
+	// The number of tokens decreases as more turns are removed, so binary
+	// search the minimum number of turns to remove. This keeps the number of
+	// calls low for counters that call the provider.
+	fits := func(dropped int, summary api.Message) (bool, int, error) {
+		tokens, err := counter.CountTokens(ctx, keptMessages(system, summary, turns[dropped:]))
+		return tokens <= options.MaxTokens, tokens, err
+	}
+	low, high := 1, len(turns)-1
+	for low < high {
+		mid := (low + high) / 2
+		ok, _, err := fits(mid, nil)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	if options.Summarize == nil {
+		ok, tokens, err := fits(low, nil)
+		if err != nil {
+			return nil, err
+		}
+		kept := keptMessages(system, nil, turns[low:])
+		if !ok {
+			return nil, trimError(kept, tokens, options.MaxTokens)
+		}
+		return &TrimResult{Messages: kept, Removed: flattenTurns(turns[:low]), Tokens: tokens}, nil
+	}
+
+	// A summary only adds tokens, so at least as many turns have to be
+	// removed as without one. The search continues from there, calling
+	// Summarize once per step instead of once per turn.
+	var result *TrimResult
+	for high := len(turns) - 1; low <= high; {
+		mid := (low + high) / 2
+		removed := flattenTurns(turns[:mid])
+		summary, err := options.Summarize(ctx, removed)
+		if err != nil {
+			return nil, err
+		}
+		ok, tokens, err := fits(mid, summary)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = &TrimResult{
+				Messages: keptMessages(system, summary, turns[mid:]),
+				Removed:  removed,
+				Summary:  summary,
+				Tokens:   tokens,
+			}
+			high = mid - 1
+		} else {
+			low = mid + 1
+		}
+	}
+	if result != nil {
+		return result, nil
+	}
+
+	dropped := len(turns) - 1
+	ok, tokens, err := fits(dropped, nil)
+	if err != nil {
+		return nil, err
+	}
+	last := keptMessages(system, nil, turns[dropped:])
+	if !ok {
+		return nil, trimError(last, tokens, options.MaxTokens)
+	}
+	return &TrimResult{Messages: last, Removed: flattenTurns(turns[:dropped]), Tokens: tokens}, nil
+}
+
+func trimError(prompt []api.Message, tokens, maxTokens int) error {
+	return api.NewInvalidPromptError(prompt, fmt.Sprintf(
+		"the last turn of the conversation has %d tokens, which exceeds the budget of %d tokens",
+		tokens, maxTokens), nil)
+}
+
+// splitTurns separates the system messages from the rest of the conversation,
+// which is split into turns that start with a user message. Messages before
+// the first user message form their own turn.
+func splitTurns(messages []api.Message) ([]api.Message, [][]api.Message) {
+	var system []api.Message
+	var turns [][]api.Message
+	for _, msg := range messages {
+		switch msg.(type) {
+		case *api.SystemMessage:
+			system = append(system, msg)
+			continue
+		case *api.UserMessage:
+			turns = append(turns, nil)
+		}
+		if len(turns) == 0 {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], msg)
+	}
+	return system, turns
+}
+
+func flattenTurns(turns [][]api.Message) []api.Message {
+	var messages []api.Message
+	for _, turn := range turns {
+		messages = append(messages, turn...)
+	}
+	return messages
+}
+
+func keptMessages(system []api.Message, summary api.Message, turns [][]api.Message) []api.Message {
+	messages := append([]api.Message{}, system...)
+	if summary != nil {
+		messages = append(messages, summary)
+	}
+	return append(messages, flattenTurns(turns)...)
+}
@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.jetify.com/ai/api"
+)
+
+// messageCounter counts every message as 10 tokens.
+var messageCounter = TokenCounterFunc(func(ctx context.Context, prompt []api.Message) (int, error) {
+	return 10 * len(prompt), nil
+})
+
+func TestTrimMessages(t *testing.T) {
+	system := &api.SystemMessage{Content: "You are helpful."}
+	user1 := &api.UserMessage{Content: api.ContentFromText("What's the weather?")}
+	call := &api.AssistantMessage{Content: []api.ContentBlock{
+		&api.ToolCallBlock{ToolCallID: "call_1", ToolName: "weather", Args: json.RawMessage(`{}`)},
+	}}
+	result := &api.ToolMessage{Content: []api.ToolResultBlock{
+		{ToolCallID: "call_1", ToolName: "weather", Result: "sunny"},
+	}}
+	answer1 := &api.AssistantMessage{Content: api.ContentFromText("It's sunny.")}
+	user2 := &api.UserMessage{Content: api.ContentFromText("And tomorrow?")}
+	answer2 := &api.AssistantMessage{Content: api.ContentFromText("Rainy.")}
+	user3 := &api.UserMessage{Content: api.ContentFromText("Thanks!")}
+
+	history := []api.Message{system, user1, call, result, answer1, user2, answer2, user3}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		expected  []api.Message
+		removed   []api.Message
+		errMsg    string
+	}{
+		{
+			name:      "fits",
+			maxTokens: 80,
+			expected:  history,
+		},
+		{
+			name:      "drops the oldest turn with its tool call and result",
+			maxTokens: 70,
+			expected:  []api.Message{system, user2, answer2, user3},
+			removed:   []api.Message{user1, call, result, answer1},
+		},
+		{
+			name:      "drops all but the last turn",
+			maxTokens: 20,
+			expected:  []api.Message{system, user3},
+			removed:   []api.Message{user1, call, result, answer1, user2, answer2},
+		},
+		{
+			name:      "last turn doesn't fit",
+			maxTokens: 10,
+			errMsg:    "the last turn of the conversation has 20 tokens, which exceeds the budget of 10 tokens",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, err := TrimMessages(t.Context(), history, TrimOptions{
+				MaxTokens: tt.maxTokens,
+				Counter:   messageCounter,
+			})
+			if tt.errMsg != "" {
+				var promptErr *api.InvalidPromptError
+				require.ErrorAs(t, err, &promptErr)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, trimmed.Messages)
+			assert.Equal(t, tt.removed, trimmed.Removed)
+			assert.Equal(t, 10*len(tt.expected), trimmed.Tokens)
+			assert.Nil(t, trimmed.Summary)
+		})
+	}
+}
+
+func TestTrimMessages_Summarize(t *testing.T) {
+	turn := func(text string) []api.Message {
+		return []api.Message{
+			&api.UserMessage{Content: api.ContentFromText(text)},
+			&api.AssistantMessage{Content: api.ContentFromText("ok")},
+		}
+	}
+	var history []api.Message
+	for _, text := range []string{"one", "two", "three", "four"} {
+		history = append(history, turn(text)...)
+	}
+
+	var summarized [][]api.Message
+	trimmed, err := TrimMessages(t.Context(), history, TrimOptions{
+		MaxTokens: 40,
+		Counter:   messageCounter,
+		Summarize: func(ctx context.Context, removed []api.Message) (api.Message, error) {
+			summarized = append(summarized, removed)
+			return &api.SystemMessage{Content: "Summary"}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	// Dropping two turns fits without the summary, but not with it.
+	require.Len(t, summarized, 2)
+	assert.Equal(t, history[:4], summarized[0])
+	assert.Equal(t, history[:6], summarized[1])
+
+	assert.Equal(t, &api.SystemMessage{Content: "Summary"}, trimmed.Summary)
+	assert.Equal(t, append([]api.Message{trimmed.Summary}, history[6:]...), trimmed.Messages)
+	assert.Equal(t, history[:6], trimmed.Removed)
+	assert.Equal(t, 30, trimmed.Tokens)
+}
+
+func TestTrimMessages_SummarizeLongConversation(t *testing.T) {
+	var history []api.Message
+	for range 100 {
+		history = append(history,
+			&api.UserMessage{Content: api.ContentFromText("question")},
+			&api.AssistantMessage{Content: api.ContentFromText("answer")},
+		)
+	}
+
+	calls := 0
+	trimmed, err := TrimMessages(t.Context(), history, TrimOptions{
+		MaxTokens: 1000,
+		Counter:   messageCounter,
+		Summarize: func(ctx context.Context, removed []api.Message) (api.Message, error) {
+			calls++
+			return &api.SystemMessage{Content: "Summary"}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	// 50 turns fit without the summary, 49 with it.
+	assert.Len(t, trimmed.Removed, 2*51)
+	assert.Equal(t, 990, trimmed.Tokens)
+	assert.LessOrEqual(t, calls, 7, "Summarize is called once per step of the search")
+}
+
+func TestTrimMessages_InvalidBudget(t *testing.T) {
+	_, err := TrimMessages(t.Context(), nil, TrimOptions{})
+	var argErr *api.InvalidArgumentError
+	assert.ErrorAs(t, err, &argErr)
+}
+
+func TestHeuristicTokenCounter(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   []api.Message
+		expected int
+	}{
+		{
+			name:     "empty",
+			expected: 0,
+		},
+		{
+			name:     "system message",
+			prompt:   []api.Message{&api.SystemMessage{Content: strings.Repeat("a", 40)}},
+			expected: 4 + 10,
+		},
+		{
+			name: "text and image",
+			prompt: []api.Message{&api.UserMessage{Content: []api.ContentBlock{
+				&api.TextBlock{Text: "abcdefg"},
+				&api.ImageBlock{URL: "https://example.com/cat.png"},
+			}}},
+			expected: 4 + 2 + 1_000,
+		},
+		{
+			name: "tool call and result",
+			prompt: []api.Message{
+				&api.AssistantMessage{Content: []api.ContentBlock{
+					&api.ToolCallBlock{ToolName: "weather", Args: json.RawMessage(`{"city":"Paris"}`)},
+				}},
+				&api.ToolMessage{Content: []api.ToolResultBlock{
+					{ToolName: "weather", Result: map[string]any{"temp": 20}},
+				}},
+			},
+			expected: 4 + 2 + 4 + 4 + 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := HeuristicTokenCounter.CountTokens(t.Context(), tt.prompt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}